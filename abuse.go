@@ -0,0 +1,199 @@
+// abuse.go
+package meego
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// IPFilter 客户端 IP 封禁列表。使用共享的 KVStore（如 Redis）时多个实例共享封禁状态
+type IPFilter struct {
+	store  KVStore
+	prefix string
+	// allow 永不封禁的地址（如内网、健康检查），优先于封禁列表
+	allow []*net.IPNet
+}
+
+// NewIPFilter 创建 IP 过滤器，store 为 nil 时使用内存存储
+func NewIPFilter(store KVStore) *IPFilter {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &IPFilter{store: store, prefix: "ipban:"}
+}
+
+// Allow 添加永不封禁的地址或网段，如 "10.0.0.0/8"、"127.0.0.1"
+func (f *IPFilter) Allow(cidrs ...string) *IPFilter {
+	for _, cidr := range cidrs {
+		if ip := net.ParseIP(cidr); ip != nil {
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			f.allow = append(f.allow, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if _, n, err := net.ParseCIDR(cidr); err == nil {
+			f.allow = append(f.allow, n)
+		}
+	}
+	return f
+}
+
+func (f *IPFilter) allowed(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range f.allow {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ban 封禁 IP，ttl <= 0 表示永久封禁
+func (f *IPFilter) Ban(ip string, ttl time.Duration, reason string) error {
+	if f.allowed(ip) {
+		return nil
+	}
+	log.Warn().Str("ip", ip).Str("reason", reason).Dur("ttl", ttl).Msg("client ip banned")
+	return f.store.Set(f.prefix+ip, []byte(reason), ttl)
+}
+
+// Unban 解除封禁
+func (f *IPFilter) Unban(ip string) error {
+	return f.store.Delete(f.prefix + ip)
+}
+
+// Banned 返回 IP 是否被封禁；存储出错时放行
+func (f *IPFilter) Banned(ip string) bool {
+	if f.allowed(ip) {
+		return false
+	}
+	_, found, err := f.store.Get(f.prefix + ip)
+	if err != nil {
+		log.Error().Err(err).Str("ip", ip).Msg("ip filter lookup failed")
+		return false
+	}
+	return found
+}
+
+// Middleware 拒绝被封禁的客户端
+func (f *IPFilter) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if f.Banned(clientIPKey(c)) {
+				c.Writer.Status(StatusForbidden).JSON(JSON{
+					"error": "Forbidden",
+					"code":  StatusForbidden,
+				})
+				return
+			}
+			next(c)
+		}
+	}
+}
+
+// HoneypotConfig 蜜罐路由配置
+type HoneypotConfig struct {
+	Filter  *IPFilter     // 必填，命中蜜罐的 IP 写入该过滤器
+	BanFor  time.Duration // 封禁时长，默认 24h
+	Reason  string        // 记录的封禁原因，默认 "honeypot"
+	Respond HandlerFunc   // 命中后的响应，默认 404，不暴露蜜罐的存在
+}
+
+// Honeypot 蜜罐处理器：正常用户不会访问的路径（如 /wp-login.php、/.env），
+// 访问者被静默封禁：
+//
+//	trap := meego.HoneypotConfig{Filter: filter}
+//	s.GET("/wp-login.php", meego.Honeypot(trap))
+func Honeypot(cfg HoneypotConfig) HandlerFunc {
+	if cfg.BanFor <= 0 {
+		cfg.BanFor = 24 * time.Hour
+	}
+	if cfg.Reason == "" {
+		cfg.Reason = "honeypot"
+	}
+	if cfg.Respond == nil {
+		cfg.Respond = notFoundHandler
+	}
+	return func(c *Context) {
+		if err := cfg.Filter.Ban(clientIPKey(c), cfg.BanFor, cfg.Reason+" "+c.Request.URL.Path); err != nil {
+			c.Logger().Error().Err(err).Msg("honeypot ban failed")
+		}
+		cfg.Respond(c)
+	}
+}
+
+// Honeypot 在路由组内注册 GET/POST 蜜罐路径
+func (g *RouteGroup) Honeypot(cfg HoneypotConfig, paths ...string) {
+	handler := Honeypot(cfg)
+	for _, p := range paths {
+		g.GET(p, handler)
+		g.POST(p, handler)
+	}
+}
+
+// TarpitConfig 拖延响应配置
+type TarpitConfig struct {
+	Interval time.Duration // 每次发送的间隔，默认 1s
+	Duration time.Duration // 最长拖延时间，默认 30s，受服务器写超时限制
+	// MaxConcurrent 同时拖延的连接数上限，默认 16；每个拖延连接占用一个工作协程，
+	// 超过上限时直接返回 404
+	MaxConcurrent int64
+}
+
+// Tarpit 拖延处理器：以 200 状态缓慢逐字节发送响应体，消耗扫描器的连接和时间
+func Tarpit(cfg TarpitConfig) HandlerFunc {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = 30 * time.Second
+	}
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 16
+	}
+	var active atomic.Int64
+
+	return func(c *Context) {
+		if active.Add(1) > cfg.MaxConcurrent {
+			active.Add(-1)
+			notFoundHandler(c)
+			return
+		}
+		defer active.Add(-1)
+
+		c.Writer.SetHeader("Content-Type", "text/html; charset=utf-8")
+		c.Writer.Status(StatusOK)
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+		deadline := time.After(cfg.Duration)
+		for {
+			if err := c.Writer.WriteChunk([]byte{'<'}); err != nil {
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-deadline:
+				return
+			case <-c.Done():
+				return
+			}
+		}
+	}
+}
+
+// Tarpit 在路由组内注册 GET/POST 拖延路径
+func (g *RouteGroup) Tarpit(cfg TarpitConfig, paths ...string) {
+	handler := Tarpit(cfg)
+	for _, p := range paths {
+		g.GET(p, handler)
+		g.POST(p, handler)
+	}
+}
@@ -0,0 +1,32 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHoneypotAndTarpit(t *testing.T) {
+	s := New()
+	filter := NewIPFilter(nil)
+	s.Use(filter.Middleware())
+	traps := s.Group("")
+	traps.Honeypot(HoneypotConfig{Filter: filter}, "/.env")
+	traps.Tarpit(TarpitConfig{Interval: 5 * time.Millisecond, Duration: 30 * time.Millisecond}, "/wp-admin")
+	s.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+
+	if resp := doRaw(s, "GET /wp-admin HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "Transfer-Encoding: chunked") ||
+		!strings.Contains(resp, "1\r\n<\r\n1\r\n<\r\n") {
+		t.Fatalf("expected trickled response: %q", resp)
+	}
+	if resp := doRaw(s, "GET /.env HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 404") {
+		t.Fatalf("honeypot should look like 404: %q", resp)
+	}
+	if resp := doRaw(s, "GET / HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 403") {
+		t.Fatalf("client should be banned: %q", resp)
+	}
+	filter.Unban("pipe")
+	if resp := doRaw(s, "GET / HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 200") {
+		t.Fatalf("client should be unbanned: %q", resp)
+	}
+}
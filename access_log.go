@@ -0,0 +1,298 @@
+// access_log.go
+package meego
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccessLogConfig 访问日志配置
+type AccessLogConfig struct {
+	Output io.Writer                                      // 输出目标，可以是 *RotatingFile 或任意 io.Writer
+	Format func(c *Context, latency time.Duration) string // 自定义格式，默认 Combined Log Format
+}
+
+// AccessLog 访问日志中间件，按 Combined Log Format 写入 w
+func AccessLog(w io.Writer) MiddlewareFunc {
+	return AccessLogWithConfig(AccessLogConfig{Output: w})
+}
+
+// AccessLogWithConfig 使用自定义配置的访问日志中间件
+func AccessLogWithConfig(cfg AccessLogConfig) MiddlewareFunc {
+	if cfg.Output == nil {
+		cfg.Output = os.Stdout
+	}
+	if cfg.Format == nil {
+		cfg.Format = combinedLogFormat
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			start := time.Now()
+			next(c)
+
+			// 一次 Write 写入整行，保证并发请求的日志行不交错
+			line := cfg.Format(c, time.Since(start))
+			io.WriteString(cfg.Output, line)
+		}
+	}
+}
+
+// combinedLogFormat Apache Combined Log Format，末尾追加处理耗时（秒）
+func combinedLogFormat(c *Context, latency time.Duration) string {
+	referer := c.Request.GetHeader("Referer")
+	if referer == "" {
+		referer = "-"
+	}
+	userAgent := c.Request.GetHeader("User-Agent")
+	if userAgent == "" {
+		userAgent = "-"
+	}
+
+	return fmt.Sprintf("%s - - [%s] \"%s %s %s\" %d %d \"%s\" \"%s\" %.6f\n",
+		clientIPKey(c),
+		now().Format("02/Jan/2006:15:04:05 -0700"),
+		c.Request.Method,
+		c.Request.RawURL,
+		c.Request.Proto,
+		c.Writer.status,
+		c.Writer.size,
+		referer,
+		userAgent,
+		latency.Seconds(),
+	)
+}
+
+//--------------------------------------------
+
+// RotatingFile 按大小/时间滚动的日志文件，支持压缩和保留策略
+type RotatingFile struct {
+	Filename   string        // 日志文件路径
+	MaxSize    int64         // 单个文件最大字节数，默认 100MB
+	Interval   time.Duration // 按时间滚动的间隔，0 表示不按时间滚动
+	MaxBackups int           // 保留的历史文件数，0 表示不限制
+	MaxAge     time.Duration // 历史文件最长保留时间，0 表示不限制
+	Compress   bool          // 是否 gzip 压缩历史文件
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	pending  []string      // 等待压缩和清理的历史文件
+	postDone chan struct{} // 后台处理协程退出时关闭，没有运行时为 nil
+}
+
+// NewRotatingFile 创建滚动日志文件，文件在首次写入时打开
+func NewRotatingFile(filename string) *RotatingFile {
+	return &RotatingFile{
+		Filename: filename,
+		MaxSize:  100 * 1024 * 1024,
+	}
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.openLocked(); err != nil {
+			return 0, err
+		}
+	}
+	if f.shouldRotateLocked(int64(len(p))) {
+		if err := f.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate 立即滚动当前文件
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotateLocked()
+}
+
+// Close 关闭当前文件，并等待后台的压缩和清理完成
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	done := f.postDone
+	f.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+	return err
+}
+
+func (f *RotatingFile) shouldRotateLocked(n int64) bool {
+	maxSize := f.MaxSize
+	if maxSize <= 0 {
+		maxSize = 100 * 1024 * 1024
+	}
+	if f.size > 0 && f.size+n > maxSize {
+		return true
+	}
+	return f.Interval > 0 && now().Sub(f.openedAt) >= f.Interval
+}
+
+func (f *RotatingFile) openLocked() error {
+	if err := os.MkdirAll(filepath.Dir(f.Filename), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = now()
+	return nil
+}
+
+func (f *RotatingFile) rotateLocked() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+
+		backup := f.backupName(now())
+		if err := os.Rename(f.Filename, backup); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		// 压缩和清理放到后台，避免阻塞写日志的请求；同一个文件只有一个
+		// 后台协程按滚动顺序处理，避免并发压缩和清理互相删除文件
+		f.pending = append(f.pending, backup)
+		if f.postDone == nil {
+			f.postDone = make(chan struct{})
+			go f.postLoop(f.postDone)
+		}
+	}
+	return f.openLocked()
+}
+
+// backupName 历史文件名：access.log -> access-20060102T150405.000.log，
+// 同一毫秒内多次滚动时追加序号：access-20060102T150405.000_001.log
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.Filename)
+	base := strings.TrimSuffix(f.Filename, ext) + "-" + t.Format("20060102T150405.000")
+	name := base + ext
+	for seq := 1; backupExists(name); seq++ {
+		name = fmt.Sprintf("%s_%03d%s", base, seq, ext)
+	}
+	return name
+}
+
+// backupExists 历史文件（或其压缩文件）是否已存在
+func backupExists(name string) bool {
+	for _, candidate := range []string{name, name + ".gz"} {
+		if _, err := os.Lstat(candidate); err == nil || !os.IsNotExist(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// postLoop 依次处理等待压缩和清理的历史文件，队列为空时退出
+func (f *RotatingFile) postLoop(done chan struct{}) {
+	defer close(done)
+	for {
+		f.mu.Lock()
+		if len(f.pending) == 0 {
+			f.postDone = nil
+			f.mu.Unlock()
+			return
+		}
+		backup := f.pending[0]
+		f.pending = f.pending[1:]
+		f.mu.Unlock()
+
+		f.postRotate(backup)
+	}
+}
+
+func (f *RotatingFile) postRotate(backup string) {
+	if f.Compress {
+		if err := gzipFile(backup); err == nil {
+			os.Remove(backup)
+		}
+	}
+	f.prune()
+}
+
+// prune 按数量和时间清理历史文件
+func (f *RotatingFile) prune() {
+	if f.MaxBackups <= 0 && f.MaxAge <= 0 {
+		return
+	}
+
+	ext := filepath.Ext(f.Filename)
+	pattern := strings.TrimSuffix(f.Filename, ext) + "-*" + ext + "*"
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return
+	}
+	// 文件名中的时间戳和序号保证字典序即时间序，最新的排在前面
+	sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+
+	for i, name := range matches {
+		remove := f.MaxBackups > 0 && i >= f.MaxBackups
+		if !remove && f.MaxAge > 0 {
+			if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > f.MaxAge {
+				remove = true
+			}
+		}
+		if remove {
+			os.Remove(name)
+		}
+	}
+}
+
+func gzipFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		zw.Close()
+		dst.Close()
+		os.Remove(name + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,111 @@
+package meego
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLog(t *testing.T) {
+	var out syncBuffer
+	s := New()
+	s.Use(AccessLog(&out))
+	s.GET("/a", func(c *Context) { c.String(StatusCreated, "hello") })
+
+	doRaw(s, "GET /a?x=1 HTTP/1.1\r\nUser-Agent: probe/1.0\r\nConnection: close\r\n\r\n")
+	line := out.String()
+	if !strings.HasPrefix(line, "pipe - - [") || !strings.Contains(line, `] "GET /a?x=1 HTTP/1.1" 201 5 "-" "probe/1.0" `) || !strings.HasSuffix(line, "\n") {
+		t.Fatalf("unexpected access log line: %q", line)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	f := NewRotatingFile(filepath.Join(dir, "logs", "access.log"))
+	f.MaxSize = 10
+	f.MaxBackups = 2
+	defer f.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := fmt.Fprintf(f, "line %d\n", i); err != nil {
+			t.Fatal(err)
+		}
+		// 历史文件名精确到毫秒
+		time.Sleep(2 * time.Millisecond)
+	}
+	if data, _ := os.ReadFile(f.Filename); string(data) != "line 3\n" {
+		t.Fatalf("current file: %q", data)
+	}
+
+	// 压缩和清理在后台进行
+	var backups []string
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		backups, _ = filepath.Glob(filepath.Join(dir, "logs", "access-*.log"))
+		if len(backups) == 2 {
+			break
+		}
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 backups to be kept, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[1]); string(data) != "line 2\n" {
+		t.Fatalf("newest backup: %q", data)
+	}
+}
+
+func TestRotatingFileSameTimestamp(t *testing.T) {
+	// 时钟不动时所有滚动都落在同一毫秒内
+	defer SetClock(NewFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))()
+
+	dir := t.TempDir()
+	f := NewRotatingFile(filepath.Join(dir, "access.log"))
+	f.MaxSize = 10
+	f.MaxBackups = 3
+	f.Compress = true
+
+	for i := 0; i < 5; i++ {
+		if _, err := fmt.Fprintf(f, "line %d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Close 等待后台压缩和清理完成
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if plain, _ := filepath.Glob(filepath.Join(dir, "access-*.log")); len(plain) != 0 {
+		t.Fatalf("uncompressed backups left: %v", plain)
+	}
+	backups, _ := filepath.Glob(filepath.Join(dir, "access-*.log.gz"))
+	want := []string{
+		"access-20240301T120000.000_001.log.gz",
+		"access-20240301T120000.000_002.log.gz",
+		"access-20240301T120000.000_003.log.gz",
+	}
+	if len(backups) != len(want) {
+		t.Fatalf("expected %d backups, got %v", len(want), backups)
+	}
+	for i, name := range backups {
+		if filepath.Base(name) != want[i] {
+			t.Fatalf("backup %d: %s", i, name)
+		}
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(zr)
+		file.Close()
+		if string(data) != fmt.Sprintf("line %d\n", i+1) {
+			t.Fatalf("%s: %q", name, data)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// assets.go
+package meego
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"path"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Assets 带指纹的静态资源：文件名加入内容哈希（app.js -> app.3f2a1b9c.js），
+// 带指纹的路径返回一年的缓存头，内容变化后路径随之变化，浏览器不会使用旧缓存
+type Assets struct {
+	fsys   fs.FS
+	prefix string
+
+	manifest map[string]string // 逻辑路径 -> 带指纹的路径
+	files    map[string]asset  // 带指纹的路径 -> 文件
+}
+
+type asset struct {
+	name string // 原始路径
+	etag string
+}
+
+// NewAssets 扫描 fsys 中的全部文件生成清单，prefix 为对外的 URL 前缀，如 "/assets"
+func NewAssets(fsys fs.FS, prefix string) (*Assets, error) {
+	a := &Assets{
+		fsys:     fsys,
+		prefix:   "/" + strings.Trim(prefix, "/"),
+		manifest: make(map[string]string),
+		files:    make(map[string]asset),
+	}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		sum := hex.EncodeToString(h.Sum(nil))
+
+		ext := path.Ext(name)
+		fingerprinted := strings.TrimSuffix(name, ext) + "." + sum[:8] + ext
+		a.manifest[name] = fingerprinted
+		a.files[fingerprinted] = asset{name: name, etag: `"` + sum[:16] + `"`}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Path 返回资源的带指纹 URL，未知资源返回不带指纹的 URL
+func (a *Assets) Path(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if fp, ok := a.manifest[name]; ok {
+		return a.prefix + "/" + fp
+	}
+	return a.prefix + "/" + name
+}
+
+// Manifest 返回逻辑路径到带指纹路径的映射
+func (a *Assets) Manifest() map[string]string {
+	m := make(map[string]string, len(a.manifest))
+	for k, v := range a.manifest {
+		m[k] = v
+	}
+	return m
+}
+
+// WriteManifest 以 JSON 写出清单（键已排序），供前端构建工具或 CDN 上传脚本使用
+func (a *Assets) WriteManifest(w io.Writer) error {
+	data, err := jsoniter.ConfigCompatibleWithStandardLibrary.MarshalIndent(a.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// TemplateFuncs 返回模板函数 asset，与 meego.TemplateFuncs() 一起注册：
+//
+//	<script src="{{asset "app.js"}}"></script>
+func (a *Assets) TemplateFuncs() template.FuncMap {
+	return template.FuncMap{"asset": a.Path}
+}
+
+// ServeAssets 在资源前缀下注册静态文件路由。带指纹的路径缓存一年，
+// 不带指纹的路径每次使用 ETag 校验
+func (s *HTTPServer) ServeAssets(a *Assets) *Route {
+	return s.GET(a.prefix+"/*filepath", func(c *Context) {
+		name := strings.TrimPrefix(c.Param("filepath"), "/")
+
+		file, fingerprinted := a.files[name]
+		if fingerprinted {
+			c.Writer.SetHeader("Cache-Control", "public, max-age=31536000, immutable")
+		} else if fp, ok := a.manifest[name]; ok {
+			file = a.files[fp]
+			c.Writer.SetHeader("Cache-Control", "no-cache")
+		} else {
+			notFoundHandler(c)
+			return
+		}
+
+		c.Writer.SetHeader("ETag", file.etag)
+		if c.Request.GetHeader("If-None-Match") == file.etag {
+			c.Writer.Status(StatusNotModified).String("")
+			return
+		}
+		data, err := fs.ReadFile(a.fsys, file.name)
+		if err != nil {
+			notFoundHandler(c)
+			return
+		}
+		c.Data(StatusOK, mime.TypeByExtension(path.Ext(file.name)), data)
+	})
+}
@@ -0,0 +1,38 @@
+package meego
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFingerprintedAssets(t *testing.T) {
+	assets, err := NewAssets(fstest.MapFS{
+		"js/app.js": {Data: []byte("console.log(1)")},
+	}, "/assets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New()
+	s.ServeAssets(assets)
+
+	url := assets.Path("js/app.js")
+	if !strings.HasPrefix(url, "/assets/js/app.") || !strings.HasSuffix(url, ".js") || url == "/assets/js/app.js" {
+		t.Fatalf("unexpected asset url %q", url)
+	}
+	var out strings.Builder
+	tmpl := template.Must(template.New("page").Funcs(assets.TemplateFuncs()).Parse(`<script src="{{asset "js/app.js"}}"></script>`))
+	if err := tmpl.Execute(&out, nil); err != nil || out.String() != `<script src="`+url+`"></script>` {
+		t.Fatalf("template: %q %v", out.String(), err)
+	}
+
+	resp := doRaw(s, "GET "+url+" HTTP/1.1\r\n\r\n")
+	if !strings.Contains(resp, "Cache-Control: public, max-age=31536000, immutable") ||
+		!strings.Contains(resp, "Content-Type: text/javascript") || !strings.HasSuffix(resp, "console.log(1)") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+	if resp := doRaw(s, "GET /assets/js/app.js HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "Cache-Control: no-cache") {
+		t.Fatalf("unfingerprinted path should revalidate: %q", resp)
+	}
+}
@@ -0,0 +1,234 @@
+// authorization.go
+package meego
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Authorization 解析后的 Authorization 请求头
+type Authorization struct {
+	// Scheme 认证方案，统一为 "Basic"、"Bearer"、"Digest" 等首字母大写形式
+	Scheme string
+	// Credentials 方案之后的原始凭据
+	Credentials string
+
+	// Username、Password Basic 解码后的用户名和密码；Digest 的 username 也写入 Username
+	Username string
+	Password string
+	// Token Bearer 令牌
+	Token string
+	// Params Digest 等以 key=value 列表表示的参数，键为小写
+	Params map[string]string
+}
+
+// ErrNoAuthorization 请求没有 Authorization 头
+var ErrNoAuthorization = errors.New("no authorization header")
+
+// ErrInvalidAuthorization Authorization 头格式错误
+var ErrInvalidAuthorization = errors.New("invalid authorization header")
+
+const authorizationKey = "meego.authorization"
+
+// Authorization 返回解析后的 Authorization 请求头，结果在本次请求内缓存，
+// 多个中间件调用不会重复解析
+func (c *Context) Authorization() (*Authorization, error) {
+	if auth, ok := c.Get(authorizationKey).(*Authorization); ok {
+		return auth, nil
+	}
+	auth, err := ParseAuthorization(c.Request.GetHeader("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	c.Set(authorizationKey, auth)
+	return auth, nil
+}
+
+// BearerToken 返回 Bearer 令牌，没有或不是 Bearer 方案时返回空字符串
+func (c *Context) BearerToken() string {
+	if auth, err := c.Authorization(); err == nil && auth.Scheme == "Bearer" {
+		return auth.Token
+	}
+	return ""
+}
+
+// BasicAuth 返回 Basic 认证的用户名和密码
+func (c *Context) BasicAuth() (username, password string, ok bool) {
+	if auth, err := c.Authorization(); err == nil && auth.Scheme == "Basic" {
+		return auth.Username, auth.Password, true
+	}
+	return "", "", false
+}
+
+// ParseAuthorization 解析 Authorization 头：Basic 解码用户名和密码，Bearer 提取令牌，
+// Digest 及其它带参数的方案解析 key=value 列表
+func ParseAuthorization(header string) (*Authorization, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return nil, ErrNoAuthorization
+	}
+	scheme, credentials, _ := strings.Cut(header, " ")
+	auth := &Authorization{
+		Scheme:      canonicalScheme(scheme),
+		Credentials: strings.TrimSpace(credentials),
+	}
+
+	switch auth.Scheme {
+	case "Basic":
+		decoded, err := base64.StdEncoding.DecodeString(auth.Credentials)
+		if err != nil {
+			return nil, ErrInvalidAuthorization
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return nil, ErrInvalidAuthorization
+		}
+		auth.Username, auth.Password = username, password
+	case "Bearer":
+		if auth.Credentials == "" || strings.ContainsAny(auth.Credentials, " \t") {
+			return nil, ErrInvalidAuthorization
+		}
+		auth.Token = auth.Credentials
+	default:
+		if strings.Contains(auth.Credentials, "=") {
+			params, err := parseAuthParams(auth.Credentials)
+			if err != nil {
+				return nil, err
+			}
+			auth.Params = params
+			auth.Username = params["username"]
+		} else if auth.Scheme == "Digest" {
+			return nil, ErrInvalidAuthorization
+		}
+	}
+	return auth, nil
+}
+
+// canonicalScheme 认证方案不区分大小写，常见方案统一写法
+func canonicalScheme(scheme string) string {
+	for _, known := range []string{"Basic", "Bearer", "Digest"} {
+		if strings.EqualFold(scheme, known) {
+			return known
+		}
+	}
+	return scheme
+}
+
+// parseAuthParams 解析逗号分隔的 key=value 列表，值可以是带转义的引号字符串
+func parseAuthParams(s string) (map[string]string, error) {
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t,")
+		if s == "" {
+			return params, nil
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, ErrInvalidAuthorization
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(s, `"`) {
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, ErrInvalidAuthorization
+			}
+			s = s[i+1:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value.WriteString(strings.TrimSpace(s[:end]))
+			s = s[end:]
+		}
+		params[key] = value.String()
+	}
+}
+
+// BasicAuth Basic 认证中间件，accounts 为用户名到密码的映射，realm 为空时为 "Restricted"。
+// 密码按常数时间比较，认证失败返回 401 和 WWW-Authenticate；通过后用户名保存在 c.Get("user")
+func BasicAuth(accounts map[string]string, realm string) MiddlewareFunc {
+	if realm == "" {
+		realm = "Restricted"
+	}
+	challenge := "Basic realm=" + strconv.Quote(realm) + `, charset="UTF-8"`
+	// 预先计算摘要，比较长度固定的摘要，不泄露密码长度
+	digests := make(map[string][32]byte, len(accounts))
+	for user, password := range accounts {
+		digests[user] = sha256.Sum256([]byte(password))
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			user, password, ok := c.BasicAuth()
+			expected, found := digests[user]
+			got := sha256.Sum256([]byte(password))
+			if subtle.ConstantTimeCompare(got[:], expected[:]) != 1 || !found || !ok {
+				unauthorized(c, challenge)
+				return
+			}
+			c.Set("user", user)
+			next(c)
+		}
+	}
+}
+
+// APIKeyAuth API 密钥认证中间件，从 header（默认 X-API-Key）读取密钥交给 validator 校验，
+// 校验函数应按常数时间比较（见 APIKeys）。失败返回 401；通过后密钥保存在 c.Get("api_key")
+func APIKeyAuth(header string, validator func(key string) bool) MiddlewareFunc {
+	if header == "" {
+		header = "X-API-Key"
+	}
+	challenge := "APIKey header=" + strconv.Quote(header)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			key := c.Request.GetHeader(header)
+			if key == "" || !validator(key) {
+				unauthorized(c, challenge)
+				return
+			}
+			c.Set("api_key", key)
+			next(c)
+		}
+	}
+}
+
+// APIKeys 返回按常数时间比较的密钥校验函数，配合 APIKeyAuth 使用
+func APIKeys(keys ...string) func(key string) bool {
+	digests := make([][32]byte, len(keys))
+	for i, key := range keys {
+		digests[i] = sha256.Sum256([]byte(key))
+	}
+	return func(key string) bool {
+		got := sha256.Sum256([]byte(key))
+		match := 0
+		// 与所有密钥比较，耗时与匹配位置无关
+		for i := range digests {
+			match |= subtle.ConstantTimeCompare(got[:], digests[i][:])
+		}
+		return match == 1
+	}
+}
+
+// unauthorized 返回 401 和认证质询
+func unauthorized(c *Context, challenge string) {
+	c.Writer.SetHeader("WWW-Authenticate", challenge)
+	c.AbortWithStatusJSON(StatusUnauthorized, JSON{
+		"error": "Unauthorized",
+		"code":  StatusUnauthorized,
+	})
+}
@@ -0,0 +1,77 @@
+package meego
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAuthorization(t *testing.T) {
+	cases := []struct {
+		header string
+		check  func(a *Authorization) bool
+	}{
+		{"Basic " + base64.StdEncoding.EncodeToString([]byte("alice:s3:cret")), func(a *Authorization) bool {
+			return a.Scheme == "Basic" && a.Username == "alice" && a.Password == "s3:cret"
+		}},
+		{"bearer abc.def", func(a *Authorization) bool { return a.Scheme == "Bearer" && a.Token == "abc.def" }},
+		{`Digest username="bob", realm="api", nonce="n\"1", uri="/x", qop=auth, nc=00000001`, func(a *Authorization) bool {
+			return a.Scheme == "Digest" && a.Username == "bob" && a.Params["nonce"] == `n"1` && a.Params["qop"] == "auth" && a.Params["nc"] == "00000001"
+		}},
+	}
+	for _, tc := range cases {
+		auth, err := ParseAuthorization(tc.header)
+		if err != nil || !tc.check(auth) {
+			t.Fatalf("%q: %+v %v", tc.header, auth, err)
+		}
+	}
+	for _, bad := range []string{"Basic !!!", "Bearer ", `Digest realm="x`} {
+		if _, err := ParseAuthorization(bad); !errors.Is(err, ErrInvalidAuthorization) {
+			t.Fatalf("%q: expected invalid, got %v", bad, err)
+		}
+	}
+
+	s := New()
+	s.Use(Auth())
+	s.GET("/", func(c *Context) {
+		c.String(StatusOK, c.BearerToken())
+	})
+	if resp := doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\nauthorization: Bearer t0k\r\n\r\n"); !strings.HasSuffix(resp, "t0k") {
+		t.Fatalf("bearer:\n%s", resp)
+	}
+	if resp := doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\nAuthorization: Basic YTpi\r\n\r\n"); !strings.Contains(resp, "401") {
+		t.Fatalf("basic should be rejected by Auth:\n%s", resp)
+	}
+}
+
+func TestBasicAndAPIKeyAuth(t *testing.T) {
+	s := New()
+	admin := s.Group("/admin")
+	admin.Use(BasicAuth(map[string]string{"alice": "s3cret"}, "ops"))
+	admin.GET("/me", func(c *Context) { c.String(StatusOK, c.Get("user").(string)) })
+	api := s.Group("/api")
+	api.Use(APIKeyAuth("", APIKeys("k1", "k2")))
+	api.GET("/ping", func(c *Context) { c.String(StatusOK, "pong") })
+
+	basic := func(user, password string) string {
+		return base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+	}
+	if resp := doRaw(s, "GET /admin/me HTTP/1.1\r\nAuthorization: Basic "+basic("alice", "s3cret")+"\r\n\r\n"); !strings.HasSuffix(resp, "\r\n\r\nalice") {
+		t.Fatalf("basic auth: %q", resp)
+	}
+	for _, header := range []string{"", "Authorization: Basic " + basic("alice", "wrong") + "\r\n", "Authorization: Basic " + basic("bob", "s3cret") + "\r\n"} {
+		resp := doRaw(s, "GET /admin/me HTTP/1.1\r\n"+header+"\r\n")
+		if !strings.HasPrefix(resp, "HTTP/1.1 401") || !strings.Contains(resp, `WWW-Authenticate: Basic realm="ops", charset="UTF-8"`) {
+			t.Fatalf("basic auth rejected: %q", resp)
+		}
+	}
+
+	if resp := doRaw(s, "GET /api/ping HTTP/1.1\r\nX-API-Key: k2\r\n\r\n"); !strings.HasSuffix(resp, "\r\n\r\npong") {
+		t.Fatalf("api key: %q", resp)
+	}
+	if resp := doRaw(s, "GET /api/ping HTTP/1.1\r\nX-API-Key: k3\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 401") ||
+		!strings.Contains(resp, `WWW-Authenticate: APIKey header="X-API-Key"`) {
+		t.Fatalf("api key rejected: %q", resp)
+	}
+}
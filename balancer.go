@@ -0,0 +1,323 @@
+// balancer.go
+package meego
+
+import (
+	"context"
+	"hash/crc32"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// KeyedBalancer 按请求键选择地址的负载均衡（会话保持），键通过 WithBalanceKey 传入
+type KeyedBalancer interface {
+	Balancer
+	PickKey(addrs []string, key string) string
+}
+
+// LatencyObserver 需要响应耗时反馈的负载均衡
+type LatencyObserver interface {
+	Observe(addr string, latency time.Duration)
+}
+
+type balanceKeyCtx struct{}
+
+// WithBalanceKey 设置会话保持的键，同一个键总是落到同一个地址（地址列表不变时）
+func WithBalanceKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, balanceKeyCtx{}, key)
+}
+
+func balanceKey(ctx context.Context) string {
+	key, _ := ctx.Value(balanceKeyCtx{}).(string)
+	return key
+}
+
+// EWMA 按指数加权平均响应时间选择地址：随机取两个地址，选择
+// 平均耗时 ×（进行中请求数 + 1）较小的一个（power of two choices）
+type EWMA struct {
+	// Decay 衰减时间常数，默认 10s，越小对最近的耗时变化越敏感
+	Decay time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*ewmaStat
+}
+
+type ewmaStat struct {
+	value    float64 // 纳秒
+	updated  time.Time
+	inflight int
+}
+
+func (b *EWMA) stat(addr string) *ewmaStat {
+	if b.stats == nil {
+		b.stats = make(map[string]*ewmaStat)
+	}
+	st := b.stats[addr]
+	if st == nil {
+		st = &ewmaStat{}
+		b.stats[addr] = st
+	}
+	return st
+}
+
+func (b *EWMA) Pick(addrs []string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	best := addrs[rand.Intn(len(addrs))]
+	if len(addrs) > 1 {
+		other := addrs[rand.Intn(len(addrs)-1)]
+		if other == best {
+			other = addrs[len(addrs)-1]
+		}
+		if b.score(other) < b.score(best) {
+			best = other
+		}
+	}
+	b.stat(best).inflight++
+	return best
+}
+
+func (b *EWMA) score(addr string) float64 {
+	st := b.stat(addr)
+	return st.value * float64(st.inflight+1)
+}
+
+func (b *EWMA) Done(addr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if st := b.stats[addr]; st != nil && st.inflight > 0 {
+		st.inflight--
+	}
+}
+
+func (b *EWMA) Observe(addr string, latency time.Duration) {
+	decay := b.Decay
+	if decay <= 0 {
+		decay = 10 * time.Second
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := b.stat(addr)
+	now := time.Now()
+	if st.updated.IsZero() {
+		st.value = float64(latency)
+	} else {
+		w := math.Exp(-float64(now.Sub(st.updated)) / float64(decay))
+		st.value = st.value*w + float64(latency)*(1-w)
+	}
+	st.updated = now
+}
+
+// ConsistentHash 一致性哈希：相同的键落到相同的地址，地址增减时只有少量键迁移。
+// 没有键时退回轮询
+type ConsistentHash struct {
+	Replicas int // 每个地址的虚拟节点数，默认 100
+
+	RoundRobin
+
+	mu      sync.Mutex
+	members string // 当前哈希环对应的地址列表
+	ring    []uint32
+	owners  map[uint32]string
+}
+
+func (b *ConsistentHash) PickKey(addrs []string, key string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sorted := append([]string(nil), addrs...)
+	sort.Strings(sorted)
+	if members := strings.Join(sorted, ","); members != b.members {
+		b.build(sorted)
+		b.members = members
+	}
+
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(b.ring), func(i int) bool { return b.ring[i] >= h })
+	if i == len(b.ring) {
+		i = 0
+	}
+	return b.owners[b.ring[i]]
+}
+
+func (b *ConsistentHash) build(addrs []string) {
+	replicas := b.Replicas
+	if replicas <= 0 {
+		replicas = 100
+	}
+	b.ring = b.ring[:0]
+	b.owners = make(map[uint32]string, len(addrs)*replicas)
+	for _, addr := range addrs {
+		for i := 0; i < replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + "#" + addr))
+			b.ring = append(b.ring, h)
+			b.owners[h] = addr
+		}
+	}
+	sort.Slice(b.ring, func(i, j int) bool { return b.ring[i] < b.ring[j] })
+}
+
+// HealthCheck 主动健康检查配置
+type HealthCheck struct {
+	Path     string        // 检查路径，如 "/healthz"，2xx 为健康
+	Interval time.Duration // 默认 10s
+	Timeout  time.Duration // 默认 2s
+	Client   *http.Client  // 默认 http.DefaultClient
+}
+
+// endpointHealth 单个地址的健康状态
+type endpointHealth struct {
+	fails        int       // 连续失败次数
+	ejectedUntil time.Time // 被动摘除的截止时间
+	down         bool      // 主动检查失败
+}
+
+// healthy 过滤掉被摘除的地址；全部被摘除时返回原列表，避免完全不可用
+func (u *Upstream) healthy(addrs []string) []string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.health) == 0 {
+		return addrs
+	}
+
+	now := time.Now()
+	out := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		h := u.health[addr]
+		if h == nil || (!h.down && now.After(h.ejectedUntil)) {
+			out = append(out, addr)
+		}
+	}
+	if len(out) == 0 {
+		return addrs
+	}
+	return out
+}
+
+// observe 记录一次请求结果：连接错误和 502/503/504 计为失败，
+// 连续失败 MaxFails 次后摘除 EjectFor 时长
+func (u *Upstream) observe(addr string, latency time.Duration, status int, err error) {
+	if obs, ok := u.Balancer.(LatencyObserver); ok && err == nil {
+		obs.Observe(addr, latency)
+	}
+	if u.MaxFails < 0 {
+		return
+	}
+	failed := err != nil || status == StatusBadGateway || status == StatusServiceUnavailable || status == StatusGatewayTimeout
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	h := u.health[addr]
+	if !failed {
+		if h != nil {
+			h.fails = 0
+		}
+		return
+	}
+	if u.health == nil {
+		u.health = make(map[string]*endpointHealth)
+	}
+	if h == nil {
+		h = &endpointHealth{}
+		u.health[addr] = h
+	}
+	h.fails++
+	maxFails := u.MaxFails
+	if maxFails == 0 {
+		maxFails = 3
+	}
+	if h.fails >= maxFails {
+		ejectFor := u.EjectFor
+		if ejectFor <= 0 {
+			ejectFor = 30 * time.Second
+		}
+		h.fails = 0
+		h.ejectedUntil = time.Now().Add(ejectFor)
+		log.Warn().Str("endpoint", addr).Dur("eject_for", ejectFor).Msg("upstream endpoint ejected")
+	}
+}
+
+// StartHealthChecks 在后台定时检查全部地址，失败的地址在恢复之前不参与负载均衡；
+// ctx 结束时停止
+func (u *Upstream) StartHealthChecks(ctx context.Context, hc HealthCheck) {
+	if hc.Interval <= 0 {
+		hc.Interval = 10 * time.Second
+	}
+	if hc.Timeout <= 0 {
+		hc.Timeout = 2 * time.Second
+	}
+	if hc.Client == nil {
+		hc.Client = http.DefaultClient
+	}
+
+	go func() {
+		ticker := clock().NewTicker(hc.Interval)
+		defer ticker.Stop()
+		for {
+			u.checkAll(ctx, hc)
+			select {
+			case <-ticker.C():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (u *Upstream) checkAll(ctx context.Context, hc HealthCheck) {
+	addrs, err := u.Resolver.Resolve(ctx)
+	if err != nil {
+		return
+	}
+	var wg sync.WaitGroup
+	for _, addr := range addrs {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			ok := probeEndpoint(ctx, hc, addr)
+
+			u.mu.Lock()
+			defer u.mu.Unlock()
+			if u.health == nil {
+				u.health = make(map[string]*endpointHealth)
+			}
+			h := u.health[addr]
+			if h == nil {
+				h = &endpointHealth{}
+				u.health[addr] = h
+			}
+			if h.down == ok {
+				log.Info().Str("endpoint", addr).Bool("healthy", ok).Msg("upstream endpoint health changed")
+			}
+			h.down = !ok
+			if ok {
+				h.ejectedUntil = time.Time{}
+			}
+		}(addr)
+	}
+	wg.Wait()
+}
+
+func probeEndpoint(ctx context.Context, hc HealthCheck, addr string) bool {
+	ctx, cancel := context.WithTimeout(ctx, hc.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", "http://"+addr+hc.Path, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := hc.Client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode/100 == 2
+}
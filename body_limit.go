@@ -0,0 +1,66 @@
+// body_limit.go
+package meego
+
+import "strings"
+
+// BodyLimits 请求体大小限制，在解析器读取请求体之前生效。
+// 优先级：路由/路由组 MaxBodySize > ByContentType > Default；
+// 内存压力下不超过 MemoryGuardConfig.MaxBody
+type BodyLimits struct {
+	Default int64 // 默认上限，0 表示 Limits.MaxBodyBytes
+	// ByContentType 按媒体类型设置上限，支持 "image/*" 通配，如：
+	//	{"application/json": 1 << 20, "multipart/form-data": 100 << 20}
+	ByContentType map[string]int64
+}
+
+// SetBodyLimits 设置请求体大小限制
+func (s *HTTPServer) SetBodyLimits(limits BodyLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodyLimits = limits
+}
+
+// MaxBodySize 设置路由的请求体上限
+func (r *Route) MaxBodySize(n int64) *Route {
+	r.maxBody = n
+	return r
+}
+
+// MaxBodySize 设置路由组内之后注册的路由的请求体上限
+func (g *RouteGroup) MaxBodySize(n int64) *RouteGroup {
+	g.maxBody = n
+	return g
+}
+
+// bodyLimitFor 解析器回调：返回请求允许的最大请求体字节数
+func (s *HTTPServer) bodyLimitFor(req *HTTPRequest) int64 {
+	limit := s.configuredBodyLimit(req)
+	if pressure := s.pressureBodyLimit(); pressure > 0 && (limit <= 0 || limit > pressure) {
+		return pressure
+	}
+	return limit
+}
+
+// configuredBodyLimit 路由和 SetBodyLimits 配置的请求体上限
+func (s *HTTPServer) configuredBodyLimit(req *HTTPRequest) int64 {
+	if route, _ := s.router.findRoute(req.Method, req.URL.Path); route != nil && route.maxBody > 0 {
+		return route.maxBody
+	}
+
+	s.mu.RLock()
+	limits := s.bodyLimits
+	s.mu.RUnlock()
+
+	if len(limits.ByContentType) > 0 {
+		contentType := strings.ToLower(req.ContentType())
+		if limit, ok := limits.ByContentType[contentType]; ok {
+			return limit
+		}
+		if i := strings.IndexByte(contentType, '/'); i > 0 {
+			if limit, ok := limits.ByContentType[contentType[:i]+"/*"]; ok {
+				return limit
+			}
+		}
+	}
+	return limits.Default
+}
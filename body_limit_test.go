@@ -0,0 +1,22 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBodyLimits(t *testing.T) {
+	s := New()
+	s.SetBodyLimits(BodyLimits{ByContentType: map[string]int64{"application/json": 4}})
+	s.POST("/json", func(c *Context) { c.String(StatusOK, "ok") })
+	s.POST("/big", func(c *Context) { c.String(StatusOK, "ok") }).MaxBodySize(64)
+
+	resp := doRaw(s, "POST /json HTTP/1.1\r\nContent-Type: application/json\r\nContent-Length: 10\r\n\r\n0123456789")
+	if !strings.HasPrefix(resp, "HTTP/1.1 413") {
+		t.Fatalf("expected 413, got %q", resp)
+	}
+	resp = doRaw(s, "POST /big HTTP/1.1\r\nContent-Type: application/json\r\nContent-Length: 10\r\n\r\n0123456789")
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") {
+		t.Fatalf("route limit should override content type limit: %q", resp)
+	}
+}
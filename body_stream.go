@@ -0,0 +1,73 @@
+// body_stream.go
+package meego
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrBodyClosed 请求体读取器已关闭
+var ErrBodyClosed = errors.New("request body closed")
+
+// StreamBodies 开启后所有请求的请求体都不预先读入内存，而是在处理器需要时读取：
+// c.Request.BodyReader() 直接从连接读取（按 Content-Length 限长或解码分块传输），
+// c.Request.ReadBody()、c.BodyBytes()、c.BindJSON() 等首次调用时才把请求体读入内存。
+// 大文件上传和代理转发不再受缓冲影响；BodyLimits 和内存保护的上限仍然生效
+func (s *HTTPServer) StreamBodies(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.streamBodies = enabled
+}
+
+// Streaming 请求体是否尚未读入内存，只能通过 BodyReader 读取或用 ReadBody 读入
+func (r *HTTPRequest) Streaming() bool {
+	return r.bodyReader != nil
+}
+
+// BodyReader 返回请求体读取器。流式请求体直接读取连接，只能读一次；
+// 已缓冲的请求体每次返回新的读取器。Close 后不能再读取，未读完的部分在请求结束时丢弃
+func (r *HTTPRequest) BodyReader() io.ReadCloser {
+	if r.bodyReader != nil {
+		return &bodyReadCloser{r: r.bodyReader}
+	}
+	return io.NopCloser(bytes.NewReader(r.Body))
+}
+
+// ReadBody 返回完整的请求体，流式请求体在首次调用时读入 r.Body，
+// 之后与预先缓冲的请求一样可以直接使用 r.Body
+func (r *HTTPRequest) ReadBody() ([]byte, error) {
+	if r.bodyReader == nil {
+		return r.Body, nil
+	}
+	buf := bytes.NewBuffer(r.Body[:0])
+	if n := r.ContentLength(); n > 0 {
+		buf.Grow(n)
+	}
+	_, err := buf.ReadFrom(r.bodyReader)
+	r.bodyReader = nil
+	r.Body = buf.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	r.contentLength = len(r.Body)
+	return r.Body, nil
+}
+
+// bodyReadCloser 流式请求体的 io.ReadCloser
+type bodyReadCloser struct {
+	r      io.Reader
+	closed bool
+}
+
+func (b *bodyReadCloser) Read(p []byte) (int, error) {
+	if b.closed {
+		return 0, ErrBodyClosed
+	}
+	return b.r.Read(p)
+}
+
+func (b *bodyReadCloser) Close() error {
+	b.closed = true
+	return nil
+}
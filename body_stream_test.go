@@ -0,0 +1,56 @@
+package meego
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamBodies(t *testing.T) {
+	s := New()
+	s.StreamBodies(true)
+	s.SetBodyLimits(BodyLimits{Default: 64})
+	s.POST("/json", func(c *Context) {
+		streaming := c.Request.Streaming()
+		var v struct{ Name string }
+		if err := c.BindJSON(&v); err != nil {
+			c.String(StatusBadRequest, err.Error())
+			return
+		}
+		c.String(StatusOK, fmt.Sprintf("%v %s %v", streaming, v.Name, c.Request.Streaming()))
+	})
+	s.POST("/count", func(c *Context) {
+		body := c.Request.BodyReader()
+		defer body.Close()
+		n, err := io.Copy(io.Discard, body)
+		c.String(StatusOK, fmt.Sprintf("%d %v", n, err))
+	})
+	s.POST("/ignore", func(c *Context) {
+		c.String(StatusOK, "ignored")
+	})
+
+	post := func(path, headers, body string) string {
+		return "POST " + path + " HTTP/1.1\r\nHost: x\r\n" + headers + "\r\n" + body
+	}
+	resp := doRaw(s, post("/json", "Content-Length: 14\r\n", `{"Name":"ann"}`))
+	if !strings.HasSuffix(resp, "true ann false") {
+		t.Fatalf("lazy body:\n%s", resp)
+	}
+
+	chunked := "Transfer-Encoding: chunked\r\n"
+	resp = doRaw(s, post("/count", chunked, "5\r\nhello\r\n3\r\nabc\r\n0\r\n\r\n"))
+	if !strings.HasSuffix(resp, "8 <nil>") {
+		t.Fatalf("chunked stream:\n%s", resp)
+	}
+	resp = doRaw(s, post("/count", chunked, "50\r\n"+strings.Repeat("x", 80)+"\r\n0\r\n\r\n"))
+	if !strings.HasSuffix(resp, "64 body too large") {
+		t.Fatalf("limit while streaming:\n%s", resp)
+	}
+
+	// 未读取的请求体在下一个请求之前被丢弃
+	resp = doRaw(s, post("/ignore", "Content-Length: 5\r\n", "abcde")+post("/count", "Content-Length: 2\r\n", "ok"))
+	if !strings.Contains(resp, "ignored") || !strings.HasSuffix(resp, "2 <nil>") {
+		t.Fatalf("unread body on keep-alive:\n%s", resp)
+	}
+}
@@ -0,0 +1,182 @@
+// bots.go
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RobotsRule robots.txt 中的一组规则
+type RobotsRule struct {
+	UserAgent  string // 默认 "*"
+	Allow      []string
+	Disallow   []string
+	CrawlDelay time.Duration
+}
+
+// RobotsConfig robots.txt 配置
+type RobotsConfig struct {
+	Rules    []RobotsRule
+	Sitemaps []string
+}
+
+// Robots 注册 GET /robots.txt
+func (s *HTTPServer) Robots(cfg RobotsConfig) *Route {
+	var b strings.Builder
+	for i, rule := range cfg.Rules {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		ua := rule.UserAgent
+		if ua == "" {
+			ua = "*"
+		}
+		b.WriteString("User-agent: " + ua + "\n")
+		for _, p := range rule.Allow {
+			b.WriteString("Allow: " + p + "\n")
+		}
+		for _, p := range rule.Disallow {
+			b.WriteString("Disallow: " + p + "\n")
+		}
+		if len(rule.Allow) == 0 && len(rule.Disallow) == 0 {
+			// 空的 Disallow 表示允许全部
+			b.WriteString("Disallow:\n")
+		}
+		if rule.CrawlDelay > 0 {
+			b.WriteString("Crawl-delay: " + strconv.FormatInt(int64(rule.CrawlDelay.Seconds()), 10) + "\n")
+		}
+	}
+	if len(cfg.Sitemaps) > 0 {
+		b.WriteString("\n")
+		for _, sm := range cfg.Sitemaps {
+			b.WriteString("Sitemap: " + sm + "\n")
+		}
+	}
+	return s.GET("/robots.txt", textFileHandler(b.String()))
+}
+
+// SecurityTxt security.txt 配置（RFC 9116）
+type SecurityTxt struct {
+	Contact            []string  // 必填，如 "mailto:security@example.com"
+	Expires            time.Time // 默认注册时间起一年
+	Encryption         []string
+	Acknowledgments    []string
+	PreferredLanguages string
+	Canonical          []string
+	Policy             []string
+	Hiring             []string
+}
+
+// SecurityTxt 注册 GET /.well-known/security.txt
+func (s *HTTPServer) SecurityTxt(cfg SecurityTxt) *Route {
+	expires := cfg.Expires
+	if expires.IsZero() {
+		expires = time.Now().AddDate(1, 0, 0)
+	}
+
+	var b strings.Builder
+	field := func(name string, values ...string) {
+		for _, v := range values {
+			if v != "" {
+				b.WriteString(name + ": " + v + "\n")
+			}
+		}
+	}
+	field("Contact", cfg.Contact...)
+	field("Expires", expires.UTC().Format(time.RFC3339))
+	field("Encryption", cfg.Encryption...)
+	field("Acknowledgments", cfg.Acknowledgments...)
+	field("Preferred-Languages", cfg.PreferredLanguages)
+	field("Canonical", cfg.Canonical...)
+	field("Policy", cfg.Policy...)
+	field("Hiring", cfg.Hiring...)
+	return s.GET("/.well-known/security.txt", textFileHandler(b.String()))
+}
+
+func textFileHandler(content string) HandlerFunc {
+	body := []byte(content)
+	return func(c *Context) {
+		c.Writer.SetHeader("Cache-Control", "public, max-age=3600")
+		c.Writer.Status(StatusOK)
+		c.Writer.writeBytes("text/plain; charset=utf-8", body)
+	}
+}
+
+// 用户代理分类
+const (
+	BotNone    = ""        // 普通浏览器
+	BotCrawler = "crawler" // 搜索引擎、社交预览等爬虫
+	BotTool    = "tool"    // curl、HTTP 库等脚本工具
+	BotOther   = "bot"     // 其它自称为 bot 的客户端，以及没有 User-Agent 的请求
+)
+
+// DefaultBotPatterns User-Agent 子串（小写）到分类的默认映射，按顺序匹配
+var DefaultBotPatterns = []BotPattern{
+	{"googlebot", BotCrawler}, {"bingbot", BotCrawler}, {"baiduspider", BotCrawler},
+	{"yandexbot", BotCrawler}, {"duckduckbot", BotCrawler}, {"sogou", BotCrawler},
+	{"bytespider", BotCrawler}, {"applebot", BotCrawler}, {"facebookexternalhit", BotCrawler},
+	{"twitterbot", BotCrawler}, {"slackbot", BotCrawler}, {"linkedinbot", BotCrawler},
+	{"curl/", BotTool}, {"wget/", BotTool}, {"python-requests", BotTool}, {"python-urllib", BotTool},
+	{"go-http-client", BotTool}, {"okhttp", BotTool}, {"java/", BotTool}, {"libwww-perl", BotTool},
+	{"httpclient", BotTool}, {"postmanruntime", BotTool},
+	{"bot", BotOther}, {"spider", BotOther}, {"crawl", BotOther}, {"scrapy", BotOther}, {"headless", BotOther},
+}
+
+// BotPattern User-Agent 匹配规则
+type BotPattern struct {
+	Match string // 小写子串
+	Kind  string
+}
+
+// BotDetectionConfig 用户代理分类配置
+type BotDetectionConfig struct {
+	// Patterns 额外的规则，优先于 DefaultBotPatterns
+	Patterns []BotPattern
+}
+
+const botKey = "meego.bot"
+
+// BotDetection 用户代理分类中间件，之后可通过 c.IsBot()/c.BotKind() 获取结果
+func BotDetection(cfg BotDetectionConfig) MiddlewareFunc {
+	patterns := append(append([]BotPattern(nil), cfg.Patterns...), DefaultBotPatterns...)
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Set(botKey, classifyUserAgent(c.Request.GetHeader("User-Agent"), patterns))
+			next(c)
+		}
+	}
+}
+
+// ClassifyUserAgent 按 DefaultBotPatterns 对 User-Agent 分类，返回 Bot* 常量之一
+func ClassifyUserAgent(ua string) string {
+	return classifyUserAgent(ua, DefaultBotPatterns)
+}
+
+func classifyUserAgent(ua string, patterns []BotPattern) string {
+	if ua == "" {
+		return BotOther
+	}
+	ua = strings.ToLower(ua)
+	for _, p := range patterns {
+		if strings.Contains(ua, p.Match) {
+			return p.Kind
+		}
+	}
+	return BotNone
+}
+
+// BotKind 返回请求的用户代理分类；未启用 BotDetection 时按默认规则即时分类
+func (c *Context) BotKind() string {
+	if kind, ok := c.Get(botKey).(string); ok {
+		return kind
+	}
+	kind := ClassifyUserAgent(c.Request.GetHeader("User-Agent"))
+	c.Set(botKey, kind)
+	return kind
+}
+
+// IsBot 请求是否来自爬虫或脚本工具
+func (c *Context) IsBot() bool {
+	return c.BotKind() != BotNone
+}
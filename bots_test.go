@@ -0,0 +1,38 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRobotsAndBotDetection(t *testing.T) {
+	s := New()
+	s.Use(BotDetection(BotDetectionConfig{Patterns: []BotPattern{{"uptime-monitor", BotTool}}}))
+	s.Robots(RobotsConfig{
+		Rules:    []RobotsRule{{Disallow: []string{"/admin"}}},
+		Sitemaps: []string{"https://example.com/sitemap.xml"},
+	})
+	s.SecurityTxt(SecurityTxt{Contact: []string{"mailto:security@example.com"}})
+	s.GET("/kind", func(c *Context) { c.String(StatusOK, "kind="+c.BotKind()) })
+
+	resp := doRaw(s, "GET /robots.txt HTTP/1.1\r\n\r\n")
+	if !strings.HasSuffix(resp, "User-agent: *\nDisallow: /admin\n\nSitemap: https://example.com/sitemap.xml\n") {
+		t.Fatalf("unexpected robots.txt: %q", resp)
+	}
+	resp = doRaw(s, "GET /.well-known/security.txt HTTP/1.1\r\n\r\n")
+	if !strings.Contains(resp, "Contact: mailto:security@example.com\nExpires: ") {
+		t.Fatalf("unexpected security.txt: %q", resp)
+	}
+
+	for ua, want := range map[string]string{
+		"Mozilla/5.0 (compatible; Googlebot/2.1)": BotCrawler,
+		"curl/8.4.0":         BotTool,
+		"Uptime-Monitor/1.0": BotTool,
+		"Mozilla/5.0 (Macintosh) Safari/605.1.15": BotNone,
+	} {
+		resp := doRaw(s, "GET /kind HTTP/1.1\r\nUser-Agent: "+ua+"\r\n\r\n")
+		if !strings.HasSuffix(resp, "kind="+want) {
+			t.Errorf("%s: got %q, want %q", ua, resp, want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// cache.go
+package meego
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+)
+
+// headerCacheRevalidate 后台刷新请求的标记头，值为每个缓存中间件随机生成的令牌
+const headerCacheRevalidate = "X-Meego-Cache-Revalidate"
+
+// CacheConfig 响应缓存配置
+type CacheConfig struct {
+	// Store 缓存存储，默认为进程内 MemoryStore
+	Store CacheStore
+	// TTL 条目保持新鲜的时间，默认 1 分钟
+	TTL time.Duration
+	// StaleWhileRevalidate 过期后仍可直接返回旧响应的时间，同时在协程池中后台刷新
+	StaleWhileRevalidate time.Duration
+	// StaleIfError 过期后处理器返回 5xx（或没有写出响应）时，改为返回旧响应的时间
+	StaleIfError time.Duration
+	// Key 缓存键，默认为请求方法和 URI。响应带有 Vary 时，Vary 列出的请求头的值会追加到键中
+	Key func(c *Context) string
+}
+
+// Cache 响应缓存中间件，缓存 ttl 时间
+func Cache(ttl time.Duration) MiddlewareFunc {
+	return CacheWithConfig(CacheConfig{TTL: ttl})
+}
+
+// CacheWithConfig 使用自定义配置的响应缓存中间件。只缓存 GET/HEAD 请求的完整（非流式）响应，
+// 带 Authorization 的请求、带 Set-Cookie、Cache-Control: no-store/private 或 Vary: * 的响应不缓存。
+// 带 Vary 的响应按 Vary 列出的请求头分别缓存。响应带有 X-Cache（HIT、MISS、STALE）和 Age 头
+func CacheWithConfig(cfg CacheConfig) MiddlewareFunc {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Minute
+	}
+	if cfg.Key == nil {
+		cfg.Key = func(c *Context) string {
+			return c.Request.Method + " " + c.Request.RawURL
+		}
+	}
+	rc := &responseCache{
+		cfg:        cfg,
+		token:      RandomIDs.NewID(IDRequest),
+		refreshing: make(map[string]bool),
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			method := c.Request.Method
+			if (method != "GET" && method != "HEAD") || c.Request.GetHeader("Authorization") != "" {
+				next(c)
+				return
+			}
+			base := cfg.Key(c)
+
+			if token := c.Request.GetHeader(headerCacheRevalidate); token != "" && token == rc.token {
+				// 后台刷新：执行处理器并更新缓存
+				delete(c.Request.Headers, headerCacheRevalidate)
+				rc.capture(c, base, nil)
+				next(c)
+				return
+			}
+
+			entry, key := rc.lookup(c, base)
+			var age time.Duration
+			if entry != nil {
+				age = now().Sub(time.Unix(0, entry.StoredAt))
+			}
+			switch {
+			case entry != nil && age < cfg.TTL:
+				rc.serve(c, entry, "HIT", age)
+				return
+			case entry != nil && age < cfg.TTL+cfg.StaleWhileRevalidate:
+				rc.serve(c, entry, "STALE", age)
+				rc.refresh(c, key, entry.StoredAt)
+				return
+			}
+
+			var stale *cacheEntry
+			if entry != nil && age < cfg.TTL+cfg.StaleIfError {
+				stale = entry
+			}
+			c.Writer.SetHeader("X-Cache", "MISS")
+			rc.capture(c, base, stale)
+			next(c)
+			if stale != nil && !c.Writer.Written() && !c.Writer.Streaming() {
+				rc.serve(c, stale, "STALE", age)
+			}
+		}
+	}
+}
+
+// cacheEntry 缓存的响应
+type cacheEntry struct {
+	Status   int               `json:"status"`
+	Header   map[string]string `json:"header"`
+	Body     []byte            `json:"body"`
+	StoredAt int64             `json:"stored_at"` // UnixNano
+	// Vary 不为空时条目只是索引：响应按这些请求头的值存放在 variantKey 下
+	Vary []string `json:"vary,omitempty"`
+}
+
+type responseCache struct {
+	cfg   CacheConfig
+	token string
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+
+	revalidatorOnce sync.Once
+	revalidator     *TestServer
+}
+
+func (rc *responseCache) load(key string) *cacheEntry {
+	data, found, err := rc.cfg.Store.Get(key)
+	if err != nil {
+		log.Error().Err(err).Msg("cache store error")
+		return nil
+	}
+	if !found {
+		return nil
+	}
+	entry := &cacheEntry{}
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, entry); err != nil {
+		return nil
+	}
+	return entry
+}
+
+// lookup 读取请求对应的条目，返回条目实际所在的键
+func (rc *responseCache) lookup(c *Context, base string) (*cacheEntry, string) {
+	entry := rc.load(base)
+	if entry == nil || len(entry.Vary) == 0 {
+		return entry, base
+	}
+	key := variantKey(c, base, entry.Vary)
+	return rc.load(key), key
+}
+
+// variantKey 把 Vary 列出的请求头的值追加到键中
+func variantKey(c *Context, base string, vary []string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, name := range vary {
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(": ")
+		b.WriteString(c.Request.GetHeader(name))
+	}
+	return b.String()
+}
+
+// varyNames 返回响应 Vary 头列出的请求头名（规范化）
+func varyNames(w *ResponseWriter) []string {
+	var names []string
+	for _, name := range strings.Split(w.header["Vary"], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, http.CanonicalHeaderKey(name))
+		}
+	}
+	return names
+}
+
+// storeTTL 条目在存储中保留的时间，覆盖两种过期后可用的窗口
+func (rc *responseCache) storeTTL() time.Duration {
+	extra := rc.cfg.StaleWhileRevalidate
+	if rc.cfg.StaleIfError > extra {
+		extra = rc.cfg.StaleIfError
+	}
+	return rc.cfg.TTL + extra
+}
+
+// capture 在写出前记录可缓存的响应；处理器返回 5xx 且有可用的旧响应时替换为旧响应。
+// 响应带有 Vary 时，在 base 下记录 Vary 的索引，响应存放在对应请求头的变体键下
+func (rc *responseCache) capture(c *Context, base string, stale *cacheEntry) {
+	c.Writer.transforms = append(c.Writer.transforms, func(w *ResponseWriter, body []byte) []byte {
+		if w.status >= 500 && stale != nil {
+			for k := range w.header {
+				delete(w.header, k)
+			}
+			for k, v := range stale.Header {
+				w.header[k] = v
+			}
+			w.header["X-Cache"] = "STALE"
+			w.header["Age"] = strconv.Itoa(int(now().Sub(time.Unix(0, stale.StoredAt)).Seconds()))
+			w.status = stale.Status
+			return stale.Body
+		}
+		if cacheable(w) {
+			if vary := varyNames(w); len(vary) > 0 {
+				rc.put(base, &cacheEntry{Vary: vary, StoredAt: now().UnixNano()})
+				rc.store(variantKey(c, base, vary), w, body)
+			} else {
+				rc.store(base, w, body)
+			}
+		}
+		return body
+	})
+}
+
+func (rc *responseCache) store(key string, w *ResponseWriter, body []byte) {
+	entry := cacheEntry{
+		Status:   w.status,
+		Header:   make(map[string]string, len(w.header)),
+		Body:     body,
+		StoredAt: now().UnixNano(),
+	}
+	for k, v := range w.header {
+		switch k {
+		case "Content-Length", "Connection", "Date", "Transfer-Encoding", "X-Cache", "Age":
+			continue
+		}
+		entry.Header[k] = v
+	}
+	rc.put(key, &entry)
+}
+
+func (rc *responseCache) put(key string, entry *cacheEntry) {
+	data, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(entry)
+	if err == nil {
+		err = rc.cfg.Store.Set(key, data, rc.storeTTL())
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("cache store error")
+	}
+}
+
+// cacheable 响应是否可以缓存
+func cacheable(w *ResponseWriter) bool {
+	switch w.status {
+	case StatusOK, StatusNonAuthoritativeInfo, StatusNoContent, StatusMultipleChoices,
+		StatusMovedPermanently, StatusNotFound, StatusGone:
+	default:
+		return false
+	}
+	if len(w.cookies) > 0 || containsFold(strings.Split(w.header["Vary"], ","), "*") {
+		return false
+	}
+	cc := strings.ToLower(w.header["Cache-Control"])
+	return !strings.Contains(cc, "no-store") && !strings.Contains(cc, "private")
+}
+
+// serve 返回缓存的响应
+func (rc *responseCache) serve(c *Context, entry *cacheEntry, state string, age time.Duration) {
+	for k, v := range entry.Header {
+		c.Writer.SetHeader(k, v)
+	}
+	c.Writer.SetHeader("X-Cache", state)
+	c.Writer.SetHeader("Age", strconv.Itoa(int(age.Seconds())))
+	c.Writer.Status(entry.Status).writeResponse(entry.Body)
+}
+
+// refresh 在协程池中重新执行请求以更新缓存，同一个键同时只有一个刷新。
+// storedAt 为请求读到的条目的写入时间，条目已被其它刷新更新时不再刷新
+func (rc *responseCache) refresh(c *Context, key string, storedAt int64) {
+	s := c.server
+	if s == nil {
+		return
+	}
+	rc.mu.Lock()
+	if rc.refreshing[key] {
+		rc.mu.Unlock()
+		return
+	}
+	rc.refreshing[key] = true
+	rc.mu.Unlock()
+	if current := rc.load(key); current == nil || current.StoredAt != storedAt {
+		rc.refreshDone(key)
+		return
+	}
+
+	// 上下文在请求结束后回收，先复制需要的请求信息
+	method, uri, host := c.Request.Method, c.Request.RawURL, c.Request.Host
+	headers := make(map[string]string, len(c.Request.Headers))
+	for k, v := range c.Request.Headers {
+		if !isHopHeader(k) {
+			headers[k] = v
+		}
+	}
+
+	task := func() {
+		defer rc.refreshDone(key)
+		rc.revalidatorOnce.Do(func() {
+			rc.revalidator = NewTestServer(s)
+			// 关闭服务器时释放刷新连接，等待其处理协程退出
+			s.OnShutdown(rc.revalidator.Close)
+		})
+		req, err := http.NewRequest(method, rc.revalidator.URL+uri, nil)
+		if err != nil {
+			return
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		req.Host = host
+		req.Header.Set(headerCacheRevalidate, rc.token)
+		resp, err := rc.revalidator.Client().Do(req)
+		if err != nil {
+			log.Warn().Err(err).Str("key", key).Msg("cache revalidation failed")
+			return
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	if err := s.pool.Submit(task); err != nil {
+		rc.refreshDone(key)
+	}
+}
+
+func (rc *responseCache) refreshDone(key string) {
+	rc.mu.Lock()
+	delete(rc.refreshing, key)
+	rc.mu.Unlock()
+}
@@ -0,0 +1,96 @@
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheStale(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer SetClock(clock)()
+
+	var calls, failing int32
+	s := New()
+	defer s.Shutdown()
+	s.Use(CacheWithConfig(CacheConfig{TTL: 10 * time.Second, StaleWhileRevalidate: 30 * time.Second, StaleIfError: time.Minute}))
+	s.GET("/n", func(c *Context) {
+		if atomic.LoadInt32(&failing) == 1 {
+			c.Writer.Status(StatusServiceUnavailable).JSON(JSON{"error": "down", "code": StatusServiceUnavailable})
+			return
+		}
+		c.String(StatusOK, strconv.Itoa(int(atomic.AddInt32(&calls, 1))))
+	})
+	get := func() string { return doRaw(s, "GET /n HTTP/1.1\r\nConnection: close\r\n\r\n") }
+
+	if resp := get(); !strings.Contains(resp, "X-Cache: MISS") || !strings.HasSuffix(resp, "\r\n\r\n1") {
+		t.Fatalf("miss: %q", resp)
+	}
+	if resp := get(); !strings.Contains(resp, "X-Cache: HIT") || !strings.HasSuffix(resp, "\r\n\r\n1") {
+		t.Fatalf("hit: %q", resp)
+	}
+
+	// 过期后先返回旧响应，后台刷新
+	clock.Advance(15 * time.Second)
+	if resp := get(); !strings.Contains(resp, "X-Cache: STALE") || !strings.Contains(resp, "Age: 15") || !strings.HasSuffix(resp, "\r\n\r\n1") {
+		t.Fatalf("stale while revalidate: %q", resp)
+	}
+	resp := get()
+	for deadline := time.Now().Add(2 * time.Second); !strings.Contains(resp, "X-Cache: HIT") && time.Now().Before(deadline); resp = get() {
+		time.Sleep(time.Millisecond)
+	}
+	if !strings.HasSuffix(resp, "\r\n\r\n2") || atomic.LoadInt32(&calls) != 2 {
+		t.Fatalf("refreshed: %q", resp)
+	}
+
+	// 超过 stale-while-revalidate，处理器出错时返回旧响应
+	atomic.StoreInt32(&failing, 1)
+	clock.Advance(50 * time.Second)
+	if resp := get(); !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.Contains(resp, "X-Cache: STALE") || !strings.HasSuffix(resp, "\r\n\r\n2") {
+		t.Fatalf("stale if error: %q", resp)
+	}
+	clock.Advance(time.Minute)
+	if resp := get(); !strings.HasPrefix(resp, "HTTP/1.1 503") {
+		t.Fatalf("expected error after stale-if-error window: %q", resp)
+	}
+}
+
+func TestCacheVary(t *testing.T) {
+	var calls int32
+	s := New()
+	s.Use(Cache(time.Minute))
+	s.GET("/greet", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.Writer.AddVary("Accept-Language")
+		c.String(StatusOK, "lang="+c.Request.GetHeader("Accept-Language"))
+	})
+	s.GET("/any", func(c *Context) {
+		atomic.AddInt32(&calls, 1)
+		c.Writer.AddVary("Origin", "*")
+		c.String(StatusOK, "any")
+	})
+	get := func(path, lang string) string {
+		return doRaw(s, "GET "+path+" HTTP/1.1\r\nAccept-Language: "+lang+"\r\nConnection: close\r\n\r\n")
+	}
+
+	// 每个 Accept-Language 的值分别缓存，不会把一个客户端的响应返回给另一个
+	for i, step := range []struct{ lang, cache string }{
+		{"en", "MISS"}, {"fr", "MISS"}, {"en", "HIT"}, {"fr", "HIT"},
+	} {
+		resp := get("/greet", step.lang)
+		if !strings.Contains(resp, "X-Cache: "+step.cache) || !strings.HasSuffix(resp, "\r\n\r\nlang="+step.lang) {
+			t.Fatalf("step %d (%s): %q", i, step.lang, resp)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("handler calls: %d", n)
+	}
+
+	// Vary: * 的响应不缓存
+	get("/any", "en")
+	if resp := get("/any", "en"); !strings.Contains(resp, "X-Cache: MISS") || atomic.LoadInt32(&calls) != 4 {
+		t.Fatalf("vary * cached: %q", resp)
+	}
+}
@@ -0,0 +1,75 @@
+// canary.go
+package meego
+
+import (
+	"math/rand"
+)
+
+// routeVariant 路由的灰度版本
+type routeVariant struct {
+	handler HandlerFunc
+	percent int                 // 按比例分流，0-100
+	match   func(*Context) bool // 按条件分流，优先于按比例
+}
+
+// Canary 为路由添加按比例分流的灰度版本（应在注册阶段调用）：
+//
+//	server.GET("/checkout", v1).Canary(v2, 10) // 10% 流量进入 v2
+func (r *Route) Canary(handler HandlerFunc, percent int) *Route {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	return r.addVariant(routeVariant{handler: handler, percent: percent})
+}
+
+// CanaryWhen 为路由添加按条件分流的灰度版本，match 返回 true 的请求进入该版本
+func (r *Route) CanaryWhen(match func(*Context) bool, handler HandlerFunc) *Route {
+	return r.addVariant(routeVariant{handler: handler, match: match})
+}
+
+// CanaryHeader 请求头 name 的值等于 value 时进入灰度版本
+func (r *Route) CanaryHeader(name, value string, handler HandlerFunc) *Route {
+	return r.CanaryWhen(func(c *Context) bool {
+		return c.Request.GetHeader(name) == value
+	}, handler)
+}
+
+// CanaryCookie Cookie name 的值等于 value 时进入灰度版本，可用于粘性灰度
+func (r *Route) CanaryCookie(name, value string, handler HandlerFunc) *Route {
+	return r.CanaryWhen(func(c *Context) bool {
+		v, err := c.Cookie(name)
+		return err == nil && v == value
+	}, handler)
+}
+
+func (r *Route) addVariant(v routeVariant) *Route {
+	r.variants = append(r.variants, v)
+	r.handler = r.dispatch
+	return r
+}
+
+// dispatch 选择本次请求使用的版本：先匹配条件版本，再按比例分流，其余走主版本
+func (r *Route) dispatch(c *Context) {
+	for _, v := range r.variants {
+		if v.match != nil && v.match(c) {
+			v.handler(c)
+			return
+		}
+	}
+
+	n := rand.Intn(100)
+	for _, v := range r.variants {
+		if v.match != nil {
+			continue
+		}
+		if n < v.percent {
+			v.handler(c)
+			return
+		}
+		n -= v.percent
+	}
+	r.primary(c)
+}
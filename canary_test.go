@@ -0,0 +1,20 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanaryRouting(t *testing.T) {
+	s := New()
+	s.GET("/v", func(c *Context) { c.String(StatusOK, "v1") }).
+		CanaryHeader("X-Canary", "1", func(c *Context) { c.String(StatusOK, "v2") }).
+		Canary(func(c *Context) { c.String(StatusOK, "v3") }, 100)
+
+	if resp := doRaw(s, "GET /v HTTP/1.1\r\nX-Canary: 1\r\n\r\n"); !strings.HasSuffix(resp, "v2") {
+		t.Fatalf("header canary not selected: %q", resp)
+	}
+	if resp := doRaw(s, "GET /v HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "v3") {
+		t.Fatalf("weighted canary not selected: %q", resp)
+	}
+}
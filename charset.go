@@ -0,0 +1,171 @@
+// charset.go
+package meego
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// CharsetConfig 字符集转换配置
+type CharsetConfig struct {
+	// DisableRequest 不转换请求体
+	DisableRequest bool
+	// DisableResponse 不根据 Accept-Charset 转换响应
+	DisableResponse bool
+}
+
+// Charset 字符集转换中间件，面向只支持 GBK、Shift_JIS 等旧字符集的客户端：
+//   - 请求体的 Content-Type 声明了非 UTF-8 字符集时，在绑定之前转换为 UTF-8；
+//   - 文本响应（text/*、JSON、XML）按 Accept-Charset 中最优先的可用字符集编码，
+//     UTF-8 与其它字符集优先级相同时保持 UTF-8。流式响应不转换
+func Charset(cfg CharsetConfig) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if !cfg.DisableRequest {
+				if err := decodeRequestCharset(c.Request); err != nil {
+					c.Writer.Status(StatusUnsupportedMediaType).JSON(JSON{
+						"error": err.Error(),
+						"code":  StatusUnsupportedMediaType,
+					})
+					return
+				}
+			}
+			if !cfg.DisableResponse {
+				if accept := c.Request.GetHeader("Accept-Charset"); accept != "" {
+					c.Writer.charset, c.Writer.encoder = negotiateCharset(accept)
+				}
+				c.Writer.AddVary("Accept-Charset")
+			}
+			next(c)
+		}
+	}
+}
+
+// decodeRequestCharset 将请求体转换为 UTF-8，并把 Content-Type 的 charset 改为 utf-8
+func decodeRequestCharset(req *HTTPRequest) error {
+	contentType := req.GetHeader("Content-Type")
+	if contentType == "" || (len(req.Body) == 0 && !req.Streaming()) {
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	charset := strings.ToLower(params["charset"])
+	if charset == "" || charset == "utf-8" || charset == "utf8" {
+		return nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return &charsetError{charset}
+	}
+	// 需要转换编码的流式请求体读入内存
+	if _, err := req.ReadBody(); err != nil {
+		return err
+	}
+	body, err := enc.NewDecoder().Bytes(req.Body)
+	if err != nil {
+		return &charsetError{charset}
+	}
+
+	req.Body = body
+	params["charset"] = "utf-8"
+	req.setHeader("Content-Type", mime.FormatMediaType(mediaType, params))
+	req.setHeader("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+type charsetError struct{ charset string }
+
+func (e *charsetError) Error() string {
+	return "unsupported charset: " + e.charset
+}
+
+// setHeader 替换请求头，保留原有的键名大小写
+func (r *HTTPRequest) setHeader(key, value string) {
+	for k := range r.Headers {
+		if strings.EqualFold(k, key) {
+			r.Headers[k] = value
+			return
+		}
+	}
+	r.Headers[key] = value
+}
+
+// negotiateCharset 按 Accept-Charset 选择响应字符集，选中 UTF-8 或没有可用字符集时返回空
+func negotiateCharset(accept string) (string, encoding.Encoding) {
+	bestQ, best := -1.0, ""
+	var bestEnc encoding.Encoding
+	utf8Q := -1.0
+	for _, part := range strings.Split(accept, ",") {
+		name, q := parseQuality(part)
+		if name == "" || q <= 0 {
+			continue
+		}
+		if name == "utf-8" || name == "utf8" || name == "*" {
+			if q > utf8Q {
+				utf8Q = q
+			}
+			continue
+		}
+		enc, err := htmlindex.Get(name)
+		if err != nil {
+			continue
+		}
+		if q > bestQ {
+			bestQ, best, bestEnc = q, name, enc
+		}
+	}
+	if bestEnc == nil || utf8Q >= bestQ {
+		return "", nil
+	}
+	return best, bestEnc
+}
+
+// parseQuality 解析 "gbk;q=0.8" 形式的条目，名称转为小写，默认权重 1
+func parseQuality(part string) (string, float64) {
+	name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	q := 1.0
+	for _, p := range strings.Split(params, ";") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(p), "="); ok && strings.EqualFold(k, "q") {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(name)), q
+}
+
+// encodeCharset 按协商的字符集编码文本响应体，并更新 Content-Type
+func (w *ResponseWriter) encodeCharset(body []byte) []byte {
+	contentType := w.header["Content-Type"]
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !isTextMediaType(mediaType) {
+		return body
+	}
+	if cs := strings.ToLower(params["charset"]); cs != "" && cs != "utf-8" {
+		// 处理器已经自行编码
+		return body
+	}
+	encoded, err := w.encoder.NewEncoder().Bytes(body)
+	if err != nil {
+		// 存在目标字符集无法表示的字符，保持 UTF-8
+		return body
+	}
+	params["charset"] = w.charset
+	w.header["Content-Type"] = mime.FormatMediaType(mediaType, params)
+	delete(w.header, "Content-Length")
+	return encoded
+}
+
+func isTextMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		mediaType == "application/xml" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		strings.HasSuffix(mediaType, "+xml")
+}
@@ -0,0 +1,39 @@
+package meego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCharsetConversion(t *testing.T) {
+	s := New()
+	s.Use(Charset(CharsetConfig{}))
+	s.POST("/echo", func(c *Context) {
+		var in struct{ Name string }
+		if err := c.BindJSON(&in); err != nil {
+			c.String(StatusBadRequest, err.Error())
+			return
+		}
+		c.String(StatusOK, in.Name)
+	})
+
+	// "张三" 的 GBK 编码
+	body := "{\"Name\":\"\xd5\xc5\xc8\xfd\"}"
+	raw := fmt.Sprintf("POST /echo HTTP/1.1\r\nContent-Type: application/json; charset=GBK\r\n"+
+		"Accept-Charset: gbk, utf-8;q=0.5\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+	resp := doRaw(s, raw)
+	if !strings.Contains(resp, "Content-Type: text/plain; charset=gbk\r\n") || !strings.HasSuffix(resp, "\xd5\xc5\xc8\xfd") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+
+	raw = fmt.Sprintf("POST /echo HTTP/1.1\r\nContent-Type: application/json; charset=GBK\r\nContent-Length: %d\r\n\r\n%s", len(body), body)
+	if resp := doRaw(s, raw); !strings.HasSuffix(resp, "张三") {
+		t.Fatalf("expected UTF-8 response: %q", resp)
+	}
+
+	raw = "POST /echo HTTP/1.1\r\nContent-Type: application/json; charset=x-unknown\r\nContent-Length: 2\r\n\r\n{}"
+	if resp := doRaw(s, raw); !strings.HasPrefix(resp, "HTTP/1.1 415") {
+		t.Fatalf("expected 415: %q", resp)
+	}
+}
@@ -0,0 +1,122 @@
+// client.go
+package meego
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Client 内置出站 HTTP 客户端，自动透传追踪上下文、请求 ID 和剩余处理时间
+type Client struct {
+	HTTPClient *http.Client
+
+	stats *clientStats // NewClientWithConfig 创建的客户端记录连接指标
+
+	defaultTLS *tls.Config            // ClientConfig.TLS
+	hostTLS    map[string]*tls.Config // SetHostTLS 设置的按主机 TLS 配置
+
+	mu        sync.RWMutex
+	upstreams map[string]*Upstream
+}
+
+// AddUpstream 注册逻辑上游：请求 URL 的主机名为 name 时（如 http://orders/api），
+// 通过服务发现选择实际地址，Host 头保持为 name
+func (cl *Client) AddUpstream(name string, up *Upstream) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if cl.upstreams == nil {
+		cl.upstreams = make(map[string]*Upstream)
+	}
+	cl.upstreams[name] = up
+}
+
+// NewClient 使用默认连接池配置创建内置客户端
+func NewClient() *Client {
+	return NewClientWithConfig(ClientConfig{})
+}
+
+// Do 发送请求；c 不为空时注入 c.PropagationHeaders() 中尚未设置的请求头，
+// 并在请求未指定 context 时随入站请求一起取消
+func (cl *Client) Do(c *Context, req *http.Request) (*http.Response, error) {
+	if c != nil {
+		for key, value := range c.PropagationHeaders() {
+			if req.Header.Get(key) == "" {
+				req.Header.Set(key, value)
+			}
+		}
+		if req.Context() == context.Background() {
+			req = req.WithContext(c.StdContext())
+		}
+	}
+
+	if cl.stats != nil {
+		req = cl.stats.trace(req)
+		if req.URL.Scheme == "https" {
+			req = req.WithContext(cl.withUpstreamTLS(req.Context(), req.URL.Host))
+		}
+	}
+
+	cl.mu.RLock()
+	up := cl.upstreams[req.URL.Host]
+	cl.mu.RUnlock()
+	if up == nil {
+		return cl.HTTPClient.Do(req)
+	}
+
+	addr, done, err := up.Pick(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+	u := *req.URL
+	u.Host = addr
+	req.URL = &u
+
+	start := time.Now()
+	resp, err := cl.HTTPClient.Do(req)
+	if err != nil {
+		up.observe(addr, time.Since(start), 0, err)
+		done()
+		return nil, err
+	}
+	up.observe(addr, time.Since(start), resp.StatusCode, nil)
+	resp.Body = &doneReadCloser{ReadCloser: resp.Body, done: done}
+	return resp, nil
+}
+
+// doneReadCloser 响应体关闭时通知负载均衡器请求结束
+type doneReadCloser struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (r *doneReadCloser) Close() error {
+	r.once.Do(r.done)
+	return r.ReadCloser.Close()
+}
+
+// Get 发送 GET 请求
+func (cl *Client) Get(c *Context, url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cl.Do(c, req)
+}
+
+// Post 发送 POST 请求
+func (cl *Client) Post(c *Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest("POST", url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return cl.Do(c, req)
+}
@@ -0,0 +1,214 @@
+// client_pool.go
+package meego
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ClientConfig 内置客户端的连接池配置，零值字段使用默认值
+type ClientConfig struct {
+	// Timeout 单个请求的总超时（包括读取响应体），默认 30s，负数表示不限制
+	Timeout time.Duration
+	// MaxIdleConns 所有主机的空闲连接总数上限，默认 256
+	MaxIdleConns int
+	// MaxIdleConnsPerHost 每个主机保留的空闲连接数，默认 32
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost 每个主机的连接总数上限（包括使用中的），默认 0 不限制
+	MaxConnsPerHost int
+	// IdleConnTimeout 空闲连接保留时长，默认 90s
+	IdleConnTimeout time.Duration
+	// DialTimeout 建立 TCP 连接的超时，默认 5s
+	DialTimeout time.Duration
+	// KeepAlive TCP keep-alive 探测间隔，默认 30s
+	KeepAlive time.Duration
+	// DisableCompression 不自动请求和解压 gzip 响应，代理时应开启
+	DisableCompression bool
+	// TLS 所有 https 上游的默认 TLS 选项，可用 SetHostTLS 按主机覆盖
+	TLS *UpstreamTLS
+}
+
+// NewClientWithConfig 创建带连接池的内置客户端。连接按主机复用，
+// 可通过 Stats 查看建连、复用和每个主机的打开连接数。TLS 证书无法加载时 panic
+func NewClientWithConfig(cfg ClientConfig) *Client {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	} else if cfg.Timeout < 0 {
+		cfg.Timeout = 0
+	}
+	if cfg.MaxIdleConns <= 0 {
+		cfg.MaxIdleConns = 256
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = 32
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = 90 * time.Second
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.KeepAlive <= 0 {
+		cfg.KeepAlive = 30 * time.Second
+	}
+
+	cl := &Client{stats: &clientStats{open: make(map[string]int)}}
+	if cfg.TLS != nil {
+		tlsConfig, err := cfg.TLS.tlsConfig()
+		if err != nil {
+			panic("meego: client tls: " + err.Error())
+		}
+		if tlsConfig.InsecureSkipVerify {
+			log.Warn().Msg("TLS certificate verification DISABLED for all upstreams")
+		}
+		cl.defaultTLS = tlsConfig
+	}
+
+	dial := cl.stats.dialer(&net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: cfg.KeepAlive})
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dial,
+		DialTLSContext:        cl.dialTLS(dial),
+		TLSClientConfig:       cl.defaultTLS,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     true,
+		DisableCompression:    cfg.DisableCompression,
+	}
+	cl.HTTPClient = &http.Client{Transport: transport, Timeout: cfg.Timeout}
+	return cl
+}
+
+// ClientStats 出站连接指标快照
+type ClientStats struct {
+	Requests   int64          // 发出的请求数
+	Dials      int64          // 新建的连接数
+	DialErrors int64          // 建连失败次数
+	Reused     int64          // 复用空闲连接的请求数
+	OpenConns  map[string]int // 每个主机当前打开的连接数
+}
+
+// Stats 返回连接指标；HTTPClient 被替换或未使用 NewClientWithConfig 创建时返回零值
+func (cl *Client) Stats() ClientStats {
+	if cl.stats == nil {
+		return ClientStats{}
+	}
+	return cl.stats.snapshot()
+}
+
+// String 以 Prometheus 文本格式输出
+func (st ClientStats) String() string {
+	var b strings.Builder
+	b.WriteString("# HELP meego_client_requests_total Outbound HTTP requests.\n")
+	b.WriteString("# TYPE meego_client_requests_total counter\n")
+	fmt.Fprintf(&b, "meego_client_requests_total %d\n", st.Requests)
+	b.WriteString("# HELP meego_client_dials_total Outbound connections dialed.\n")
+	b.WriteString("# TYPE meego_client_dials_total counter\n")
+	fmt.Fprintf(&b, "meego_client_dials_total %d\n", st.Dials)
+	b.WriteString("# HELP meego_client_dial_errors_total Failed outbound dials.\n")
+	b.WriteString("# TYPE meego_client_dial_errors_total counter\n")
+	fmt.Fprintf(&b, "meego_client_dial_errors_total %d\n", st.DialErrors)
+	b.WriteString("# HELP meego_client_connections_reused_total Requests served on a reused connection.\n")
+	b.WriteString("# TYPE meego_client_connections_reused_total counter\n")
+	fmt.Fprintf(&b, "meego_client_connections_reused_total %d\n", st.Reused)
+	b.WriteString("# HELP meego_client_open_connections Open outbound connections per host.\n")
+	b.WriteString("# TYPE meego_client_open_connections gauge\n")
+	hosts := make([]string, 0, len(st.OpenConns))
+	for host := range st.OpenConns {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "meego_client_open_connections{host=%q} %d\n", host, st.OpenConns[host])
+	}
+	return b.String()
+}
+
+// clientStats 连接计数
+type clientStats struct {
+	requests   atomic.Int64
+	dials      atomic.Int64
+	dialErrors atomic.Int64
+	reused     atomic.Int64
+
+	mu   sync.Mutex
+	open map[string]int
+}
+
+func (s *clientStats) snapshot() ClientStats {
+	st := ClientStats{
+		Requests:   s.requests.Load(),
+		Dials:      s.dials.Load(),
+		DialErrors: s.dialErrors.Load(),
+		Reused:     s.reused.Load(),
+	}
+	s.mu.Lock()
+	st.OpenConns = make(map[string]int, len(s.open))
+	for host, n := range s.open {
+		st.OpenConns[host] = n
+	}
+	s.mu.Unlock()
+	return st
+}
+
+// dialer 包装拨号函数，跟踪每个主机的打开连接数
+func (s *clientStats) dialer(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			s.dialErrors.Add(1)
+			return nil, err
+		}
+		s.dials.Add(1)
+		s.mu.Lock()
+		s.open[addr]++
+		s.mu.Unlock()
+		return &trackedConn{Conn: conn, stats: s, addr: addr}, nil
+	}
+}
+
+// trace 为请求挂上连接跟踪
+func (s *clientStats) trace(req *http.Request) *http.Request {
+	s.requests.Add(1)
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				s.reused.Add(1)
+			}
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// trackedConn 关闭时减少主机的打开连接数
+type trackedConn struct {
+	net.Conn
+	stats *clientStats
+	addr  string
+	once  sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.once.Do(func() {
+		c.stats.mu.Lock()
+		if c.stats.open[c.addr]--; c.stats.open[c.addr] <= 0 {
+			delete(c.stats.open, c.addr)
+		}
+		c.stats.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
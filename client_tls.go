@@ -0,0 +1,160 @@
+// client_tls.go
+package meego
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// UpstreamTLS 出站 TLS 选项：客户端证书（mTLS）、自定义根证书和跳过校验
+type UpstreamTLS struct {
+	// CertFile、KeyFile 客户端证书，上游要求 mTLS 时使用
+	CertFile string
+	KeyFile  string
+	// Certificates 已加载的客户端证书，与 CertFile 合并
+	Certificates []tls.Certificate
+	// CAFile 用于校验上游证书的根证书（PEM），设置后不再信任系统根证书
+	CAFile string
+	// RootCAs 已加载的根证书，与 CAFile 合并
+	RootCAs *x509.CertPool
+	// ServerName 校验证书时使用的名称，默认为请求 URL 的主机名（逻辑上游为上游名）
+	ServerName string
+	// InsecureSkipVerify 不校验上游证书，仅用于测试环境，每次建连都会记录警告
+	InsecureSkipVerify bool
+}
+
+// tlsConfig 构建 tls.Config
+func (t *UpstreamTLS) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		NextProtos:         []string{"h2", "http/1.1"},
+	}
+	cfg.Certificates = append(cfg.Certificates, t.Certificates...)
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+
+	cfg.RootCAs = t.RootCAs
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("ca file: %w", err)
+		}
+		if cfg.RootCAs == nil {
+			cfg.RootCAs = x509.NewCertPool()
+		} else {
+			cfg.RootCAs = cfg.RootCAs.Clone()
+		}
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca file %s: no certificates found", t.CAFile)
+		}
+	}
+	return cfg, nil
+}
+
+// SetHostTLS 为主机（host、host:port 或 AddUpstream 注册的逻辑上游名）设置 TLS 选项，
+// 覆盖 ClientConfig.TLS；t 为 nil 时删除。只对 NewClient/NewClientWithConfig 创建的客户端有效
+func (cl *Client) SetHostTLS(host string, t *UpstreamTLS) error {
+	if cl.stats == nil {
+		return errors.New("meego: SetHostTLS requires a client created by NewClientWithConfig")
+	}
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	if t == nil {
+		delete(cl.hostTLS, host)
+		return nil
+	}
+	cfg, err := t.tlsConfig()
+	if err != nil {
+		return fmt.Errorf("meego: tls for %s: %w", host, err)
+	}
+	if cfg.InsecureSkipVerify {
+		log.Warn().Str("host", host).Msg("TLS certificate verification DISABLED for upstream")
+	}
+	if cl.hostTLS == nil {
+		cl.hostTLS = make(map[string]*tls.Config)
+	}
+	cl.hostTLS[host] = cfg
+	return nil
+}
+
+// lookupTLS 查找主机的 TLS 配置，依次匹配 host:port、主机名，最后使用默认配置
+func (cl *Client) lookupTLS(host string) *tls.Config {
+	cl.mu.RLock()
+	defer cl.mu.RUnlock()
+	if cfg, ok := cl.hostTLS[host]; ok {
+		return cfg
+	}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		if cfg, ok := cl.hostTLS[name]; ok {
+			return cfg
+		}
+	}
+	return cl.defaultTLS
+}
+
+// upstreamTLSKey 请求 context 中记录的 TLS 选择，逻辑上游改写地址后仍按上游名选择配置
+type upstreamTLSKey struct{}
+
+type upstreamTLSChoice struct {
+	config     *tls.Config
+	serverName string
+}
+
+// withUpstreamTLS 在地址改写前记录请求主机对应的 TLS 配置和证书名
+func (cl *Client) withUpstreamTLS(ctx context.Context, host string) context.Context {
+	name := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		name = h
+	}
+	return context.WithValue(ctx, upstreamTLSKey{}, upstreamTLSChoice{
+		config:     cl.lookupTLS(host),
+		serverName: name,
+	})
+}
+
+// dialTLS 建立 TCP 连接后按主机选择的配置完成 TLS 握手
+func (cl *Client) dialTLS(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		choice, ok := ctx.Value(upstreamTLSKey{}).(upstreamTLSChoice)
+		if !ok {
+			choice.config = cl.lookupTLS(addr)
+			choice.serverName, _, _ = net.SplitHostPort(addr)
+		}
+		cfg := &tls.Config{MinVersion: tls.VersionTLS12, NextProtos: []string{"h2", "http/1.1"}}
+		if choice.config != nil {
+			cfg = choice.config.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = choice.serverName
+		}
+		if cfg.InsecureSkipVerify {
+			log.Warn().Str("addr", addr).Str("server_name", cfg.ServerName).
+				Msg("opening upstream TLS connection WITHOUT certificate verification")
+		}
+
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tc := tls.Client(conn, cfg)
+		if err := tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tc, nil
+	}
+}
@@ -0,0 +1,213 @@
+// clock.go
+package meego
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock 时间来源。超时中间件、请求期限、Date 响应头、内存存储（限流计数、会话）的过期、
+// 配额窗口、对冲请求、调试延迟、日志轮转以及健康检查、心跳等定时任务都通过它取时间，测试中用 SetClock 注入
+// FakeClock 后可以直接拨动时间，不需要 sleep
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker Clock 创建的周期定时器
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock 使用系统时间的时钟（默认）
+var SystemClock Clock = systemClock{}
+
+type clockHolder struct{ Clock }
+
+var currentClock atomic.Value
+
+func init() {
+	currentClock.Store(clockHolder{SystemClock})
+}
+
+// SetClock 替换全局时钟，返回恢复原时钟的函数，通常在测试中配合 defer 使用：
+//
+//	clock := meego.NewFakeClock(time.Now())
+//	defer meego.SetClock(clock)()
+func SetClock(c Clock) (restore func()) {
+	if c == nil {
+		c = SystemClock
+	}
+	prev := currentClock.Swap(clockHolder{c}).(clockHolder)
+	return func() { currentClock.Store(prev) }
+}
+
+// clock 返回当前时钟
+func clock() Clock {
+	return currentClock.Load().(clockHolder).Clock
+}
+
+// now 当前时钟的时间
+func now() time.Time {
+	return clock().Now()
+}
+
+// wallTime 把按当前时钟计算的时间点换算为系统时间。net.Conn 的读写期限和
+// 标准 context 只认系统时间，注入 FakeClock 时按剩余时长换算
+func wallTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Now().Add(t.Sub(now()))
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (systemClock) NewTicker(d time.Duration) Ticker       { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct{ t *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+func (t systemTicker) Stop()               { t.t.Stop() }
+
+// FakeClock 手动拨动的时钟，Advance 时触发到期的 After 和 Ticker
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	at      time.Time
+	period  time.Duration // 0 表示 After
+	ch      chan time.Time
+	stopped bool
+}
+
+// NewFakeClock 创建从 t 开始的手动时钟
+func NewFakeClock(t time.Time) *FakeClock {
+	return &FakeClock{now: t}
+}
+
+// Now 返回手动时钟的当前时间
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// After 在时钟前进 d 之后收到当前时间
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{at: f.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- f.now
+		return w.ch
+	}
+	f.waiters = append(f.waiters, w)
+	return w.ch
+}
+
+// NewTicker 每当时钟前进 d 触发一次；与 time.Ticker 一样，接收方来不及处理时丢弃多余的触发
+func (f *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("meego: non-positive interval for NewTicker")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWaiter{at: f.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	f.waiters = append(f.waiters, w)
+	return &fakeTicker{clock: f, w: w}
+}
+
+// Advance 时钟前进 d，按到期顺序触发定时器
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.fire()
+}
+
+// Set 把时钟设置为 t（不能回拨），触发到期的定时器
+func (f *FakeClock) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.After(f.now) {
+		f.now = t
+	}
+	f.fire()
+}
+
+// Waiters 返回等待中的定时器数量，测试可以据此确认协程已经开始等待
+func (f *FakeClock) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+func (f *FakeClock) fire() {
+	sort.SliceStable(f.waiters, func(i, j int) bool { return f.waiters[i].at.Before(f.waiters[j].at) })
+	kept := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.stopped {
+			continue
+		}
+		for !w.at.After(f.now) {
+			select {
+			case w.ch <- w.at:
+			default:
+			}
+			if w.period == 0 {
+				break
+			}
+			w.at = w.at.Add(w.period)
+		}
+		if w.period > 0 || w.at.After(f.now) {
+			kept = append(kept, w)
+		}
+	}
+	for i := len(kept); i < len(f.waiters); i++ {
+		f.waiters[i] = nil
+	}
+	f.waiters = kept
+}
+
+type fakeTicker struct {
+	clock *FakeClock
+	w     *fakeWaiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.w.stopped = true
+}
+
+// cachedDate 按秒缓存的 Date 响应头
+type cachedDate struct {
+	unix  int64
+	value string
+}
+
+var dateCache atomic.Pointer[cachedDate]
+
+// httpDate 返回当前时钟时间的 HTTP 日期格式
+func httpDate() string {
+	t := now()
+	if d := dateCache.Load(); d != nil && d.unix == t.Unix() {
+		return d.value
+	}
+	d := &cachedDate{unix: t.Unix(), value: t.UTC().Format(http.TimeFormat)}
+	dateCache.Store(d)
+	return d.value
+}
@@ -0,0 +1,106 @@
+package meego
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFakeClock(start)
+	defer SetClock(fake)()
+
+	s := New()
+	s.Use(RateLimit(RateLimitConfig{Limit: 1, Window: time.Minute}))
+	s.GET("/", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+	req := "GET / HTTP/1.1\r\nHost: x\r\n\r\n"
+	if resp := doRaw(s, req); !strings.Contains(resp, "Date: Fri, 01 Mar 2024 12:00:00 GMT") {
+		t.Fatalf("date header:\n%s", resp)
+	}
+	if resp := doRaw(s, req); !strings.HasPrefix(resp, "HTTP/1.1 429") {
+		t.Fatalf("expected rate limit:\n%s", resp)
+	}
+	fake.Advance(time.Minute)
+	if resp := doRaw(s, req); !strings.HasPrefix(resp, "HTTP/1.1 200") {
+		t.Fatalf("window should reset after advancing the clock:\n%s", resp)
+	}
+
+	// 定时器随时钟触发，来不及接收的触发被丢弃
+	ticker := fake.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	fake.Advance(25 * time.Second)
+	if got := <-ticker.C(); !got.Equal(start.Add(time.Minute + 10*time.Second)) {
+		t.Fatalf("ticker fired at %v", got)
+	}
+
+	timeout := fake.After(time.Hour)
+	fake.Advance(59 * time.Minute)
+	select {
+	case <-timeout:
+		t.Fatal("after fired early")
+	default:
+	}
+	fake.Advance(time.Minute)
+	<-timeout
+}
+
+func TestClockDeadlines(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fake := NewFakeClock(start)
+	defer SetClock(fake)()
+
+	s := New()
+	s.Use(DebugOverrides(DebugOverridesConfig{Token: "secret"}))
+	var (
+		deadline  time.Time
+		remaining string
+		before    error
+		after     error
+	)
+	s.GET("/d", func(c *Context) {
+		deadline, _ = c.Deadline()
+		remaining = c.PropagationHeaders()[HeaderRequestTimeout]
+		before = c.Err()
+		fake.Advance(time.Second)
+		after = c.Err()
+		c.String(StatusOK, "ok")
+	})
+
+	// 请求期限按注入的时钟计算，拨动时钟即可让请求过期
+	resp := doRaw(s, "GET /d HTTP/1.1\r\nHost: x\r\nX-Request-Timeout: 500\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") {
+		t.Fatalf("unexpected response:\n%s", resp)
+	}
+	if !deadline.Equal(start.Add(500*time.Millisecond)) || remaining != "500" {
+		t.Fatalf("deadline %v, propagated %q", deadline, remaining)
+	}
+	if before != nil || after != context.DeadlineExceeded {
+		t.Fatalf("err before %v, after %v", before, after)
+	}
+
+	// 调试延迟等待注入的时钟
+	done := make(chan string, 1)
+	go func() {
+		done <- doRaw(s, "GET /d HTTP/1.1\r\nHost: x\r\nX-Meego-Debug-Token: secret\r\nX-Meego-Debug-Delay: 10s\r\n\r\n")
+	}()
+	for wait := time.Now(); fake.Waiters() == 0; time.Sleep(time.Millisecond) {
+		if time.Since(wait) > 2*time.Second {
+			t.Fatal("debug delay is not waiting on the injected clock")
+		}
+	}
+	select {
+	case resp := <-done:
+		t.Fatalf("delay finished before the clock moved:\n%s", resp)
+	case <-time.After(20 * time.Millisecond):
+	}
+	fake.Advance(10 * time.Second)
+	if resp := <-done; !strings.HasSuffix(resp, "ok") {
+		t.Fatalf("delayed request:\n%s", resp)
+	}
+}
@@ -0,0 +1,261 @@
+// compression.go
+package meego
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// CompressWriter 压缩编码器写入端，Flush 把已写入的数据编码输出（流式响应每个数据块后调用）
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// CompressionEncoder 自定义内容编码，如 brotli：
+//
+//	meego.CompressionEncoder{Name: "br", NewWriter: func(w io.Writer, level int) meego.CompressWriter {
+//		return brotli.NewWriterLevel(w, brotli.DefaultCompression)
+//	}}
+type CompressionEncoder struct {
+	// Name Content-Encoding 的值
+	Name string
+	// NewWriter 创建写入 w 的编码器，level 为 CompressionConfig.Level
+	NewWriter func(w io.Writer, level int) CompressWriter
+}
+
+// CompressionConfig 响应压缩配置
+type CompressionConfig struct {
+	// Level 压缩级别，默认 flate.DefaultCompression
+	Level int
+	// MinLength 小于该长度的非流式响应不压缩，默认 1024
+	MinLength int
+	// ContentTypes 压缩的媒体类型，以 "/" 结尾表示前缀，默认为文本、JSON、JavaScript、XML、SVG 等
+	ContentTypes []string
+	// Encoders 额外的编码（如 br），客户端同等偏好时优先于内置的 gzip 和 deflate
+	Encoders []CompressionEncoder
+}
+
+// DefaultCompressibleTypes 默认压缩的媒体类型
+var DefaultCompressibleTypes = []string{
+	"text/",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/wasm",
+	"application/x-ndjson",
+	"image/svg+xml",
+}
+
+// Compression 使用默认配置的响应压缩中间件
+func Compression() MiddlewareFunc {
+	return CompressionWithConfig(CompressionConfig{})
+}
+
+// CompressionWithConfig 使用自定义配置的响应压缩中间件：按 Accept-Encoding 协商 gzip、deflate
+// 或自定义编码，设置 Content-Encoding 和 Vary: Accept-Encoding。已编码、过小、类型不适合压缩、
+// 带 Cache-Control: no-transform 的响应和 206 响应不压缩。流式响应每个数据块压缩后立即发送；
+// 设置了 Content-Length 的流式响应不压缩
+func CompressionWithConfig(cfg CompressionConfig) MiddlewareFunc {
+	if cfg.Level == 0 {
+		cfg.Level = flate.DefaultCompression
+	}
+	if cfg.MinLength <= 0 {
+		cfg.MinLength = 1024
+	}
+	if len(cfg.ContentTypes) == 0 {
+		cfg.ContentTypes = DefaultCompressibleTypes
+	}
+	encoders := append([]CompressionEncoder(nil), cfg.Encoders...)
+	encoders = append(encoders,
+		CompressionEncoder{Name: "gzip", NewWriter: newGzipWriter},
+		CompressionEncoder{Name: "deflate", NewWriter: newZlibWriter},
+	)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Writer.compression = &responseCompression{
+				cfg:     &cfg,
+				encoder: negotiateEncoding(c.Request.GetHeader("Accept-Encoding"), encoders),
+			}
+			next(c)
+		}
+	}
+}
+
+// negotiateEncoding 选择客户端 q 值最高的编码，同等 q 值按 encoders 的顺序，不接受任何编码时返回 nil
+func negotiateEncoding(header string, encoders []CompressionEncoder) *CompressionEncoder {
+	if header == "" {
+		return nil
+	}
+	quality := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, q := parseQuality(part)
+		quality[name] = q
+	}
+	var best *CompressionEncoder
+	bestQ := 0.0
+	for i := range encoders {
+		q, ok := quality[encoders[i].Name]
+		if !ok {
+			q, ok = quality["*"]
+		}
+		if ok && q > bestQ {
+			best, bestQ = &encoders[i], q
+		}
+	}
+	return best
+}
+
+// responseCompression 单个响应的压缩状态
+type responseCompression struct {
+	cfg     *CompressionConfig
+	encoder *CompressionEncoder // 协商的编码，nil 表示客户端不接受压缩
+	stream  CompressWriter      // 流式响应的编码器
+}
+
+// compressible 响应是否适合压缩；类型适合压缩时无论是否压缩都添加 Vary
+func (rc *responseCompression) compressible(w *ResponseWriter) bool {
+	if !bodyAllowedForStatus(w.status) || w.status == StatusPartialContent {
+		return false
+	}
+	if w.header["Content-Encoding"] != "" && !strings.EqualFold(w.header["Content-Encoding"], "identity") {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(w.header["Content-Type"])
+	if !rc.compressibleType(mediaType) {
+		return false
+	}
+	w.AddVary("Accept-Encoding")
+	return rc.encoder != nil && !strings.Contains(strings.ToLower(w.header["Cache-Control"]), "no-transform")
+}
+
+func (rc *responseCompression) compressibleType(mediaType string) bool {
+	if mediaType == "" {
+		return false
+	}
+	for _, t := range rc.cfg.ContentTypes {
+		if mediaType == t || (strings.HasSuffix(t, "/") && strings.HasPrefix(mediaType, t)) {
+			return true
+		}
+	}
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+// setEncodingHeaders 设置 Content-Encoding，强 ETag 改为弱 ETag（编码后的字节不同）
+func (rc *responseCompression) setEncodingHeaders(w *ResponseWriter) {
+	w.header["Content-Encoding"] = rc.encoder.Name
+	delete(w.header, "Content-Length")
+	if etag := w.header["ETag"]; etag != "" && !strings.HasPrefix(etag, "W/") {
+		w.header["ETag"] = "W/" + etag
+	}
+}
+
+// compressBody 压缩非流式响应体，在字符集编码之后执行
+func (rc *responseCompression) compressBody(w *ResponseWriter, body []byte) []byte {
+	if len(body) < rc.cfg.MinLength || !rc.compressible(w) {
+		return body
+	}
+	var buf bytes.Buffer
+	zw := rc.encoder.NewWriter(&buf, rc.cfg.Level)
+	_, err := zw.Write(body)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return body
+	}
+	rc.setEncodingHeaders(w)
+	return buf.Bytes()
+}
+
+// startStream 流式响应发送头部之前决定是否压缩，持有 w.mu 时调用
+func (rc *responseCompression) startStream(w *ResponseWriter) {
+	if _, fixedLength := w.header["Content-Length"]; fixedLength || w.method == "HEAD" || !rc.compressible(w) {
+		return
+	}
+	rc.setEncodingHeaders(w)
+	rc.stream = rc.encoder.NewWriter(chunkWriter{w}, rc.cfg.Level)
+}
+
+// finish 结束压缩流，发送编码器缓冲的剩余数据
+func (rc *responseCompression) finish() {
+	if rc.stream != nil {
+		rc.stream.Close()
+		rc.stream = nil
+	}
+}
+
+// chunkWriter 把编码器的输出作为数据块发送
+type chunkWriter struct{ w *ResponseWriter }
+
+func (cw chunkWriter) Write(p []byte) (int, error) {
+	if err := cw.w.writeChunk(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+var (
+	gzipWriters  = make(map[int]*sync.Pool)
+	zlibWriters  = make(map[int]*sync.Pool)
+	writerPoolMu sync.Mutex
+)
+
+// writerPool 按压缩级别取得编码器对象池
+func writerPool(pools map[int]*sync.Pool, level int) *sync.Pool {
+	writerPoolMu.Lock()
+	defer writerPoolMu.Unlock()
+	p := pools[level]
+	if p == nil {
+		p = &sync.Pool{}
+		pools[level] = p
+	}
+	return p
+}
+
+// pooledWriter Close 后放回对象池的编码器
+type pooledWriter struct {
+	CompressWriter
+	pool *sync.Pool
+}
+
+func (p *pooledWriter) Close() error {
+	err := p.CompressWriter.Close()
+	p.pool.Put(p.CompressWriter)
+	p.CompressWriter = nil
+	return err
+}
+
+func newGzipWriter(w io.Writer, level int) CompressWriter {
+	pool := writerPool(gzipWriters, level)
+	if zw, ok := pool.Get().(*gzip.Writer); ok {
+		zw.Reset(w)
+		return &pooledWriter{zw, pool}
+	}
+	zw, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		zw = gzip.NewWriter(w)
+	}
+	return &pooledWriter{zw, pool}
+}
+
+// newZlibWriter HTTP 的 deflate 编码是 zlib 格式（RFC 9110 8.4.1.2），不是原始 deflate 流
+func newZlibWriter(w io.Writer, level int) CompressWriter {
+	pool := writerPool(zlibWriters, level)
+	if zw, ok := pool.Get().(*zlib.Writer); ok {
+		zw.Reset(w)
+		return &pooledWriter{zw, pool}
+	}
+	zw, err := zlib.NewWriterLevel(w, level)
+	if err != nil {
+		zw = zlib.NewWriter(w)
+	}
+	return &pooledWriter{zw, pool}
+}
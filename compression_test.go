@@ -0,0 +1,127 @@
+package meego
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCompression(t *testing.T) {
+	text := strings.Repeat("compressible text ", 200)
+	s := New()
+	s.Use(Compression())
+	s.GET("/text", func(c *Context) {
+		c.Writer.SetHeader("ETag", `"v1"`)
+		c.String(StatusOK, text)
+	})
+	s.GET("/small", func(c *Context) { c.String(StatusOK, "tiny") })
+	s.GET("/png", func(c *Context) { c.Data(StatusOK, "image/png", []byte(text)) })
+	s.GET("/stream", func(c *Context) {
+		c.Writer.SetHeader("Content-Type", "text/event-stream")
+		for i := 0; i < 3; i++ {
+			c.Writer.WriteChunk([]byte("data: " + text[:50] + "\n\n"))
+		}
+	})
+
+	resp, body := readRawResponse(t, doRaw(s, "GET /text HTTP/1.1\r\nAccept-Encoding: br;q=1, gzip;q=0.8, deflate;q=0.5\r\n\r\n"))
+	if resp.Header.Get("Content-Encoding") != "gzip" || resp.Header.Get("Vary") != "Accept-Encoding" ||
+		resp.Header.Get("ETag") != `W/"v1"` || len(body) >= len(text) {
+		t.Fatalf("gzip response: %v, %d bytes", resp.Header, len(body))
+	}
+	zr, err := gzip.NewReader(strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, _ := io.ReadAll(zr); string(plain) != text {
+		t.Fatalf("gzip body mismatch: %d bytes", len(plain))
+	}
+
+	resp, body = readRawResponse(t, doRaw(s, "GET /text HTTP/1.1\r\nAccept-Encoding: deflate\r\n\r\n"))
+	zl, err := zlib.NewReader(strings.NewReader(string(body)))
+	if resp.Header.Get("Content-Encoding") != "deflate" || err != nil {
+		t.Fatalf("deflate response: %v %v", resp.Header, err)
+	}
+	if plain, _ := io.ReadAll(zl); string(plain) != text {
+		t.Fatalf("deflate body mismatch: %d bytes", len(plain))
+	}
+
+	for _, path := range []string{"/small", "/png"} {
+		resp, body = readRawResponse(t, doRaw(s, "GET "+path+" HTTP/1.1\r\nAccept-Encoding: gzip\r\n\r\n"))
+		if resp.Header.Get("Content-Encoding") != "" || (path == "/small" && string(body) != "tiny") {
+			t.Fatalf("%s should not be compressed: %v", path, resp.Header)
+		}
+	}
+	if resp, _ = readRawResponse(t, doRaw(s, "GET /text HTTP/1.1\r\n\r\n")); resp.Header.Get("Content-Encoding") != "" ||
+		resp.Header.Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("identity response: %v", resp.Header)
+	}
+
+	resp, body = readRawResponse(t, doRaw(s, "GET /stream HTTP/1.1\r\nAccept-Encoding: gzip\r\n\r\n"))
+	if resp.Header.Get("Content-Encoding") != "gzip" || len(resp.TransferEncoding) == 0 {
+		t.Fatalf("stream response: %v %v", resp.Header, resp.TransferEncoding)
+	}
+	zr, err = gzip.NewReader(strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, _ := io.ReadAll(zr); strings.Count(string(plain), "data: ") != 3 {
+		t.Fatalf("stream body mismatch: %q", plain)
+	}
+}
+
+func gzipString(t *testing.T, s string) string {
+	t.Helper()
+	var b strings.Builder
+	zw := gzip.NewWriter(&b)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	zw.Close()
+	return b.String()
+}
+
+func TestDecompress(t *testing.T) {
+	s := New()
+	s.Use(DecompressWithConfig(DecompressConfig{MaxSize: 1 << 20}))
+	s.POST("/json", func(c *Context) {
+		var v struct{ Name string }
+		if err := c.BindJSON(&v); err != nil {
+			c.String(StatusBadRequest, err.Error())
+			return
+		}
+		c.String(StatusOK, v.Name+" "+c.Request.GetHeader("Content-Encoding"))
+	})
+	s.POST("/stream", func(c *Context) {
+		body, err := io.ReadAll(c.Request.BodyReader())
+		if err != nil {
+			c.String(StatusRequestEntityTooLarge, err.Error())
+			return
+		}
+		c.String(StatusOK, string(body))
+	}).StreamBody()
+
+	post := func(path, encoding, body string) string {
+		return doRaw(s, "POST "+path+" HTTP/1.1\r\nContent-Encoding: "+encoding+"\r\nContent-Length: "+
+			strconv.Itoa(len(body))+"\r\n\r\n"+body)
+	}
+	if resp := post("/json", "gzip", gzipString(t, `{"Name":"meego"}`)); !strings.HasSuffix(resp, "\r\n\r\nmeego ") {
+		t.Fatalf("gzip json: %q", resp)
+	}
+	if resp := post("/stream", "gzip", gzipString(t, "streamed")); !strings.HasSuffix(resp, "\r\n\r\nstreamed") {
+		t.Fatalf("gzip stream: %q", resp)
+	}
+	if resp := post("/json", "br", "xx"); !strings.HasPrefix(resp, "HTTP/1.1 415") {
+		t.Fatalf("unsupported encoding: %q", resp)
+	}
+	if resp := post("/json", "gzip", "not gzip"); !strings.HasPrefix(resp, "HTTP/1.1 400") {
+		t.Fatalf("corrupt body: %q", resp)
+	}
+	// 压缩炸弹：2MB 的零压缩后只有几 KB
+	bomb := gzipString(t, strings.Repeat("\x00", 2<<20))
+	if resp := post("/json", "gzip", bomb); !strings.HasPrefix(resp, "HTTP/1.1 413") {
+		t.Fatalf("bomb: %q", resp)
+	}
+}
@@ -0,0 +1,64 @@
+// concurrency_limit.go
+package meego
+
+import "time"
+
+// concurrencyLimit 路由组的并发上限设置
+type concurrencyLimit struct {
+	max  int
+	wait time.Duration
+}
+
+// MaxConcurrency 限制路由同时执行的请求数，用于报表生成、导出等开销大的接口，
+// 与全局协程池独立。达到上限时新请求最多排队 wait，仍未获得名额返回 429；wait <= 0 时立即返回 429
+func (r *Route) MaxConcurrency(n int, wait time.Duration) *Route {
+	return r.Use(ConcurrencyLimit(n, wait))
+}
+
+// MaxConcurrency 为路由组内之后注册的每个路由分别设置并发上限
+func (g *RouteGroup) MaxConcurrency(n int, wait time.Duration) *RouteGroup {
+	g.concurrency = concurrencyLimit{max: n, wait: wait}
+	return g
+}
+
+// ConcurrencyLimit 并发上限中间件，同一个中间件实例注册到多个路由时它们共享名额
+func ConcurrencyLimit(n int, wait time.Duration) MiddlewareFunc {
+	if n <= 0 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			select {
+			case sem <- struct{}{}:
+			default:
+				if !acquireSlot(c, sem, wait) {
+					c.Writer.SetHeader("Retry-After", "1")
+					c.Writer.Status(StatusTooManyRequests).JSON(JSON{
+						"error": "Too Many Requests",
+						"code":  StatusTooManyRequests,
+					})
+					return
+				}
+			}
+			defer func() { <-sem }()
+			next(c)
+		}
+	}
+}
+
+// acquireSlot 排队等待名额，超时或请求被取消时返回 false
+func acquireSlot(c *Context, sem chan struct{}, wait time.Duration) bool {
+	if wait <= 0 {
+		return false
+	}
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-clock().After(wait):
+		return false
+	case <-c.Done():
+		return false
+	}
+}
@@ -0,0 +1,38 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrency(t *testing.T) {
+	s := New()
+	started, unblock := make(chan struct{}), make(chan struct{})
+	s.GET("/export", func(c *Context) {
+		started <- struct{}{}
+		<-unblock
+		c.String(StatusOK, "done")
+	}).MaxConcurrency(1, 0)
+	s.GET("/report", func(c *Context) { c.String(StatusOK, "report") }).MaxConcurrency(1, time.Second)
+
+	first := make(chan string, 1)
+	go func() { first <- doRaw(s, "GET /export HTTP/1.1\r\n\r\n") }()
+	<-started
+	if resp := doRaw(s, "GET /export HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 429") || !strings.Contains(resp, "Retry-After: 1") {
+		t.Fatalf("expected 429: %q", resp)
+	}
+	// 其它路由的名额独立
+	if resp := doRaw(s, "GET /report HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "report") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+	close(unblock)
+	if resp := <-first; !strings.HasSuffix(resp, "done") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+	// 名额释放后可以再次执行
+	go func() { <-started }()
+	if resp := doRaw(s, "GET /export HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "done") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+}
@@ -0,0 +1,89 @@
+// conn_values.go
+package meego
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+)
+
+// ConnKeyTLS ConnValues 中 TLS 连接的握手状态（*tls.ConnectionState）
+const ConnKeyTLS = "meego.tls"
+
+// ConnValues 连接级存储，在连接的整个生命周期内有效（包括 Hijack 之后的协议），
+// 适合缓存握手得到的信息和连接级认证结果。可以并发访问
+type ConnValues struct {
+	mu     sync.RWMutex
+	values map[string]interface{}
+}
+
+// newConnValues 创建连接存储，TLS 连接预置握手状态
+func newConnValues(conn net.Conn) *ConnValues {
+	v := &ConnValues{}
+	if tc, ok := conn.(*tls.Conn); ok {
+		state := tc.ConnectionState()
+		v.Set(ConnKeyTLS, &state)
+	}
+	return v
+}
+
+// Get 读取值
+func (v *ConnValues) Get(key string) (interface{}, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	value, ok := v.values[key]
+	return value, ok
+}
+
+// Set 写入值
+func (v *ConnValues) Set(key string, value interface{}) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.values == nil {
+		v.values = make(map[string]interface{}, 4)
+	}
+	v.values[key] = value
+}
+
+// Delete 删除值
+func (v *ConnValues) Delete(key string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.values, key)
+}
+
+// TLS 返回 TLS 握手状态，非 TLS 连接返回 nil
+func (v *ConnValues) TLS() *tls.ConnectionState {
+	state, _ := v.Get(ConnKeyTLS)
+	tlsState, _ := state.(*tls.ConnectionState)
+	return tlsState
+}
+
+// OnConnect 注册连接建立（TLS 握手完成）后、读取请求之前执行的回调，用于给连接打标签；
+// 回调返回错误时直接关闭连接
+func (s *HTTPServer) OnConnect(fn func(conn net.Conn, values *ConnValues) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connectHooks = append(s.connectHooks, fn)
+}
+
+// runConnectHooks 执行连接回调
+func (s *HTTPServer) runConnectHooks(conn net.Conn, values *ConnValues) error {
+	s.mu.RLock()
+	hooks := s.connectHooks
+	s.mu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(conn, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConnValues 返回当前连接的存储
+func (c *Context) ConnValues() *ConnValues {
+	if c.connValues == nil {
+		c.connValues = newConnValues(c.Conn)
+	}
+	return c.connValues
+}
@@ -0,0 +1,29 @@
+package meego
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestConnValues(t *testing.T) {
+	s := New()
+	s.OnConnect(func(conn net.Conn, values *ConnValues) error {
+		if conn.RemoteAddr().String() == "blocked" {
+			return errors.New("blocked")
+		}
+		values.Set("tag", "edge-1")
+		return nil
+	})
+	s.GET("/", func(c *Context) {
+		tag, _ := c.ConnValues().Get("tag")
+		c.ConnValues().Set("authenticated", true)
+		c.String(StatusOK, fmt.Sprintf("%v %v", tag, c.ConnValues().TLS() == nil))
+	})
+
+	if resp := doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\n\r\n"); !strings.HasSuffix(resp, "edge-1 true") {
+		t.Fatalf("conn values:\n%s", resp)
+	}
+}
@@ -0,0 +1,67 @@
+// context_cancel.go
+package meego
+
+import (
+	"context"
+	"time"
+)
+
+// Context 实现 context.Context：服务器 Shutdown 或超过写超时期限时被取消，
+// 处理器中的长循环可以通过 c.Err() / c.Canceled() 协作退出
+
+// Deadline 返回请求处理期限
+func (c *Context) Deadline() (time.Time, bool) {
+	return c.deadline, !c.deadline.IsZero()
+}
+
+// Done 返回请求取消时关闭的 channel，首次调用时创建
+func (c *Context) Done() <-chan struct{} {
+	return c.StdContext().Done()
+}
+
+// Err 返回取消原因：服务器关闭时为 context.Canceled，超过期限时为 context.DeadlineExceeded
+func (c *Context) Err() error {
+	if c.stdCtx != nil {
+		return c.stdCtx.Err()
+	}
+	// 未创建标准 context 时直接判断，避免分配
+	if c.server != nil {
+		if err := c.server.serverCtx.Err(); err != nil {
+			return err
+		}
+	}
+	if !c.deadline.IsZero() && !now().Before(c.deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// Value 按字符串键读取 c.Set 设置的值
+func (c *Context) Value(key interface{}) interface{} {
+	if k, ok := key.(string); ok {
+		return c.Values[k]
+	}
+	return nil
+}
+
+// Canceled 请求是否已被取消
+func (c *Context) Canceled() bool {
+	return c.Err() != nil
+}
+
+// StdContext 返回与本次请求生命周期一致的标准 context，可安全地传递给
+// 数据库驱动、net/http 等第三方库（*Context 本身会被放回对象池复用）
+func (c *Context) StdContext() context.Context {
+	if c.stdCtx == nil {
+		parent := context.Background()
+		if c.server != nil {
+			parent = c.server.serverCtx
+		}
+		if c.deadline.IsZero() {
+			c.stdCtx, c.cancel = context.WithCancel(parent)
+		} else {
+			c.stdCtx, c.cancel = context.WithDeadline(parent, wallTime(c.deadline))
+		}
+	}
+	return c.stdCtx
+}
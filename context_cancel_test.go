@@ -0,0 +1,46 @@
+package meego
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextCancellation(t *testing.T) {
+	s := New()
+	entered := make(chan struct{})
+	s.GET("/wait", func(c *Context) {
+		close(entered)
+		select {
+		case <-c.Done():
+		case <-time.After(2 * time.Second):
+		}
+		c.String(StatusOK, fmt.Sprint(c.Err()))
+	})
+	var pollErr error
+	s.GET("/poll", func(c *Context) {
+		// 不创建标准 context 时 Err 直接按期限判断
+		for start := time.Now(); !c.Canceled() && time.Since(start) < 2*time.Second; {
+			time.Sleep(time.Millisecond)
+		}
+		// 期限同时是写超时，超过后无法再写出响应
+		pollErr = c.Err()
+	})
+
+	// X-Request-Timeout 缩短处理期限
+	doRaw(s, "GET /poll HTTP/1.1\r\nX-Request-Timeout: 20\r\n\r\n")
+	if pollErr != context.DeadlineExceeded {
+		t.Fatalf("deadline: %v", pollErr)
+	}
+
+	done := make(chan string)
+	go func() { done <- doRaw(s, "GET /wait HTTP/1.1\r\n\r\n") }()
+	<-entered
+	start := time.Now()
+	s.Shutdown()
+	if resp := <-done; !strings.HasSuffix(resp, context.Canceled.Error()) || time.Since(start) > time.Second {
+		t.Fatalf("shutdown did not cancel the request:\n%s", resp)
+	}
+}
@@ -0,0 +1,77 @@
+// curl.go
+package meego
+
+import (
+	"crypto/tls"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SensitiveHeaders 生成 curl 命令时替换为 REDACTED 的请求头（大小写不敏感）
+var SensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// maxCurlBody 写入 curl 命令的请求体上限，超过或非文本时只保留说明
+const maxCurlBody = 64 * 1024
+
+// CurlCommand 生成复现当前请求的 curl 命令，敏感请求头已脱敏
+func (c *Context) CurlCommand() string {
+	c.guard.check("Context", "CurlCommand")
+	req := c.Request
+
+	scheme := "http"
+	if _, ok := c.Conn.(*tls.Conn); ok {
+		scheme = "https"
+	}
+	host := req.Host
+	if host == "" {
+		host = "localhost"
+	}
+
+	var b strings.Builder
+	b.WriteString("curl -X ")
+	b.WriteString(req.Method)
+	b.WriteString(" " + shellQuote(scheme+"://"+host+req.RawURL))
+
+	keys := make([]string, 0, len(req.Headers))
+	for k := range req.Headers {
+		// Host 已在 URL 中，Content-Length 由 curl 计算
+		if strings.EqualFold(k, "Host") || strings.EqualFold(k, "Content-Length") {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := req.Headers[k]
+		for _, sensitive := range SensitiveHeaders {
+			if strings.EqualFold(k, sensitive) {
+				value = "REDACTED"
+				break
+			}
+		}
+		b.WriteString(" -H " + shellQuote(k+": "+value))
+	}
+
+	switch body := req.Body; {
+	case req.bodyReader != nil:
+		b.WriteString(" # streamed request body not captured")
+	case len(body) == 0:
+	case len(body) > maxCurlBody || !utf8.Valid(body):
+		b.WriteString(" # request body omitted (binary or too large)")
+	default:
+		b.WriteString(" --data-binary " + shellQuote(string(body)))
+	}
+	return b.String()
+}
+
+// shellQuote 用单引号包裹参数，可直接粘贴到 sh/bash
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,21 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCurlCommand(t *testing.T) {
+	s := New()
+	s.POST("/orders", func(c *Context) {
+		c.String(StatusOK, c.CurlCommand())
+	})
+
+	resp := doRaw(s, "POST /orders?x=1 HTTP/1.1\r\nHost: api.example.com\r\nAuthorization: Bearer secret\r\n"+
+		"Content-Type: application/json\r\nContent-Length: 13\r\n\r\n{\"id\":\"it's\"}")
+	want := `curl -X POST 'http://api.example.com/orders?x=1' -H 'Authorization: REDACTED' ` +
+		`-H 'Content-Type: application/json' --data-binary '{"id":"it'\''s"}'`
+	if !strings.HasSuffix(resp, want) {
+		t.Fatalf("unexpected curl command:\n%s\nwant suffix:\n%s", resp, want)
+	}
+}
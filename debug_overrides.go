@@ -0,0 +1,122 @@
+// debug_overrides.go
+package meego
+
+import (
+	"crypto/subtle"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 调试覆盖请求头
+const (
+	HeaderDebugToken  = "X-Meego-Debug-Token"  // DebugOverridesConfig.Token
+	HeaderDebugDelay  = "X-Meego-Debug-Delay"  // 处理前等待的时长
+	HeaderForceStatus = "X-Meego-Force-Status" // 跳过处理器直接返回的状态码
+)
+
+// ParseHeaderDuration 解析请求头中的时长：纯数字按毫秒（与 X-Request-Timeout 一致），
+// 否则按 time.ParseDuration 格式（如 "1.5s"）。为空、无效、不为正数或超出 time.Duration
+// 范围时返回 false
+func ParseHeaderDuration(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		// 先检查范围再换算，避免乘法溢出成负数或很小的时长
+		if ms <= 0 || ms > math.MaxInt64/int64(time.Millisecond) {
+			return 0, false
+		}
+		return time.Duration(ms) * time.Millisecond, true
+	}
+	d, err := time.ParseDuration(value)
+	return d, err == nil && d > 0
+}
+
+// ParseHeaderLimit 解析请求头中的非负整数限制值，为空或无效时返回 false
+func ParseHeaderLimit(value string) (int64, bool) {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return n, err == nil && n >= 0
+}
+
+// HeaderDuration 按 ParseHeaderDuration 读取请求头
+func (c *Context) HeaderDuration(key string) (time.Duration, bool) {
+	return ParseHeaderDuration(c.Request.GetHeader(key))
+}
+
+// HeaderLimit 按 ParseHeaderLimit 读取请求头
+func (c *Context) HeaderLimit(key string) (int64, bool) {
+	return ParseHeaderLimit(c.Request.GetHeader(key))
+}
+
+// DebugOverridesConfig 调试覆盖配置
+type DebugOverridesConfig struct {
+	// Token 非空时，携带相同 X-Meego-Debug-Token 的请求才能使用覆盖
+	Token string
+	// Trusted 自定义可信请求判断，优先于 Token；两者都为空时中间件不生效
+	Trusted func(c *Context) bool
+	// MaxDelay X-Meego-Debug-Delay 的上限，默认 30s
+	MaxDelay time.Duration
+}
+
+// DebugOverrides 调试覆盖中间件，用于在预发环境演练超时和错误路径：可信请求可以携带
+// X-Meego-Debug-Delay 延迟处理，或携带 X-Meego-Force-Status 直接返回指定状态码。
+// ReleaseMode 下始终不生效
+func DebugOverrides(cfg DebugOverridesConfig) MiddlewareFunc {
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = 30 * time.Second
+	}
+	if cfg.Trusted == nil {
+		if cfg.Token == "" {
+			// 同机反向代理后所有客户端都像回环地址，没有显式凭据时不信任任何请求
+			log.Warn().Msg("debug overrides disabled: set DebugOverridesConfig.Token or Trusted")
+			return func(next HandlerFunc) HandlerFunc { return next }
+		}
+		cfg.Trusted = func(c *Context) bool {
+			token := c.Request.GetHeader(HeaderDebugToken)
+			return subtle.ConstantTimeCompare([]byte(token), []byte(cfg.Token)) == 1
+		}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if Mode() == ReleaseMode {
+				next(c)
+				return
+			}
+			delayHeader := c.Request.GetHeader(HeaderDebugDelay)
+			statusHeader := c.Request.GetHeader(HeaderForceStatus)
+			if (delayHeader == "" && statusHeader == "") || !cfg.Trusted(c) {
+				next(c)
+				return
+			}
+
+			if delay, ok := ParseHeaderDuration(delayHeader); ok {
+				if delay > cfg.MaxDelay {
+					delay = cfg.MaxDelay
+				}
+				c.Logger().Warn().Dur("delay", delay).Msg("debug override: delaying request")
+				select {
+				case <-clock().After(delay):
+				case <-c.Done():
+					return
+				}
+			}
+
+			if code, ok := ParseHeaderLimit(statusHeader); ok && code >= 100 && code <= 599 {
+				c.Logger().Warn().Int64("status", code).Msg("debug override: forcing status")
+				c.Writer.SetHeader(HeaderForceStatus, statusHeader)
+				c.JSON(int(code), JSON{
+					"error": getStatusText(int(code)),
+					"code":  code,
+				})
+				return
+			}
+			next(c)
+		}
+	}
+}
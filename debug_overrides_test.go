@@ -0,0 +1,80 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebugOverrides(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+
+	s := New()
+	s.Use(DebugOverrides(DebugOverridesConfig{Token: "secret", MaxDelay: 50 * time.Millisecond}))
+	s.GET("/", func(c *Context) { c.String(StatusOK, "handled") })
+
+	resp := doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\nX-Meego-Force-Status: 503\r\n\r\n")
+	if !strings.HasSuffix(resp, "handled") {
+		t.Fatalf("untrusted override applied:\n%s", resp)
+	}
+
+	resp = doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\nX-Meego-Debug-Token: secret\r\nX-Meego-Force-Status: 503\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 503") {
+		t.Fatalf("force status:\n%s", resp)
+	}
+
+	start := time.Now()
+	resp = doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\nX-Meego-Debug-Token: secret\r\nX-Meego-Debug-Delay: 10s\r\n\r\n")
+	if d := time.Since(start); d < 50*time.Millisecond || d > 2*time.Second || !strings.HasSuffix(resp, "handled") {
+		t.Fatalf("delay capped at MaxDelay: took %v\n%s", d, resp)
+	}
+
+	resp = doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\nX-Meego-Debug-Token: wrong\r\nX-Meego-Force-Status: 503\r\n\r\n")
+	if !strings.HasSuffix(resp, "handled") {
+		t.Fatalf("wrong token accepted:\n%s", resp)
+	}
+
+	// 没有 Token 和 Trusted 时不信任任何请求
+	bare := New()
+	bare.Use(DebugOverrides(DebugOverridesConfig{}))
+	bare.GET("/", func(c *Context) { c.String(StatusOK, "handled") })
+	resp = doRaw(bare, "GET / HTTP/1.1\r\nHost: x\r\nX-Meego-Force-Status: 503\r\n\r\n")
+	if !strings.HasSuffix(resp, "handled") {
+		t.Fatalf("override applied without credentials:\n%s", resp)
+	}
+
+	custom := New()
+	custom.Use(DebugOverrides(DebugOverridesConfig{Trusted: func(c *Context) bool { return c.Request.GetHeader("X-Staff") == "1" }}))
+	custom.GET("/", func(c *Context) { c.String(StatusOK, "handled") })
+	if resp := doRaw(custom, "GET / HTTP/1.1\r\nX-Staff: 1\r\nX-Meego-Force-Status: 503\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 503") {
+		t.Fatalf("custom trust not applied:\n%s", resp)
+	}
+
+	SetMode(ReleaseMode)
+	resp = doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\nX-Meego-Debug-Token: secret\r\nX-Meego-Force-Status: 503\r\n\r\n")
+	if !strings.HasSuffix(resp, "handled") {
+		t.Fatalf("override active in release mode:\n%s", resp)
+	}
+	if resp := doRaw(custom, "GET / HTTP/1.1\r\nX-Staff: 1\r\nX-Meego-Force-Status: 503\r\n\r\n"); !strings.HasSuffix(resp, "handled") {
+		t.Fatalf("custom trust active in release mode:\n%s", resp)
+	}
+
+	for value, want := range map[string]time.Duration{"250": 250 * time.Millisecond, "1.5s": 1500 * time.Millisecond} {
+		if d, ok := ParseHeaderDuration(value); !ok || d != want {
+			t.Fatalf("ParseHeaderDuration(%q) = %v, %v", value, d, ok)
+		}
+	}
+	if _, ok := ParseHeaderDuration("-1"); ok {
+		t.Fatal("negative duration accepted")
+	}
+	// 超出 time.Duration 范围的毫秒数不能溢出成其他时长
+	for _, value := range []string{"9223372036855", "18446744073709552", "9223372036854775807"} {
+		if d, ok := ParseHeaderDuration(value); ok {
+			t.Fatalf("ParseHeaderDuration(%q) overflowed to %v", value, d)
+		}
+	}
+	if d, ok := ParseHeaderDuration("9223372036854"); !ok || d != 9223372036854*time.Millisecond {
+		t.Fatalf("largest millisecond value rejected: %v, %v", d, ok)
+	}
+}
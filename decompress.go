@@ -0,0 +1,148 @@
+// decompress.go
+package meego
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"strings"
+)
+
+// DecompressConfig 请求体解压配置
+type DecompressConfig struct {
+	// MaxSize 解压后的请求体上限，默认 DefaultMaxBodySize
+	MaxSize int64
+	// MaxRatio 解压后与压缩数据的大小之比上限（压缩炸弹防护），默认 100，负数表示不限制。
+	// 解压出的前 64KB 不检查比例
+	MaxRatio int64
+}
+
+// Decompress 使用默认配置的请求体解压中间件
+func Decompress() MiddlewareFunc {
+	return DecompressWithConfig(DecompressConfig{})
+}
+
+// DecompressWithConfig 使用自定义配置的请求体解压中间件：Content-Encoding 为 gzip、x-gzip
+// 或 deflate 时透明解压，之后 BindJSON、BodyBytes 和 BodyReader 得到解压后的数据，
+// Content-Encoding 和 Content-Length 头被删除。不支持的编码返回 415，数据损坏返回 400，
+// 超过 MaxSize 或 MaxRatio 返回 413。流式请求体边读边解压，错误由读取方得到
+func DecompressWithConfig(cfg DecompressConfig) MiddlewareFunc {
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = DefaultMaxBodySize
+	}
+	if cfg.MaxRatio == 0 {
+		cfg.MaxRatio = 100
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			encoding := strings.ToLower(strings.TrimSpace(c.Request.GetHeader("Content-Encoding")))
+			if encoding == "" {
+				next(c)
+				return
+			}
+			if err := decompressBody(c.Request, encoding, cfg); err != nil {
+				code, msg := StatusBadRequest, "Invalid Request Body Encoding"
+				switch {
+				case errors.Is(err, errUnsupportedEncoding):
+					code, msg = StatusUnsupportedMediaType, "Unsupported Content-Encoding"
+					c.Writer.SetHeader("Accept-Encoding", "gzip, deflate")
+				case errors.Is(err, ErrBodyTooLarge):
+					code, msg = StatusRequestEntityTooLarge, "Request Entity Too Large"
+				}
+				c.Writer.Status(code).JSON(JSON{"error": msg, "code": code})
+				return
+			}
+			next(c)
+		}
+	}
+}
+
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// decompressBody 用解压后的数据替换请求体
+func decompressBody(req *HTTPRequest, encoding string, cfg DecompressConfig) error {
+	src := req.bodyReader
+	if src == nil {
+		src = bytes.NewReader(req.Body)
+	}
+	compressed := &countingReader{r: src}
+
+	var r io.Reader
+	var err error
+	switch encoding {
+	case "identity":
+		r = compressed
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(compressed)
+	case "deflate":
+		r, err = zlib.NewReader(compressed)
+	default:
+		return errUnsupportedEncoding
+	}
+	if err != nil {
+		return err
+	}
+	if cfg.MaxRatio > 0 {
+		r = &ratioReader{r: r, compressed: compressed, ratio: cfg.MaxRatio}
+	}
+	r = &maxBytesReader{r: r, n: cfg.MaxSize}
+
+	delete(req.Headers, headerKey(req.Headers, "Content-Encoding"))
+	delete(req.Headers, headerKey(req.Headers, "Content-Length"))
+	req.contentLength = 0
+	if req.bodyReader != nil {
+		req.bodyReader = r
+		return nil
+	}
+
+	// 已缓冲的请求体立即解压，解压结果不放入池化缓冲区
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return err
+	}
+	req.Body = buf.Bytes()
+	req.contentLength = len(req.Body)
+	return nil
+}
+
+// headerKey 返回请求头在 Headers 中实际使用的键名
+func headerKey(headers map[string]string, key string) string {
+	for k := range headers {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return key
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ratioReader 解压数据超过压缩数据的 ratio 倍时返回 ErrBodyTooLarge
+type ratioReader struct {
+	r          io.Reader
+	compressed *countingReader
+	ratio      int64
+	n          int64
+}
+
+func (r *ratioReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.n > 64<<10 && r.n > r.compressed.n*r.ratio {
+		return n, ErrBodyTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,114 @@
+// dedup.go
+package meego
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DedupConfig 重复投递去重配置
+type DedupConfig struct {
+	// Header 投递 ID 所在的请求头，默认 "Webhook-Id"（GitHub 为 X-GitHub-Delivery）
+	Header string
+	// Store 已处理 ID 的存储，默认进程内 MemoryStore；多实例部署时应使用共享存储
+	Store IdempotencyStore
+	// Window 记住投递 ID 的时间，默认 24h，应覆盖发送方的重试周期
+	Window time.Duration
+	// KeyPrefix 存储键前缀，默认 "dedup:"，多个接收端点共享存储时用于区分
+	KeyPrefix string
+	// Lease 处理中标记的保留时间，默认 5 分钟，应大于处理器的最长执行时间；
+	// 进程在处理中退出时，超过 Lease 后发送方的重试会重新处理
+	Lease time.Duration
+	// RetryAfter 同一 ID 仍在处理时建议发送方重试的间隔，默认 5 秒
+	RetryAfter time.Duration
+}
+
+// 存储中投递 ID 的状态
+var (
+	dedupProcessing = []byte("processing")
+	dedupDone       = []byte("done")
+)
+
+// Dedup 使用默认配置的重复投递去重中间件
+func Dedup() MiddlewareFunc {
+	return DedupWithConfig(DedupConfig{})
+}
+
+// DedupWithConfig 使用自定义配置的重复投递去重中间件，用于至少一次投递的 webhook 接收端：
+// Window 内已处理完成的投递 ID 直接返回 200 和 X-Duplicate-Delivery: true，不再调用处理器；
+// 同一 ID 仍在处理中时返回 409 和 Retry-After，发送方稍后重试时才能知道处理结果。
+// 处理器返回 5xx 或 panic 时忘记该 ID，发送方重试时重新处理；
+// 没有投递 ID 的请求和存储故障时照常处理
+func DedupWithConfig(cfg DedupConfig) MiddlewareFunc {
+	if cfg.Header == "" {
+		cfg.Header = "Webhook-Id"
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 24 * time.Hour
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "dedup:"
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 5 * time.Minute
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = 5 * time.Second
+	}
+	retryAfter := strconv.Itoa(int((cfg.RetryAfter + time.Second - 1) / time.Second))
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			id := c.Request.GetHeader(cfg.Header)
+			if id == "" {
+				next(c)
+				return
+			}
+			key := cfg.KeyPrefix + id
+			first, err := cfg.Store.SetNX(key, dedupProcessing, cfg.Lease)
+			if err != nil {
+				log.Error().Err(err).Str("key", key).Msg("dedup store error")
+				next(c)
+				return
+			}
+			if !first {
+				state, found, err := cfg.Store.Get(key)
+				switch {
+				case err != nil:
+					log.Error().Err(err).Str("key", key).Msg("dedup store error")
+					c.Writer.SetHeader("Retry-After", retryAfter)
+					c.Writer.Status(StatusServiceUnavailable).JSON(JSON{"error": "Service Unavailable", "code": StatusServiceUnavailable})
+				case found && bytes.Equal(state, dedupDone):
+					c.Writer.SetHeader("X-Duplicate-Delivery", "true")
+					c.Writer.Status(StatusOK).JSON(JSON{"duplicate": true, "id": id})
+				default:
+					// 第一次投递仍在处理，结果未知，不能确认
+					c.Writer.SetHeader("Retry-After", retryAfter)
+					c.Writer.Status(StatusConflict).JSON(JSON{"error": "delivery in progress", "code": StatusConflict, "id": id})
+				}
+				return
+			}
+
+			processed := false
+			defer func() {
+				var err error
+				if !processed || c.Writer.status >= 500 {
+					err = cfg.Store.Delete(key)
+				} else {
+					err = cfg.Store.Set(key, dedupDone, cfg.Window)
+				}
+				if err != nil {
+					log.Error().Err(err).Str("key", key).Msg("dedup store error")
+				}
+			}()
+			next(c)
+			processed = true
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDedup(t *testing.T) {
+	s := New()
+	s.Use(Dedup())
+	calls := 0
+	entered, release := make(chan struct{}), make(chan struct{})
+	s.POST("/hook", func(c *Context) {
+		calls++
+		if c.Request.GetHeader("X-Block") != "" {
+			close(entered)
+			<-release
+		}
+		if c.Request.GetHeader("X-Fail") != "" {
+			c.String(StatusServiceUnavailable, "retry")
+			return
+		}
+		c.String(StatusOK, "processed")
+	})
+
+	if resp := doRaw(s, "POST /hook HTTP/1.1\r\nWebhook-Id: a\r\n\r\n"); !strings.HasSuffix(resp, "processed") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+	resp := doRaw(s, "POST /hook HTTP/1.1\r\nWebhook-Id: a\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.Contains(resp, "X-Duplicate-Delivery: true") || calls != 1 {
+		t.Fatalf("expected duplicate: %q (calls %d)", resp, calls)
+	}
+
+	// 处理失败的投递可以重试
+	doRaw(s, "POST /hook HTTP/1.1\r\nWebhook-Id: b\r\nX-Fail: 1\r\n\r\n")
+	if resp := doRaw(s, "POST /hook HTTP/1.1\r\nWebhook-Id: b\r\n\r\n"); !strings.HasSuffix(resp, "processed") || calls != 3 {
+		t.Fatalf("expected retry to be processed: %q (calls %d)", resp, calls)
+	}
+	doRaw(s, "POST /hook HTTP/1.1\r\n\r\n")
+	doRaw(s, "POST /hook HTTP/1.1\r\n\r\n")
+	if calls != 5 {
+		t.Fatalf("requests without delivery ID should not be deduplicated: %d", calls)
+	}
+
+	// 第一次投递仍在处理时，重试得到 409 和 Retry-After 而不是成功，处理完成后才确认为重复
+	done := make(chan string)
+	go func() { done <- doRaw(s, "POST /hook HTTP/1.1\r\nWebhook-Id: c\r\nX-Block: 1\r\n\r\n") }()
+	<-entered
+	resp = doRaw(s, "POST /hook HTTP/1.1\r\nWebhook-Id: c\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 409") || !strings.Contains(resp, "Retry-After: 5") || strings.Contains(resp, "X-Duplicate-Delivery") {
+		t.Fatalf("expected in-progress conflict: %q", resp)
+	}
+	close(release)
+	if resp := <-done; !strings.HasSuffix(resp, "processed") {
+		t.Fatalf("first delivery: %q", resp)
+	}
+	resp = doRaw(s, "POST /hook HTTP/1.1\r\nWebhook-Id: c\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.Contains(resp, "X-Duplicate-Delivery: true") || calls != 6 {
+		t.Fatalf("expected duplicate after completion: %q (calls %d)", resp, calls)
+	}
+}
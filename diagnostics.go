@@ -0,0 +1,173 @@
+// diagnostics.go
+package meego
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	diagnosticsMaxDelay = 60 * time.Second
+	diagnosticsMaxBytes = 10 * 1024 * 1024
+)
+
+// EnableDiagnostics 注册压测和客户端调试用的诊断路由，prefix 默认 /debug：
+//
+//	{prefix}/echo          返回解析后的请求，不包括 Authorization、Cookie 等凭据
+//	{prefix}/delay/:ms     延迟指定毫秒后响应（最长 60s）
+//	{prefix}/status/:code  返回指定状态码
+//	{prefix}/bytes/:n      返回 n 字节数据（最多 10MB）
+//
+// 这些路由回显客户端 IP 并能产生负载，必须通过 middlewares（如 BasicAuth、APIKeyAuth）保护。
+// 返回注册的路由，可以继续设置路由中间件
+func (s *HTTPServer) EnableDiagnostics(prefix string, middlewares ...MiddlewareFunc) []*Route {
+	if prefix == "" {
+		prefix = "/debug"
+	}
+	prefix = strings.TrimRight(prefix, "/")
+	if len(middlewares) == 0 {
+		log.Warn().Str("prefix", prefix).Msg("diagnostics endpoints registered without middleware: protect them with authentication")
+	}
+
+	g := s.Group(prefix, middlewares...)
+	return []*Route{
+		g.GET("/echo", diagnosticsEcho),
+		g.POST("/echo", diagnosticsEcho),
+		g.PUT("/echo", diagnosticsEcho),
+		g.DELETE("/echo", diagnosticsEcho),
+		g.GET("/delay/:ms", diagnosticsDelay),
+		g.GET("/status/:code", diagnosticsStatus),
+		g.GET("/bytes/:n", diagnosticsBytes),
+	}
+}
+
+// diagnosticsEcho 与 TRACE 一样不回显 DefaultTraceExcludeHeaders 中的凭据
+func diagnosticsEcho(c *Context) {
+	headers := make(map[string]string, len(c.Request.Headers))
+	for k, v := range c.Request.Headers {
+		if !containsFold(DefaultTraceExcludeHeaders, k) {
+			headers[k] = v
+		}
+	}
+	c.JSON(StatusOK, JSON{
+		"method":    c.Request.Method,
+		"path":      c.Request.URL.Path,
+		"raw_url":   c.Request.RawURL,
+		"proto":     c.Request.Proto,
+		"host":      c.Request.Host,
+		"headers":   headers,
+		"query":     c.GetAllQuery(),
+		"body":      c.BodyString(),
+		"client_ip": clientIPKey(c),
+	})
+}
+
+func diagnosticsDelay(c *Context) {
+	ms, err := strconv.Atoi(c.Param("ms"))
+	if err != nil || ms < 0 {
+		c.JSON(StatusBadRequest, JSON{"error": "invalid delay", "code": StatusBadRequest})
+		return
+	}
+	delay := time.Duration(ms) * time.Millisecond
+	if delay > diagnosticsMaxDelay {
+		delay = diagnosticsMaxDelay
+	}
+
+	select {
+	case <-clock().After(delay):
+	case <-c.Done():
+		return
+	}
+	c.JSON(StatusOK, JSON{"delay_ms": delay.Milliseconds()})
+}
+
+func diagnosticsStatus(c *Context) {
+	code, err := strconv.Atoi(c.Param("code"))
+	if err != nil || code < 200 || code > 599 {
+		c.JSON(StatusBadRequest, JSON{"error": "invalid status code", "code": StatusBadRequest})
+		return
+	}
+	c.JSON(code, JSON{"code": code, "status": StatusText(code)})
+}
+
+func diagnosticsBytes(c *Context) {
+	n, err := strconv.Atoi(c.Param("n"))
+	if err != nil || n < 0 {
+		c.JSON(StatusBadRequest, JSON{"error": "invalid size", "code": StatusBadRequest})
+		return
+	}
+	if n > diagnosticsMaxBytes {
+		n = diagnosticsMaxBytes
+	}
+
+	body := make([]byte, n)
+	for i := range body {
+		body[i] = 'a' + byte(i%26)
+	}
+	c.Writer.Status(StatusOK).writeBytes("application/octet-stream", body)
+}
+
+// DefaultTraceExcludeHeaders TRACE 回显时默认去掉的敏感请求头
+var DefaultTraceExcludeHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// TraceConfig TRACE 回显配置
+type TraceConfig struct {
+	// ExcludeHeaders 不回显的请求头（不区分大小写），默认 DefaultTraceExcludeHeaders
+	ExcludeHeaders []string
+}
+
+// EnableTrace 启用 TRACE 回显（RFC 9110 9.3.8）：TRACE 请求以 200 和 message/http 返回收到的
+// 请求行和请求头，用于排查中间代理对请求的修改。TRACE 会暴露请求头，默认关闭，只应在受控环境中启用；
+// 未启用时 TRACE 请求与其它未注册的方法一样返回 405
+func (s *HTTPServer) EnableTrace(cfg TraceConfig) {
+	if cfg.ExcludeHeaders == nil {
+		cfg.ExcludeHeaders = DefaultTraceExcludeHeaders
+	}
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if c.Request.Method != "TRACE" {
+				next(c)
+				return
+			}
+			if c.Request.ContentLength() > 0 || c.Request.Streaming() {
+				// TRACE 请求不能携带请求体
+				c.JSON(StatusBadRequest, JSON{"error": "TRACE request must not have a body", "code": StatusBadRequest})
+				return
+			}
+			c.Writer.SetHeader("Cache-Control", "no-store")
+			c.Writer.Status(StatusOK).writeBytes("message/http", DumpRequest(c.Request, cfg.ExcludeHeaders...))
+		}
+	})
+}
+
+// DumpRequest 按 HTTP/1.1 报文格式输出请求行和请求头（按名称排序），不包括请求体，
+// exclude 中的请求头不输出
+func DumpRequest(req *HTTPRequest, exclude ...string) []byte {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteByte(' ')
+	b.WriteString(req.RawURL)
+	b.WriteByte(' ')
+	b.WriteString(req.Proto)
+	b.WriteString("\r\n")
+
+	keys := make([]string, 0, len(req.Headers))
+	for k := range req.Headers {
+		if !containsFold(exclude, k) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteString(": ")
+		b.WriteString(req.Headers[k])
+		b.WriteString("\r\n")
+	}
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
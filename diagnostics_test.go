@@ -0,0 +1,63 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiagnostics(t *testing.T) {
+	s := New()
+	routes := s.EnableDiagnostics("", APIKeyAuth("", func(key string) bool { return key == "ops" }))
+	if len(routes) != 7 {
+		t.Fatalf("registered %d routes", len(routes))
+	}
+	// 诊断路由经过注册时传入的中间件
+	if resp := doRaw(s, "GET /debug/bytes/10 HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 401") {
+		t.Fatalf("unauthenticated diagnostics request:\n%s", resp)
+	}
+
+	const auth = "X-API-Key: ops\r\n"
+	resp := doRaw(s, "POST /debug/echo?a=1 HTTP/1.1\r\n"+auth+"Host: x\r\nX-Custom: yes\r\nAuthorization: Bearer secret\r\ncookie: sid=secret\r\nContent-Length: 4\r\n\r\nping")
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.Contains(resp, `"X-Custom":"yes"`) || !strings.Contains(resp, `"body":"ping"`) {
+		t.Fatalf("echo:\n%s", resp)
+	}
+	if strings.Contains(resp, "secret") {
+		t.Fatalf("echo leaked credentials:\n%s", resp)
+	}
+
+	if resp := doRaw(s, "GET /debug/status/418 HTTP/1.1\r\n"+auth+"\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 418") {
+		t.Fatalf("status:\n%s", resp)
+	}
+	if resp := doRaw(s, "GET /debug/status/99 HTTP/1.1\r\n"+auth+"\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 400") {
+		t.Fatalf("invalid status:\n%s", resp)
+	}
+	if resp := doRaw(s, "GET /debug/bytes/30 HTTP/1.1\r\n"+auth+"\r\n"); !strings.HasSuffix(resp, "\r\n\r\nabcdefghijklmnopqrstuvwxyzabcd") {
+		t.Fatalf("bytes:\n%s", resp)
+	}
+
+	start := time.Now()
+	resp = doRaw(s, "GET /debug/delay/30 HTTP/1.1\r\n"+auth+"\r\n")
+	if time.Since(start) < 30*time.Millisecond || !strings.Contains(resp, `"delay_ms":30`) {
+		t.Fatalf("delay:\n%s", resp)
+	}
+}
+
+func TestEnableTrace(t *testing.T) {
+	s := New()
+	s.GET("/x", func(c *Context) { c.String(StatusOK, "ok") })
+	req := "TRACE /x?a=1 HTTP/1.1\r\nHost: t.test\r\nVia: 1.1 proxy\r\nCookie: secret=1\r\n\r\n"
+	if resp := doRaw(s, req); !strings.HasPrefix(resp, "HTTP/1.1 405") {
+		t.Fatalf("TRACE should be disabled by default: %q", resp)
+	}
+
+	s.EnableTrace(TraceConfig{})
+	resp := doRaw(s, req)
+	want := "\r\n\r\nTRACE /x?a=1 HTTP/1.1\r\nHost: t.test\r\nVia: 1.1 proxy\r\n\r\n"
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.Contains(resp, "Content-Type: message/http") || !strings.HasSuffix(resp, want) {
+		t.Fatalf("unexpected TRACE echo: %q", resp)
+	}
+	if resp := doRaw(s, "TRACE /x HTTP/1.1\r\nContent-Length: 2\r\n\r\nhi"); !strings.HasPrefix(resp, "HTTP/1.1 400") {
+		t.Fatalf("TRACE with body: %q", resp)
+	}
+}
@@ -0,0 +1,260 @@
+// discovery.go
+package meego
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoEndpoints 上游没有可用地址
+var ErrNoEndpoints = errors.New("no upstream endpoints available")
+
+// Resolver 上游地址解析器，返回 host:port 列表
+type Resolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// StaticResolver 固定的上游地址列表
+type StaticResolver []string
+
+func (r StaticResolver) Resolve(ctx context.Context) ([]string, error) {
+	return r, nil
+}
+
+// DNSResolver 通过 DNS 解析上游地址，结果缓存 Refresh 时长后在后台刷新；
+// 刷新失败时继续使用上一次的结果
+type DNSResolver struct {
+	// Host 要解析的域名；设置 Service 时查询 _Service._Proto.Host 的 SRV 记录
+	Host    string
+	Port    int    // A/AAAA 记录使用的端口
+	Service string // SRV 服务名，如 "http"
+	Proto   string // SRV 协议，默认 "tcp"
+	// Refresh 缓存时长，默认 30s（Go 的解析器不提供 TTL）
+	Refresh  time.Duration
+	Resolver *net.Resolver // 默认 net.DefaultResolver
+
+	mu         sync.Mutex
+	addrs      []string
+	expires    time.Time
+	refreshing atomic.Bool // 正在后台刷新
+}
+
+// Resolve 返回缓存的地址，过期时在后台刷新；首次调用时同步解析
+func (r *DNSResolver) Resolve(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	addrs, expires := r.addrs, r.expires
+	r.mu.Unlock()
+
+	if addrs == nil {
+		return r.refresh(ctx)
+	}
+	if time.Now().After(expires) && r.refreshing.CompareAndSwap(false, true) {
+		go func() {
+			defer r.refreshing.Store(false)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := r.refresh(ctx); err != nil {
+				log.Warn().Err(err).Str("host", r.Host).Msg("dns refresh failed, using cached endpoints")
+			}
+		}()
+	}
+	return addrs, nil
+}
+
+func (r *DNSResolver) refresh(ctx context.Context) ([]string, error) {
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	var addrs []string
+	if r.Service != "" {
+		proto := r.Proto
+		if proto == "" {
+			proto = "tcp"
+		}
+		_, records, err := resolver.LookupSRV(ctx, r.Service, proto, r.Host)
+		if err != nil {
+			return nil, err
+		}
+		for _, srv := range records {
+			addrs = append(addrs, net.JoinHostPort(trimDot(srv.Target), strconv.Itoa(int(srv.Port))))
+		}
+	} else {
+		ips, err := resolver.LookupHost(ctx, r.Host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			addrs = append(addrs, net.JoinHostPort(ip, strconv.Itoa(r.Port)))
+		}
+	}
+
+	refresh := r.Refresh
+	if refresh <= 0 {
+		refresh = 30 * time.Second
+	}
+	r.mu.Lock()
+	r.addrs = addrs
+	r.expires = time.Now().Add(refresh)
+	r.mu.Unlock()
+	return addrs, nil
+}
+
+func trimDot(host string) string {
+	if len(host) > 0 && host[len(host)-1] == '.' {
+		return host[:len(host)-1]
+	}
+	return host
+}
+
+// WatchResolver 由用户提供的监听器推送地址变化，适合接入注册中心：
+//
+//	r := meego.NewWatchResolver(func(update func([]string)) {
+//		for addrs := range registry.Watch("orders") {
+//			update(addrs)
+//		}
+//	})
+type WatchResolver struct {
+	mu    sync.RWMutex
+	addrs []string
+	ready chan struct{}
+	once  sync.Once
+}
+
+// NewWatchResolver 启动 watch 并返回解析器，watch 在独立协程中运行
+func NewWatchResolver(watch func(update func(addrs []string))) *WatchResolver {
+	r := &WatchResolver{ready: make(chan struct{})}
+	go watch(r.update)
+	return r
+}
+
+func (r *WatchResolver) update(addrs []string) {
+	r.mu.Lock()
+	r.addrs = append([]string(nil), addrs...)
+	r.mu.Unlock()
+	r.once.Do(func() { close(r.ready) })
+}
+
+// Resolve 返回最新地址，首次推送之前等待到 ctx 结束
+func (r *WatchResolver) Resolve(ctx context.Context) ([]string, error) {
+	select {
+	case <-r.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.addrs, nil
+}
+
+// Balancer 负载均衡策略
+type Balancer interface {
+	// Pick 从 addrs 中选择一个地址
+	Pick(addrs []string) string
+	// Done 请求结束时调用
+	Done(addr string)
+}
+
+// RoundRobin 轮询
+type RoundRobin struct {
+	next atomic.Uint64
+}
+
+func (b *RoundRobin) Pick(addrs []string) string {
+	n := b.next.Add(1) - 1
+	return addrs[n%uint64(len(addrs))]
+}
+
+func (b *RoundRobin) Done(addr string) {}
+
+// LeastConnections 选择进行中请求最少的地址，相同时随机选择
+type LeastConnections struct {
+	mu     sync.Mutex
+	active map[string]int
+}
+
+func (b *LeastConnections) Pick(addrs []string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.active == nil {
+		b.active = make(map[string]int)
+	}
+
+	best, ties := "", 0
+	for _, addr := range addrs {
+		n := b.active[addr]
+		switch {
+		case best == "" || n < b.active[best]:
+			best, ties = addr, 1
+		case n == b.active[best]:
+			ties++
+			if rand.Intn(ties) == 0 {
+				best = addr
+			}
+		}
+	}
+	b.active[best]++
+	return best
+}
+
+func (b *LeastConnections) Done(addr string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.active[addr] > 0 {
+		b.active[addr]--
+	}
+	if b.active[addr] == 0 {
+		delete(b.active, addr)
+	}
+}
+
+// Upstream 逻辑上游服务：解析器 + 负载均衡 + 健康状态
+type Upstream struct {
+	Resolver Resolver
+	Balancer Balancer // 默认 RoundRobin
+	// MaxFails 连续失败多少次后摘除地址，默认 3，负数表示不摘除
+	MaxFails int
+	// EjectFor 被动摘除的时长，默认 30s
+	EjectFor time.Duration
+
+	mu     sync.Mutex
+	health map[string]*endpointHealth
+}
+
+// NewUpstream 创建上游，balancer 为空时使用轮询
+func NewUpstream(resolver Resolver, balancer Balancer) *Upstream {
+	if balancer == nil {
+		balancer = &RoundRobin{}
+	}
+	return &Upstream{Resolver: resolver, Balancer: balancer}
+}
+
+// Pick 选择一个健康的地址，请求结束后必须调用 done。
+// Balancer 实现了 KeyedBalancer 且 ctx 带有 WithBalanceKey 设置的键时按键选择
+func (u *Upstream) Pick(ctx context.Context) (addr string, done func(), err error) {
+	addrs, err := u.Resolver.Resolve(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(addrs) == 0 {
+		return "", nil, ErrNoEndpoints
+	}
+	addrs = u.healthy(addrs)
+	if kb, ok := u.Balancer.(KeyedBalancer); ok {
+		if key := balanceKey(ctx); key != "" {
+			addr = kb.PickKey(addrs, key)
+			return addr, func() {}, nil
+		}
+	}
+	addr = u.Balancer.Pick(addrs)
+	return addr, func() { u.Balancer.Done(addr) }, nil
+}
@@ -0,0 +1,151 @@
+package meego
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClientUpstreamBalancing(t *testing.T) {
+	var hosts []string
+	backend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hosts = append(hosts, r.Host)
+			io.WriteString(w, name)
+		}))
+	}
+	a, b := backend("a"), backend("b")
+	defer a.Close()
+	defer b.Close()
+
+	cl := NewClient()
+	cl.AddUpstream("orders", NewUpstream(StaticResolver{
+		strings.TrimPrefix(a.URL, "http://"),
+		strings.TrimPrefix(b.URL, "http://"),
+	}, nil))
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		resp, err := cl.Get(nil, "http://orders/api")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		got = append(got, string(body))
+	}
+	if strings.Join(got, "") != "abab" {
+		t.Fatalf("expected round robin, got %v", got)
+	}
+	if hosts[0] != "orders" {
+		t.Fatalf("Host header should keep the logical name, got %q", hosts[0])
+	}
+
+	lc := &LeastConnections{}
+	first := lc.Pick([]string{"x", "y"})
+	if second := lc.Pick([]string{"x", "y"}); second == first {
+		t.Fatalf("least connections picked busy endpoint twice: %q", second)
+	}
+}
+
+func TestStickyProxyAndEjection(t *testing.T) {
+	backend := func(name string, status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			io.WriteString(w, name)
+		}))
+	}
+	a, b, bad := backend("a", 200), backend("b", 200), backend("bad", 503)
+	defer a.Close()
+	defer b.Close()
+	defer bad.Close()
+	addr := func(s *httptest.Server) string { return strings.TrimPrefix(s.URL, "http://") }
+
+	up := NewUpstream(StaticResolver{addr(a), addr(b)}, &ConsistentHash{})
+	s := New()
+	s.GET("/api", ReverseProxy(ProxyConfig{
+		Target:   "http://backend",
+		Upstream: up,
+		HashKey:  CookieHashKey("sid"),
+	}))
+
+	for _, sid := range []string{"alice", "bob", "carol"} {
+		first := doRaw(s, "GET /api HTTP/1.1\r\nCookie: sid="+sid+"\r\n\r\n")
+		for i := 0; i < 3; i++ {
+			if resp := doRaw(s, "GET /api HTTP/1.1\r\nCookie: sid="+sid+"\r\n\r\n"); resp[len(resp)-1] != first[len(first)-1] {
+				t.Fatalf("session %s moved between backends", sid)
+			}
+		}
+	}
+
+	// 连续返回 503 的地址被摘除
+	failing := NewUpstream(StaticResolver{addr(a), addr(bad)}, nil)
+	failing.MaxFails = 2
+	cl := NewClient()
+	cl.AddUpstream("svc", failing)
+	var got []string
+	for i := 0; i < 8; i++ {
+		resp, err := cl.Get(nil, "http://svc/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		got = append(got, string(body))
+	}
+	if strings.Join(got[4:], ",") != "a,a,a,a" {
+		t.Fatalf("failing endpoint should be ejected, got %v", got)
+	}
+}
+
+func TestEWMAPrefersFasterEndpoint(t *testing.T) {
+	b := &EWMA{}
+	b.Observe("slow", 200*time.Millisecond)
+	b.Observe("fast", 10*time.Millisecond)
+	counts := map[string]int{}
+	for i := 0; i < 100; i++ {
+		addr := b.Pick([]string{"slow", "fast"})
+		counts[addr]++
+		b.Done(addr)
+	}
+	if counts["fast"] != 100 {
+		t.Fatalf("expected all picks on fast endpoint, got %v", counts)
+	}
+}
+
+func TestClientConnectionPool(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer backend.Close()
+
+	cl := NewClientWithConfig(ClientConfig{MaxIdleConnsPerHost: 2})
+	for i := 0; i < 5; i++ {
+		resp, err := cl.Get(nil, backend.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	st := cl.Stats()
+	if st.Requests != 5 || st.Dials != 1 || st.Reused != 4 {
+		t.Fatalf("stats = %+v, want 5 requests on 1 reused connection", st)
+	}
+	host := strings.TrimPrefix(backend.URL, "http://")
+	if st.OpenConns[host] != 1 {
+		t.Fatalf("open conns = %v", st.OpenConns)
+	}
+	if !strings.Contains(st.String(), "meego_client_dials_total 1\n") {
+		t.Fatalf("metrics:\n%s", st)
+	}
+
+	cl.HTTPClient.CloseIdleConnections()
+	if n := cl.Stats().OpenConns[host]; n != 0 {
+		t.Fatalf("open conns after close = %d", n)
+	}
+}
@@ -0,0 +1,59 @@
+// double_write.go
+package meego
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DoubleWriteError 调试模式下同一请求第二次写出完整响应时的 panic 值，
+// 记录两次写出的调用栈，便于找到冲突的处理器和中间件。
+// 非调试模式下不记录调用栈，第二次写出被丢弃并关闭连接
+type DoubleWriteError struct {
+	First  string // 第一次写出的调用栈
+	Second string // 第二次写出的调用栈
+}
+
+func (e *DoubleWriteError) Error() string {
+	return "meego: response already written\nfirst write:\n" + e.First + "second write:\n" + e.Second
+}
+
+// Written 响应状态行和头部是否已经写出
+func (w *ResponseWriter) Written() bool {
+	return w.written
+}
+
+// recordWrite 调试模式下记录第一次写出的位置，已写出时 panic
+func (w *ResponseWriter) recordWrite() {
+	if !IsDebugging() {
+		return
+	}
+	if w.written && w.firstWrite != "" {
+		panic(&DoubleWriteError{First: w.firstWrite, Second: callerStack()})
+	}
+	w.firstWrite = callerStack()
+}
+
+// callerStack 格式化 recordWrite 调用方的调用栈，跳过 ResponseWriter 的方法和运行时帧
+func callerStack() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") && !strings.HasPrefix(frame.Function, "github.com/asaka1234/meego.(*ResponseWriter).") {
+			b.WriteString(frame.Function)
+			b.WriteString("\n\t")
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+			b.WriteByte('\n')
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDoubleWriteDebug(t *testing.T) {
+	var got *DoubleWriteError
+	s := New()
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			defer func() { got, _ = recover().(*DoubleWriteError) }()
+			next(c)
+		}
+	})
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			next(c)
+			c.String(StatusOK, "again")
+		}
+	})
+	s.GET("/twice", func(c *Context) { c.JSON(StatusOK, JSON{"ok": true}) })
+
+	resp := doRaw(s, "GET /twice HTTP/1.1\r\nConnection: close\r\n\r\n")
+	if strings.Count(resp, "HTTP/1.1 ") != 1 || strings.Contains(resp, "again") {
+		t.Fatalf("second write was sent:\n%q", resp)
+	}
+	if got == nil {
+		t.Fatal("expected DoubleWriteError panic")
+	}
+	if !strings.Contains(got.First, "TestDoubleWriteDebug.func3") || !strings.Contains(got.Second, "TestDoubleWriteDebug.func2") {
+		t.Fatalf("unexpected call sites:\nfirst:\n%s\nsecond:\n%s", got.First, got.Second)
+	}
+}
+
+func TestDoubleWriteRelease(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(ReleaseMode)
+
+	var second error
+	s := New()
+	s.GET("/twice", func(c *Context) {
+		c.String(StatusOK, "first")
+		second = c.Writer.String("again")
+	})
+	s.GET("/next", func(c *Context) { c.String(StatusOK, "next") })
+
+	// 第二次写出被丢弃，连接随后关闭，后续流水线请求不会收到错位的响应
+	resp := doRaw(s, "GET /twice HTTP/1.1\r\nHost: x\r\n\r\nGET /next HTTP/1.1\r\nHost: x\r\n\r\n")
+	if n := strings.Count(resp, "HTTP/1.1 "); n != 1 || !strings.Contains(resp, "first") || strings.Contains(resp, "again") {
+		t.Fatalf("expected a single response (%d):\n%q", n, resp)
+	}
+	if second != errResponseFinished {
+		t.Fatalf("second write: %v", second)
+	}
+}
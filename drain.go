@@ -0,0 +1,120 @@
+// drain.go
+package meego
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultDrainWindow 关闭时等待长连接（WebSocket、SSE）自行结束的默认时间
+const DefaultDrainWindow = 5 * time.Second
+
+// longLived 关闭时需要排空的长连接
+type longLived interface {
+	// drain 通知对端服务器即将关闭（WebSocket 关闭帧、SSE 注释），请对端重连到其它实例
+	drain()
+	// forceClose 排空窗口结束后仍未结束时强制关闭
+	forceClose()
+}
+
+// longLivedSet 进行中的长连接
+type longLivedSet struct {
+	mu       sync.Mutex
+	conns    map[longLived]struct{}
+	window   time.Duration
+	draining bool
+	drained  chan struct{} // 排空期间最后一个长连接结束时关闭
+}
+
+// SetDrainWindow 设置关闭时等待 WebSocket 和 SSE 连接结束的时间，默认 DefaultDrainWindow，
+// 负数表示通知后立即强制关闭
+func (s *HTTPServer) SetDrainWindow(d time.Duration) {
+	s.lifecycle.longLived.mu.Lock()
+	defer s.lifecycle.longLived.mu.Unlock()
+	s.lifecycle.longLived.window = d
+}
+
+// LongLivedConns 进行中的 WebSocket 和 SSE 连接数
+func (s *HTTPServer) LongLivedConns() int {
+	set := &s.lifecycle.longLived
+	set.mu.Lock()
+	defer set.mu.Unlock()
+	return len(set.conns)
+}
+
+// trackLongLived 登记长连接，连接结束时调用返回的函数。已经在排空时立即通知
+func (s *HTTPServer) trackLongLived(l longLived) (untrack func()) {
+	set := &s.lifecycle.longLived
+	set.mu.Lock()
+	if set.conns == nil {
+		set.conns = make(map[longLived]struct{})
+	}
+	set.conns[l] = struct{}{}
+	draining := set.draining
+	set.mu.Unlock()
+	if draining {
+		l.drain()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			set.mu.Lock()
+			defer set.mu.Unlock()
+			delete(set.conns, l)
+			if set.draining && len(set.conns) == 0 && set.drained != nil {
+				close(set.drained)
+				set.drained = nil
+			}
+		})
+	}
+}
+
+// drainLongLived 通知所有长连接服务器即将关闭，等待它们结束，排空窗口结束后强制关闭剩余的连接
+func (s *HTTPServer) drainLongLived() {
+	set := &s.lifecycle.longLived
+	set.mu.Lock()
+	set.draining = true
+	if len(set.conns) == 0 {
+		set.mu.Unlock()
+		return
+	}
+	window := set.window
+	if window == 0 {
+		window = DefaultDrainWindow
+	}
+	drained := make(chan struct{})
+	set.drained = drained
+	conns := make([]longLived, 0, len(set.conns))
+	for l := range set.conns {
+		conns = append(conns, l)
+	}
+	set.mu.Unlock()
+
+	log.Info().Int("connections", len(conns)).Dur("window", window).Msg("draining long-lived connections")
+	for _, l := range conns {
+		l.drain()
+	}
+	if window > 0 {
+		select {
+		case <-drained:
+			return
+		case <-time.After(window):
+		}
+	}
+
+	set.mu.Lock()
+	remaining := make([]longLived, 0, len(set.conns))
+	for l := range set.conns {
+		remaining = append(remaining, l)
+	}
+	set.mu.Unlock()
+	if len(remaining) > 0 {
+		log.Warn().Int("connections", len(remaining)).Msg("force closing long-lived connections")
+	}
+	for _, l := range remaining {
+		l.forceClose()
+	}
+}
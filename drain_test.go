@@ -0,0 +1,60 @@
+package meego
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testLongLived struct {
+	drained, closed atomic.Bool
+	onDrain         func()
+}
+
+func (l *testLongLived) drain() {
+	l.drained.Store(true)
+	if l.onDrain != nil {
+		l.onDrain()
+	}
+}
+
+func (l *testLongLived) forceClose() { l.closed.Store(true) }
+
+func TestDrainLongLived(t *testing.T) {
+	s := New()
+	s.SetDrainWindow(50 * time.Millisecond)
+
+	polite, stubborn := &testLongLived{}, &testLongLived{}
+	untrack := s.trackLongLived(polite)
+	polite.onDrain = untrack
+	s.trackLongLived(stubborn)
+
+	stream := make(chan string, 1)
+	s.GET("/events", func(c *Context) {
+		st, err := c.SSE()
+		if err != nil {
+			return
+		}
+		<-st.Done()
+	})
+	go func() { stream <- doRaw(s, "GET /events HTTP/1.1\r\n\r\n") }()
+	for i := 0; s.LongLivedConns() != 3; i++ {
+		if i > 1000 {
+			t.Fatal("SSE stream not tracked")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	s.drainLongLived()
+	if !polite.drained.Load() || polite.closed.Load() || !stubborn.drained.Load() || !stubborn.closed.Load() {
+		t.Fatalf("unexpected drain: polite=%v/%v stubborn=%v/%v",
+			polite.drained.Load(), polite.closed.Load(), stubborn.drained.Load(), stubborn.closed.Load())
+	}
+	if resp := <-stream; !strings.Contains(resp, ": server shutting down\n\n") {
+		t.Fatalf("SSE client not notified: %q", resp)
+	}
+	if n := s.LongLivedConns(); n != 1 {
+		t.Fatalf("SSE stream still tracked: %d", n)
+	}
+}
@@ -0,0 +1,64 @@
+// early_hints.go
+package meego
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PreloadLink 构造 rel=preload 的 Link 值，as 为资源类型，如 style、script、font
+func PreloadLink(url, as string) string {
+	link := "<" + url + ">; rel=preload"
+	if as != "" {
+		link += "; as=" + as
+	}
+	if as == "font" {
+		// 字体预加载必须带 crossorigin
+		link += "; crossorigin"
+	}
+	return link
+}
+
+// EarlyHints 在最终响应之前发送 103 Early Hints，让浏览器提前加载资源：
+//
+//	c.EarlyHints(meego.PreloadLink("/app.css", "style"), meego.PreloadLink("/app.js", "script"))
+//	c.HTML(200, render())
+//
+// 同样的 Link 头会保留到最终响应中。HTTP/1.0 客户端不支持 1xx 响应，此时只设置 Link 头
+func (c *Context) EarlyHints(links ...string) error {
+	if len(links) == 0 {
+		return nil
+	}
+	link := strings.Join(links, ", ")
+	if prev := c.Writer.header["Link"]; prev != "" {
+		link = prev + ", " + link
+	}
+	c.Writer.SetHeader("Link", link)
+
+	if c.Request.Proto == "HTTP/1.0" {
+		return nil
+	}
+	return c.Writer.writeInformational(StatusEarlyHints, map[string]string{"Link": link})
+}
+
+// writeInformational 在最终响应之前写出 1xx 响应
+func (w *ResponseWriter) writeInformational(code int, header map[string]string) error {
+	w.guard.check("ResponseWriter", "writeInformational")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP/1.1 %d %s\r\n", code, getStatusText(code))
+	for key, value := range header {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	b.WriteString("\r\n")
+	_, err := w.conn.Write([]byte(b.String()))
+	return err
+}
+
+// Push 推送资源。服务器目前只支持 HTTP/1.1，退化为通过 103 Early Hints 预加载；
+// 支持 HTTP/2 后会改为服务器推送，调用方无需修改
+func (c *Context) Push(target, as string) error {
+	return c.EarlyHints(PreloadLink(target, as))
+}
@@ -0,0 +1,19 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEarlyHints(t *testing.T) {
+	s := New()
+	s.GET("/page", func(c *Context) {
+		c.EarlyHints(PreloadLink("/app.css", "style"))
+		c.HTML(StatusOK, "<html></html>")
+	})
+
+	resp := doRaw(s, "GET /page HTTP/1.1\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 103 Early Hints\r\nLink: </app.css>; rel=preload; as=style\r\n\r\nHTTP/1.1 200") {
+		t.Fatalf("expected 103 before final response: %q", resp)
+	}
+}
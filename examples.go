@@ -0,0 +1,250 @@
+// examples.go
+package meego
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// RouteExample 路由的请求/响应示例，写入 OpenAPI 文档，并可由 TestServer.VerifyExamples
+// 作为契约测试发送到服务器
+type RouteExample struct {
+	Name    string         `json:"name,omitempty" yaml:"name,omitempty"`
+	Request ExampleRequest `json:"request,omitempty" yaml:"request,omitempty"`
+	// Status 期望的状态码，默认 200
+	Status int `json:"status,omitempty" yaml:"status,omitempty"`
+	// Response 期望的响应体：JSON 值（map、切片、结构体）按结构校验，
+	// 只要求示例中的字段存在且类型一致；字符串只用于文档，不校验
+	Response interface{} `json:"response,omitempty" yaml:"response,omitempty"`
+	// ContentType 响应内容类型，默认按 Response 推断
+	ContentType string `json:"content_type,omitempty" yaml:"content_type,omitempty"`
+}
+
+// ExampleRequest 示例请求
+type ExampleRequest struct {
+	// Params 填充路由中的 :name 和 *name 参数
+	Params  map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	Query   string            `json:"query,omitempty" yaml:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Body 请求体，字符串原样发送，其它值编码为 JSON
+	Body interface{} `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Example 为路由添加示例，随 Routes、ExportRoutes 和 OpenAPI 导出
+func (r *Route) Example(ex RouteExample) *Route {
+	r.examples = append(r.examples, ex)
+	return r
+}
+
+func (ex RouteExample) status() int {
+	if ex.Status == 0 {
+		return StatusOK
+	}
+	return ex.Status
+}
+
+func (ex RouteExample) contentType() string {
+	if ex.ContentType != "" {
+		return ex.ContentType
+	}
+	if _, ok := ex.Response.(string); ok {
+		return "text/plain"
+	}
+	return "application/json"
+}
+
+func (r ExampleRequest) contentType() string {
+	if ct := r.Headers["Content-Type"]; ct != "" {
+		return ct
+	}
+	if _, ok := r.Body.(string); ok {
+		return "text/plain"
+	}
+	return "application/json"
+}
+
+// exampleValue 把示例值转换为 JSON 解码后的形式（map[string]interface{}、[]interface{} 等），
+// 结构体按 json 标签转换
+func exampleValue(v interface{}) interface{} {
+	if _, ok := v.(string); ok || v == nil {
+		return v
+	}
+	data, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// ContractError 路由示例与服务器实际响应不一致
+type ContractError struct {
+	Failures []string
+}
+
+func (e *ContractError) Error() string {
+	return "route examples failed:\n  " + strings.Join(e.Failures, "\n  ")
+}
+
+// VerifyExamples 把所有路由示例发送到测试服务器，校验状态码和响应结构，
+// 不一致时返回 *ContractError
+func (ts *TestServer) VerifyExamples() error {
+	contract := &ContractError{}
+	for _, route := range ts.server.Routes() {
+		for i, ex := range route.Examples {
+			name := ex.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i+1)
+			}
+			if err := ts.verifyExample(route, ex); err != nil {
+				contract.Failures = append(contract.Failures,
+					fmt.Sprintf("%s %s [%s]: %v", route.Method, route.Path, name, err))
+			}
+		}
+	}
+	if len(contract.Failures) > 0 {
+		sort.Strings(contract.Failures)
+		return contract
+	}
+	return nil
+}
+
+func (ts *TestServer) verifyExample(route RouteInfo, ex RouteExample) error {
+	var body io.Reader
+	if ex.Request.Body != nil {
+		if s, ok := ex.Request.Body.(string); ok {
+			body = strings.NewReader(s)
+		} else {
+			data, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(ex.Request.Body)
+			if err != nil {
+				return err
+			}
+			body = bytes.NewReader(data)
+		}
+	}
+	target := ts.URL + examplePath(route.Path, ex.Request.Params)
+	if ex.Request.Query != "" {
+		target += "?" + strings.TrimPrefix(ex.Request.Query, "?")
+	}
+	req, err := http.NewRequest(route.Method, target, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", ex.Request.contentType())
+	}
+	for key, value := range ex.Request.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := ts.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != ex.status() {
+		return fmt.Errorf("status %d, want %d", resp.StatusCode, ex.status())
+	}
+
+	expected := exampleValue(ex.Response)
+	if _, ok := expected.(string); ok || expected == nil || route.Method == "HEAD" {
+		return nil
+	}
+	var actual interface{}
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, &actual); err != nil {
+		return fmt.Errorf("response is not JSON: %v", err)
+	}
+	return matchShape(expected, actual, "$")
+}
+
+// examplePath 用示例参数填充路由路径
+func examplePath(path string, params map[string]string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			segments[i] = strings.TrimPrefix(params[seg[1:]], "/")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// matchShape 校验 actual 具有 expected 的结构：对象包含示例中的全部字段，数组元素与
+// 示例的第一个元素结构一致，标量类型相同；null 匹配任意值
+func matchShape(expected, actual interface{}, at string) error {
+	switch want := expected.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		got, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %s", at, jsonType(actual))
+		}
+		keys := make([]string, 0, len(want))
+		for key := range want {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := got[key]
+			if !ok {
+				return fmt.Errorf("%s.%s: missing", at, key)
+			}
+			if err := matchShape(want[key], value, at+"."+key); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		got, ok := actual.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %s", at, jsonType(actual))
+		}
+		if len(want) == 0 {
+			return nil
+		}
+		for i, item := range got {
+			if err := matchShape(want[0], item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if actual != nil && jsonType(expected) != jsonType(actual) {
+		return fmt.Errorf("%s: expected %s, got %s", at, jsonType(expected), jsonType(actual))
+	}
+	if actual == nil {
+		return fmt.Errorf("%s: expected %s, got null", at, jsonType(expected))
+	}
+	return nil
+}
+
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
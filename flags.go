@@ -0,0 +1,79 @@
+// flags.go
+package meego
+
+import "sync"
+
+// FlagProvider 功能开关提供者，每个请求对同一开关只解析一次
+type FlagProvider interface {
+	Enabled(c *Context, flag string) bool
+}
+
+// FlagProviderFunc 函数形式的 FlagProvider
+type FlagProviderFunc func(c *Context, flag string) bool
+
+func (f FlagProviderFunc) Enabled(c *Context, flag string) bool {
+	return f(c, flag)
+}
+
+// StaticFlags 内存中的静态开关，可在运行时修改
+type StaticFlags struct {
+	mu    sync.RWMutex
+	flags map[string]bool
+}
+
+// NewStaticFlags 创建静态开关提供者
+func NewStaticFlags(flags map[string]bool) *StaticFlags {
+	m := make(map[string]bool, len(flags))
+	for k, v := range flags {
+		m[k] = v
+	}
+	return &StaticFlags{flags: m}
+}
+
+func (f *StaticFlags) Enabled(c *Context, flag string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.flags[flag]
+}
+
+// Set 修改开关
+func (f *StaticFlags) Set(flag string, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flags[flag] = enabled
+}
+
+// SetFlagProvider 设置功能开关提供者
+func (s *HTTPServer) SetFlagProvider(p FlagProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flagProvider = p
+}
+
+// FlagEnabled 判断功能开关是否打开，结果在本次请求内缓存；未设置提供者时总是 false
+func (c *Context) FlagEnabled(flag string) bool {
+	if enabled, ok := c.flags[flag]; ok {
+		return enabled
+	}
+	if c.server == nil {
+		return false
+	}
+
+	c.server.mu.RLock()
+	provider := c.server.flagProvider
+	c.server.mu.RUnlock()
+
+	enabled := provider != nil && provider.Enabled(c, flag)
+	if c.flags == nil {
+		c.flags = make(map[string]bool, 4)
+	}
+	c.flags[flag] = enabled
+	return enabled
+}
+
+// CanaryFlag 功能开关打开时进入灰度版本
+func (r *Route) CanaryFlag(flag string, handler HandlerFunc) *Route {
+	return r.CanaryWhen(func(c *Context) bool {
+		return c.FlagEnabled(flag)
+	}, handler)
+}
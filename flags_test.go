@@ -0,0 +1,41 @@
+package meego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFeatureFlags(t *testing.T) {
+	s := New()
+	if c := (&Context{}); c.FlagEnabled("x") {
+		t.Fatal("flag enabled without a server")
+	}
+
+	static := NewStaticFlags(map[string]bool{"new-checkout": true})
+	calls := 0
+	s.SetFlagProvider(FlagProviderFunc(func(c *Context, flag string) bool {
+		calls++
+		if flag == "beta" {
+			return c.Request.GetHeader("X-Beta") == "1"
+		}
+		return static.Enabled(c, flag)
+	}))
+	s.GET("/checkout", func(c *Context) {
+		// 同一请求内只解析一次，beta 沿用灰度选择时的结果
+		c.String(StatusOK, fmt.Sprint(c.FlagEnabled("new-checkout"), c.FlagEnabled("new-checkout"), c.FlagEnabled("beta"), c.FlagEnabled("missing")))
+	}).CanaryFlag("beta", func(c *Context) { c.String(StatusOK, "beta") })
+
+	if resp := doRaw(s, "GET /checkout HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "true true false false") || calls != 3 {
+		t.Fatalf("flag evaluation (%d provider calls):\n%s", calls, resp)
+	}
+	if resp := doRaw(s, "GET /checkout HTTP/1.1\r\nX-Beta: 1\r\n\r\n"); !strings.HasSuffix(resp, "beta") {
+		t.Fatalf("flag canary:\n%s", resp)
+	}
+
+	// 运行时修改立即对之后的请求生效，上一次请求的缓存不会泄漏
+	static.Set("new-checkout", false)
+	if resp := doRaw(s, "GET /checkout HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "false false false false") {
+		t.Fatalf("flag update:\n%s", resp)
+	}
+}
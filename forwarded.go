@@ -0,0 +1,137 @@
+// forwarded.go
+package meego
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ForwardedMode 代理向上游传递客户端信息的方式
+type ForwardedMode int
+
+const (
+	// ForwardedXHeaders 追加 X-Forwarded-For，设置 X-Forwarded-Proto、X-Forwarded-Host（默认）
+	ForwardedXHeaders ForwardedMode = iota
+	// ForwardedRFC7239 追加标准 Forwarded 头（RFC 7239），包含 for、by、proto、host
+	ForwardedRFC7239
+	// ForwardedBoth 同时发送 Forwarded 和 X-Forwarded-*
+	ForwardedBoth
+	// ForwardedNone 不添加转发头，原样透传客户端发送的值
+	ForwardedNone
+)
+
+// setForwardedHeaders 按 mode 为转发到上游的请求添加转发头，by 为空时使用本机监听地址
+func setForwardedHeaders(c *Context, header http.Header, mode ForwardedMode, by string) {
+	if mode == ForwardedNone {
+		return
+	}
+	client := hostOnly(c.Conn.RemoteAddr().String())
+	proto := "http"
+	if c.ConnValues().TLS() != nil {
+		proto = "https"
+	}
+	host := c.Request.Host
+
+	if mode == ForwardedXHeaders || mode == ForwardedBoth {
+		appendHeader(header, "X-Forwarded-For", client)
+		header.Set("X-Forwarded-Proto", proto)
+		if host != "" {
+			header.Set("X-Forwarded-Host", host)
+		}
+	}
+	if mode == ForwardedRFC7239 || mode == ForwardedBoth {
+		if by == "" {
+			by = hostOnly(c.Conn.LocalAddr().String())
+		}
+		elem := "for=" + forwardedNode(client) + ";by=" + forwardedNode(by) + ";proto=" + proto
+		if host != "" {
+			elem += ";host=" + forwardedValue(host)
+		}
+		appendHeader(header, "Forwarded", elem)
+	}
+}
+
+// appendHeader 在已有值（来自上一跳）后追加
+func appendHeader(header http.Header, key, value string) {
+	if prior := header.Get(key); prior != "" {
+		value = prior + ", " + value
+	}
+	header.Set(key, value)
+}
+
+// hostOnly 去掉地址中的端口
+func hostOnly(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// forwardedNode 格式化 for/by 节点：IPv6 加方括号并加引号
+func forwardedNode(node string) string {
+	if ip := net.ParseIP(node); ip != nil && ip.To4() == nil {
+		return `"[` + node + `]"`
+	}
+	return forwardedValue(node)
+}
+
+// forwardedValue 值不是合法 token 时加引号
+func forwardedValue(value string) string {
+	for _, r := range value {
+		if !isTokenChar(r) {
+			return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+		}
+	}
+	return value
+}
+
+func isTokenChar(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	}
+	return strings.ContainsRune("!#$%&'*+-.^_`|~", r)
+}
+
+// parseForwardedFor 按顺序返回 Forwarded 头各元素的 for 值，去掉引号、方括号和端口；
+// "unknown" 和混淆标识（如 "_hidden"）原样返回
+func parseForwardedFor(header string) []string {
+	var hops []string
+	for _, elem := range splitQuoted(header, ',') {
+		for _, pair := range splitQuoted(elem, ';') {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "for") {
+				continue
+			}
+			value = strings.Trim(strings.TrimSpace(value), `"`)
+			if strings.HasPrefix(value, "[") {
+				if end := strings.IndexByte(value, ']'); end > 0 {
+					value = value[1:end]
+				}
+			} else if host, _, err := net.SplitHostPort(value); err == nil {
+				value = host
+			}
+			hops = append(hops, value)
+		}
+	}
+	return hops
+}
+
+// splitQuoted 按 sep 分割，忽略引号内的分隔符
+func splitQuoted(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,47 @@
+// global_headers.go
+package meego
+
+import "net/http"
+
+// SetGlobalHeaders 设置每个响应都带有的响应头，包括框架生成的 404、405、500 和解析错误响应，
+// 如 X-Service、X-Region 或默认的 Cache-Control。处理器和 HeaderPolicy 设置的同名响应头优先；
+// 再次调用替换之前的设置
+func (s *HTTPServer) SetGlobalHeaders(headers map[string]string) {
+	global := make(map[string]string, len(headers))
+	for key, value := range headers {
+		global[http.CanonicalHeaderKey(key)] = value
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.globalHeaders = global
+}
+
+// applyGlobalHeaders 在执行处理链之前写入全局响应头
+func (s *HTTPServer) applyGlobalHeaders(w *ResponseWriter) {
+	s.mu.RLock()
+	global := s.globalHeaders
+	s.mu.RUnlock()
+	for key, value := range global {
+		w.header[key] = value
+	}
+}
+
+// HeaderPolicy 为路由组或路由覆盖响应头，值为空字符串时删除该响应头（如去掉全局的缓存默认值）
+func HeaderPolicy(headers map[string]string) MiddlewareFunc {
+	policy := make(map[string]string, len(headers))
+	for key, value := range headers {
+		policy[http.CanonicalHeaderKey(key)] = value
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			for key, value := range policy {
+				if value == "" {
+					delete(c.Writer.header, key)
+				} else {
+					c.Writer.header[key] = value
+				}
+			}
+			next(c)
+		}
+	}
+}
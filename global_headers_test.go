@@ -0,0 +1,37 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlobalHeaders(t *testing.T) {
+	s := New()
+	s.SetGlobalHeaders(map[string]string{"x-service": "orders", "Cache-Control": "no-store"})
+	s.GET("/", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+	s.GET("/static", func(c *Context) {
+		c.String(StatusOK, "cached")
+	}).Use(HeaderPolicy(map[string]string{"Cache-Control": "max-age=60"}))
+	s.GET("/plain", func(c *Context) {
+		c.String(StatusOK, "plain")
+	}).Use(HeaderPolicy(map[string]string{"cache-control": ""}))
+
+	for raw, want := range map[string][]string{
+		"GET / HTTP/1.1\r\n\r\n":        {"X-Service: orders", "Cache-Control: no-store"},
+		"GET /missing HTTP/1.1\r\n\r\n": {"HTTP/1.1 404", "X-Service: orders"},
+		"BOGUS / HTTP/1.1\r\n\r\n":      {"HTTP/1.1 400", "X-Service: orders"},
+		"GET /static HTTP/1.1\r\n\r\n":  {"X-Service: orders", "Cache-Control: max-age=60"},
+	} {
+		resp := doRaw(s, raw)
+		for _, w := range want {
+			if !strings.Contains(resp, w) {
+				t.Fatalf("%q: missing %q:\n%s", raw, w, resp)
+			}
+		}
+	}
+	if resp := doRaw(s, "GET /plain HTTP/1.1\r\n\r\n"); strings.Contains(resp, "Cache-Control") {
+		t.Fatalf("override should remove cache header:\n%s", resp)
+	}
+}
@@ -3,9 +3,14 @@ module github.com/asaka1234/meego
 go 1.24.2
 
 require (
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/json-iterator/go v1.1.12
+	github.com/panjf2000/ants/v2 v2.11.3
 	github.com/rs/zerolog v1.34.0
 	github.com/spf13/cast v1.10.0
+	golang.org/x/image v0.24.0
+	golang.org/x/text v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -13,7 +18,6 @@ require (
 	github.com/mattn/go-isatty v0.0.19 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
-	github.com/panjf2000/ants/v2 v2.11.3 // indirect
 	golang.org/x/sync v0.11.0 // indirect
 	golang.org/x/sys v0.12.0 // indirect
 )
@@ -0,0 +1,210 @@
+// hedge.go
+package meego
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HedgeConfig 对冲请求配置
+type HedgeConfig struct {
+	// Percentile 上游响应耗时超过该分位数后发出第二次请求，默认 0.95
+	Percentile float64
+	// MinDelay 对冲延迟的下限，避免上游很快时对冲过多，默认 5ms
+	MinDelay time.Duration
+	// Budget 对冲请求占全部请求的比例上限，默认 0.05
+	Budget float64
+	// Window 计算分位数使用的最近样本数，默认 1000
+	Window int
+	// MinSamples 样本数达到该值之前不对冲，默认 20
+	MinSamples int
+}
+
+// HedgeStats 对冲效果统计
+type HedgeStats struct {
+	Requests  int64         `json:"requests"`   // 经过对冲中间件的代理请求
+	Hedged    int64         `json:"hedged"`     // 发出了第二次请求的次数
+	Wins      int64         `json:"wins"`       // 第二次请求先返回的次数
+	Canceled  int64         `json:"canceled"`   // 取消的落后请求
+	OverLimit int64         `json:"over_limit"` // 超过延迟但预算不足、没有对冲的次数
+	Delay     time.Duration `json:"delay"`      // 当前的对冲延迟，0 表示样本不足
+}
+
+// Hedging 代理 GET 请求的对冲：上游响应耗时超过近期的分位数时向上游再发一次相同请求，
+// 采用先返回的响应并取消另一个。对冲请求数受 Budget 限制，避免上游变慢时负载翻倍
+type Hedging struct {
+	cfg HedgeConfig
+
+	mu      sync.Mutex
+	samples []time.Duration // 环形缓冲
+	next    int
+	delay   time.Duration
+	tokens  float64
+	stats   HedgeStats
+}
+
+// NewHedging 创建对冲策略
+func NewHedging(cfg HedgeConfig) *Hedging {
+	if cfg.Percentile <= 0 || cfg.Percentile >= 1 {
+		cfg.Percentile = 0.95
+	}
+	if cfg.MinDelay <= 0 {
+		cfg.MinDelay = 5 * time.Millisecond
+	}
+	if cfg.Budget <= 0 {
+		cfg.Budget = 0.05
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 1000
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = 20
+	}
+	return &Hedging{cfg: cfg, samples: make([]time.Duration, 0, cfg.Window)}
+}
+
+const hedgingKey = "meego.hedging"
+
+// Middleware 为之后的 ReverseProxy、Proxy 和 ProxyPass 处理器启用对冲，
+// 只对没有请求体的 GET 和 HEAD 请求生效
+func (h *Hedging) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Set(hedgingKey, h)
+			next(c)
+		}
+	}
+}
+
+// Stats 返回对冲效果统计
+func (h *Hedging) Stats() HedgeStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stats := h.stats
+	stats.Delay = h.delay
+	return stats
+}
+
+// begin 记录一次请求并返回对冲延迟，0 表示不对冲
+func (h *Hedging) begin() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stats.Requests++
+	// 每个请求积累 Budget 个令牌，对冲消耗一个；上限允许少量突发
+	h.tokens += h.cfg.Budget
+	if h.tokens > 10 {
+		h.tokens = 10
+	}
+	return h.delay
+}
+
+// allow 延迟到期时检查预算，允许时扣除令牌
+func (h *Hedging) allow() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		h.stats.OverLimit++
+		return false
+	}
+	h.tokens--
+	h.stats.Hedged++
+	return true
+}
+
+// finish 记录上游耗时和对冲结果
+func (h *Hedging) finish(latency time.Duration, hedgeWon bool, canceled int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if hedgeWon {
+		h.stats.Wins++
+	}
+	h.stats.Canceled += int64(canceled)
+
+	if len(h.samples) < h.cfg.Window {
+		h.samples = append(h.samples, latency)
+	} else {
+		h.samples[h.next] = latency
+	}
+	h.next = (h.next + 1) % h.cfg.Window
+	// 每 16 个样本重新计算一次分位数
+	if len(h.samples) >= h.cfg.MinSamples && (h.delay == 0 || h.next%16 == 0) {
+		sorted := append([]time.Duration(nil), h.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		h.delay = sorted[int(float64(len(sorted)-1)*h.cfg.Percentile)]
+		if h.delay < h.cfg.MinDelay {
+			h.delay = h.cfg.MinDelay
+		}
+	}
+}
+
+// hedgeAttempt 一次上游请求的结果
+type hedgeAttempt struct {
+	resp   *http.Response
+	err    error
+	hedged bool
+}
+
+// do 发送请求，延迟到期且预算允许时发出第二次请求，返回先成功的响应。
+// 每次请求使用 ctx 的子 context，落后的请求被取消，它的响应体被关闭
+func (h *Hedging) do(ctx context.Context, cl *Client, out *http.Request) (*http.Response, error) {
+	start := now()
+	delay := h.begin()
+	results := make(chan hedgeAttempt, 2)
+	var cancels []context.CancelFunc
+	send := func(req *http.Request, hedged bool) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		req = req.WithContext(attemptCtx)
+		go func() {
+			resp, err := cl.Do(nil, req)
+			results <- hedgeAttempt{resp: resp, err: err, hedged: hedged}
+		}()
+	}
+	// 第二次请求在发送前克隆，避免与第一次请求的传输共享请求头
+	hedgeReq := out.Clone(ctx)
+	send(out, false)
+
+	var timer <-chan time.Time
+	if delay > 0 {
+		timer = clock().After(delay)
+	}
+	pending := 1
+	for {
+		select {
+		case <-timer:
+			timer = nil
+			if h.allow() {
+				send(hedgeReq, true)
+				pending++
+			}
+		case a := <-results:
+			pending--
+			if a.err != nil {
+				if pending > 0 {
+					// 等待另一个请求的结果
+					continue
+				}
+				// 对冲只处理慢请求，请求失败时不再发出第二次请求，失败的耗时也不计入样本
+				return nil, a.err
+			}
+			h.finish(now().Sub(start), a.hedged, pending)
+			for i, cancel := range cancels {
+				if (i == 1) != a.hedged {
+					cancel()
+				}
+			}
+			if pending > 0 {
+				// 关闭落后请求可能已经返回的响应
+				go func() {
+					if late := <-results; late.resp != nil {
+						late.resp.Body.Close()
+					}
+				}()
+			}
+			return a.resp, nil
+		}
+	}
+}
@@ -0,0 +1,61 @@
+// hijack.go
+package meego
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"time"
+)
+
+// ErrHijacked 连接已被接管
+var ErrHijacked = errors.New("connection has been hijacked")
+
+// ErrAbortConnection 以此值 panic 可以有意断开连接：不发送响应、不记录调用栈，
+// Recovery 会放行它。用于识别出的攻击流量等不值得回复的请求
+var ErrAbortConnection = errors.New("meego: abort connection")
+
+// AbortConnection 中止处理链并立即断开连接（TCP 连接发送 RST），不发送响应
+func (c *Context) AbortConnection() {
+	c.Abort()
+	panic(ErrAbortConnection)
+}
+
+// isAbortConnection panic 值是否为 ErrAbortConnection
+func isAbortConnection(v interface{}) bool {
+	err, ok := v.(error)
+	return ok && errors.Is(err, ErrAbortConnection)
+}
+
+// dropConnection 丢弃未发送的数据并以 RST 关闭 TCP 连接
+func dropConnection(conn net.Conn) {
+	if tc, ok := conn.(*net.TCPConn); ok {
+		tc.SetLinger(0)
+	}
+	conn.Close()
+}
+
+// Hijack 接管底层连接，用于 WebSocket 等协议升级。
+// 返回的 Reader 可能已缓冲了请求之后的数据，必须通过它读取。
+// 接管后不能再通过 c.Writer 写出响应，读写超时被清除；
+// 连接在处理器返回后关闭，因此处理器应当在连接使用结束后才返回
+func (c *Context) Hijack() (net.Conn, *bufio.Reader, error) {
+	c.guard.check("Context", "Hijack")
+	// 与 Timeout 中间件的超时响应互斥
+	c.Writer.mu.Lock()
+	defer c.Writer.mu.Unlock()
+	if c.Writer.hijacked {
+		return nil, nil, ErrHijacked
+	}
+	if c.Writer.streaming || c.Writer.written {
+		return nil, nil, errors.New("response already started")
+	}
+	c.Writer.hijacked = true
+	c.Conn.SetDeadline(time.Time{})
+
+	reader := c.Request.reader
+	if reader == nil {
+		reader = bufio.NewReader(c.Conn)
+	}
+	return c.Conn, reader, nil
+}
@@ -0,0 +1,33 @@
+package meego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestAbortConnection(t *testing.T) {
+	var logs syncBuffer
+	defer func(l zerolog.Logger) { log.Logger = l }(log.Logger)
+	log.Logger = zerolog.New(&logs)
+
+	s := New()
+	s.Use(Recovery())
+	s.GET("/drop", func(c *Context) {
+		c.Writer.SetHeader("X-Partial", "1")
+		c.AbortConnection()
+	})
+	s.GET("/panic", func(c *Context) { panic(fmt.Errorf("scanner detected: %w", ErrAbortConnection)) })
+
+	for _, path := range []string{"/drop", "/panic"} {
+		if resp := doRaw(s, "GET "+path+" HTTP/1.1\r\nHost: x\r\n\r\n"); resp != "" {
+			t.Fatalf("%s: expected no response, got:\n%s", path, resp)
+		}
+	}
+	if strings.Contains(logs.String(), "panic recovered") {
+		t.Fatalf("abort logged as panic:\n%s", logs.String())
+	}
+}
@@ -1,9 +1,18 @@
 package meego
 
 import (
+	"context"
+	"math"
+	"mime/multipart"
 	"net"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
 )
 
 // Context 请求上下文
@@ -19,10 +28,30 @@ type Context struct {
 	Values   map[string]interface{}
 	Index    int
 	handlers []HandlerFunc
+
+	server     *HTTPServer
+	connValues *ConnValues // 连接级存储
+	deadline   time.Time   // 请求处理期限（写超时）
+	fullPath   string      // 匹配的路由模式
+	logger     *zerolog.Logger
+	timings    requestTimings
+	flags      map[string]bool // 本次请求已解析的功能开关
+	// 已解析的 multipart 表单，请求结束时删除临时文件
+	multipartForm *multipart.Form
+	uploads       []*UploadedFile
+
+	// 按需创建的标准 context，请求结束时取消
+	stdCtx context.Context
+	cancel context.CancelFunc
+
+	// 请求结束、上下文放回对象池之前执行的回调
+	releaseHooks []func()
+
+	guard poolGuard
 }
 
-// 快速初始化
-func (c *Context) fastInit(conn net.Conn, req *HTTPRequest, writer *ResponseWriter, params map[string]string, handler HandlerFunc) {
+// 快速初始化，处理链由调用方追加到 c.handlers
+func (c *Context) fastInit(conn net.Conn, req *HTTPRequest, writer *ResponseWriter, params map[string]string) {
 	c.Conn = conn
 	c.Request = req
 	c.Writer = writer
@@ -31,10 +60,9 @@ func (c *Context) fastInit(conn net.Conn, req *HTTPRequest, writer *ResponseWrit
 
 	// 重用 handlers 切片
 	if cap(c.handlers) == 0 {
-		c.handlers = make([]HandlerFunc, 0, 4)
+		c.handlers = make([]HandlerFunc, 0, 8)
 	}
 	c.handlers = c.handlers[:0]
-	c.handlers = append(c.handlers, handler)
 
 	// 清空 Values 但保留容量
 	for k := range c.Values {
@@ -42,42 +70,92 @@ func (c *Context) fastInit(conn net.Conn, req *HTTPRequest, writer *ResponseWrit
 	}
 }
 
-// Context 方法
+// abortIndex Abort 后的 Index，大于任何处理链的长度
+const abortIndex = math.MaxInt32 / 2
+
+// Next 依次执行处理链中剩余的处理器（全局、路由组、路由中间件和路由处理器），
+// 在中间件中调用时，返回后可以继续执行后置逻辑
 func (c *Context) Next() {
+	c.guard.check("Context", "Next")
 	c.Index++
-	if c.Index < len(c.handlers) {
+	for c.Index < len(c.handlers) {
 		c.handlers[c.Index](c)
+		c.Index++
 	}
 }
 
+// Abort 阻止执行处理链中剩余的处理器，不影响当前处理器继续执行和已写出的响应
+func (c *Context) Abort() {
+	c.Index = abortIndex
+}
+
+// IsAborted 处理链是否已被中止
+func (c *Context) IsAborted() bool {
+	return c.Index >= abortIndex
+}
+
+// AbortWithStatus 中止处理链并返回状态码，响应体为空
+func (c *Context) AbortWithStatus(code int) {
+	c.Abort()
+	c.Writer.Status(code).writeResponse(nil)
+}
+
+// AbortWithStatusJSON 中止处理链并返回 JSON 响应
+func (c *Context) AbortWithStatusJSON(code int, obj interface{}) {
+	c.Abort()
+	c.JSON(code, obj)
+}
+
 func (c *Context) JSON(code int, data interface{}) {
+	c.guard.check("Context", "JSON")
 	c.Writer.Status(code).JSON(data)
 }
 
 func (c *Context) String(code int, text string) {
+	c.guard.check("Context", "String")
 	c.Writer.Status(code).String(text)
 }
 
 func (c *Context) HTML(code int, html string) {
+	c.guard.check("Context", "HTML")
 	c.Writer.Status(code).HTML(html)
 }
 
+// Data 写出原始字节，contentType 为空时根据内容前 512 字节嗅探类型
+func (c *Context) Data(code int, contentType string, data []byte) {
+	c.guard.check("Context", "Data")
+	c.Writer.Status(code).Data(contentType, data)
+}
+
+// Blob Data 的别名
+func (c *Context) Blob(code int, contentType string, data []byte) {
+	c.Data(code, contentType, data)
+}
+
 func (c *Context) Set(key string, value interface{}) {
+	c.guard.check("Context", "Set")
 	c.Values[key] = value
 }
 
 func (c *Context) Get(key string) interface{} {
+	c.guard.check("Context", "Get")
 	return c.Values[key]
 }
 
 // Param 获取路径参数（类似 gin.Param）
 func (c *Context) Param(key string) string {
+	c.guard.check("Context", "Param")
 	if c.params == nil {
 		return ""
 	}
 	return c.params[key]
 }
 
+// FullPath 返回匹配的路由模式，如 /api/users/:id
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
 // Params 获取所有路径参数
 func (c *Context) Params() map[string]string {
 	return c.params
@@ -90,6 +168,7 @@ func (c *Context) SetParams(params map[string]string) {
 
 // Query 获取查询字符串参数（类似 gin.Query）
 func (c *Context) Query(key string) string {
+	c.guard.check("Context", "Query")
 	if c.Request.URL == nil {
 		return ""
 	}
@@ -211,19 +290,157 @@ func (c *Context) GetAllQuery() url.Values {
 	return c.Request.URL.Query()
 }
 
+// Cookie 读取请求中的 Cookie 值
+func (c *Context) Cookie(name string) (string, error) {
+	header := c.Request.GetHeader("Cookie")
+	for header != "" {
+		var part string
+		part, header, _ = strings.Cut(header, ";")
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && key == name {
+			return strings.Trim(value, `"`), nil
+		}
+	}
+	return "", ErrCookieNotFound
+}
+
+// SetCookie 添加响应 Cookie，需要在写出响应之前调用
+func (c *Context) SetCookie(cookie *http.Cookie) {
+	c.guard.check("Context", "SetCookie")
+	c.Writer.SetCookie(cookie)
+}
+
+// BodyBytes 返回请求体的副本，可以安全地保存到请求结束之后
+func (c *Context) BodyBytes() []byte {
+	c.guard.check("Context", "BodyBytes")
+	c.Request.ReadBody()
+	if len(c.Request.Body) == 0 {
+		return nil
+	}
+	body := make([]byte, len(c.Request.Body))
+	copy(body, c.Request.Body)
+	return body
+}
+
+// BodyUnsafe 零拷贝返回请求体。底层缓冲区随 HTTPRequest 放回对象池后会被复用，
+// 返回值只能在当前请求处理期间使用，需要保留时请使用 BodyBytes
+func (c *Context) BodyUnsafe() []byte {
+	c.guard.check("Context", "BodyUnsafe")
+	c.Request.ReadBody()
+	return c.Request.Body
+}
+
+// BodyString 以字符串形式返回请求体（总是复制）
+func (c *Context) BodyString() string {
+	c.guard.check("Context", "BodyString")
+	c.Request.ReadBody()
+	return string(c.Request.Body)
+}
+
+// ClientIP 返回客户端地址。直连地址属于受信任代理（热加载配置 trusted_proxies）时，
+// 从 Forwarded（RFC 7239）的 for 列表或 X-Forwarded-For 右侧开始跳过受信任代理取第一个地址，
+// 其次使用 X-Real-IP
 func (c *Context) ClientIP() string {
-	// 简化实现，实际应该从 X-Forwarded-For 等头部获取
-	return c.Conn.RemoteAddr().String()
+	remote := c.Conn.RemoteAddr().String()
+	if c.server == nil {
+		return remote
+	}
+	state := c.server.runtimeState()
+	if state == nil || len(state.trustedProxies) == 0 {
+		return remote
+	}
+
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		host = remote
+	}
+	if ip := net.ParseIP(host); ip == nil || !state.isTrustedProxy(ip) {
+		return remote
+	}
+
+	var hops []string
+	if forwarded := c.Request.GetHeader("Forwarded"); forwarded != "" {
+		hops = parseForwardedFor(forwarded)
+	} else if xff := c.Request.GetHeader("X-Forwarded-For"); xff != "" {
+		hops = strings.Split(xff, ",")
+	}
+	if len(hops) > 0 {
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			ip := net.ParseIP(hop)
+			if ip == nil {
+				break
+			}
+			if i == 0 || !state.isTrustedProxy(ip) {
+				return hop
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(c.Request.GetHeader("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return remote
+}
+
+// Logger 返回请求级结构化日志，预置请求 ID、路由、方法和客户端 IP
+func (c *Context) Logger() *zerolog.Logger {
+	if c.logger == nil {
+		lc := log.Logger.With().
+			Str("request_id", c.RequestID()).
+			Str("route", c.fullPath).
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("client_ip", clientIPKey(c))
+		if t := c.Tenant(); t != nil {
+			lc = lc.Str("tenant", t.ID)
+		}
+		l := lc.Logger()
+		c.logger = &l
+	}
+	return c.logger
+}
+
+// onRelease 注册请求结束时执行的回调，用于注销与请求同生命周期的资源
+func (c *Context) onRelease(fn func()) {
+	c.releaseHooks = append(c.releaseHooks, fn)
 }
 
 // Context 的 reset 方法
 func (c *Context) reset() {
+	for i, fn := range c.releaseHooks {
+		fn()
+		c.releaseHooks[i] = nil
+	}
+	c.releaseHooks = c.releaseHooks[:0]
 	c.Conn = nil
 	c.Request = nil
 	c.Writer = nil
 	c.params = nil
 	c.handlers = nil
 	c.Index = -1
+	c.server = nil
+	c.connValues = nil
+	c.deadline = time.Time{}
+	c.fullPath = ""
+	c.logger = nil
+	c.timings = requestTimings{}
+	for k := range c.flags {
+		delete(c.flags, k)
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.stdCtx = nil
+	c.cancel = nil
+	for i, f := range c.uploads {
+		f.Close()
+		c.uploads[i] = nil
+	}
+	c.uploads = c.uploads[:0]
+	if c.multipartForm != nil {
+		c.multipartForm.RemoveAll()
+		c.multipartForm = nil
+	}
 
 	if c.Values != nil {
 		for k := range c.Values {
@@ -237,6 +454,8 @@ func (c *Context) reset() {
 // 错误定义
 var ErrQueryParamNotFound = &QueryError{Message: "query parameter not found"}
 
+var ErrCookieNotFound = &QueryError{Message: "named cookie not present"}
+
 type QueryError struct {
 	Message string
 }
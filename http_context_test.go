@@ -0,0 +1,147 @@
+package meego
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestContextLogger(t *testing.T) {
+	var logs syncBuffer
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+
+	s := New()
+	s.Use(RequestID())
+	s.GET("/users/:id", func(c *Context) {
+		// 只替换输出，保留请求级字段；不修改全局 log.Logger
+		l := c.Logger().Output(&logs)
+		l.Info().Msg("loaded user")
+		c.String(StatusOK, "ok")
+	})
+
+	resp := doRaw(s, "GET /users/7 HTTP/1.1\r\nX-Request-ID: req-42\r\nConnection: close\r\n\r\n")
+	if !strings.Contains(resp, "X-Request-ID: req-42") {
+		t.Fatalf("request id not echoed:\n%s", resp)
+	}
+	for _, field := range []string{`"request_id":"req-42"`, `"route":"/users/:id"`, `"method":"GET"`, `"path":"/users/7"`, `"message":"loaded user"`} {
+		if !strings.Contains(logs.String(), field) {
+			t.Fatalf("log line missing %s:\n%s", field, logs.String())
+		}
+	}
+}
+
+func TestBodyBytes(t *testing.T) {
+	s := New()
+	var kept [][]byte
+	s.POST("/keep", func(c *Context) {
+		body := c.BodyBytes()
+		kept = append(kept, body)
+		// 返回的是副本，修改不影响再次读取
+		body[0] = '#'
+		again := c.BodyBytes()
+		c.String(StatusOK, fmt.Sprintf("%s|%s|%s", again, c.BodyString(), c.BodyUnsafe()))
+	})
+	s.POST("/small", func(c *Context) { c.String(StatusOK, string(c.BodyBytes())) }).MaxBodySize(4)
+
+	post := func(path, body string) string {
+		return "POST " + path + " HTTP/1.1\r\nContent-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+	}
+	resp := doRaw(s, post("/keep", "first")+post("/keep", "other"))
+	if !strings.Contains(resp, "first|first|first") || !strings.HasSuffix(resp, "other|other|other") {
+		t.Fatalf("re-reading body:\n%s", resp)
+	}
+	// 请求缓冲区放回对象池并被下一个请求复用后，副本保持不变
+	if len(kept) != 2 || string(kept[0]) != "#irst" || string(kept[1]) != "#ther" {
+		t.Fatalf("retained bodies changed: %q", kept)
+	}
+
+	if resp := doRaw(s, post("/small", "12345")); !strings.HasPrefix(resp, "HTTP/1.1 413") {
+		t.Fatalf("expected 413 over route limit:\n%s", resp)
+	}
+	if resp := doRaw(s, post("/small", "1234")); !strings.HasSuffix(resp, "\r\n\r\n1234") {
+		t.Fatalf("body within limit:\n%s", resp)
+	}
+	if resp := doRaw(s, "POST /small HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "\r\n\r\n") {
+		t.Fatalf("empty body:\n%s", resp)
+	}
+}
+
+func TestMiddlewareChainNextAndAbort(t *testing.T) {
+	var trace []string
+	mark := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) {
+				trace = append(trace, name+">")
+				c.Next()
+				trace = append(trace, "<"+name)
+			}
+		}
+	}
+	guard := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if c.Query("deny") != "" {
+				c.Abort()
+				c.String(StatusForbidden, "denied")
+				return
+			}
+			next(c)
+		}
+	}
+	reject := func(next HandlerFunc) HandlerFunc {
+		// 不调用 next 同样中止处理链
+		return func(c *Context) { c.String(StatusUnauthorized, "no") }
+	}
+
+	s := New()
+	s.Use(mark("global"))
+	g := s.Group("/api", mark("group"))
+	g.Use(guard)
+	g.GET("/x", func(c *Context) {
+		trace = append(trace, "handler")
+		c.String(StatusOK, "ok")
+	}).Use(mark("route"))
+	g.GET("/locked", func(c *Context) { trace = append(trace, "leaked") }).Use(reject)
+
+	resp := doRaw(s, "GET /api/x HTTP/1.1\r\nHost: x\r\n\r\n")
+	want := "global> group> route> handler <route <group <global"
+	if got := strings.Join(trace, " "); got != want || !strings.HasSuffix(resp, "ok") {
+		t.Fatalf("trace = %q, want %q\n%s", got, want, resp)
+	}
+
+	trace = nil
+	resp = doRaw(s, "GET /api/x?deny=1 HTTP/1.1\r\nHost: x\r\n\r\n")
+	if got := strings.Join(trace, " "); got != "global> group> <group <global" || !strings.HasPrefix(resp, "HTTP/1.1 403") {
+		t.Fatalf("abort trace = %q\n%s", got, resp)
+	}
+
+	trace = nil
+	resp = doRaw(s, "GET /api/locked HTTP/1.1\r\nHost: x\r\n\r\n")
+	if strings.Contains(strings.Join(trace, " "), "leaked") || !strings.HasPrefix(resp, "HTTP/1.1 401") {
+		t.Fatalf("handler ran after middleware short-circuit: %v\n%s", trace, resp)
+	}
+}
+
+func TestAbortWithStatus(t *testing.T) {
+	s := New()
+	ran := false
+	s.GET("/status", func(c *Context) { ran = true }).Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.AbortWithStatus(StatusForbidden)
+			next(c) // 已中止，不再执行后续处理器
+		}
+	})
+	s.GET("/json", func(c *Context) { ran = true }).Use(Auth())
+
+	resp := doRaw(s, "GET /status HTTP/1.1\r\nHost: x\r\n\r\n")
+	if ran || !strings.HasPrefix(resp, "HTTP/1.1 403") || !strings.Contains(resp, "Content-Length: 0") {
+		t.Fatalf("AbortWithStatus ran=%v:\n%s", ran, resp)
+	}
+	resp = doRaw(s, "GET /json HTTP/1.1\r\nHost: x\r\n\r\n")
+	if ran || !strings.HasPrefix(resp, "HTTP/1.1 401") || !strings.Contains(resp, `"error":"Unauthorized"`) {
+		t.Fatalf("AbortWithStatusJSON ran=%v:\n%s", ran, resp)
+	}
+}
@@ -3,13 +3,17 @@ package meego
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
+	"net/http/httputil"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // 全局对象池
@@ -26,12 +30,19 @@ type HTTPRequest struct {
 	URL     *url.URL
 	Proto   string
 	Headers map[string]string
-	Body    []byte
+	Body    []byte // 池化复用的缓冲区，需要在请求结束后保留时使用 Context.BodyBytes
 	Host    string
 	RawURL  string
 
 	contentLength int
 	parsed        bool
+	parseTime     time.Duration
+	// bodyReader 流式路由的请求体，解析器不预先读取，由处理器直接从连接读取
+	bodyReader io.Reader
+	// reader 连接的缓冲读取器，Hijack 后继续从这里读取，避免丢失已缓冲的数据
+	reader *bufio.Reader
+
+	guard poolGuard
 }
 
 func (r *HTTPRequest) reset() {
@@ -42,6 +53,9 @@ func (r *HTTPRequest) reset() {
 	r.RawURL = ""
 	r.contentLength = 0
 	r.parsed = false
+	r.parseTime = 0
+	r.bodyReader = nil
+	r.reader = nil
 	r.Body = r.Body[:0]
 
 	for k := range r.Headers {
@@ -64,8 +78,18 @@ func (r *HTTPRequest) ContentLength() int {
 	return 0
 }
 
+// GetHeader 获取请求头，优先精确匹配，找不到时按大小写不敏感匹配
 func (r *HTTPRequest) GetHeader(key string) string {
-	return r.Headers[key]
+	r.guard.check("HTTPRequest", "GetHeader")
+	if value, ok := r.Headers[key]; ok {
+		return value
+	}
+	for k, value := range r.Headers {
+		if strings.EqualFold(k, key) {
+			return value
+		}
+	}
+	return ""
 }
 
 func (r *HTTPRequest) ContentType() string {
@@ -83,6 +107,8 @@ func filterFlags(content string) string {
 
 func ReleaseRequest(req *HTTPRequest) {
 	if req != nil {
+		req.guard.release("HTTPRequest")
+		trackLeak(&leakStats.requests, -1)
 		req.reset()
 		requestPool.Put(req)
 	}
@@ -93,18 +119,65 @@ type HTTPParser struct {
 	reader      *bufio.Reader
 	lineBuffer  []byte
 	chunkBuffer []byte
+
+	// bodyLimit 在读取请求体之前按请求返回允许的最大字节数，为空或返回 0 时使用 limits.MaxBodyBytes
+	bodyLimit func(req *HTTPRequest) int64
+	// streamBody 返回 true 时不读取请求体，交给处理器流式读取
+	streamBody func(req *HTTPRequest) bool
+	// limits 请求行、请求头和默认请求体的大小限制（已填充默认值）
+	limits Limits
+	// headerBudget 当前请求剩余可读的请求头字节数，headerLimited 为 false 时不限制
+	headerBudget  int
+	headerLimited bool
+}
+
+// DefaultMaxBodySize 默认请求体大小上限
+const DefaultMaxBodySize = 10 * 1024 * 1024
+
+// ErrBodyTooLarge 请求体超过上限
+var ErrBodyTooLarge = errors.New("body too large")
+
+// ErrBadFraming 请求体长度无法确定（Content-Length 与 Transfer-Encoding 冲突、取值非法等），
+// 返回 400 并关闭连接，防止持久连接上的请求走私
+var ErrBadFraming = errors.New("invalid request framing")
+
+func (p *HTTPParser) maxBodySize(req *HTTPRequest) int64 {
+	if p.bodyLimit != nil {
+		if limit := p.bodyLimit(req); limit > 0 {
+			return limit
+		}
+	}
+	switch {
+	case p.limits.MaxBodyBytes > 0:
+		return p.limits.MaxBodyBytes
+	case p.limits.MaxBodyBytes < 0:
+		return math.MaxInt64
+	}
+	return DefaultMaxBodySize
 }
 
 func NewHTTPParser(conn net.Conn) *HTTPParser {
+	return newHTTPParserSize(conn, 0)
+}
+
+// newHTTPParserSize 使用指定读缓冲区大小创建解析器，size <= 0 时使用 bufio 默认值
+func newHTTPParserSize(conn net.Conn, size int) *HTTPParser {
+	reader := bufio.NewReader(conn)
+	if size > 0 {
+		reader = bufio.NewReaderSize(conn, size)
+	}
 	return &HTTPParser{
-		reader:      bufio.NewReader(conn),
+		reader:      reader,
 		lineBuffer:  make([]byte, 0, 4096),
 		chunkBuffer: make([]byte, 0, 8192),
+		limits:      Limits{}.withDefaults(),
 	}
 }
 
 func (p *HTTPParser) ParseRequest() (*HTTPRequest, error) {
 	req := requestPool.Get().(*HTTPRequest)
+	req.guard.acquire()
+	trackLeak(&leakStats.requests, 1)
 
 	if err := p.parseRequestInto(req); err != nil {
 		ReleaseRequest(req) // 使用 ReleaseRequest 确保正确释放
@@ -112,23 +185,29 @@ func (p *HTTPParser) ParseRequest() (*HTTPRequest, error) {
 	}
 
 	req.parsed = true
+	req.reader = p.reader
 	return req, nil
 }
 
 func (p *HTTPParser) parseRequestInto(req *HTTPRequest) error {
+	// 请求行和头部共用 MaxHeaderBytes
+	p.headerBudget = p.limits.MaxHeaderBytes
+	p.headerLimited = p.headerBudget > 0
+	defer func() { p.headerLimited = false }()
+
 	// 解析请求行
 	if err := p.parseRequestLineFast(req); err != nil {
-		return fmt.Errorf("request line error: %v", err)
+		return fmt.Errorf("request line error: %w", err)
 	}
 
 	// 解析头部
 	if err := p.parseHeadersFast(req); err != nil {
-		return fmt.Errorf("headers error: %v", err)
+		return fmt.Errorf("headers error: %w", err)
 	}
 
 	// 解析请求体
 	if err := p.parseBodyFast(req); err != nil {
-		return fmt.Errorf("body error: %v", err)
+		return fmt.Errorf("body error: %w", err)
 	}
 
 	return nil
@@ -137,10 +216,18 @@ func (p *HTTPParser) parseRequestInto(req *HTTPRequest) error {
 func (p *HTTPParser) parseRequestLineFast(req *HTTPRequest) error {
 	line, err := p.readLineFast()
 	if err != nil {
-		return err
+		return p.requestLineError(err)
+	}
+	// 请求行之前的空行忽略（RFC 9112 2.2），持久连接上客户端可能多发送一个 CRLF
+	if len(line) == 0 {
+		if line, err = p.readLineFast(); err != nil {
+			return p.requestLineError(err)
+		}
 	}
 
-	fmt.Printf("DEBUG Request line: %q\n", string(line))
+	if IsDebugging() {
+		debugPrint("Request line: %q", line)
+	}
 
 	// 更健壮的请求行解析
 	parts := bytes.Split(line, []byte{' '})
@@ -155,6 +242,9 @@ func (p *HTTPParser) parseRequestLineFast(req *HTTPRequest) error {
 		return fmt.Errorf("invalid HTTP method: %s", req.Method)
 	}
 
+	if max := p.limits.MaxURILength; max > 0 && len(parts[1]) > max {
+		return fmt.Errorf("%w: %d bytes (limit %d)", ErrURITooLong, len(parts[1]), max)
+	}
 	req.RawURL = string(parts[1])
 
 	// 处理协议
@@ -184,10 +274,20 @@ func (p *HTTPParser) parseRequestLineFast(req *HTTPRequest) error {
 	}
 	req.URL = parsedURL
 
-	fmt.Printf("DEBUG Parsed: %s %s %s\n", req.Method, req.RawURL, req.Proto)
+	if IsDebugging() {
+		debugPrint("Parsed: %s %s %s", req.Method, req.RawURL, req.Proto)
+	}
 	return nil
 }
 
+// requestLineError 请求行本身超过请求头上限时按 URI 过长处理
+func (p *HTTPParser) requestLineError(err error) error {
+	if errors.Is(err, ErrHeaderTooLarge) {
+		return fmt.Errorf("%w: request line exceeds %d bytes", ErrURITooLong, p.limits.MaxHeaderBytes)
+	}
+	return err
+}
+
 func isValidMethod(method string) bool {
 	switch method {
 	case "GET", "POST", "PUT", "DELETE", "HEAD", "OPTIONS", "PATCH", "CONNECT", "TRACE":
@@ -213,12 +313,23 @@ func (p *HTTPParser) readLineFast() ([]byte, error) {
 		}
 
 		p.lineBuffer = append(p.lineBuffer, line...)
+		if p.headerLimited && len(p.lineBuffer) > p.headerBudget {
+			return nil, errHeaderTooLarge("exceeds %d bytes", p.limits.MaxHeaderBytes)
+		}
 
 		if !isPrefix {
 			break
 		}
 	}
 
+	if p.headerLimited {
+		// 行尾 CRLF 也计入请求头大小
+		p.headerBudget -= len(p.lineBuffer) + 2
+		if p.headerBudget < 0 {
+			return nil, errHeaderTooLarge("exceeds %d bytes", p.limits.MaxHeaderBytes)
+		}
+	}
+
 	// 清理 CRLF
 	if len(p.lineBuffer) > 0 && p.lineBuffer[len(p.lineBuffer)-1] == '\r' {
 		p.lineBuffer = p.lineBuffer[:len(p.lineBuffer)-1]
@@ -229,6 +340,7 @@ func (p *HTTPParser) readLineFast() ([]byte, error) {
 
 func (p *HTTPParser) parseHeadersFast(req *HTTPRequest) error {
 	headerCount := 0
+	var framing bodyFraming
 
 	for {
 		line, err := p.readLineFast()
@@ -246,13 +358,23 @@ func (p *HTTPParser) parseHeadersFast(req *HTTPRequest) error {
 
 		idx := bytes.IndexByte(line, ':')
 		if idx <= 0 { // 确保 key 不为空
-			fmt.Printf("WARNING: Skipping malformed header: %q\n", string(line))
+			if IsDebugging() {
+				debugPrint("Skipping malformed header: %q", line)
+			}
 			continue
 		}
 
+		if max := p.limits.MaxHeaderCount; max > 0 && headerCount >= max {
+			return errHeaderTooLarge("more than %d headers", max)
+		}
+
 		key := strings.TrimSpace(string(line[:idx]))
 		value := strings.TrimSpace(string(line[idx+1:]))
 
+		if err := framing.add(key, value); err != nil {
+			return err
+		}
+
 		// 存储头部
 		req.Headers[key] = value
 
@@ -264,17 +386,64 @@ func (p *HTTPParser) parseHeadersFast(req *HTTPRequest) error {
 		headerCount++
 	}
 
-	fmt.Printf("DEBUG Parsed %d headers\n", headerCount)
+	if IsDebugging() {
+		debugPrint("Parsed %d headers", headerCount)
+	}
+	return framing.apply(req)
+}
+
+// bodyFraming 收集决定请求体长度的请求头，大小写不同的重复头也计入
+type bodyFraming struct {
+	contentLength    string
+	hasLength        bool
+	transferEncoding string
+	encodings        int
+}
+
+func (f *bodyFraming) add(key, value string) error {
+	switch {
+	case strings.EqualFold(key, "Content-Length"):
+		if f.hasLength && value != f.contentLength {
+			return fmt.Errorf("%w: conflicting Content-Length %q and %q", ErrBadFraming, f.contentLength, value)
+		}
+		f.contentLength, f.hasLength = value, true
+	case strings.EqualFold(key, "Transfer-Encoding"):
+		f.transferEncoding = value
+		f.encodings++
+	}
+	return nil
+}
+
+// apply 校验请求体长度并缓存到 req：不允许同时出现 Content-Length 和 Transfer-Encoding，
+// Content-Length 必须是非负十进制整数，Transfer-Encoding 只支持单独的 chunked
+func (f *bodyFraming) apply(req *HTTPRequest) error {
+	if f.hasLength && f.encodings > 0 {
+		return fmt.Errorf("%w: both Content-Length and Transfer-Encoding", ErrBadFraming)
+	}
+	if f.encodings > 1 || (f.encodings == 1 && !strings.EqualFold(f.transferEncoding, "chunked")) {
+		return fmt.Errorf("%w: unsupported Transfer-Encoding %q", ErrBadFraming, f.transferEncoding)
+	}
+	if f.hasLength {
+		n, err := strconv.ParseUint(f.contentLength, 10, 62)
+		if err != nil {
+			return fmt.Errorf("%w: invalid Content-Length %q", ErrBadFraming, f.contentLength)
+		}
+		req.contentLength = int(n)
+	}
 	return nil
 }
 
 func (p *HTTPParser) parseBodyFast(req *HTTPRequest) error {
+	if p.streamBody != nil && p.streamBody(req) {
+		return p.prepareBodyStream(req)
+	}
+
 	contentLength := req.ContentLength()
 
 	if contentLength > 0 {
-		// 检查大小限制
-		if contentLength > 10*1024*1024 { // 10MB
-			return fmt.Errorf("body too large: %d bytes", contentLength)
+		// 检查大小限制，在分配缓冲区之前拒绝
+		if limit := p.maxBodySize(req); int64(contentLength) > limit {
+			return fmt.Errorf("%w: %d bytes (limit %d)", ErrBodyTooLarge, contentLength, limit)
 		}
 
 		// 分配或重用 body
@@ -289,38 +458,105 @@ func (p *HTTPParser) parseBodyFast(req *HTTPRequest) error {
 			return fmt.Errorf("failed to read body: %v", err)
 		}
 
-		fmt.Printf("DEBUG Read body: %d bytes\n", contentLength)
-	} else if te := req.GetHeader("Transfer-Encoding"); te != "" && strings.Contains(strings.ToLower(te), "chunked") {
-		fmt.Println("DEBUG Processing chunked encoding")
+		if IsDebugging() {
+			debugPrint("Read body: %d bytes", contentLength)
+		}
+	} else if strings.EqualFold(req.GetHeader("Transfer-Encoding"), "chunked") {
 		return p.parseChunkedBodyFast(req)
-	} else {
-		fmt.Println("DEBUG No body to read")
 	}
 
 	return nil
 }
 
+// prepareBodyStream 为流式路由准备请求体读取器，大小上限在读取过程中检查
+func (p *HTTPParser) prepareBodyStream(req *HTTPRequest) error {
+	limit := p.maxBodySize(req)
+
+	if contentLength := req.ContentLength(); contentLength > 0 {
+		if int64(contentLength) > limit {
+			return fmt.Errorf("%w: %d bytes (limit %d)", ErrBodyTooLarge, contentLength, limit)
+		}
+		req.bodyReader = io.LimitReader(p.reader, int64(contentLength))
+	} else if strings.EqualFold(req.GetHeader("Transfer-Encoding"), "chunked") {
+		req.bodyReader = &maxBytesReader{r: &chunkedBodyReader{r: httputil.NewChunkedReader(p.reader), p: p}, n: limit}
+	}
+	return nil
+}
+
+// chunkedBodyReader 分块请求体读完后继续读取尾部头部和结束空行，
+// 持久连接上的下一个请求从正确的位置开始
+type chunkedBodyReader struct {
+	r    io.Reader
+	p    *HTTPParser
+	done bool
+}
+
+func (c *chunkedBodyReader) Read(b []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+	n, err := c.r.Read(b)
+	if err == io.EOF {
+		for {
+			line, lerr := c.p.readLineFast()
+			if lerr != nil {
+				return n, lerr
+			}
+			if len(line) == 0 {
+				break
+			}
+		}
+		c.done = true
+	}
+	return n, err
+}
+
+// maxBytesReader 超过上限时返回 ErrBodyTooLarge
+type maxBytesReader struct {
+	r io.Reader
+	n int64
+}
+
+func (m *maxBytesReader) Read(b []byte) (int, error) {
+	if m.n < 0 {
+		return 0, ErrBodyTooLarge
+	}
+	// 多读一个字节用于判断是否超限
+	if int64(len(b)) > m.n+1 {
+		b = b[:m.n+1]
+	}
+	n, err := m.r.Read(b)
+	if int64(n) > m.n {
+		n = int(m.n)
+		m.n = -1
+		return n, ErrBodyTooLarge
+	}
+	m.n -= int64(n)
+	return n, err
+}
+
 func (p *HTTPParser) parseChunkedBodyFast(req *HTTPRequest) error {
 	p.chunkBuffer = p.chunkBuffer[:0]
 	totalRead := 0
+	limit := p.maxBodySize(req)
 
 	for {
 		// 读取块大小行
-		line, err := p.readLineFast()
+		line, err := p.readChunkLine()
 		if err != nil {
 			return err
 		}
 
 		// 解析块大小
-		chunkSize, err := strconv.ParseInt(string(line), 16, 64)
+		chunkSize, err := parseChunkSize(line)
 		if err != nil {
-			return fmt.Errorf("invalid chunk size: %q", string(line))
+			return err
 		}
 
 		if chunkSize == 0 {
 			// 读取尾部头部
 			for {
-				line, err := p.readLineFast()
+				line, err := p.readChunkLine()
 				if err != nil {
 					return err
 				}
@@ -331,10 +567,10 @@ func (p *HTTPParser) parseChunkedBodyFast(req *HTTPRequest) error {
 			break
 		}
 
-		totalRead += int(chunkSize)
-		if totalRead > 10*1024*1024 {
-			return fmt.Errorf("chunked body too large: %d bytes", totalRead)
+		if chunkSize > limit-int64(totalRead) {
+			return fmt.Errorf("%w: chunked body exceeds %d bytes", ErrBodyTooLarge, limit)
 		}
+		totalRead += int(chunkSize)
 
 		// 确保容量
 		if cap(p.chunkBuffer) < len(p.chunkBuffer)+int(chunkSize) {
@@ -352,15 +588,110 @@ func (p *HTTPParser) parseChunkedBodyFast(req *HTTPRequest) error {
 			return err
 		}
 
-		// 读取 CRLF
-		if _, err := p.reader.Discard(2); err != nil {
+		// 块数据之后必须紧跟 CRLF
+		crlf, err := p.reader.Peek(2)
+		if err != nil {
 			return err
 		}
+		if crlf[0] != '\r' || crlf[1] != '\n' {
+			return fmt.Errorf("%w: chunk data not followed by CRLF", ErrBadFraming)
+		}
+		p.reader.Discard(2)
 	}
 
 	req.Body = append(req.Body[:0], p.chunkBuffer...)
 	req.contentLength = len(req.Body)
-	fmt.Printf("DEBUG Chunked body: %d bytes\n", len(req.Body))
+	if IsDebugging() {
+		debugPrint("Chunked body: %d bytes", len(req.Body))
+	}
 
 	return nil
 }
+
+// readChunkLine 读取块大小行或尾部头部行，必须以 CRLF 结束，返回的切片在下次读取前有效
+func (p *HTTPParser) readChunkLine() ([]byte, error) {
+	line, err := p.reader.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		return nil, fmt.Errorf("%w: chunk line too long", ErrBadFraming)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("%w: chunk line not terminated by CRLF", ErrBadFraming)
+	}
+	return line[:len(line)-2], nil
+}
+
+// parseChunkSize 解析块大小行：1*HEXDIG [ chunk-ext ]。ParseUint 不接受符号，
+// 带符号、空白或格式错误的块扩展都按请求边界不明确处理
+func parseChunkSize(line []byte) (int64, error) {
+	size := string(line)
+	if i := strings.IndexByte(size, ';'); i >= 0 {
+		if !validChunkExtensions(size[i+1:]) {
+			return 0, fmt.Errorf("%w: invalid chunk extension %q", ErrBadFraming, line)
+		}
+		size = strings.TrimRight(size[:i], " \t")
+	}
+	n, err := strconv.ParseUint(size, 16, 62)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid chunk size %q", ErrBadFraming, line)
+	}
+	return int64(n), nil
+}
+
+// validChunkExtensions 校验 ";" 之后的块扩展：name [ = token / quoted-string ]，以 ";" 分隔
+func validChunkExtensions(ext string) bool {
+	for {
+		ext = strings.TrimLeft(ext, " \t")
+		n := tokenLen(ext)
+		if n == 0 {
+			return false
+		}
+		ext = strings.TrimLeft(ext[n:], " \t")
+		if strings.HasPrefix(ext, "=") {
+			ext = strings.TrimLeft(ext[1:], " \t")
+			if strings.HasPrefix(ext, `"`) {
+				n = quotedStringLen(ext)
+			} else {
+				n = tokenLen(ext)
+			}
+			if n <= 0 {
+				return false
+			}
+			ext = strings.TrimLeft(ext[n:], " \t")
+		}
+		if ext == "" {
+			return true
+		}
+		if ext[0] != ';' {
+			return false
+		}
+		ext = ext[1:]
+	}
+}
+
+// tokenLen 返回 s 开头 token 的长度
+func tokenLen(s string) int {
+	for i, r := range s {
+		if !isTokenChar(r) {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// quotedStringLen 返回 s 开头带引号字符串（含引号）的长度，格式错误时返回 -1
+func quotedStringLen(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			return i + 1
+		case c == '\\':
+			i++
+		case c < ' ' && c != '\t', c == 0x7f:
+			return -1
+		}
+	}
+	return -1
+}
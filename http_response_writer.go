@@ -3,10 +3,13 @@ package meego
 import (
 	"fmt"
 	jsoniter "github.com/json-iterator/go"
+	"golang.org/x/text/encoding"
 	"net"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 // ResponseWriter 响应写入器
@@ -14,11 +17,49 @@ type ResponseWriter struct {
 	conn   net.Conn
 	header map[string]string
 	status int
+	size   int          // 已写出的响应体字节数
 	json   jsoniter.API //序列化/反序列化
 
+	// 请求方法和协议，用于决定响应体的传输方式
+	method string
+	proto  string
+
+	// 流式响应状态：头部已发送后，后续写入按块发送
+	streaming bool
+	chunked   bool
+	hijacked  bool // 连接已被接管，不能再写出 HTTP 响应
+	written   bool // 已写出状态行和头部
+	timedOut  bool // Timeout 中间件已经响应，处理器之后的写入被丢弃
+	// firstWrite 调试模式下第一次写出的调用栈，见 DoubleWriteError
+	firstWrite string
+	keepAlive  bool // 响应后保持连接，由服务器按请求协商
+
+	// Accept-Charset 协商的响应字符集，为空表示 UTF-8
+	charset string
+	encoder encoding.Encoding
+	// Set-Cookie 可以出现多次，不放在单值的 header 中
+	cookies []string
+	// 写出前依次应用的响应体变换（如压缩空白），流式响应不应用
+	transforms []func(w *ResponseWriter, body []byte) []byte
+	// Compression 中间件协商的压缩，在变换和字符集编码之后应用
+	compression *responseCompression
+	// JSON 响应计算 ETag，见 SetJSONETag；ifNoneMatch 为请求的 If-None-Match
+	jsonETag    bool
+	ifNoneMatch string
+	// 路由的响应体上限，见 Route.MaxResponseSize
+	maxResponse responseLimit
+	// 稀疏字段集请求的字段，nil 表示不过滤，见 Route.Fields
+	fields []string
+
+	// 阶段耗时
+	serializeTime time.Duration
+	writeTime     time.Duration
+
 	// 缓冲优化
 	buffer strings.Builder
 	mu     sync.Mutex
+
+	guard poolGuard
 }
 
 // ResponseWriter 方法
@@ -34,7 +75,28 @@ func NewResponseWriter(conn net.Conn) *ResponseWriter {
 // 快速初始化
 func (w *ResponseWriter) fastInit(conn net.Conn) {
 	w.conn = conn
+	w.method = ""
+	w.proto = ""
+	w.streaming = false
+	w.chunked = false
+	w.hijacked = false
+	w.written = false
+	w.timedOut = false
+	w.firstWrite = ""
+	w.keepAlive = false
+	w.charset = ""
+	w.encoder = nil
+	w.transforms = w.transforms[:0]
+	w.compression = nil
+	w.jsonETag = false
+	w.ifNoneMatch = ""
+	w.maxResponse = responseLimit{}
+	w.fields = nil
+	w.cookies = w.cookies[:0]
 	w.status = 200
+	w.size = 0
+	w.serializeTime = 0
+	w.writeTime = 0
 	w.buffer.Reset()
 
 	// 清空 header 但保留容量
@@ -46,7 +108,28 @@ func (w *ResponseWriter) fastInit(conn net.Conn) {
 // 重置方法用于对象池
 func (w *ResponseWriter) reset() {
 	w.conn = nil
+	w.method = ""
+	w.proto = ""
+	w.streaming = false
+	w.chunked = false
+	w.hijacked = false
+	w.written = false
+	w.timedOut = false
+	w.firstWrite = ""
+	w.keepAlive = false
+	w.charset = ""
+	w.encoder = nil
+	w.transforms = w.transforms[:0]
+	w.compression = nil
+	w.jsonETag = false
+	w.ifNoneMatch = ""
+	w.maxResponse = responseLimit{}
+	w.fields = nil
+	w.cookies = w.cookies[:0]
 	w.status = 200
+	w.size = 0
+	w.serializeTime = 0
+	w.writeTime = 0
 	w.buffer.Reset()
 
 	if w.header != nil {
@@ -56,26 +139,82 @@ func (w *ResponseWriter) reset() {
 	}
 }
 
+// setRequest 记录请求方法和协议
+func (w *ResponseWriter) setRequest(req *HTTPRequest) {
+	w.method = req.Method
+	w.proto = req.Proto
+	w.ifNoneMatch = req.GetHeader("If-None-Match")
+}
+
 func (w *ResponseWriter) Header() map[string]string {
 	return w.header
 }
 
 func (w *ResponseWriter) SetHeader(key, value string) {
+	w.guard.check("ResponseWriter", "SetHeader")
 	w.header[key] = value
 }
 
+// SetCookie 添加 Set-Cookie 头，同名（且 Path、Domain 相同）的 Cookie 会被替换
+func (w *ResponseWriter) SetCookie(cookie *http.Cookie) {
+	w.guard.check("ResponseWriter", "SetCookie")
+	line := cookie.String()
+	if line == "" {
+		return
+	}
+	for i, existing := range w.cookies {
+		if old, err := http.ParseSetCookie(existing); err == nil &&
+			old.Name == cookie.Name && old.Path == cookie.Path && old.Domain == cookie.Domain {
+			w.cookies[i] = line
+			return
+		}
+	}
+	w.cookies = append(w.cookies, line)
+}
+
+// Cookies 返回已设置的 Set-Cookie 头
+func (w *ResponseWriter) Cookies() []string {
+	return w.cookies
+}
+
+// writeHeaderLines 写出头部和 Set-Cookie 行
+func (w *ResponseWriter) writeHeaderLines() {
+	for key, value := range w.header {
+		w.buffer.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
+	}
+	for _, cookie := range w.cookies {
+		w.buffer.WriteString("Set-Cookie: " + cookie + "\r\n")
+	}
+}
+
+// StatusCode 返回响应状态码
+func (w *ResponseWriter) StatusCode() int {
+	return w.status
+}
+
+// Size 返回已写出的响应体字节数
+func (w *ResponseWriter) Size() int {
+	return w.size
+}
+
 func (w *ResponseWriter) Status(code int) *ResponseWriter {
 	w.status = code
 	return w
 }
 
 func (w *ResponseWriter) JSON(data interface{}) error {
+	start := time.Now()
 	jsonData, err := w.json.Marshal(data)
+	w.serializeTime += time.Since(start)
 	if err != nil {
 		return err
 	}
 
+	jsonData = w.filterFields(jsonData)
 	w.SetHeader("Content-Type", "application/json; charset=utf-8")
+	if w.applyJSONETag(jsonData) {
+		return w.writeResponse(nil)
+	}
 	return w.writeResponse(jsonData)
 }
 
@@ -89,36 +228,108 @@ func (w *ResponseWriter) HTML(html string) error {
 	return w.writeResponse([]byte(html))
 }
 
+// Data 以指定内容类型写出原始字节，contentType 为空时嗅探内容类型
+func (w *ResponseWriter) Data(contentType string, data []byte) error {
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return w.writeBytes(contentType, data)
+}
+
+// writeBytes 以指定内容类型写出原始字节
+func (w *ResponseWriter) writeBytes(contentType string, body []byte) error {
+	w.SetHeader("Content-Type", contentType)
+	return w.writeResponse(body)
+}
+
 func (w *ResponseWriter) writeResponse(body []byte) error {
+	w.guard.check("ResponseWriter", "write")
+	if w.hijacked {
+		return ErrHijacked
+	}
+	if w.streaming {
+		// 流式响应已开始，追加为一个数据块
+		return w.WriteChunk(body)
+	}
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	if w.written {
+		// 第二个响应会被客户端当作下一个请求的响应，任何模式下都丢弃，
+		// 并在本次请求后关闭连接；调试模式下 recordWrite 报告两次写出的位置
+		w.keepAlive = false
+		if !w.timedOut {
+			w.recordWrite()
+		}
+		return errResponseFinished
+	}
+	w.recordWrite()
+
+	start := time.Now()
+	defer func() {
+		w.writeTime += time.Since(start)
+	}()
 
 	// 重用 buffer
 	w.buffer.Reset()
 
+	for _, transform := range w.transforms {
+		body = transform(w, body)
+	}
+	if w.encoder != nil {
+		body = w.encodeCharset(body)
+	}
+	body = w.limitResponse(body)
+	if w.compression != nil {
+		body = w.compression.compressBody(w, body)
+	}
+
 	// 构建状态行
 	statusText := getStatusText(w.status)
 	w.buffer.WriteString(fmt.Sprintf("HTTP/1.1 %d %s\r\n", w.status, statusText))
 
-	// 设置默认头部
-	if w.header["Content-Length"] == "" {
-		w.header["Content-Length"] = strconv.Itoa(len(body))
+	// 选择响应体的传输方式（RFC 7230 3.3）
+	payload := len(body)
+	switch {
+	case !bodyAllowedForStatus(w.status):
+		// 1xx/204/304 不能有响应体，204 和 1xx 也不能有 Content-Length
+		body = nil
+		payload = 0
+		delete(w.header, "Transfer-Encoding")
+		if w.status != StatusNotModified {
+			delete(w.header, "Content-Length")
+		}
+	case w.header["Transfer-Encoding"] == "chunked" && w.proto != "HTTP/1.0":
+		// 处理器声明了分块传输且客户端支持
+		delete(w.header, "Content-Length")
+		body = appendChunk(nil, body)
+	default:
+		// HTTP/1.0 客户端不支持分块，退回 Content-Length
+		delete(w.header, "Transfer-Encoding")
+		if w.header["Content-Length"] == "" {
+			w.header["Content-Length"] = strconv.Itoa(len(body))
+		}
+	}
+	if w.method == "HEAD" {
+		// HEAD 保留头部（包括 Content-Length），不发送响应体
+		body = nil
+		payload = 0
 	}
-	// 设置 Connection: close
-	w.header["Connection"] = "close"
+	w.setConnectionHeader()
+	w.setDateHeader()
+	w.written = true
 
 	// 写入头部
-	for key, value := range w.header {
-		w.buffer.WriteString(fmt.Sprintf("%s: %s\r\n", key, value))
-	}
+	w.writeHeaderLines()
 	w.buffer.WriteString("\r\n")
 
+	w.size = payload
+
 	// 批量写入
 	headers := w.buffer.String()
 	if len(body) > 0 {
 		// 使用 net.Buffers 减少系统调用
 		buffers := net.Buffers{[]byte(headers), body}
-		_, err := buffers.WriteTo(w.conn)
+		_, err := writeBuffers(w.conn, buffers)
 		return err
 	} else {
 		_, err := w.conn.Write([]byte(headers))
@@ -126,16 +337,81 @@ func (w *ResponseWriter) writeResponse(body []byte) error {
 	}
 }
 
+// writeTimeout 写出 Timeout 中间件的 503 响应，之后处理器的写入被丢弃。
+// 处理器可能仍在另一个协程中修改头部，因此不读取 w.header；处理器已经开始写出时返回 false
+func (w *ResponseWriter) writeTimeout(body []byte) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timedOut = true
+	if w.written || w.hijacked {
+		return false
+	}
+	w.written = true
+	if w.method == "HEAD" {
+		body = nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP/1.1 %d %s\r\n", StatusServiceUnavailable, getStatusText(StatusServiceUnavailable))
+	b.WriteString("Content-Type: application/json; charset=utf-8\r\n")
+	fmt.Fprintf(&b, "Content-Length: %d\r\n", len(body))
+	b.WriteString("Connection: close\r\n")
+	b.WriteString("Date: " + httpDate() + "\r\n\r\n")
+	writeBuffers(w.conn, net.Buffers{[]byte(b.String()), body})
+	return true
+}
+
+// setConnectionHeader 按连接是否保持写出 Connection 头。处理器设置 Connection: close 时关闭连接，
+// 设置其它值（如 Upgrade）时保持原样
+func (w *ResponseWriter) setConnectionHeader() {
+	switch value := strings.ToLower(w.header["Connection"]); {
+	case value == "close":
+		w.keepAlive = false
+	case value != "" && value != "keep-alive":
+		return
+	}
+	switch {
+	case !w.keepAlive:
+		w.header["Connection"] = "close"
+	case w.proto == "HTTP/1.0":
+		w.header["Connection"] = "keep-alive"
+	default:
+		delete(w.header, "Connection")
+	}
+}
+
+// setDateHeader 处理器没有设置 Date 时按当前时钟写出
+func (w *ResponseWriter) setDateHeader() {
+	if _, ok := w.header["Date"]; !ok {
+		w.header["Date"] = httpDate()
+	}
+}
+
+// bodyAllowedForStatus 状态码是否允许响应体
+func bodyAllowedForStatus(code int) bool {
+	switch {
+	case code >= 100 && code < 200:
+		return false
+	case code == StatusNoContent, code == StatusNotModified:
+		return false
+	}
+	return true
+}
+
+// appendChunk 把 data 编码为一个数据块加结束块
+func appendChunk(dst, data []byte) []byte {
+	if len(data) > 0 {
+		dst = strconv.AppendInt(dst, int64(len(data)), 16)
+		dst = append(dst, "\r\n"...)
+		dst = append(dst, data...)
+		dst = append(dst, "\r\n"...)
+	}
+	return append(dst, "0\r\n\r\n"...)
+}
+
 // 工具函数
 func getStatusText(code int) string {
-	statusTexts := map[int]string{
-		200: "OK",
-		201: "Created",
-		400: "Bad Request",
-		404: "Not Found",
-		500: "Internal Server Error",
-	}
-	if text, ok := statusTexts[code]; ok {
+	if text := StatusText(code); text != "" {
 		return text
 	}
 	return "Unknown Status"
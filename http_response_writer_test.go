@@ -0,0 +1,49 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResponseFraming(t *testing.T) {
+	s := New()
+	s.GET("/empty", func(c *Context) { c.String(StatusNoContent, "ignored") })
+	s.GET("/chunked", func(c *Context) {
+		c.Writer.SetHeader("Transfer-Encoding", "chunked")
+		c.String(StatusOK, "hello")
+	})
+	s.router.AddRoute("HEAD", "/head", func(c *Context) { c.String(StatusOK, "hello") })
+
+	resp := doRaw(s, "GET /empty HTTP/1.1\r\n\r\n")
+	if strings.Contains(resp, "Content-Length") || strings.Contains(resp, "ignored") {
+		t.Fatalf("204 must not carry a body: %q", resp)
+	}
+	resp = doRaw(s, "GET /chunked HTTP/1.1\r\n\r\n")
+	if !strings.HasSuffix(resp, "\r\n\r\n5\r\nhello\r\n0\r\n\r\n") {
+		t.Fatalf("expected chunked body: %q", resp)
+	}
+	resp = doRaw(s, "GET /chunked HTTP/1.0\r\n\r\n")
+	if !strings.Contains(resp, "Content-Length: 5") || strings.Contains(resp, "Transfer-Encoding") {
+		t.Fatalf("HTTP/1.0 must not get chunked encoding: %q", resp)
+	}
+	resp = doRaw(s, "HEAD /head HTTP/1.1\r\n\r\n")
+	if !strings.Contains(resp, "Content-Length: 5") || !strings.HasSuffix(resp, "\r\n\r\n") {
+		t.Fatalf("HEAD must keep Content-Length without body: %q", resp)
+	}
+}
+
+func TestDataSniffing(t *testing.T) {
+	s := New()
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	s.GET("/sniff", func(c *Context) { c.Data(StatusOK, "", png) })
+	s.GET("/blob", func(c *Context) { c.Blob(StatusOK, "application/octet-stream", []byte{0, 1, 2}) })
+
+	if resp := doRaw(s, "GET /sniff HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "Content-Type: image/png\r\n") ||
+		!strings.HasSuffix(resp, string(png)) {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+	if resp := doRaw(s, "GET /blob HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "Content-Length: 3\r\n") ||
+		!strings.HasSuffix(resp, "\r\n\r\n\x00\x01\x02") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+}
@@ -2,8 +2,10 @@
 package meego
 
 import (
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // Route 路由信息
@@ -13,6 +15,23 @@ type Route struct {
 	handler    HandlerFunc
 	segments   []string // 路径分段
 	paramNames []string // 参数名
+
+	// 灰度版本
+	primary  HandlerFunc // 主版本处理器
+	variants []routeVariant
+
+	chain []HandlerFunc // 路由组和路由中间件，位于全局中间件之后、处理器之前
+
+	maxBody     int64         // 请求体上限，0 表示使用服务器配置
+	streamBody  bool          // 请求体不预先读取，由处理器流式读取
+	maxResponse responseLimit // 响应体上限，见 MaxResponseSize
+	fields      []string      // 稀疏字段集允许的字段，nil 表示未启用，见 Fields
+
+	metadata   map[string]string // 文档元数据，见 Meta
+	examples   []RouteExample    // 请求/响应示例，见 Example
+	middleware []string          // 路由组和路由中间件名称，用于导出路由表
+
+	uncached bool // 路径基数过高，不再进入路由缓存，由 Router.cacheMu 保护
 }
 
 // Router 实现
@@ -22,26 +41,29 @@ type Router struct {
 
 	// 缓存优化 - 使用独立的锁
 	cacheMu    sync.RWMutex
-	routeCache map[string]struct {
-		handler HandlerFunc
-		params  map[string]string
-	}
-	cacheSize int
+	routeCache map[string]routeCacheEntry
+	cacheSize  int
+	cacheHits  atomic.Uint64
+	cacheMiss  atomic.Uint64
+	cacheWipes uint64 // 缓存写满时整体清空的次数，由 cacheMu 保护
+}
+
+// routeCacheEntry 路由缓存条目
+type routeCacheEntry struct {
+	route  *Route
+	params map[string]string
 }
 
 func NewRouter() *Router {
 	return &Router{
-		routes: make(map[string][]*Route),
-		routeCache: make(map[string]struct {
-			handler HandlerFunc
-			params  map[string]string
-		}, 1024),
-		cacheSize: 1024,
+		routes:     make(map[string][]*Route),
+		routeCache: make(map[string]routeCacheEntry, 1024),
+		cacheSize:  1024,
 	}
 }
 
 // AddRoute 添加路由，支持路径参数
-func (r *Router) AddRoute(method, path string, handler HandlerFunc) {
+func (r *Router) AddRoute(method, path string, handler HandlerFunc) *Route {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -49,6 +71,7 @@ func (r *Router) AddRoute(method, path string, handler HandlerFunc) {
 		method:  method,
 		path:    path,
 		handler: handler,
+		primary: handler,
 	}
 
 	// 解析路径参数
@@ -58,14 +81,24 @@ func (r *Router) AddRoute(method, path string, handler HandlerFunc) {
 
 	// 清空缓存 - 使用独立的锁
 	r.clearCache()
+	return route
 }
 
 // FindRoute 查找路由并解析参数 - 优化版本
 func (r *Router) FindRoute(method, path string) (HandlerFunc, map[string]string) {
+	route, params := r.findRoute(method, path)
+	if route == nil {
+		return nil, nil
+	}
+	return route.handler, params
+}
+
+// findRoute 查找匹配的路由
+func (r *Router) findRoute(method, path string) (*Route, map[string]string) {
 	// 首先尝试缓存
 	cacheKey := method + ":" + path
 	if result, found := r.getFromCache(cacheKey); found {
-		return result.handler, result.params
+		return result.route, result.params
 	}
 
 	r.mu.RLock()
@@ -73,50 +106,176 @@ func (r *Router) FindRoute(method, path string) (HandlerFunc, map[string]string)
 	r.mu.RUnlock()
 
 	if !exists {
+		if method == "HEAD" {
+			return r.findRoute("GET", path)
+		}
 		return nil, nil
 	}
 
 	pathSegments := splitPathFast(path)
 
+	// 多个路由匹配时按优先级选择：静态段 > 参数段 > 通配段，相同时先注册的优先
+	var best *Route
+	var bestParams map[string]string
 	for _, route := range routes {
 		if params := route.matchFast(pathSegments); params != nil {
-			// 缓存结果
-			r.putToCache(cacheKey, route.handler, params)
-			return route.handler, params
+			if best == nil || route.morePreciseThan(best) {
+				best, bestParams = route, params
+			}
 		}
 	}
+	if best != nil {
+		// 缓存结果
+		r.putToCache(cacheKey, best, bestParams)
+	} else if method == "HEAD" {
+		// 没有显式的 HEAD 路由时使用 GET 路由，响应写出时省略响应体
+		return r.findRoute("GET", path)
+	}
+	return best, bestParams
+}
+
+// 路径段类型，数值越小优先级越高
+const (
+	segmentStatic = iota
+	segmentParam
+	segmentWildcard
+)
 
-	return nil, nil
+func segmentKind(seg string) int {
+	switch seg {
+	case ":":
+		return segmentParam
+	case "*":
+		return segmentWildcard
+	}
+	return segmentStatic
+}
+
+// morePreciseThan 从左到右比较路径段，第一个不同类型的段决定优先级
+func (r *Route) morePreciseThan(other *Route) bool {
+	for i := 0; i < len(r.segments) && i < len(other.segments); i++ {
+		a, b := segmentKind(r.segments[i]), segmentKind(other.segments[i])
+		if a != b {
+			return a < b
+		}
+	}
+	// 前缀相同时，更长的路由更具体（通配段可以匹配零个段）
+	return len(r.segments) > len(other.segments)
 }
 
 // 缓存操作 - 使用独立的锁
-func (r *Router) getFromCache(key string) (struct {
-	handler HandlerFunc
-	params  map[string]string
-}, bool) {
+func (r *Router) getFromCache(key string) (routeCacheEntry, bool) {
 	r.cacheMu.RLock()
-	defer r.cacheMu.RUnlock()
 	result, exists := r.routeCache[key]
+	r.cacheMu.RUnlock()
+	if exists {
+		r.cacheHits.Add(1)
+	} else {
+		r.cacheMiss.Add(1)
+	}
 	return result, exists
 }
 
-func (r *Router) putToCache(key string, handler HandlerFunc, params map[string]string) {
+func (r *Router) putToCache(key string, route *Route, params map[string]string) {
 	r.cacheMu.Lock()
 	defer r.cacheMu.Unlock()
 
-	// 简单的缓存淘汰策略
+	if route.uncached {
+		return
+	}
 	if len(r.routeCache) >= r.cacheSize {
-		// 清空缓存 - 这里不需要获取锁，因为已经在写锁保护中
-		r.routeCache = make(map[string]struct {
-			handler HandlerFunc
-			params  map[string]string
-		}, r.cacheSize)
+		r.evictLocked()
+		if route.uncached {
+			return
+		}
+	}
+
+	r.routeCache[key] = routeCacheEntry{route: route, params: params}
+}
+
+// evictLocked 缓存写满时调用。占用超过四分之一容量的参数路由（路径中带有 ID、令牌等）
+// 被判定为高基数路由，此后不再缓存，只移除它们的条目；没有这样的路由时整体清空
+func (r *Router) evictLocked() {
+	counts := make(map[*Route]int)
+	for _, entry := range r.routeCache {
+		counts[entry.route]++
+	}
+	marked := false
+	for route, n := range counts {
+		if n > r.cacheSize/4 && len(route.paramNames) > 0 {
+			route.uncached = true
+			marked = true
+		}
+	}
+	if marked {
+		for key, entry := range r.routeCache {
+			if entry.route.uncached {
+				delete(r.routeCache, key)
+			}
+		}
+		if len(r.routeCache) < r.cacheSize {
+			return
+		}
+	}
+	r.routeCache = make(map[string]routeCacheEntry, r.cacheSize)
+	r.cacheWipes++
+}
+
+// RouteCacheStats 路由缓存统计
+type RouteCacheStats struct {
+	Size     int    `json:"size"`
+	Capacity int    `json:"capacity"`
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+	Wipes    uint64 `json:"wipes"` // 写满时整体清空的次数
+	// Uncached 判定为高基数、不再缓存的路由，如 "GET /users/:id"
+	Uncached []string `json:"uncached,omitempty"`
+}
+
+// RouteCacheStats 返回路由缓存统计
+func (s *HTTPServer) RouteCacheStats() RouteCacheStats {
+	return s.router.cacheStats()
+}
+
+func (r *Router) cacheStats() RouteCacheStats {
+	r.mu.RLock()
+	var routes []*Route
+	for _, list := range r.routes {
+		routes = append(routes, list...)
+	}
+	r.mu.RUnlock()
+
+	r.cacheMu.RLock()
+	defer r.cacheMu.RUnlock()
+	stats := RouteCacheStats{
+		Size:     len(r.routeCache),
+		Capacity: r.cacheSize,
+		Hits:     r.cacheHits.Load(),
+		Misses:   r.cacheMiss.Load(),
+		Wipes:    r.cacheWipes,
+	}
+	for _, route := range routes {
+		if route.uncached {
+			stats.Uncached = append(stats.Uncached, route.method+" "+route.path)
+		}
 	}
+	sort.Strings(stats.Uncached)
+	return stats
+}
 
-	r.routeCache[key] = struct {
-		handler HandlerFunc
-		params  map[string]string
-	}{handler: handler, params: params}
+// setCacheSize 调整路由缓存容量并清空缓存
+func (r *Router) setCacheSize(size int) {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	r.cacheSize = size
+	r.routeCache = make(map[string]routeCacheEntry, size)
+}
+
+// dropCache 丢弃路由缓存并释放其内存，用于内存压力下收缩
+func (r *Router) dropCache() {
+	r.cacheMu.Lock()
+	defer r.cacheMu.Unlock()
+	r.routeCache = make(map[string]routeCacheEntry)
 }
 
 func (r *Router) clearCache() {
@@ -145,19 +304,35 @@ func (r *Route) parsePath() {
 	r.paramNames = make([]string, 0, 2)
 
 	for i, segment := range segments {
+		if segment == ":" || segment == "*" {
+			// 没有参数名的段会被当作参数匹配，却没有名字可以保存参数值
+			panic("meego: " + segment + " segment must be followed by a parameter name in path " + r.path)
+		}
 		if len(segment) > 1 && segment[0] == ':' {
 			paramName := segment[1:]
 			r.segments[i] = ":"
 			r.paramNames = append(r.paramNames, paramName)
+		} else if len(segment) > 1 && segment[0] == '*' {
+			if i != len(segments)-1 {
+				panic("meego: catch-all segment must be the last segment in path " + r.path)
+			}
+			r.segments[i] = "*"
+			r.paramNames = append(r.paramNames, segment[1:])
 		} else {
 			r.segments[i] = segment
 		}
 	}
 }
 
-// matchFast 快速匹配路径并提取参数
+// matchFast 快速匹配路径并提取参数。通配段 *name 匹配剩余的全部路径（可以为空），
+// 参数值以 "/" 开头，如 /static/*filepath 匹配 /static/css/a.css 时 filepath 为 "/css/a.css"
 func (r *Route) matchFast(pathSegments []string) map[string]string {
-	if len(r.segments) != len(pathSegments) {
+	wildcard := len(r.segments) > 0 && r.segments[len(r.segments)-1] == "*"
+	if wildcard {
+		if len(pathSegments) < len(r.segments)-1 {
+			return nil
+		}
+	} else if len(r.segments) != len(pathSegments) {
 		return nil
 	}
 
@@ -165,6 +340,15 @@ func (r *Route) matchFast(pathSegments []string) map[string]string {
 	paramIndex := 0
 
 	for i, routeSeg := range r.segments {
+		if routeSeg == "*" {
+			rest := pathSegments[i:]
+			if len(rest) == 1 && rest[0] == "" {
+				rest = nil
+			}
+			params[r.paramNames[paramIndex]] = "/" + strings.Join(rest, "/")
+			paramIndex++
+			break
+		}
 		pathSeg := pathSegments[i]
 
 		if routeSeg == ":" {
@@ -243,6 +427,7 @@ func (r *Router) AddRoutes(method string, routes map[string]HandlerFunc) {
 			method:  method,
 			path:    path,
 			handler: handler,
+			primary: handler,
 		}
 		route.parsePath()
 		r.routes[method] = append(r.routes[method], route)
@@ -252,6 +437,28 @@ func (r *Router) AddRoutes(method string, routes map[string]HandlerFunc) {
 	r.clearCache()
 }
 
+// AllowedMethods 返回路径上已注册路由的方法（已排序）
+func (r *Router) AllowedMethods(path string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pathSegments := splitPathFast(path)
+	methods := make([]string, 0, 4)
+	for method, routes := range r.routes {
+		for _, route := range routes {
+			if route.matchFast(pathSegments) != nil {
+				methods = append(methods, method)
+				break
+			}
+		}
+	}
+	if containsString(methods, "GET") && !containsString(methods, "HEAD") {
+		methods = append(methods, "HEAD")
+	}
+	sort.Strings(methods)
+	return methods
+}
+
 // 获取所有路由（用于调试）
 func (r *Router) GetRoutes() map[string][]string {
 	r.mu.RLock()
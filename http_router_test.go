@@ -0,0 +1,91 @@
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWildcardRoutes(t *testing.T) {
+	s := New()
+	s.GET("/static/*filepath", func(c *Context) { c.String(StatusOK, "wild "+c.Param("filepath")) })
+	s.GET("/static/:file", func(c *Context) { c.String(StatusOK, "param "+c.Param("file")) })
+	s.GET("/static/app.js", func(c *Context) { c.String(StatusOK, "static") })
+
+	cases := map[string]string{
+		"/static/app.js":       "static",
+		"/static/logo.png":     "param logo.png",
+		"/static/css/site.css": "wild /css/site.css",
+		"/static/":             "wild /",
+	}
+	for path, want := range cases {
+		if resp := doRaw(s, "GET "+path+" HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "\r\n\r\n"+want) {
+			t.Errorf("%s: got %q, want %q", path, resp, want)
+		}
+	}
+
+	// 没有参数名的 * 和 : 段在注册时 panic，而不是在匹配请求时越界
+	for _, path := range []string{"/files/*", "/users/:", "/:/edit"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected registration to panic", path)
+				}
+			}()
+			s.GET(path, func(c *Context) {})
+		}()
+	}
+}
+
+func TestHeadOptionsPatchAny(t *testing.T) {
+	s := New()
+	s.GET("/doc", func(c *Context) { c.String(StatusOK, "hello world") })
+	s.HEAD("/explicit", func(c *Context) { c.Writer.SetHeader("X-Head", "1"); c.String(StatusOK, "") })
+	s.GET("/explicit", func(c *Context) { c.String(StatusOK, "get") })
+	s.PATCH("/doc", func(c *Context) { c.String(StatusOK, "patched") })
+	s.OPTIONS("/doc", func(c *Context) { c.String(StatusOK, "custom options") })
+	s.Any("/any", func(c *Context) { c.String(StatusOK, c.Request.Method) })
+
+	resp := doRaw(s, "HEAD /doc HTTP/1.1\r\nHost: x\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.Contains(resp, "Content-Length: 11") || !strings.HasSuffix(resp, "\r\n\r\n") {
+		t.Fatalf("automatic HEAD:\n%q", resp)
+	}
+	if resp := doRaw(s, "HEAD /explicit HTTP/1.1\r\nHost: x\r\n\r\n"); !strings.Contains(resp, "X-Head: 1") {
+		t.Fatalf("explicit HEAD route not preferred:\n%s", resp)
+	}
+	if resp := doRaw(s, "PATCH /doc HTTP/1.1\r\nHost: x\r\n\r\n"); !strings.HasSuffix(resp, "patched") {
+		t.Fatalf("PATCH:\n%s", resp)
+	}
+	if resp := doRaw(s, "OPTIONS /doc HTTP/1.1\r\nHost: x\r\n\r\n"); !strings.HasSuffix(resp, "custom options") {
+		t.Fatalf("OPTIONS:\n%s", resp)
+	}
+	for _, method := range []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"} {
+		if resp := doRaw(s, method+" /any HTTP/1.1\r\nHost: x\r\n\r\n"); !strings.HasSuffix(resp, method) {
+			t.Fatalf("Any %s:\n%s", method, resp)
+		}
+	}
+}
+
+func TestRouteCacheHighCardinality(t *testing.T) {
+	s := New()
+	s.router.setCacheSize(16)
+	s.GET("/users/:id", func(c *Context) {})
+	s.GET("/lang/:code", func(c *Context) {})
+	s.GET("/about", func(c *Context) {})
+
+	for i := 0; i < 100; i++ {
+		s.router.findRoute("GET", "/users/"+strconv.Itoa(i))
+		s.router.findRoute("GET", "/lang/"+[]string{"en", "zh", "ja"}[i%3])
+		s.router.findRoute("GET", "/about")
+	}
+	stats := s.RouteCacheStats()
+	if len(stats.Uncached) != 1 || stats.Uncached[0] != "GET /users/:id" || stats.Wipes != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if stats.Size != 4 || stats.Hits < 190 {
+		t.Fatalf("low-cardinality routes should stay cached: %+v", stats)
+	}
+	if route, params := s.router.findRoute("GET", "/users/7"); route == nil || params["id"] != "7" {
+		t.Fatalf("uncached route lookup failed")
+	}
+}
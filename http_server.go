@@ -2,13 +2,16 @@ package meego
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
+	"errors"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/panjf2000/ants/v2"
+	"github.com/rs/zerolog/log"
 	"io"
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -49,16 +52,89 @@ func init() {
 	}
 }
 
+// acquireContext 从对象池获取上下文
+func acquireContext() *Context {
+	trackLeak(&leakStats.contexts, 1)
+	c := contextPool.Get().(*Context)
+	c.guard.acquire()
+	return c
+}
+
+// releaseContext 重置上下文并放回对象池
+func releaseContext(c *Context) {
+	c.guard.release("Context")
+	trackLeak(&leakStats.contexts, -1)
+	c.reset()
+	contextPool.Put(c)
+}
+
+// acquireWriter 从对象池获取响应写入器
+func acquireWriter() *ResponseWriter {
+	trackLeak(&leakStats.writers, 1)
+	w := responseWriterPool.Get().(*ResponseWriter)
+	w.guard.acquire()
+	return w
+}
+
+// releaseWriter 重置响应写入器并放回对象池
+func releaseWriter(w *ResponseWriter) {
+	w.guard.release("ResponseWriter")
+	trackLeak(&leakStats.writers, -1)
+	w.reset()
+	responseWriterPool.Put(w)
+}
+
 // HTTPServer HTTP服务器 - 优化版本
 type HTTPServer struct {
 	addr        string
 	router      *Router
 	middlewares []MiddlewareFunc
+	chain       []HandlerFunc // middlewares 转换后的处理链
 
 	readTimeout  time.Duration
 	writeTimeout time.Duration
+	// 每个连接的读缓冲区大小，0 表示 bufio 默认值
+	readBufferSize int
 
 	pool *ants.Pool
+
+	// 出站调用时透传的请求头白名单
+	propagateHeaders []string
+	// 每个响应都带有的响应头
+	globalHeaders map[string]string
+	// 请求完成后的耗时回调
+	timingHooks []func(*Context, Timings)
+	// 连接建立后的回调
+	connectHooks []func(net.Conn, *ConnValues) error
+	// 功能开关
+	flagProvider FlagProvider
+	// 请求体大小限制
+	bodyLimits BodyLimits
+	// 请求头、URI 和默认请求体的大小限制
+	limits Limits
+	// 所有请求都流式读取请求体，见 StreamBodies
+	streamBodies bool
+	// 未匹配路由/方法时的处理器
+	noRoute  HandlerFunc
+	noMethod HandlerFunc
+	// 正在运行接受循环的监听器
+	listeners map[net.Listener]struct{}
+	// 等待下一个请求的持久连接
+	idleConns map[net.Conn]struct{}
+	// 排空状态和关闭回调
+	lifecycle lifecycle
+	// JSON 响应是否计算 ETag，见 SetJSONETag
+	jsonETag atomic.Bool
+	// 热加载的运行时配置
+	runtime atomic.Pointer[runtimeState]
+	// 内存压力保护
+	memGuard *memoryGuard
+	// ListenTLS 加载的证书
+	tlsCert atomic.Pointer[tls.Certificate]
+	// HTTPS 配置和按 ALPN 协议分发的连接处理器
+	tlsConfig    *tls.Config
+	alpnHandlers map[string]func(*tls.Conn)
+
 	// 性能优化字段
 	mu         sync.RWMutex
 	serverCtx  context.Context
@@ -84,6 +160,8 @@ func New() *HTTPServer {
 		writeTimeout: 10 * time.Second,
 		serverCtx:    ctx,
 		cancelFunc:   cancel,
+
+		propagateHeaders: append([]string(nil), defaultPropagateHeaders...),
 	}
 }
 
@@ -126,33 +204,61 @@ func NewHTTPServer(addr string) *HTTPServer {
 
 // 优化的启动方法
 func (s *HTTPServer) Start() error {
+	if err := s.startupCheck(nil); err != nil {
+		return err
+	}
 	ln, err := net.Listen("tcp", s.addr)
 	if err != nil {
 		return err
 	}
-	defer ln.Close()
+	return s.serveHTTP(ln, s.handleConnectionFast)
+}
 
-	fmt.Printf("HTTPServer started on %s\n", s.addr)
+// serveHTTP 执行启动回调后在 ln 上处理 HTTP 连接
+func (s *HTTPServer) serveHTTP(ln net.Listener, handler func(net.Conn)) error {
+	if err := s.runStartHooks(ln.Addr()); err != nil {
+		ln.Close()
+		return err
+	}
+
+	log.Info().Str("addr", ln.Addr().String()).Msg("HTTPServer started")
+	return s.Serve(ln, handler)
+}
+
+// Serve 在 ln 上运行接受循环，每个连接交给协程池中的 handler 处理。
+// 与 HTTP 服务共享协程池和关闭流程，可用于在同一进程中承载辅助协议
+// （调试 REPL、statsd 接收等）：
+//
+//	ln, _ := net.Listen("tcp", ":8125")
+//	go server.Serve(ln, handleStatsd)
+//
+// Shutdown 时关闭 ln 并返回 nil。handler 返回后连接不会自动关闭
+func (s *HTTPServer) Serve(ln net.Listener, handler func(conn net.Conn)) error {
+	s.trackListener(ln, true)
+	defer s.trackListener(ln, false)
+	defer ln.Close()
 
 	// 主接受循环
 	for {
 		select {
 		case <-s.serverCtx.Done():
-			fmt.Println("Server received shutdown signal")
+			debugPrint("Server received shutdown signal")
 			return nil
 		default:
-			fmt.Printf("=Start default==============\n")
 			conn, err := ln.Accept()
 			if err != nil {
 				if ne, ok := err.(net.Error); ok && ne.Temporary() {
 					time.Sleep(5 * time.Millisecond)
 					continue
 				}
-				// 检查是否因为上下文取消导致的错误
+				// 检查是否因为上下文取消或优雅关闭导致的错误
 				select {
 				case <-s.serverCtx.Done():
 					return nil
 				default:
+					if s.Draining() && errors.Is(err, net.ErrClosed) {
+						return nil
+					}
 					return err
 				}
 			}
@@ -166,69 +272,182 @@ func (s *HTTPServer) Start() error {
 
 			// 使用协程池处理连接
 			err = s.pool.Submit(func() {
-				s.handleConnectionFast(conn)
+				handler(conn)
 			})
 			if err != nil {
 				// 协程池已满，直接关闭连接
-				fmt.Printf("Pool is full, rejecting connection: %v\n", err)
+				log.Warn().Err(err).Msg("pool is full, rejecting connection")
 				conn.Close()
 			}
 		}
 	}
 }
 
+// Serve 使用独立的协程池在 ln 上运行接受循环，关闭 ln 后返回。
+// 需要与 HTTP 服务共享协程池和关闭流程时使用 HTTPServer.Serve
+func Serve(ln net.Listener, handler func(conn net.Conn)) error {
+	s := New()
+	defer s.Shutdown()
+	return s.Serve(ln, handler)
+}
+
+func (s *HTTPServer) trackListener(ln net.Listener, add bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if add {
+		if s.listeners == nil {
+			s.listeners = make(map[net.Listener]struct{})
+		}
+		s.listeners[ln] = struct{}{}
+	} else {
+		delete(s.listeners, ln)
+	}
+}
+
 // 优化的连接处理方法
 func (s *HTTPServer) handleConnectionFast(conn net.Conn) {
-	// 对于短连接，可以禁用 Nagle 算法以减少延迟
+	trackLeak(&leakStats.connections, 1)
+	defer trackLeak(&leakStats.connections, -1)
+
+	// 禁用 Nagle 算法以减少小响应的延迟
 	if tc, ok := conn.(*net.TCPConn); ok {
 		tc.SetNoDelay(true)
-		// 不需要设置 KeepAlive，因为马上就会关闭
 	}
 
 	remoteAddr := conn.RemoteAddr().String()
-	fmt.Printf("DEBUG [%s] Connection established\n", remoteAddr)
+	if IsDebugging() {
+		debugPrint("[%s] Connection established", remoteAddr)
+	}
 
 	defer func() {
 		conn.Close()
-		fmt.Printf("DEBUG [%s] Connection closed\n", remoteAddr)
+		if IsDebugging() {
+			debugPrint("[%s] Connection closed", remoteAddr)
+		}
 
 		if r := recover(); r != nil {
-			// 静默处理 panic
-			fmt.Printf("PANIC in handleConnectionFast: %v\n", r)
+			log.Error().Interface("panic", r).Str("remote_addr", remoteAddr).Msg("panic in connection handler")
 		}
 		//conn.Close()
 	}()
 
+	connValues := newConnValues(conn)
+	responses := newResponseQueue(conn)
+	if err := s.runConnectHooks(conn, connValues); err != nil {
+		debugPrint("[%s] Connection rejected: %v", remoteAddr, err)
+		return
+	}
+
 	// 为每个连接创建新的解析器
-	parser := NewHTTPParser(conn)
+	s.mu.RLock()
+	bufferSize := s.readBufferSize
+	limits := s.limits
+	s.mu.RUnlock()
+	parser := newHTTPParserSize(conn, bufferSize)
+	parser.limits = limits.withDefaults()
+	parser.bodyLimit = s.bodyLimitFor
+	parser.streamBody = s.streamBodyFor
+
+	// 持久连接上依次处理请求，直到任一方要求关闭
+	for served := 0; ; served++ {
+		conn.SetReadDeadline(time.Now().Add(s.readTimeout))
+		if served > 0 {
+			// 请求之间的空闲连接在 Shutdown 时直接关闭
+			s.trackIdleConn(conn, true)
+		}
+
+		// 使用对象池获取请求
+		parseStart := time.Now()
+		req, err := parser.ParseRequest()
+		if served > 0 {
+			s.trackIdleConn(conn, false)
+		}
+		if err != nil {
+			s.handleParseError(conn, remoteAddr, err)
+			return // 直接返回，defer 会关闭连接
+		}
+		req.parseTime = time.Since(parseStart)
 
-	// 只处理一个请求，然后立即关闭连接
-	conn.SetReadDeadline(time.Now().Add(s.readTimeout))
+		if IsDebugging() {
+			debugPrint("[%s] Processing: %s %s", remoteAddr, req.Method, req.RawURL)
+		}
+		keepAlive := s.processRequestFast(conn, responses.next(), connValues, req)
+		ReleaseRequest(req)
+		if !keepAlive {
+			if IsDebugging() {
+				debugPrint("[%s] Request processed, closing connection", remoteAddr)
+			}
+			return
+		}
+	}
+}
 
-	fmt.Printf("DEBUG [%s] Waiting for request...\n", remoteAddr)
-	// 使用对象池获取请求
-	req, err := parser.ParseRequest()
-	if err != nil {
-		s.handleParseError(conn, remoteAddr, err)
-		return // 直接返回，defer 会关闭连接
+// trackIdleConn 记录等待下一个请求的持久连接
+func (s *HTTPServer) trackIdleConn(conn net.Conn, idle bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !idle {
+		delete(s.idleConns, conn)
+		return
+	}
+	if s.serverCtx.Err() != nil {
+		conn.Close()
+		return
+	}
+	if s.idleConns == nil {
+		s.idleConns = make(map[net.Conn]struct{})
 	}
+	s.idleConns[conn] = struct{}{}
+}
 
-	fmt.Printf("DEBUG [%s] Processing: %s %s\n", remoteAddr, req.Method, req.RawURL)
-	s.processRequestFast(conn, req)
-	ReleaseRequest(req)
+// wantsKeepAlive 按请求协商连接是否保持：HTTP/1.1 默认保持，HTTP/1.0 需要 Connection: keep-alive；
+// 排空或关闭中的服务器不再保持连接
+func (s *HTTPServer) wantsKeepAlive(req *HTTPRequest) bool {
+	if s.Draining() || s.serverCtx.Err() != nil {
+		return false
+	}
+	connection := req.GetHeader("Connection")
+	if req.Proto == "HTTP/1.0" {
+		return headerContainsToken(connection, "keep-alive")
+	}
+	return !headerContainsToken(connection, "close")
+}
+
+// maxBodyDrain 流式请求体未读完时，为复用连接最多丢弃的字节数
+const maxBodyDrain = 256 << 10
 
-	// 处理完一个请求就直接结束，连接会在 defer 中关闭
-	fmt.Printf("DEBUG [%s] Request processed, closing connection\n", remoteAddr)
+// drainBody 丢弃未读取的流式请求体，读完返回 true
+func drainBody(req *HTTPRequest) bool {
+	if req.bodyReader == nil {
+		return true
+	}
+	n, err := io.CopyN(io.Discard, req.bodyReader, maxBodyDrain+1)
+	return n <= maxBodyDrain && err == io.EOF
 }
 
 func (s *HTTPServer) handleParseError(conn net.Conn, remoteAddr string, err error) {
 	switch {
 	case err == io.EOF:
-		fmt.Printf("DEBUG [%s] Client closed connection\n", remoteAddr)
+		debugPrint("[%s] Client closed connection", remoteAddr)
 	case isTimeoutError(err):
-		fmt.Printf("DEBUG [%s] Read timeout (no data sent)\n", remoteAddr)
+		debugPrint("[%s] Read timeout (no data sent)", remoteAddr)
+	case errors.Is(err, ErrBodyTooLarge) && s.UnderMemoryPressure():
+		debugPrint("[%s] Rejected under memory pressure: %v", remoteAddr, err)
+		s.sendErrorFast(conn, StatusServiceUnavailable, "Service Unavailable")
+	case errors.Is(err, ErrBodyTooLarge):
+		debugPrint("[%s] Parse error: %v", remoteAddr, err)
+		s.sendErrorFast(conn, StatusRequestEntityTooLarge, "Request Entity Too Large")
+	case errors.Is(err, ErrURITooLong):
+		debugPrint("[%s] Parse error: %v", remoteAddr, err)
+		s.sendErrorFast(conn, StatusRequestURITooLong, "Request URI Too Long")
+	case errors.Is(err, ErrHeaderTooLarge):
+		debugPrint("[%s] Parse error: %v", remoteAddr, err)
+		s.sendErrorFast(conn, StatusRequestHeaderFieldsTooLarge, "Request Header Fields Too Large")
+	case errors.Is(err, ErrBadFraming):
+		debugPrint("[%s] Parse error: %v", remoteAddr, err)
+		s.sendErrorFast(conn, StatusBadRequest, "Bad Request")
 	default:
-		fmt.Printf("DEBUG [%s] Parse error: %v\n", remoteAddr, err)
+		debugPrint("[%s] Parse error: %v", remoteAddr, err)
 		if isParseError(err) {
 			s.sendErrorFast(conn, 400, "Bad Request")
 		}
@@ -237,133 +456,149 @@ func (s *HTTPServer) handleParseError(conn net.Conn, remoteAddr string, err erro
 }
 
 // 优化的请求处理方法
-func (s *HTTPServer) processRequestFast(conn net.Conn, req *HTTPRequest) {
+// 返回连接是否可以继续处理下一个请求。响应通过 out 写出，返回后 out 不再接受写入
+func (s *HTTPServer) processRequestFast(conn net.Conn, out *queuedConn, connValues *ConnValues, req *HTTPRequest) (keepAlive bool) {
+	defer out.queue.finish(out)
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("PANIC in processRequestFast: %v\n", r)
+			log.Error().Interface("panic", r).Msg("panic in request processing")
 			s.sendErrorFast(conn, 500, "Internal Server Error")
+			keepAlive = false
 		}
-		// 确保连接关闭
-		conn.Close()
 	}()
-	// 设置写入超时
-	conn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	// 设置写入超时，上游通过 X-Request-Timeout 传入的更短期限优先
+	deadline := requestDeadline(req, now().Add(s.writeTimeout))
+	conn.SetWriteDeadline(wallTime(deadline))
 
 	// 快速路由查找
-	handler, params := s.findRouteHandler(req.Method, req.URL.Path)
-	if handler == nil {
-		fmt.Printf("No handler found for %s %s\n", req.Method, req.URL.Path)
-		s.sendErrorFast(conn, 404, "Not Found")
-		return
-	}
+	routeStart := time.Now()
+	var (
+		route   *Route
+		handler HandlerFunc
+		params  map[string]string
+	)
+	if maintenance := s.maintenanceHandler(req.URL.Path); maintenance != nil {
+		handler = maintenance
+	} else if route, params = s.router.findRoute(req.Method, req.URL.Path); route != nil {
+		handler = timedHandler(route.handler)
+	} else {
+		// 未匹配的请求同样经过全局中间件，日志、指标、CORS 都能看到 404/405
+		handler = s.unmatchedHandler(req)
+	}
+	routeTime := time.Since(routeStart)
 
 	// 从对象池获取上下文和响应写入器
-	var ctx *Context
-	var writer *ResponseWriter
+	ctx := acquireContext()
+	writer := acquireWriter()
 
 	// 确保在函数返回时释放对象
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("Recovered from panic in request processing: %v\n", r)
+			if isAbortConnection(r) {
+				debugPrint("[%s] Connection aborted by handler", conn.RemoteAddr())
+				dropConnection(conn)
+			} else {
+				log.Error().Interface("panic", r).Str("method", req.Method).Str("path", req.URL.Path).Msg("recovered from panic in handler")
+			}
+			keepAlive = false
 		}
 
 		// 重置并放回对象池
-		if ctx != nil {
-			ctx.reset()
-			contextPool.Put(ctx)
-		}
-		if writer != nil {
-			writer.reset()
-			responseWriterPool.Put(writer)
-		}
+		releaseContext(ctx)
+		releaseWriter(writer)
 	}()
 
-	// 安全地从对象池获取
-	ctxObj := contextPool.Get()
-	writerObj := responseWriterPool.Get()
-
-	var ok bool
-	ctx, ok = ctxObj.(*Context)
-	if !ok || ctx == nil {
-		fmt.Printf("Failed to get context from pool\n")
-		s.sendErrorFast(conn, 500, "Internal Server Error")
-		return
-	}
-
-	writer, ok = writerObj.(*ResponseWriter)
-	if !ok || writer == nil {
-		fmt.Printf("Failed to get writer from pool\n")
-		s.sendErrorFast(conn, 500, "Internal Server Error")
-		// 如果writer获取失败，但ctx已获取，需要放回
-		if ctx != nil {
-			ctx.reset()
-			contextPool.Put(ctx)
+	// 快速初始化
+	ctx.fastInit(conn, req, writer, params)
+	ctx.handlers = s.handlerChain(ctx.handlers, route, handler)
+	ctx.server = s
+	ctx.connValues = connValues
+	ctx.deadline = deadline
+	if route != nil {
+		ctx.fullPath = route.path
+	}
+	ctx.timings.parse = req.parseTime
+	ctx.timings.route = routeTime
+	writer.fastInit(out)
+	writer.setRequest(req)
+	writer.jsonETag = s.jsonETag.Load()
+	if route != nil {
+		writer.maxResponse = route.maxResponse
+		if route.fields != nil {
+			writer.fields = requestedFields(req, route.fields)
 		}
-		return
 	}
-
-	// 快速初始化
-	ctx.fastInit(conn, req, writer, params, handler)
-	writer.fastInit(conn)
-	// 强制短连接
-	writer.SetHeader("Connection", "close")
+	writer.keepAlive = s.wantsKeepAlive(req)
+	s.applyGlobalHeaders(writer)
 
 	// 执行处理链
+	chainStart := time.Now()
 	ctx.Next()
-}
+	writer.finishStream()
+	ctx.timings.chain = time.Since(chainStart)
 
-// 优化的路由查找方法
-func (s *HTTPServer) findRouteHandler(method, path string) (HandlerFunc, map[string]string) {
-	handler, params := s.router.FindRoute(method, path)
-	if handler == nil {
-		return nil, nil
+	// 连接被接管、响应未写出或未按长度结束、请求体未读完时关闭连接
+	keepAlive = writer.keepAlive && writer.written && !writer.hijacked && drainBody(req)
+
+	s.mu.RLock()
+	hooks := s.timingHooks
+	s.mu.RUnlock()
+	if len(hooks) > 0 {
+		timings := ctx.Timings()
+		for _, hook := range hooks {
+			hook(ctx, timings)
+		}
 	}
+	return keepAlive
+}
 
-	// 应用中间件（优化：避免在每次请求时创建闭包）
+// handlerChain 组装本次请求的处理链：全局中间件、路由组和路由中间件、处理器
+func (s *HTTPServer) handlerChain(dst []HandlerFunc, route *Route, handler HandlerFunc) []HandlerFunc {
 	s.mu.RLock()
-	middlewares := s.middlewares
+	dst = append(dst, s.chain...)
 	s.mu.RUnlock()
+	if route != nil {
+		dst = append(dst, route.chain...)
+	}
+	return append(dst, handler)
+}
 
-	if len(middlewares) > 0 {
-		wrappedHandler := handler
-		for i := len(middlewares) - 1; i >= 0; i-- {
-			wrappedHandler = middlewares[i](wrappedHandler)
+// chainHandler 把闭包式中间件转换为处理链中的一环：传入的 next 即 c.Next，
+// 中间件没有调用 next 时中止处理链
+func chainHandler(middleware MiddlewareFunc) HandlerFunc {
+	h := middleware(func(c *Context) { c.Next() })
+	return func(c *Context) {
+		index := c.Index
+		h(c)
+		if c.Index == index {
+			c.Abort()
 		}
-		return wrappedHandler, params
 	}
+}
 
-	return handler, params
+// chainHandlers 转换一组中间件
+func chainHandlers(middlewares []MiddlewareFunc) []HandlerFunc {
+	handlers := make([]HandlerFunc, len(middlewares))
+	for i, m := range middlewares {
+		handlers[i] = chainHandler(m)
+	}
+	return handlers
 }
 
 // 优化的错误发送方法
 func (s *HTTPServer) sendErrorFast(conn net.Conn, code int, message string) {
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("Recovered from panic in error sending: %v\n", r)
+			log.Error().Interface("panic", r).Msg("recovered from panic in error sending")
 		}
 	}()
 	// 从对象池获取响应写入器
-	writerObj := responseWriterPool.Get()
-	if writerObj == nil {
-		// 对象池可能返回nil，需要处理
-		fmt.Printf("Failed to get writer from pool for error response\n")
-		return
-	}
-	writer, ok := writerObj.(*ResponseWriter)
-	if !ok {
-		fmt.Printf("Type assertion failed for writer from pool\n")
-		return
-	}
+	writer := acquireWriter()
 	// 关键：必须初始化writer！
 	writer.fastInit(conn)
+	defer releaseWriter(writer)
 
-	defer func() {
-		if writer != nil {
-			writer.reset()
-			responseWriterPool.Put(writer)
-		}
-	}()
-
+	s.applyGlobalHeaders(writer)
 	// 强制短连接
 	writer.SetHeader("Connection", "close")
 	writer.Status(code).JSON(JSON{
@@ -372,22 +607,31 @@ func (s *HTTPServer) sendErrorFast(conn net.Conn, code int, message string) {
 	})
 }
 
-// 检查连接是否应该关闭
-func (s *HTTPServer) shouldClose(req *HTTPRequest) bool {
-	connection := req.GetHeader("Connection")
-	return connection == "close"
-}
-
-// 关闭服务器
+// 关闭服务器。WebSocket 和 SSE 连接先收到关闭通知，在排空窗口（SetDrainWindow）内
+// 自行断开，超时后强制关闭
 func (s *HTTPServer) Shutdown() {
-	fmt.Printf("=Shutdown==============\n")
+	debugPrint("Shutdown")
 
 	select {
 	case <-s.serverCtx.Done():
 		// 已经关闭
 		return
 	default:
+		s.Drain()
+		// 先通知 WebSocket 和 SSE 客户端并等待它们断开，再取消上下文
+		s.drainLongLived()
 		s.cancelFunc() // 取消上下文
+		s.runShutdownHooks()
+
+		// 关闭所有监听器，使接受循环退出；关闭等待下一个请求的持久连接
+		s.mu.RLock()
+		for ln := range s.listeners {
+			ln.Close()
+		}
+		for conn := range s.idleConns {
+			conn.Close()
+		}
+		s.mu.RUnlock()
 		s.pool.Release()
 	}
 }
@@ -397,23 +641,104 @@ func (s *HTTPServer) Use(middleware MiddlewareFunc) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.middlewares = append(s.middlewares, middleware)
+	s.chain = append(s.chain, chainHandler(middleware))
+}
+
+// NoRoute 设置没有匹配路由时的处理器，默认返回 404 JSON
+func (s *HTTPServer) NoRoute(handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.noRoute = handler
+}
+
+// NoMethod 设置路径存在但方法不匹配时的处理器，默认返回 405 JSON；
+// 调用前已设置 Allow 响应头
+func (s *HTTPServer) NoMethod(handler HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.noMethod = handler
+}
+
+// unmatchedHandler 返回未匹配请求的终端处理器：
+// OPTIONS 预检 -> 204，路径存在但方法不匹配 -> 405，其余 -> 404
+func (s *HTTPServer) unmatchedHandler(req *HTTPRequest) HandlerFunc {
+	s.mu.RLock()
+	noRoute, noMethod := s.noRoute, s.noMethod
+	s.mu.RUnlock()
+
+	allowed := s.router.AllowedMethods(req.URL.Path)
+	if len(allowed) == 0 {
+		if noRoute != nil {
+			return noRoute
+		}
+		return notFoundHandler
+	}
+	if req.Method == "OPTIONS" {
+		return preflightHandler(allowed)
+	}
+
+	allow := allowHeader(allowed)
+	return func(c *Context) {
+		c.Writer.SetHeader("Allow", allow)
+		if noMethod != nil {
+			noMethod(c)
+			return
+		}
+		c.Writer.Status(StatusMethodNotAllowed).JSON(JSON{
+			"error": "Method Not Allowed",
+			"code":  StatusMethodNotAllowed,
+		})
+	}
+}
+
+func notFoundHandler(c *Context) {
+	c.Writer.Status(StatusNotFound).JSON(JSON{
+		"error": "Not Found",
+		"code":  StatusNotFound,
+	})
 }
 
 // 注册路由 - 线程安全版本
-func (s *HTTPServer) GET(path string, handler HandlerFunc) {
-	s.router.AddRoute("GET", path, handler)
+func (s *HTTPServer) GET(path string, handler HandlerFunc) *Route {
+	return s.router.AddRoute("GET", path, handler)
 }
 
-func (s *HTTPServer) POST(path string, handler HandlerFunc) {
-	s.router.AddRoute("POST", path, handler)
+func (s *HTTPServer) POST(path string, handler HandlerFunc) *Route {
+	return s.router.AddRoute("POST", path, handler)
 }
 
-func (s *HTTPServer) PUT(path string, handler HandlerFunc) {
-	s.router.AddRoute("PUT", path, handler)
+func (s *HTTPServer) PUT(path string, handler HandlerFunc) *Route {
+	return s.router.AddRoute("PUT", path, handler)
 }
 
-func (s *HTTPServer) DELETE(path string, handler HandlerFunc) {
-	s.router.AddRoute("DELETE", path, handler)
+func (s *HTTPServer) DELETE(path string, handler HandlerFunc) *Route {
+	return s.router.AddRoute("DELETE", path, handler)
+}
+
+func (s *HTTPServer) PATCH(path string, handler HandlerFunc) *Route {
+	return s.router.AddRoute("PATCH", path, handler)
+}
+
+// HEAD 注册 HEAD 路由；未注册时 HEAD 请求自动由同路径的 GET 路由处理，只发送头部
+func (s *HTTPServer) HEAD(path string, handler HandlerFunc) *Route {
+	return s.router.AddRoute("HEAD", path, handler)
+}
+
+// OPTIONS 注册 OPTIONS 路由，替代自动的预检响应
+func (s *HTTPServer) OPTIONS(path string, handler HandlerFunc) *Route {
+	return s.router.AddRoute("OPTIONS", path, handler)
+}
+
+// anyMethods Any 注册的方法
+var anyMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
+
+// Any 为所有常用方法注册同一个处理器
+func (s *HTTPServer) Any(path string, handler HandlerFunc) []*Route {
+	routes := make([]*Route, len(anyMethods))
+	for i, method := range anyMethods {
+		routes[i] = s.router.AddRoute(method, path, handler)
+	}
+	return routes
 }
 
 // 配置方法
@@ -435,44 +760,78 @@ type RouteGroup struct {
 	server      *HTTPServer
 	prefix      string
 	middlewares []MiddlewareFunc
+	maxBody     int64            // 组内路由的请求体上限
+	maxResponse responseLimit    // 组内路由的响应体上限
+	concurrency concurrencyLimit // 组内每个路由的并发上限
+	fields      []string         // 组内路由的稀疏字段集
 }
 
-// 预编译中间件链
-func (g *RouteGroup) wrapHandler(handler HandlerFunc) HandlerFunc {
-	if len(g.middlewares) == 0 {
-		return handler
-	}
+// Use 添加路由组中间件，只对之后注册的路由生效
+func (g *RouteGroup) Use(middlewares ...MiddlewareFunc) *RouteGroup {
+	g.middlewares = append(g.middlewares, middlewares...)
+	return g
+}
 
-	wrapped := handler
-	for i := len(g.middlewares) - 1; i >= 0; i-- {
-		wrapped = g.middlewares[i](wrapped)
+// handle 注册路由组内的路由
+func (g *RouteGroup) handle(method, path string, handler HandlerFunc) *Route {
+	fullPath := g.prefix + path
+	route := g.server.router.AddRoute(method, fullPath, handler)
+	route.chain = chainHandlers(g.middlewares)
+	route.middleware = middlewareNames(g.middlewares)
+	if g.maxBody > 0 {
+		route.maxBody = g.maxBody
 	}
-	return wrapped
+	route.maxResponse = g.maxResponse
+	route.fields = g.fields
+	if g.concurrency.max > 0 {
+		route.MaxConcurrency(g.concurrency.max, g.concurrency.wait)
+	}
+	return route
 }
 
-func (g *RouteGroup) GET(path string, handler HandlerFunc) {
-	fullPath := g.prefix + path
-	wrappedHandler := g.wrapHandler(handler)
-	g.server.GET(fullPath, wrappedHandler)
+// Use 添加路由中间件，在全局和路由组中间件之后执行
+func (r *Route) Use(middlewares ...MiddlewareFunc) *Route {
+	r.chain = append(r.chain, chainHandlers(middlewares)...)
+	r.middleware = append(r.middleware, middlewareNames(middlewares)...)
+	return r
 }
 
-func (g *RouteGroup) POST(path string, handler HandlerFunc) {
-	fullPath := g.prefix + path
-	wrappedHandler := g.wrapHandler(handler)
-	g.server.POST(fullPath, wrappedHandler)
+func (g *RouteGroup) GET(path string, handler HandlerFunc) *Route {
+	return g.handle("GET", path, handler)
+}
+
+func (g *RouteGroup) POST(path string, handler HandlerFunc) *Route {
+	return g.handle("POST", path, handler)
 }
 
 // 添加其他方法
-func (g *RouteGroup) PUT(path string, handler HandlerFunc) {
-	fullPath := g.prefix + path
-	wrappedHandler := g.wrapHandler(handler)
-	g.server.PUT(fullPath, wrappedHandler)
+func (g *RouteGroup) PUT(path string, handler HandlerFunc) *Route {
+	return g.handle("PUT", path, handler)
 }
 
-func (g *RouteGroup) DELETE(path string, handler HandlerFunc) {
-	fullPath := g.prefix + path
-	wrappedHandler := g.wrapHandler(handler)
-	g.server.DELETE(fullPath, wrappedHandler)
+func (g *RouteGroup) DELETE(path string, handler HandlerFunc) *Route {
+	return g.handle("DELETE", path, handler)
+}
+
+func (g *RouteGroup) PATCH(path string, handler HandlerFunc) *Route {
+	return g.handle("PATCH", path, handler)
+}
+
+func (g *RouteGroup) HEAD(path string, handler HandlerFunc) *Route {
+	return g.handle("HEAD", path, handler)
+}
+
+func (g *RouteGroup) OPTIONS(path string, handler HandlerFunc) *Route {
+	return g.handle("OPTIONS", path, handler)
+}
+
+// Any 为所有常用方法注册同一个处理器
+func (g *RouteGroup) Any(path string, handler HandlerFunc) []*Route {
+	routes := make([]*Route, len(anyMethods))
+	for i, method := range anyMethods {
+		routes[i] = g.handle(method, path, handler)
+	}
+	return routes
 }
 
 //====
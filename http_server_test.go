@@ -0,0 +1,220 @@
+package meego
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// doRaw 通过内存管道向服务器发送原始请求并返回完整响应
+// raw 中可以包含多个流水线请求；读完后服务器看到 EOF，持久连接随之关闭。
+// 返回前等待连接处理协程结束，关闭连接时的日志不会与调用方的检查并发
+func doRaw(s *HTTPServer, raw string) string {
+	client, server := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.handleConnectionFast(&rawConn{Conn: server, r: strings.NewReader(raw)})
+	}()
+
+	resp, _ := io.ReadAll(client)
+	client.Close()
+	<-done
+	return string(resp)
+}
+
+// rawConn 从固定内容读取请求，响应写入管道
+type rawConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *rawConn) Read(b []byte) (int, error) { return c.r.Read(b) }
+
+// readRawResponse 解析 doRaw 返回的原始响应，自动去掉分块编码
+func readRawResponse(t *testing.T, raw string) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := http.ReadResponse(bufio.NewReader(strings.NewReader(raw)), nil)
+	if err != nil {
+		t.Fatalf("parse response: %v\n%q", err, raw)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp, body
+}
+
+// syncBuffer 可并发写入的日志缓冲区
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Len()
+}
+
+func (b *syncBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+func TestServeCustomProtocol(t *testing.T) {
+	s := New()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Serve(ln, func(conn net.Conn) {
+			defer conn.Close()
+			io.Copy(conn, conn)
+		})
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(conn, "ping")
+	buf := make([]byte, 4)
+	io.ReadFull(conn, buf)
+	conn.Close()
+	if string(buf) != "ping" {
+		t.Fatalf("unexpected echo %q", buf)
+	}
+
+	s.Shutdown()
+	if err := <-done; err != nil {
+		t.Fatalf("Serve should return nil after Shutdown: %v", err)
+	}
+}
+
+func TestKeepAlive(t *testing.T) {
+	s := New()
+	s.GET("/", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+	s.GET("/bye", func(c *Context) {
+		c.Writer.SetHeader("Connection", "close")
+		c.String(StatusOK, "bye")
+	})
+
+	get := func(path, proto, extra string) string {
+		return "GET " + path + " " + proto + "\r\nHost: x\r\n" + extra + "\r\n"
+	}
+
+	// HTTP/1.1 默认保持连接，流水线请求依次得到响应
+	resp := doRaw(s, get("/", "HTTP/1.1", "")+get("/", "HTTP/1.1", ""))
+	if n := strings.Count(resp, "HTTP/1.1 200"); n != 2 || strings.Contains(resp, "Connection:") {
+		t.Fatalf("http/1.1 keep-alive (%d responses):\n%s", n, resp)
+	}
+
+	// 请求或处理器要求关闭时不再处理后续请求
+	for _, raw := range []string{
+		get("/", "HTTP/1.1", "Connection: close\r\n") + get("/", "HTTP/1.1", ""),
+		get("/bye", "HTTP/1.1", "") + get("/", "HTTP/1.1", ""),
+		get("/", "HTTP/1.0", "") + get("/", "HTTP/1.0", ""),
+	} {
+		resp := doRaw(s, raw)
+		if strings.Count(resp, " 200 ") != 1 || !strings.Contains(resp, "Connection: close") {
+			t.Fatalf("expected single closing response for %q:\n%s", raw, resp)
+		}
+	}
+
+	// HTTP/1.0 需要显式 keep-alive
+	resp = doRaw(s, get("/", "HTTP/1.0", "Connection: keep-alive\r\n")+get("/", "HTTP/1.0", ""))
+	if strings.Count(resp, " 200 ") != 2 || !strings.Contains(resp, "Connection: keep-alive") {
+		t.Fatalf("http/1.0 keep-alive:\n%s", resp)
+	}
+}
+
+func TestRequestFraming(t *testing.T) {
+	s := New()
+	s.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	s.POST("/p", func(c *Context) { c.String(StatusOK, "ok") })
+
+	next := "GET / HTTP/1.1\r\nHost: x\r\n\r\n"
+
+	// 长度合法时流水线请求继续处理
+	resp := doRaw(s, "POST /p HTTP/1.1\r\nContent-Length: 2\r\ncontent-length: 2\r\n\r\nok"+next)
+	if n := strings.Count(resp, "HTTP/1.1 200"); n != 2 {
+		t.Fatalf("identical Content-Length (%d responses):\n%s", n, resp)
+	}
+
+	// 长度有歧义的请求返回 400 并关闭连接，后续请求不会被当作请求体之外的数据处理
+	for name, headers := range map[string]string{
+		"cl and te":       "Content-Length: 5\r\nTransfer-Encoding: chunked\r\n",
+		"te and cl":       "Transfer-Encoding: chunked\r\nContent-Length: 5\r\n",
+		"invalid cl":      "Content-Length: 5x\r\n",
+		"negative cl":     "Content-Length: -5\r\n",
+		"signed cl":       "Content-Length: +5\r\n",
+		"conflicting cl":  "Content-Length: 5\r\nContent-Length: 0\r\n",
+		"case variant cl": "Content-Length: 0\r\ncontent-length: 5\r\n",
+		"gzip te":         "Transfer-Encoding: gzip, chunked\r\n",
+		"repeated te":     "Transfer-Encoding: chunked\r\ntransfer-encoding: chunked\r\n",
+		"unknown te":      "Transfer-Encoding: identity\r\n",
+	} {
+		resp := doRaw(s, "POST /p HTTP/1.1\r\nHost: x\r\n"+headers+"\r\n0\r\n\r\n"+next)
+		if !strings.HasPrefix(resp, "HTTP/1.1 400") || strings.Count(resp, "HTTP/1.1 ") != 1 || !strings.Contains(resp, "Connection: close") {
+			t.Fatalf("%s: expected a single closing 400:\n%s", name, resp)
+		}
+	}
+}
+
+func TestChunkedFraming(t *testing.T) {
+	s := New()
+	s.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	s.POST("/p", func(c *Context) { c.String(StatusOK, c.BodyString()) })
+
+	post := "POST /p HTTP/1.1\r\nHost: x\r\nTransfer-Encoding: chunked\r\n\r\n"
+	next := "GET / HTTP/1.1\r\nHost: x\r\n\r\n"
+
+	// 合法的块扩展和尾部头部被接受，连接继续处理后续请求
+	resp := doRaw(s, post+"5;name=value;q=\"a;b\"\r\nhello\r\n1 ; last\r\n!\r\n0\r\nX-Trailer: 1\r\n\r\n"+next)
+	if n := strings.Count(resp, "HTTP/1.1 200"); n != 2 || !strings.Contains(resp, "hello!") {
+		t.Fatalf("valid chunked body (%d responses):\n%s", n, resp)
+	}
+
+	// 分块格式错误时返回 400 并关闭保持的连接，不会把剩余数据当作下一个请求
+	for name, body := range map[string]string{
+		"negative size":    "-1\r\nx\r\n0\r\n\r\n",
+		"signed size":      "+a\r\n0123456789\r\n0\r\n\r\n",
+		"hex prefix":       "0x5\r\nhello\r\n0\r\n\r\n",
+		"empty size":       "\r\nhello\r\n0\r\n\r\n",
+		"overflow":         "fffffffffffffffffff\r\nhello\r\n0\r\n\r\n",
+		"bare lf":          "5\nhello\r\n0\r\n\r\n",
+		"missing crlf":     "5\r\nhelloXX0\r\n\r\n",
+		"bad extension":    "5;=x\r\nhello\r\n0\r\n\r\n",
+		"unterminated ext": "5;a=\"b\r\nhello\r\n0\r\n\r\n",
+		"bare lf last":     "0\n\r\n",
+		"bare lf trailer":  "0\r\nX-Trailer: 1\n\r\n",
+		"smuggled request": "5\r\nhelloGET /admin HTTP/1.1\r\n\r\n",
+	} {
+		resp := doRaw(s, post+body+next)
+		if !strings.HasPrefix(resp, "HTTP/1.1 400") || strings.Count(resp, "HTTP/1.1 ") != 1 || !strings.Contains(resp, "Connection: close") {
+			t.Fatalf("%s: expected a single closing 400:\n%s", name, resp)
+		}
+	}
+}
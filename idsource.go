@@ -0,0 +1,103 @@
+// idsource.go
+package meego
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// IDKind 标识符的用途
+type IDKind string
+
+const (
+	IDRequest     IDKind = "request"     // RequestID 中间件生成的请求 ID
+	IDCorrelation IDKind = "correlation" // Recovery 日志中的关联 ID（没有请求 ID 时）
+	IDSession     IDKind = "session"     // 会话 ID
+	IDConnection  IDKind = "connection"  // WebSocket 连接、Socket.IO 会话和 socket 的 ID
+	IDEvent       IDKind = "event"       // Outbox 事件 ID
+)
+
+// IDSource 标识符来源。默认使用随机 ID；测试中用 SetIDSource 注入 SequentialIDs 得到稳定的
+// 标识符，也可以替换为 ULID、雪花 ID 等。会话 ID 用于鉴权，自定义来源需要保证不可猜测
+type IDSource interface {
+	NewID(kind IDKind) string
+}
+
+// IDSourceFunc 函数形式的 IDSource
+type IDSourceFunc func(kind IDKind) string
+
+// NewID 调用 f(kind)
+func (f IDSourceFunc) NewID(kind IDKind) string { return f(kind) }
+
+// RandomIDs 默认的随机标识符：会话 ID 为 256 位 base64url，其它为 128 位十六进制
+var RandomIDs IDSource = randomIDs{}
+
+type idSourceHolder struct{ IDSource }
+
+var currentIDSource atomic.Value
+
+func init() {
+	currentIDSource.Store(idSourceHolder{RandomIDs})
+}
+
+// SetIDSource 替换全局标识符来源，返回恢复原来源的函数：
+//
+//	defer meego.SetIDSource(meego.SequentialIDs())()
+func SetIDSource(src IDSource) (restore func()) {
+	if src == nil {
+		src = RandomIDs
+	}
+	prev := currentIDSource.Swap(idSourceHolder{src}).(idSourceHolder)
+	return func() { currentIDSource.Store(prev) }
+}
+
+// newID 从当前来源生成标识符
+func newID(kind IDKind) string {
+	return currentIDSource.Load().(idSourceHolder).NewID(kind)
+}
+
+type randomIDs struct{}
+
+func (randomIDs) NewID(kind IDKind) string {
+	if kind == IDSession {
+		var b [32]byte
+		rand.Read(b[:])
+		return base64.RawURLEncoding.EncodeToString(b[:])
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// SequenceSource 按用途分别计数的确定性标识符，如 "request-1"、"session-1"
+type SequenceSource struct {
+	mu   sync.Mutex
+	next map[IDKind]int
+}
+
+// SequentialIDs 创建从 1 开始计数的确定性标识符来源，用于测试
+func SequentialIDs() *SequenceSource {
+	return &SequenceSource{next: make(map[IDKind]int)}
+}
+
+// NewID 返回 "<kind>-<序号>"
+func (s *SequenceSource) NewID(kind IDKind) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next[kind]++
+	return fmt.Sprintf("%s-%d", kind, s.next[kind])
+}
+
+// Reset 重新从 1 开始计数
+func (s *SequenceSource) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next = make(map[IDKind]int)
+}
@@ -0,0 +1,24 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIDSource(t *testing.T) {
+	defer SetIDSource(SequentialIDs())()
+	s := New()
+	s.Use(RequestID())
+	s.GET("/id", func(c *Context) { c.String(StatusOK, c.RequestID()) })
+
+	resp := doRaw(s, "GET /id HTTP/1.1\r\n\r\nGET /id HTTP/1.1\r\nConnection: close\r\n\r\n")
+	if !strings.Contains(resp, "X-Request-ID: request-1\r\n") || !strings.HasSuffix(resp, "request-2") {
+		t.Fatalf("sequential request ids:\n%q", resp)
+	}
+	if resp := doRaw(s, "GET /id HTTP/1.1\r\nX-Request-ID: upstream\r\n\r\n"); !strings.HasSuffix(resp, "upstream") {
+		t.Fatalf("incoming request id not kept: %q", resp)
+	}
+	if id := newID(IDSession); id != "session-1" {
+		t.Fatalf("session id %q", id)
+	}
+}
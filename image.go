@@ -0,0 +1,348 @@
+// image.go
+package meego
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/draw"
+)
+
+// ImageEncoder 图片编码器，quality 为 1-100
+type ImageEncoder func(w io.Writer, img image.Image, quality int) error
+
+// ImageConfig 图片处理配置
+type ImageConfig struct {
+	FS fs.FS // 原图目录，如 os.DirFS("./public/images")
+	// CacheDir 处理结果的磁盘缓存目录，为空时不缓存
+	CacheDir string
+	// CacheSize 磁盘缓存上限（字节），超过后按最近最少使用淘汰，默认 256MB
+	CacheSize int64
+	// MaxWidth/MaxHeight 允许请求的最大尺寸，默认 4096
+	MaxWidth  int
+	MaxHeight int
+	Quality   int // 默认 82
+	// Encoders 额外的输出格式，键为格式名（如 "webp"、"avif"），
+	// 内置 jpeg、png、gif。注册后按 Accept 头自动协商
+	Encoders map[string]ImageEncoder
+}
+
+// imageContentTypes 格式名到媒体类型
+var imageContentTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"avif": "image/avif",
+}
+
+// Images 注册图片处理路由 prefix/*filepath。查询参数：
+//   - w、h：目标宽高，只指定一个时按比例计算；
+//   - fit：contain（默认，完整显示）、cover（裁剪填满）、fill（拉伸）；
+//   - format：jpeg、png、gif 或已注册的格式，为空时按 Accept 协商 avif/webp，否则保持原格式。
+//
+// 没有任何参数时直接返回原图
+func (s *HTTPServer) Images(prefix string, cfg ImageConfig) *Route {
+	h := newImageHandler(cfg)
+	return s.GET(strings.TrimRight(prefix, "/")+"/*filepath", h.serve)
+}
+
+type imageHandler struct {
+	cfg      ImageConfig
+	encoders map[string]ImageEncoder
+	cache    *diskLRU
+}
+
+func newImageHandler(cfg ImageConfig) *imageHandler {
+	if cfg.MaxWidth <= 0 {
+		cfg.MaxWidth = 4096
+	}
+	if cfg.MaxHeight <= 0 {
+		cfg.MaxHeight = 4096
+	}
+	if cfg.Quality <= 0 || cfg.Quality > 100 {
+		cfg.Quality = 82
+	}
+	if cfg.CacheSize <= 0 {
+		cfg.CacheSize = 256 << 20
+	}
+
+	h := &imageHandler{
+		cfg: cfg,
+		encoders: map[string]ImageEncoder{
+			"jpeg": func(w io.Writer, img image.Image, q int) error {
+				return jpeg.Encode(w, img, &jpeg.Options{Quality: q})
+			},
+			"png": func(w io.Writer, img image.Image, q int) error { return png.Encode(w, img) },
+			"gif": func(w io.Writer, img image.Image, q int) error { return gif.Encode(w, img, nil) },
+		},
+	}
+	for name, enc := range cfg.Encoders {
+		h.encoders[name] = enc
+	}
+	if cfg.CacheDir != "" {
+		h.cache = newDiskLRU(cfg.CacheDir, cfg.CacheSize)
+	}
+	return h
+}
+
+func (h *imageHandler) serve(c *Context) {
+	name := strings.TrimPrefix(path.Clean(c.Param("filepath")), "/")
+	if !fs.ValidPath(name) {
+		notFoundHandler(c)
+		return
+	}
+	src, err := fs.ReadFile(h.cfg.FS, name)
+	if err != nil {
+		notFoundHandler(c)
+		return
+	}
+
+	width, _ := strconv.Atoi(c.Query("w"))
+	height, _ := strconv.Atoi(c.Query("h"))
+	fit := c.QueryDefault("fit", "contain")
+	format := c.Query("format")
+	if width < 0 || height < 0 || width > h.cfg.MaxWidth || height > h.cfg.MaxHeight ||
+		(fit != "contain" && fit != "cover" && fit != "fill") ||
+		(format != "" && h.encoders[format] == nil) {
+		c.Writer.Status(StatusBadRequest).JSON(JSON{"error": "invalid image parameters", "code": StatusBadRequest})
+		return
+	}
+	if format == "" {
+		format = h.negotiate(c.Request.GetHeader("Accept"))
+		c.Writer.AddVary("Accept")
+	}
+	if width == 0 && height == 0 && format == "" {
+		c.Writer.SetHeader("Cache-Control", "public, max-age=86400")
+		c.Data(StatusOK, "", src)
+		return
+	}
+
+	sum := sha256.Sum256(src)
+	key := hex.EncodeToString(sum[:8]) + "-" + fmt.Sprintf("%dx%d-%s-%s-q%d", width, height, fit, format, h.cfg.Quality)
+	etag := `"` + key + `"`
+	c.Writer.SetHeader("ETag", etag)
+	c.Writer.SetHeader("Cache-Control", "public, max-age=86400")
+	if c.Request.GetHeader("If-None-Match") == etag {
+		c.Writer.Status(StatusNotModified).String("")
+		return
+	}
+
+	if h.cache != nil {
+		if data, ok := h.cache.get(key); ok {
+			// 缓存键中的格式可能为空（保持原格式），此时嗅探内容类型
+			c.Data(StatusOK, imageContentTypes[format], data)
+			return
+		}
+	}
+
+	img, srcFormat, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		c.Writer.Status(StatusUnsupportedMediaType).JSON(JSON{"error": "unsupported image", "code": StatusUnsupportedMediaType})
+		return
+	}
+	if format == "" {
+		format = srcFormat
+		if h.encoders[format] == nil {
+			format = "png"
+		}
+	}
+	img = resizeImage(img, width, height, fit)
+
+	var buf bytes.Buffer
+	if err := h.encoders[format](&buf, img, h.cfg.Quality); err != nil {
+		c.Logger().Error().Err(err).Str("image", name).Msg("image encode failed")
+		c.Writer.Status(StatusInternalServerError).JSON(JSON{"error": "Internal Server Error", "code": StatusInternalServerError})
+		return
+	}
+	if h.cache != nil {
+		h.cache.put(key, buf.Bytes())
+	}
+	c.Data(StatusOK, imageContentTypes[format], buf.Bytes())
+}
+
+// negotiate 按 Accept 选择已注册的 avif/webp 编码器，都不可用时返回空（保持原格式）
+func (h *imageHandler) negotiate(accept string) string {
+	for _, format := range []string{"avif", "webp"} {
+		if h.encoders[format] != nil && strings.Contains(accept, imageContentTypes[format]) {
+			return format
+		}
+	}
+	return ""
+}
+
+// resizeImage 按 fit 模式缩放；宽高只给出一个时按比例计算，都为 0 时不缩放
+func resizeImage(img image.Image, width, height int, fit string) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if (width == 0 && height == 0) || sw == 0 || sh == 0 {
+		return img
+	}
+	if width == 0 {
+		width = sw * height / sh
+	}
+	if height == 0 {
+		height = sh * width / sw
+	}
+
+	srcRect := b
+	dw, dh := width, height
+	switch fit {
+	case "contain":
+		// 缩放到能完整放入目标框
+		if sw*height > sh*width {
+			dh = sh * width / sw
+		} else {
+			dw = sw * height / sh
+		}
+	case "cover":
+		// 从中间裁剪出与目标框相同宽高比的区域
+		if sw*height > sh*width {
+			cw := sh * width / height
+			x := b.Min.X + (sw-cw)/2
+			srcRect = image.Rect(x, b.Min.Y, x+cw, b.Max.Y)
+		} else {
+			ch := sw * height / width
+			y := b.Min.Y + (sh-ch)/2
+			srcRect = image.Rect(b.Min.X, y, b.Max.X, y+ch)
+		}
+	}
+	if dw < 1 {
+		dw = 1
+	}
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, srcRect, draw.Over, nil)
+	return dst
+}
+
+// diskLRU 按总大小淘汰的磁盘缓存
+type diskLRU struct {
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64
+	order   *list.List // 最近使用的在前，元素为 key
+	entries map[string]*list.Element
+	sizes   map[string]int64
+}
+
+func newDiskLRU(dir string, maxSize int64) *diskLRU {
+	c := &diskLRU{
+		dir:     dir,
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		sizes:   make(map[string]int64),
+	}
+	os.MkdirAll(dir, 0755)
+
+	// 加载已有的缓存文件，按修改时间从旧到新排列
+	files, _ := os.ReadDir(dir)
+	infos := make([]fs.FileInfo, 0, len(files))
+	for _, f := range files {
+		if info, err := f.Info(); err == nil && info.Mode().IsRegular() && !strings.HasPrefix(info.Name(), ".tmp") {
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].ModTime().Before(infos[j].ModTime()) })
+	for _, info := range infos {
+		c.entries[info.Name()] = c.order.PushFront(info.Name())
+		c.sizes[info.Name()] = info.Size()
+		c.size += info.Size()
+	}
+	c.evictLocked()
+	return c
+}
+
+func (c *diskLRU) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	el, ok := c.entries[key]
+	if ok {
+		c.order.MoveToFront(el)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		c.remove(key)
+		return nil, false
+	}
+	return data, true
+}
+
+func (c *diskLRU) put(key string, data []byte) {
+	// 先写临时文件再重命名，并发读取不会看到写了一半的文件
+	tmp, err := os.CreateTemp(c.dir, ".tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		c.size -= c.sizes[key]
+	} else {
+		c.entries[key] = c.order.PushFront(key)
+	}
+	c.sizes[key] = int64(len(data))
+	c.size += int64(len(data))
+	c.evictLocked()
+}
+
+func (c *diskLRU) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.removeLocked(el)
+	}
+}
+
+func (c *diskLRU) evictLocked() {
+	for c.size > c.maxSize && c.order.Len() > 0 {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+func (c *diskLRU) removeLocked(el *list.Element) {
+	key := el.Value.(string)
+	c.order.Remove(el)
+	delete(c.entries, key)
+	c.size -= c.sizes[key]
+	delete(c.sizes, key)
+	os.Remove(filepath.Join(c.dir, key))
+}
@@ -0,0 +1,73 @@
+package meego
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestImageResize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			src.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, src)
+
+	cacheDir := t.TempDir()
+	s := New()
+	s.Images("/img", ImageConfig{
+		FS:       fstest.MapFS{"photo.png": {Data: buf.Bytes()}},
+		CacheDir: cacheDir,
+		Encoders: map[string]ImageEncoder{
+			// 测试用的假 WebP 编码器，验证内容协商
+			"webp": func(w io.Writer, img image.Image, q int) error {
+				_, err := io.WriteString(w, "webp")
+				return err
+			},
+		},
+	})
+
+	decode := func(resp string) image.Image {
+		_, body, _ := strings.Cut(resp, "\r\n\r\n")
+		img, _, err := image.Decode(strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("decode %q: %v", resp, err)
+		}
+		return img
+	}
+
+	for query, want := range map[string]image.Point{
+		"w=100":                 {100, 50},
+		"w=100&h=100":           {100, 50},
+		"w=100&h=100&fit=cover": {100, 100},
+		"h=40&fit=fill":         {80, 40},
+	} {
+		resp := doRaw(s, "GET /img/photo.png?"+query+" HTTP/1.1\r\n\r\n")
+		if size := decode(resp).Bounds().Size(); size != want {
+			t.Errorf("%s: got %v, want %v", query, size, want)
+		}
+	}
+
+	resp := doRaw(s, "GET /img/photo.png?w=50 HTTP/1.1\r\nAccept: image/avif,image/webp,*/*\r\n\r\n")
+	if !strings.Contains(resp, "Content-Type: image/webp") || !strings.HasSuffix(resp, "webp") {
+		t.Fatalf("expected negotiated webp: %q", resp)
+	}
+	if resp := doRaw(s, "GET /img/../secret.png HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 404") {
+		t.Fatalf("expected 404: %q", resp)
+	}
+	if resp := doRaw(s, "GET /img/photo.png?w=99999 HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 400") {
+		t.Fatalf("expected 400: %q", resp)
+	}
+	if files, _ := os.ReadDir(cacheDir); len(files) != 5 {
+		t.Fatalf("expected 5 cached variants, got %d", len(files))
+	}
+}
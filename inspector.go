@@ -0,0 +1,147 @@
+// inspector.go
+package meego
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RequestInfo 请求快照
+type RequestInfo struct {
+	ID       uint64        `json:"id"`
+	Method   string        `json:"method"`
+	Path     string        `json:"path"`
+	Route    string        `json:"route"`
+	Client   string        `json:"client"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration_ns"` // 进行中的请求为已耗时
+	Status   int           `json:"status,omitempty"`
+}
+
+// Inspector 记录进行中的请求和最近完成请求中最慢的部分，用于排查卡住的实例
+type Inspector struct {
+	mu       sync.Mutex
+	seq      uint64
+	inflight map[uint64]*RequestInfo
+
+	// 最近完成请求的环形缓冲
+	recent []RequestInfo
+	next   int
+	filled bool
+}
+
+// NewInspector 创建请求检查器，size 为保留的最近完成请求数
+func NewInspector(size int) *Inspector {
+	if size <= 0 {
+		size = 256
+	}
+	return &Inspector{
+		inflight: make(map[uint64]*RequestInfo, 64),
+		recent:   make([]RequestInfo, size),
+	}
+}
+
+// EnableInspector 注册请求检查中间件和调试端点（如 /debug/requests）。端点公开客户端 IP 和
+// 进行中的请求路径，必须通过 middlewares（如 BasicAuth、APIKeyAuth）保护；
+// 也可以只使用 Middleware，把 Handler 注册到受保护的路由组中
+func (s *HTTPServer) EnableInspector(path string, middlewares ...MiddlewareFunc) *Inspector {
+	in := NewInspector(256)
+	s.Use(in.Middleware())
+	s.Group("", middlewares...).GET(path, in.Handler())
+	if len(middlewares) == 0 {
+		log.Warn().Str("path", path).Msg("inspector endpoint registered without middleware: protect it with authentication")
+	}
+	return in
+}
+
+// Middleware 返回跟踪请求的中间件
+func (in *Inspector) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			info := &RequestInfo{
+				Method: c.Request.Method,
+				Path:   c.Request.URL.Path,
+				Route:  c.FullPath(),
+				Client: clientIPKey(c),
+				Start:  time.Now(),
+			}
+
+			in.mu.Lock()
+			in.seq++
+			info.ID = in.seq
+			in.inflight[info.ID] = info
+			in.mu.Unlock()
+
+			defer func() {
+				// info 可能正被 InFlight 读取，在锁内写入完成字段
+				duration, status := time.Since(info.Start), c.Writer.status
+
+				in.mu.Lock()
+				delete(in.inflight, info.ID)
+				info.Duration = duration
+				info.Status = status
+				in.recent[in.next] = *info
+				in.next++
+				if in.next == len(in.recent) {
+					in.next = 0
+					in.filled = true
+				}
+				in.mu.Unlock()
+			}()
+
+			next(c)
+		}
+	}
+}
+
+// InFlight 返回进行中的请求，按开始时间从早到晚排序
+func (in *Inspector) InFlight() []RequestInfo {
+	now := time.Now()
+
+	in.mu.Lock()
+	result := make([]RequestInfo, 0, len(in.inflight))
+	for _, info := range in.inflight {
+		r := *info
+		r.Duration = now.Sub(r.Start)
+		result = append(result, r)
+	}
+	in.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Start.Before(result[j].Start)
+	})
+	return result
+}
+
+// Slowest 返回最近完成的请求中最慢的 n 个
+func (in *Inspector) Slowest(n int) []RequestInfo {
+	in.mu.Lock()
+	count := in.next
+	if in.filled {
+		count = len(in.recent)
+	}
+	result := make([]RequestInfo, count)
+	copy(result, in.recent[:count])
+	in.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Duration > result[j].Duration
+	})
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// Handler 调试端点，?n= 指定返回的慢请求数（默认 20）
+func (in *Inspector) Handler() HandlerFunc {
+	return func(c *Context) {
+		c.JSON(StatusOK, JSON{
+			"in_flight": in.InFlight(),
+			"slowest":   in.Slowest(c.QueryIntDefault("n", 20)),
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInspector(t *testing.T) {
+	s := New()
+	in := s.EnableInspector("/debug/requests", APIKeyAuth("", func(key string) bool { return key == "ops" }))
+	entered, release := make(chan struct{}), make(chan struct{})
+	s.GET("/slow", func(c *Context) {
+		close(entered)
+		<-release
+		c.String(StatusOK, "ok")
+	})
+
+	done := make(chan string)
+	go func() { done <- doRaw(s, "GET /slow HTTP/1.1\r\nConnection: close\r\n\r\n") }()
+	<-entered
+	if got := in.InFlight(); len(got) != 1 || got[0].Route != "/slow" {
+		t.Fatalf("unexpected in-flight requests: %+v", got)
+	}
+
+	// 请求结束与读取快照并发进行
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				in.InFlight()
+			}
+		}
+	}()
+	close(release)
+	<-done
+	close(stop)
+
+	if got := in.Slowest(1); len(got) != 1 || got[0].Status != StatusOK || got[0].Duration <= 0 {
+		t.Fatalf("unexpected finished requests: %+v", got)
+	}
+	if got := in.InFlight(); len(got) != 0 {
+		t.Fatalf("request still in flight: %+v", got)
+	}
+
+	// 端点经过注册时传入的中间件
+	if resp := doRaw(s, "GET /debug/requests HTTP/1.1\r\nConnection: close\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 401") {
+		t.Fatalf("unauthenticated inspector request:\n%s", resp)
+	}
+	if resp := doRaw(s, "GET /debug/requests HTTP/1.1\r\nX-API-Key: ops\r\nConnection: close\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.Contains(resp, `"/slow"`) {
+		t.Fatalf("authenticated inspector request:\n%s", resp)
+	}
+}
@@ -0,0 +1,56 @@
+// json_etag.go
+package meego
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// SetJSONETag 为所有 JSON 响应计算 ETag：GET/HEAD 的 200 响应按序列化后的内容生成 ETag，
+// 与请求的 If-None-Match 匹配时直接返回 304，不发送响应体。只对部分路由启用时使用 JSONETag 中间件
+func (s *HTTPServer) SetJSONETag(enabled bool) {
+	s.jsonETag.Store(enabled)
+}
+
+// JSONETag 为之后的处理器的 JSON 响应启用 ETag，见 SetJSONETag
+func JSONETag() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Writer.jsonETag = true
+			next(c)
+		}
+	}
+}
+
+// applyJSONETag 设置 JSON 响应的 ETag，If-None-Match 匹配时返回 true，响应改为 304。
+// 处理器已经设置 ETag 时不覆盖，仍按它判断是否匹配
+func (w *ResponseWriter) applyJSONETag(body []byte) bool {
+	if !w.jsonETag || w.status != StatusOK || (w.method != "GET" && w.method != "HEAD") {
+		return false
+	}
+	etag := w.header["ETag"]
+	if etag == "" {
+		h := fnv.New64a()
+		h.Write(body)
+		etag = `"` + strconv.FormatUint(h.Sum64(), 36) + `"`
+		w.header["ETag"] = etag
+	}
+	if w.ifNoneMatch == "" || !etagMatches(w.ifNoneMatch, etag) {
+		return false
+	}
+	w.status = StatusNotModified
+	return true
+}
+
+// etagMatches If-None-Match 是否匹配 etag，使用弱比较（RFC 9110 13.1.2）
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
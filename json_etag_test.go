@@ -0,0 +1,31 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONETag(t *testing.T) {
+	s := New()
+	s.GET("/items", func(c *Context) { c.JSON(StatusOK, JSON{"items": []int{1, 2, 3}}) })
+	s.POST("/items", func(c *Context) { c.JSON(StatusOK, JSON{"ok": true}) })
+
+	if resp := doRaw(s, "GET /items HTTP/1.1\r\n\r\n"); strings.Contains(resp, "ETag") {
+		t.Fatalf("ETag should be opt-in: %q", resp)
+	}
+	s.SetJSONETag(true)
+	resp := doRaw(s, "GET /items HTTP/1.1\r\n\r\n")
+	_, rest, _ := strings.Cut(resp, "ETag: ")
+	etag, _, _ := strings.Cut(rest, "\r\n")
+	if !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("missing ETag: %q", resp)
+	}
+
+	resp = doRaw(s, "GET /items HTTP/1.1\r\nIf-None-Match: \"other\", W/"+etag+"\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 304") || !strings.HasSuffix(resp, "\r\n\r\n") || strings.Contains(resp, "items") {
+		t.Fatalf("expected 304: %q", resp)
+	}
+	if resp := doRaw(s, "POST /items HTTP/1.1\r\nIf-None-Match: *\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 200") || strings.Contains(resp, "ETag") {
+		t.Fatalf("POST should not use ETag: %q", resp)
+	}
+}
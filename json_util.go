@@ -9,6 +9,9 @@ import (
 
 // BindJSON 绑定 JSON 请求体到结构体
 func (c *Context) BindJSON(v interface{}) error {
+	if _, err := c.Request.ReadBody(); err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
 	if len(c.Request.Body) == 0 {
 		return fmt.Errorf("empty request body")
 	}
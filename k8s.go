@@ -0,0 +1,117 @@
+// k8s.go
+package meego
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// KubernetesConfig Kubernetes 部署预设配置
+type KubernetesConfig struct {
+	Addr          string // 监听地址，默认 ":" + $PORT，未设置 PORT 时为 ":8080"
+	LivenessPath  string // 默认 /livez
+	ReadinessPath string // 默认 /readyz
+	MetricsPath   string // 默认 /metrics，设置为 "-" 关闭
+	LogLevel      string // 默认 info
+
+	// PreStopDelay 收到 SIGTERM 后继续服务的时间，等待 Endpoints 摘除实例，默认 5s
+	PreStopDelay time.Duration
+	// ShutdownTimeout 等待进行中请求完成的时间，默认 20s；
+	// PreStopDelay + ShutdownTimeout 应小于 terminationGracePeriodSeconds
+	ShutdownTimeout time.Duration
+
+	// ReadinessChecks 就绪检查，任一失败时就绪探针返回 503
+	ReadinessChecks map[string]func(ctx context.Context) error
+}
+
+// NewForKubernetes 创建适合 Kubernetes 的服务器：
+//   - 存活/就绪探针端点，排空期间就绪探针返回 503
+//   - SIGTERM/SIGINT 时排空、等待 PreStopDelay 后优雅关闭
+//   - 输出到 stdout 的 JSON 结构化日志
+//   - 请求 ID、panic 恢复和 Prometheus 指标
+func NewForKubernetes(cfgs ...KubernetesConfig) *HTTPServer {
+	var cfg KubernetesConfig
+	if len(cfgs) > 0 {
+		cfg = cfgs[0]
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = ":8080"
+		if port := os.Getenv("PORT"); port != "" {
+			cfg.Addr = ":" + port
+		}
+	}
+	if cfg.LivenessPath == "" {
+		cfg.LivenessPath = "/livez"
+	}
+	if cfg.ReadinessPath == "" {
+		cfg.ReadinessPath = "/readyz"
+	}
+	if cfg.MetricsPath == "" {
+		cfg.MetricsPath = "/metrics"
+	}
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = "info"
+	}
+	if cfg.PreStopDelay == 0 {
+		cfg.PreStopDelay = 5 * time.Second
+	}
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = 20 * time.Second
+	}
+
+	if err := SetupLogging(LogConfig{Sink: "stdout", Level: cfg.LogLevel}); err != nil {
+		log.Error().Err(err).Msg("setup logging failed")
+	}
+
+	s := NewHTTPServer(cfg.Addr)
+	s.Use(RequestID())
+	s.Use(Recovery())
+	if cfg.MetricsPath != "-" {
+		metrics := NewMetrics()
+		s.Use(metrics.Middleware())
+		s.GET(cfg.MetricsPath, metrics.Handler())
+	}
+
+	s.GET(cfg.LivenessPath, func(c *Context) {
+		c.JSON(StatusOK, JSON{"status": "ok"})
+	})
+	s.GET(cfg.ReadinessPath, func(c *Context) {
+		if s.Draining() {
+			c.JSON(StatusServiceUnavailable, JSON{"status": "draining"})
+			return
+		}
+		failed := JSON{}
+		for name, check := range cfg.ReadinessChecks {
+			if err := check(c.StdContext()); err != nil {
+				failed[name] = err.Error()
+			}
+		}
+		if len(failed) > 0 {
+			c.JSON(StatusServiceUnavailable, JSON{"status": "not ready", "checks": failed})
+			return
+		}
+		c.JSON(StatusOK, JSON{"status": "ok"})
+	})
+
+	s.OnStart(func(net.Addr) error {
+		go func() {
+			sig := make(chan os.Signal, 1)
+			signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+			defer signal.Stop(sig)
+			select {
+			case received := <-sig:
+				log.Info().Str("signal", received.String()).Msg("draining before shutdown")
+				s.GracefulShutdown(cfg.PreStopDelay, cfg.ShutdownTimeout)
+			case <-s.serverCtx.Done():
+			}
+		}()
+		return nil
+	})
+	return s
+}
@@ -0,0 +1,28 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKubernetesPreset(t *testing.T) {
+	s := NewForKubernetes(KubernetesConfig{LogLevel: "warn"})
+	s.GET("/users/:id", func(c *Context) { c.String(StatusOK, "ok") })
+
+	if resp := doRaw(s, "GET /readyz HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 200") {
+		t.Fatalf("expected ready: %q", resp)
+	}
+	doRaw(s, "GET /users/1 HTTP/1.1\r\n\r\n")
+	resp := doRaw(s, "GET /metrics HTTP/1.1\r\n\r\n")
+	if !strings.Contains(resp, `meego_http_requests_total{method="GET",route="/users/:id",status="200"} 1`) {
+		t.Fatalf("missing request metric: %q", resp)
+	}
+
+	s.Drain()
+	if resp := doRaw(s, "GET /readyz HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 503") {
+		t.Fatalf("expected not ready while draining: %q", resp)
+	}
+	if resp := doRaw(s, "GET /livez HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 200") {
+		t.Fatalf("liveness must not depend on draining: %q", resp)
+	}
+}
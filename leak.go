@@ -0,0 +1,87 @@
+// leak.go
+package meego
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// TestMode 下的对象池和协程泄漏统计
+var leakStats struct {
+	contexts    int64 // 未归还的 Context
+	writers     int64 // 未归还的 ResponseWriter
+	requests    int64 // 未归还的 HTTPRequest
+	goroutines  int64 // 通过 c.Go 启动且尚未结束的协程
+	connections int64 // 正在处理的连接
+}
+
+// trackLeak 仅在 TestMode 下计数，避免影响生产热路径
+func trackLeak(counter *int64, delta int64) {
+	if isTestMode() {
+		atomic.AddInt64(counter, delta)
+	}
+}
+
+// LeakReport 未释放对象和协程的数量
+type LeakReport struct {
+	Contexts    int64
+	Writers     int64
+	Requests    int64
+	Goroutines  int64
+	Connections int64
+}
+
+// Clean 是否没有泄漏
+func (r LeakReport) Clean() bool {
+	return r == LeakReport{}
+}
+
+// CheckLeaks 返回当前未释放的对象和协程数量（仅 TestMode 下统计）
+func CheckLeaks() LeakReport {
+	return LeakReport{
+		Contexts:    atomic.LoadInt64(&leakStats.contexts),
+		Writers:     atomic.LoadInt64(&leakStats.writers),
+		Requests:    atomic.LoadInt64(&leakStats.requests),
+		Goroutines:  atomic.LoadInt64(&leakStats.goroutines),
+		Connections: atomic.LoadInt64(&leakStats.connections),
+	}
+}
+
+// TB testing.TB 的子集，避免在库代码中引入 testing 包
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// VerifyNoLeaks 在测试结束时断言没有泄漏，等待最多 1 秒让进行中的请求收尾：
+//
+//	meego.SetMode(meego.TestMode)
+//	defer meego.VerifyNoLeaks(t)
+func VerifyNoLeaks(t TB) {
+	t.Helper()
+	if !isTestMode() {
+		t.Errorf("meego: VerifyNoLeaks requires TestMode (current mode: %s)", Mode())
+		return
+	}
+
+	deadline := time.Now().Add(time.Second)
+	report := CheckLeaks()
+	for !report.Clean() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		report = CheckLeaks()
+	}
+	if !report.Clean() {
+		t.Errorf("meego: leaked pooled objects or goroutines: contexts=%d writers=%d requests=%d goroutines=%d connections=%d",
+			report.Contexts, report.Writers, report.Requests, report.Goroutines, report.Connections)
+	}
+}
+
+// Go 启动与请求相关的后台协程；TestMode 下会被 VerifyNoLeaks 跟踪。
+// 协程中不要继续使用 c，它在请求结束后会被放回对象池
+func (c *Context) Go(fn func()) {
+	trackLeak(&leakStats.goroutines, 1)
+	go func() {
+		defer trackLeak(&leakStats.goroutines, -1)
+		fn()
+	}()
+}
@@ -0,0 +1,21 @@
+package meego
+
+import (
+	"testing"
+)
+
+func TestNoPoolLeaks(t *testing.T) {
+	SetMode(TestMode)
+	defer SetMode(DebugMode)
+
+	s := New()
+	s.GET("/ok", func(c *Context) {
+		c.Go(func() {})
+		c.String(StatusOK, "ok")
+	})
+	doRaw(s, "GET /ok HTTP/1.1\r\nHost: x\r\n\r\n")
+	doRaw(s, "GET /missing HTTP/1.1\r\nHost: x\r\n\r\n")
+	doRaw(s, "BOGUS\r\n\r\n")
+
+	VerifyNoLeaks(t)
+}
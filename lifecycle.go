@@ -0,0 +1,94 @@
+// lifecycle.go
+package meego
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// lifecycle 服务器生命周期状态，HTTP 服务和辅助模块（探针、服务注册等）共享
+type lifecycle struct {
+	draining   atomic.Bool
+	onStart    []func(addr net.Addr) error
+	onShutdown []func()
+	longLived  longLivedSet // WebSocket、SSE 等长连接，见 drain.go
+}
+
+// OnStart 注册启动回调，在 Start 开始监听之后、接受连接之前执行；
+// 回调返回错误时服务器停止启动
+func (s *HTTPServer) OnStart(fn func(addr net.Addr) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lifecycle.onStart = append(s.lifecycle.onStart, fn)
+}
+
+// runStartHooks 执行启动回调
+func (s *HTTPServer) runStartHooks(addr net.Addr) error {
+	s.mu.RLock()
+	hooks := s.lifecycle.onStart
+	s.mu.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Drain 进入排空状态：继续处理请求，但健康探针报告不可用，
+// 让负载均衡器停止分配新流量。Shutdown 会自动进入排空状态
+func (s *HTTPServer) Drain() {
+	s.lifecycle.draining.Store(true)
+}
+
+// Draining 是否处于排空状态
+func (s *HTTPServer) Draining() bool {
+	return s.lifecycle.draining.Load()
+}
+
+// OnShutdown 注册关闭回调，在 Shutdown 时按注册的逆序执行
+func (s *HTTPServer) OnShutdown(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lifecycle.onShutdown = append(s.lifecycle.onShutdown, fn)
+}
+
+// runShutdownHooks 执行关闭回调
+func (s *HTTPServer) runShutdownHooks() {
+	s.mu.Lock()
+	hooks := s.lifecycle.onShutdown
+	s.lifecycle.onShutdown = nil
+	s.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+}
+
+// GracefulShutdown 优雅关闭：进入排空状态，等待 preStopDelay 让负载均衡器摘除实例，
+// 然后停止接受新连接，等待进行中的连接处理完毕（最多 timeout），最后 Shutdown
+func (s *HTTPServer) GracefulShutdown(preStopDelay, timeout time.Duration) {
+	s.Drain()
+	if preStopDelay > 0 {
+		time.Sleep(preStopDelay)
+	}
+
+	s.mu.RLock()
+	for ln := range s.listeners {
+		ln.Close()
+	}
+	s.mu.RUnlock()
+
+	deadline := time.Now().Add(timeout)
+	for s.pool.Running() > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if n := s.pool.Running(); n > 0 {
+		log.Warn().Int("connections", n).Msg("shutdown timeout, closing with active connections")
+	}
+	s.Shutdown()
+}
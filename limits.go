@@ -0,0 +1,68 @@
+// limits.go
+package meego
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 默认的请求头限制
+const (
+	DefaultMaxHeaderBytes = 1 << 20 // 请求行和全部请求头合计
+	DefaultMaxHeaderCount = 100
+	DefaultMaxURILength   = 8 << 10
+)
+
+var (
+	// ErrHeaderTooLarge 请求头总大小或数量超过上限，返回 431
+	ErrHeaderTooLarge = errors.New("request header too large")
+	// ErrURITooLong 请求 URI 超过上限，返回 414
+	ErrURITooLong = errors.New("request URI too long")
+)
+
+// Limits 解析器对单个请求的限制，0 表示使用默认值，负数表示不限制。
+// 超过请求头限制返回 431，URI 过长返回 414，请求体过大返回 413
+type Limits struct {
+	// MaxHeaderBytes 请求行和全部请求头的总字节数，默认 1MB
+	MaxHeaderBytes int
+	// MaxHeaderCount 请求头个数，默认 100
+	MaxHeaderCount int
+	// MaxURILength 请求 URI 的长度，默认 8KB
+	MaxURILength int
+	// MaxBodyBytes 请求体上限，默认 DefaultMaxBodySize；
+	// SetBodyLimits 和路由 MaxBodySize 的设置优先
+	MaxBodyBytes int64
+}
+
+// SetLimits 设置请求大小限制，对之后建立的连接生效
+func (s *HTTPServer) SetLimits(limits Limits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits
+}
+
+// withDefaults 填充默认值，负数转换为 0（不限制）
+func (l Limits) withDefaults() Limits {
+	l.MaxHeaderBytes = limitOrDefault(l.MaxHeaderBytes, DefaultMaxHeaderBytes)
+	l.MaxHeaderCount = limitOrDefault(l.MaxHeaderCount, DefaultMaxHeaderCount)
+	l.MaxURILength = limitOrDefault(l.MaxURILength, DefaultMaxURILength)
+	if l.MaxBodyBytes == 0 {
+		l.MaxBodyBytes = DefaultMaxBodySize
+	}
+	return l
+}
+
+func limitOrDefault(n, def int) int {
+	switch {
+	case n == 0:
+		return def
+	case n < 0:
+		return 0
+	}
+	return n
+}
+
+// errHeaderTooLarge 带有具体原因的 ErrHeaderTooLarge
+func errHeaderTooLarge(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrHeaderTooLarge}, args...)...)
+}
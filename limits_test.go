@@ -0,0 +1,28 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	s := New()
+	s.SetLimits(Limits{MaxHeaderBytes: 256, MaxHeaderCount: 3, MaxURILength: 32, MaxBodyBytes: 8})
+	s.POST("/p", func(c *Context) { c.String(StatusOK, "ok") })
+	s.POST("/big", func(c *Context) { c.String(StatusOK, "ok") }).MaxBodySize(64)
+
+	cases := []struct{ raw, status string }{
+		{"POST /p HTTP/1.1\r\nA: 1\r\nB: 2\r\nContent-Length: 2\r\n\r\nok", "200"},
+		{"POST /p HTTP/1.1\r\nA: 1\r\nB: 2\r\nC: 3\r\nD: 4\r\n\r\n", "431"},
+		{"POST /p HTTP/1.1\r\nA: " + strings.Repeat("x", 300) + "\r\n\r\n", "431"},
+		{"POST /p?q=" + strings.Repeat("x", 40) + " HTTP/1.1\r\n\r\n", "414"},
+		{"POST /p?q=" + strings.Repeat("x", 300) + " HTTP/1.1\r\n\r\n", "414"},
+		{"POST /p HTTP/1.1\r\nContent-Length: 10\r\n\r\n0123456789", "413"},
+		{"POST /big HTTP/1.1\r\nContent-Length: 10\r\n\r\n0123456789", "200"},
+	}
+	for _, tc := range cases {
+		if resp := doRaw(s, tc.raw); !strings.HasPrefix(resp, "HTTP/1.1 "+tc.status) {
+			t.Errorf("%.40q: expected %s, got %q", tc.raw, tc.status, resp)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// list_params.go
+package meego
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SortField 排序字段
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// ListParams 列表接口的分页、排序和过滤参数
+type ListParams struct {
+	// Page 页码，从 1 开始；使用 Cursor 时为 0
+	Page int
+	// Limit 每页条数
+	Limit int
+	// Offset 由 Page 和 Limit 计算的偏移量
+	Offset int
+	// Cursor 游标分页的游标，由上一页的响应给出
+	Cursor string
+	// Sort 排序字段，按优先级排列
+	Sort []SortField
+	// Filters filter[field]=value 形式的过滤条件
+	Filters map[string]string
+}
+
+// ListConfig 列表参数解析配置
+type ListConfig struct {
+	// DefaultLimit 默认每页条数，默认 20
+	DefaultLimit int
+	// MaxLimit 每页条数上限，超过时按上限处理，默认 100
+	MaxLimit int
+	// SortFields 允许排序的字段，为空时不限制
+	SortFields []string
+	// FilterFields 允许过滤的字段，为空时不限制
+	FilterFields []string
+}
+
+// ListParams 使用默认配置解析列表参数，见 ListParamsWithConfig
+func (c *Context) ListParams() (ListParams, error) {
+	return c.ListParamsWithConfig(ListConfig{})
+}
+
+// ListParamsWithConfig 按统一约定解析列表参数：
+//
+//	?page=2&limit=50                     页码分页
+//	?cursor=abc&limit=50                 游标分页，不能与 page 同时使用
+//	?sort=name:asc,created_at:desc       排序，方向默认 asc，可以重复 sort 参数
+//	?filter[status]=active               过滤
+//
+// 参数格式错误、字段不在允许列表中时返回 *QueryError，处理器通常以 400 响应
+func (c *Context) ListParamsWithConfig(cfg ListConfig) (ListParams, error) {
+	if cfg.DefaultLimit <= 0 {
+		cfg.DefaultLimit = 20
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = 100
+	}
+	if cfg.DefaultLimit > cfg.MaxLimit {
+		cfg.DefaultLimit = cfg.MaxLimit
+	}
+	p := ListParams{Limit: cfg.DefaultLimit}
+	if c.Request.URL == nil {
+		p.Page = 1
+		return p, nil
+	}
+	query := c.Request.URL.Query()
+
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return p, &QueryError{Message: "invalid limit: " + strconv.Quote(v)}
+		}
+		p.Limit = min(n, cfg.MaxLimit)
+	}
+
+	p.Cursor = query.Get("cursor")
+	if v := query.Get("page"); v != "" {
+		if p.Cursor != "" {
+			return p, &QueryError{Message: "page and cursor cannot be used together"}
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return p, &QueryError{Message: "invalid page: " + strconv.Quote(v)}
+		}
+		// 偏移量溢出为负数后会被直接用于 SQL 或切片下标
+		if n-1 > math.MaxInt/p.Limit {
+			return p, &QueryError{Message: "page out of range: " + strconv.Quote(v)}
+		}
+		p.Page = n
+	} else if p.Cursor == "" {
+		p.Page = 1
+	}
+	if p.Page > 0 {
+		p.Offset = (p.Page - 1) * p.Limit
+	}
+
+	for _, v := range query["sort"] {
+		for _, part := range strings.Split(v, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			field, dir, _ := strings.Cut(part, ":")
+			sort := SortField{Field: field}
+			switch strings.ToLower(dir) {
+			case "", "asc":
+			case "desc":
+				sort.Desc = true
+			default:
+				return p, &QueryError{Message: "invalid sort direction: " + strconv.Quote(part)}
+			}
+			if len(cfg.SortFields) > 0 && !containsString(cfg.SortFields, field) {
+				return p, &QueryError{Message: "unsupported sort field: " + strconv.Quote(field)}
+			}
+			p.Sort = append(p.Sort, sort)
+		}
+	}
+
+	for key, values := range query {
+		field, ok := strings.CutPrefix(key, "filter[")
+		if !ok {
+			continue
+		}
+		field, ok = strings.CutSuffix(field, "]")
+		if !ok || field == "" {
+			return p, &QueryError{Message: "invalid filter: " + strconv.Quote(key)}
+		}
+		if len(cfg.FilterFields) > 0 && !containsString(cfg.FilterFields, field) {
+			return p, &QueryError{Message: "unsupported filter field: " + strconv.Quote(field)}
+		}
+		if p.Filters == nil {
+			p.Filters = make(map[string]string)
+		}
+		p.Filters[field] = values[0]
+	}
+	return p, nil
+}
@@ -0,0 +1,41 @@
+package meego
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListParams(t *testing.T) {
+	s := New()
+	var got ListParams
+	s.GET("/users", func(c *Context) {
+		p, err := c.ListParamsWithConfig(ListConfig{MaxLimit: 50, SortFields: []string{"name", "created_at"}, FilterFields: []string{"status"}})
+		if err != nil {
+			c.JSON(StatusBadRequest, JSON{"error": err.Error(), "code": StatusBadRequest})
+			return
+		}
+		got = p
+		c.String(StatusOK, "ok")
+	})
+
+	doRaw(s, "GET /users?page=3&limit=500&sort=name,created_at:desc&filter%5Bstatus%5D=active HTTP/1.1\r\n\r\n")
+	want := ListParams{
+		Page: 3, Limit: 50, Offset: 100,
+		Sort:    []SortField{{Field: "name"}, {Field: "created_at", Desc: true}},
+		Filters: map[string]string{"status": "active"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	doRaw(s, "GET /users?cursor=abc HTTP/1.1\r\n\r\n")
+	if got.Page != 0 || got.Cursor != "abc" || got.Limit != 20 {
+		t.Fatalf("unexpected cursor params: %+v", got)
+	}
+
+	for _, q := range []string{"limit=x", "page=0", "page=184467440737095518&limit=50", "page=1&cursor=a", "sort=name:up", "sort=password", "filter%5Bsecret%5D=1"} {
+		if resp := doRaw(s, "GET /users?"+q+" HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 400") {
+			t.Fatalf("%s: expected 400: %q", q, resp)
+		}
+	}
+}
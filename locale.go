@@ -0,0 +1,130 @@
+// locale.go
+package meego
+
+import (
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// LocaleConfig 语言协商配置
+type LocaleConfig struct {
+	// Supported 支持的语言，如 {"en", "zh-CN", "ja"}，第一个为默认语言
+	Supported []string
+	// Query 显式指定语言的查询参数，如 "lang"，为空时不使用
+	Query string
+	// Cookie 显式指定语言的 Cookie，如 "lang"，为空时不使用
+	Cookie string
+}
+
+const localeKey = "meego.locale"
+
+// Locale 语言协商中间件：依次使用查询参数、Cookie 和 Accept-Language 选择语言，
+// 设置 Content-Language 响应头，之后可通过 c.Locale() 获取
+func Locale(cfg LocaleConfig) MiddlewareFunc {
+	if len(cfg.Supported) == 0 {
+		cfg.Supported = []string{"en"}
+	}
+	tags := make([]language.Tag, 0, len(cfg.Supported))
+	for _, s := range cfg.Supported {
+		tags = append(tags, language.Make(s))
+	}
+	matcher := language.NewMatcher(tags)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			var prefs []string
+			if cfg.Query != "" {
+				if v := c.Query(cfg.Query); v != "" {
+					prefs = append(prefs, v)
+				}
+			}
+			if cfg.Cookie != "" {
+				c.Writer.AddVary("Cookie")
+				if v, err := c.Cookie(cfg.Cookie); err == nil && v != "" {
+					prefs = append(prefs, v)
+				}
+			}
+			prefs = append(prefs, c.Request.GetHeader("Accept-Language"))
+
+			_, index := language.MatchStrings(matcher, prefs...)
+			c.Writer.AddVary("Accept-Language")
+			c.SetLocale(cfg.Supported[index])
+			next(c)
+		}
+	}
+}
+
+// SetLocale 设置本次请求的语言并更新 Content-Language 响应头
+func (c *Context) SetLocale(locale string) {
+	c.Set(localeKey, locale)
+	c.Writer.SetHeader("Content-Language", locale)
+}
+
+// Locale 返回本次请求协商的语言，未启用 Locale 中间件时返回 "en"
+func (c *Context) Locale() string {
+	if locale, ok := c.Get(localeKey).(string); ok {
+		return locale
+	}
+	return "en"
+}
+
+// FormatDate 按请求语言格式化日期
+func (c *Context) FormatDate(t time.Time) string {
+	return FormatDate(c.Locale(), t)
+}
+
+// FormatDateTime 按请求语言格式化日期和时间
+func (c *Context) FormatDateTime(t time.Time) string {
+	return FormatDateTime(c.Locale(), t)
+}
+
+// FormatNumber 按请求语言格式化数字（千分位、小数点）
+func (c *Context) FormatNumber(v interface{}) string {
+	return FormatNumber(c.Locale(), v)
+}
+
+// dateLayouts 日期、日期时间格式，键为语言或语言-地区
+var dateLayouts = map[string][2]string{
+	"en":    {"Jan 2, 2006", "Jan 2, 2006 3:04 PM"},
+	"en-GB": {"2 Jan 2006", "2 Jan 2006 15:04"},
+	"zh":    {"2006年1月2日", "2006年1月2日 15:04"},
+	"ja":    {"2006年1月2日", "2006年1月2日 15:04"},
+	"ko":    {"2006년 1월 2일", "2006년 1월 2일 15:04"},
+	"de":    {"02.01.2006", "02.01.2006 15:04"},
+	"fr":    {"02/01/2006", "02/01/2006 15:04"},
+	"es":    {"02/01/2006", "02/01/2006 15:04"},
+	"ru":    {"02.01.2006", "02.01.2006 15:04"},
+}
+
+// localeLayout 查找语言对应的格式，依次尝试 语言-地区、语言，最后使用英语
+func localeLayout(locale string, index int) string {
+	tag := language.Make(locale)
+	base, _ := tag.Base()
+	if region, conf := tag.Region(); conf == language.Exact {
+		if l, ok := dateLayouts[base.String()+"-"+region.String()]; ok {
+			return l[index]
+		}
+	}
+	if l, ok := dateLayouts[base.String()]; ok {
+		return l[index]
+	}
+	return dateLayouts["en"][index]
+}
+
+// FormatDate 按语言格式化日期，可用于 JSON 响应的预处理
+func FormatDate(locale string, t time.Time) string {
+	return t.Format(localeLayout(locale, 0))
+}
+
+// FormatDateTime 按语言格式化日期和时间
+func FormatDateTime(locale string, t time.Time) string {
+	return t.Format(localeLayout(locale, 1))
+}
+
+// FormatNumber 按语言格式化数字，如 en 下 1234567.5 -> "1,234,567.5"，de 下为 "1.234.567,5"
+func FormatNumber(locale string, v interface{}) string {
+	return message.NewPrinter(language.Make(locale)).Sprint(number.Decimal(v))
+}
@@ -0,0 +1,28 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLocaleNegotiation(t *testing.T) {
+	s := New()
+	s.Use(Locale(LocaleConfig{Supported: []string{"en", "zh-CN", "de"}, Query: "lang"}))
+	s.GET("/", func(c *Context) {
+		date := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+		c.String(StatusOK, c.FormatDate(date)+"|"+c.FormatNumber(1234567.5))
+	})
+
+	cases := []struct{ raw, lang, body string }{
+		{"GET / HTTP/1.1\r\nAccept-Language: zh-CN,zh;q=0.9\r\n\r\n", "zh-CN", "2024年3月9日|1,234,567.5"},
+		{"GET /?lang=de HTTP/1.1\r\nAccept-Language: zh-CN\r\n\r\n", "de", "09.03.2024|1.234.567,5"},
+		{"GET / HTTP/1.1\r\nAccept-Language: fr\r\n\r\n", "en", "Mar 9, 2024|1,234,567.5"},
+	}
+	for _, tc := range cases {
+		resp := doRaw(s, tc.raw)
+		if !strings.Contains(resp, "Content-Language: "+tc.lang+"\r\n") || !strings.HasSuffix(resp, tc.body) {
+			t.Errorf("want %s %q, got %q", tc.lang, tc.body, resp)
+		}
+	}
+}
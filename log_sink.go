@@ -0,0 +1,408 @@
+// log_sink.go
+package meego
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// LogConfig 日志配置，通过 SetupLogging 选择日志输出
+type LogConfig struct {
+	Sink  string // stdout（默认）、stderr、file、syslog、journald
+	Level string // zerolog 日志级别，如 debug、info、warn，默认不修改
+
+	// file
+	Filename   string
+	MaxSize    int64
+	MaxBackups int
+	MaxAge     time.Duration
+	Compress   bool
+
+	// syslog
+	SyslogNetwork string // udp、tcp、unixgram，为空时使用本地 /dev/log
+	SyslogAddress string
+	Facility      int // 默认 1（user-level）
+
+	AppName string // syslog APP-NAME / journald SYSLOG_IDENTIFIER，默认进程名
+}
+
+// NewLogSink 按配置创建日志输出
+func NewLogSink(cfg LogConfig) (io.Writer, error) {
+	switch strings.ToLower(cfg.Sink) {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	case "file":
+		if cfg.Filename == "" {
+			return nil, fmt.Errorf("log sink file: filename is required")
+		}
+		f := NewRotatingFile(cfg.Filename)
+		if cfg.MaxSize > 0 {
+			f.MaxSize = cfg.MaxSize
+		}
+		f.MaxBackups = cfg.MaxBackups
+		f.MaxAge = cfg.MaxAge
+		f.Compress = cfg.Compress
+		return f, nil
+	case "syslog":
+		return NewSyslogWriter(cfg.SyslogNetwork, cfg.SyslogAddress, cfg.Facility, cfg.AppName)
+	case "journald":
+		return NewJournaldWriter(cfg.AppName)
+	default:
+		return nil, fmt.Errorf("unknown log sink %q", cfg.Sink)
+	}
+}
+
+// SetupLogging 按配置设置全局 zerolog 日志
+func SetupLogging(cfg LogConfig) error {
+	w, err := NewLogSink(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.Level != "" {
+		level, err := zerolog.ParseLevel(cfg.Level)
+		if err != nil {
+			return err
+		}
+		zerolog.SetGlobalLevel(level)
+	}
+	log.Logger = zerolog.New(w).With().Timestamp().Logger()
+	return nil
+}
+
+//--------------------------------------------
+
+// logRecord 解析后的一条 zerolog JSON 日志
+type logRecord struct {
+	level   string
+	message string
+	fields  map[string]string
+}
+
+// parseLogRecord 解析 zerolog 输出的 JSON 行，非 JSON 内容整体作为消息
+func parseLogRecord(p []byte) logRecord {
+	rec := logRecord{level: "info"}
+
+	var raw map[string]interface{}
+	if err := jsoniter.Unmarshal(p, &raw); err != nil {
+		rec.message = strings.TrimRight(string(p), "\n")
+		return rec
+	}
+
+	rec.fields = make(map[string]string, len(raw))
+	for k, v := range raw {
+		var s string
+		switch val := v.(type) {
+		case string:
+			s = val
+		default:
+			b, _ := jsoniter.Marshal(val)
+			s = string(b)
+		}
+
+		switch k {
+		case zerolog.LevelFieldName:
+			rec.level = s
+		case zerolog.MessageFieldName:
+			rec.message = s
+		case zerolog.TimestampFieldName:
+			// 由各协议自带的时间戳表示
+		default:
+			rec.fields[k] = s
+		}
+	}
+	return rec
+}
+
+// sortedKeys 保证结构化字段输出顺序稳定
+func (rec logRecord) sortedKeys() []string {
+	keys := make([]string, 0, len(rec.fields))
+	for k := range rec.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// logSeverity zerolog 级别映射到 syslog severity
+func logSeverity(level string) int {
+	switch level {
+	case "panic":
+		return 0 // emerg
+	case "fatal":
+		return 2 // crit
+	case "error":
+		return 3
+	case "warn":
+		return 4
+	case "info":
+		return 6
+	case "debug", "trace":
+		return 7
+	default:
+		return 5 // notice
+	}
+}
+
+func defaultAppName(name string) string {
+	if name != "" {
+		return name
+	}
+	if len(os.Args) > 0 {
+		parts := strings.Split(os.Args[0], string(os.PathSeparator))
+		return parts[len(parts)-1]
+	}
+	return "meego"
+}
+
+//--------------------------------------------
+
+// SyslogWriter RFC 5424 syslog 输出，zerolog 字段写入 STRUCTURED-DATA
+type SyslogWriter struct {
+	network  string
+	address  string
+	facility int
+	appName  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// syslogSDID 结构化数据 ID（使用 IANA 保留给示例的企业号）
+const syslogSDID = "meego@32473"
+
+// NewSyslogWriter 创建 syslog 输出，network 为空时写入本地 /dev/log
+func NewSyslogWriter(network, address string, facility int, appName string) (*SyslogWriter, error) {
+	if network == "" {
+		network, address = "unixgram", "/dev/log"
+	}
+	if facility <= 0 {
+		facility = 1
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+
+	w := &SyslogWriter{
+		network:  network,
+		address:  address,
+		facility: facility,
+		appName:  defaultAppName(appName),
+		hostname: hostname,
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *SyslogWriter) connect() error {
+	conn, err := net.DialTimeout(w.network, w.address, 3*time.Second)
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	msg := w.format(parseLogRecord(p), time.Now())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if err := w.connect(); err != nil {
+			return 0, err
+		}
+	}
+	if err := w.send(msg); err != nil {
+		// 连接断开后重连一次
+		w.conn.Close()
+		w.conn = nil
+		if err := w.connect(); err != nil {
+			return 0, err
+		}
+		if err := w.send(msg); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (w *SyslogWriter) send(msg []byte) error {
+	if w.network == "tcp" || strings.HasPrefix(w.network, "tcp") {
+		// RFC 6587 octet-counting 分帧
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	_, err := w.conn.Write(msg)
+	return err
+}
+
+// Close 关闭连接
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// format <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG
+func (w *SyslogWriter) format(rec logRecord, t time.Time) []byte {
+	var b bytes.Buffer
+	pri := w.facility*8 + logSeverity(rec.level)
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %d - ", pri, t.Format(time.RFC3339Nano), w.hostname, w.appName, os.Getpid())
+
+	if len(rec.fields) == 0 {
+		b.WriteString("-")
+	} else {
+		b.WriteString("[" + syslogSDID)
+		for _, k := range rec.sortedKeys() {
+			fmt.Fprintf(&b, " %s=\"%s\"", syslogParamName(k), syslogEscape(rec.fields[k]))
+		}
+		b.WriteString("]")
+	}
+
+	if rec.message != "" {
+		b.WriteString(" ")
+		b.WriteString(rec.message)
+	}
+	return b.Bytes()
+}
+
+// syslogParamName PARAM-NAME 不允许包含 = ] " 和空格，且最长 32 字符
+func syslogParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r <= 32 || r >= 127 || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// syslogEscape PARAM-VALUE 中需要转义 " \ ]
+func syslogEscape(value string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	return r.Replace(value)
+}
+
+//--------------------------------------------
+
+// JournaldWriter systemd-journald 原生协议输出，zerolog 字段写为 journal 字段
+type JournaldWriter struct {
+	identifier string
+	addr       *net.UnixAddr
+
+	mu   sync.Mutex
+	conn *net.UnixConn
+}
+
+// journaldSocket journald 原生协议套接字
+const journaldSocket = "/run/systemd/journal/socket"
+
+// NewJournaldWriter 创建 journald 输出
+func NewJournaldWriter(identifier string) (*JournaldWriter, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(journaldSocket); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("journald not available: %v", err)
+	}
+
+	return &JournaldWriter{
+		identifier: defaultAppName(identifier),
+		addr:       &net.UnixAddr{Name: journaldSocket, Net: "unixgram"},
+		conn:       conn,
+	}, nil
+}
+
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	msg := w.format(parseLogRecord(p))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := w.conn.WriteToUnix(msg, w.addr); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close 关闭套接字
+func (w *JournaldWriter) Close() error {
+	return w.conn.Close()
+}
+
+func (w *JournaldWriter) format(rec logRecord) []byte {
+	var b bytes.Buffer
+	writeJournalField(&b, "MESSAGE", rec.message)
+	writeJournalField(&b, "PRIORITY", strconv.Itoa(logSeverity(rec.level)))
+	writeJournalField(&b, "SYSLOG_IDENTIFIER", w.identifier)
+	for _, k := range rec.sortedKeys() {
+		writeJournalField(&b, journalFieldName(k), rec.fields[k])
+	}
+	return b.Bytes()
+}
+
+// writeJournalField 写入一个字段，包含换行的值使用二进制长度前缀格式
+func writeJournalField(b *bytes.Buffer, name, value string) {
+	if !strings.ContainsRune(value, '\n') {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+
+	b.WriteString(name)
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
+
+// journalFieldName 字段名只允许大写字母、数字和下划线，且不能以下划线开头
+func journalFieldName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	name = strings.TrimLeft(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "F_" + name
+	}
+	return name
+}
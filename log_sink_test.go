@@ -0,0 +1,55 @@
+package meego
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSyslogWriter(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	w, err := NewSyslogWriter("udp", pc.LocalAddr().String(), 3, "api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	logger := zerolog.New(w)
+	logger.Warn().Str("route", "/a").Str("quote", `say "hi"]`).Msg("slow request")
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := string(buf[:n])
+	// facility 3 * 8 + warning 4
+	prefix := "<28>1 "
+	sd := ` api ` + strconv.Itoa(os.Getpid()) + ` - [meego@32473 quote="say \"hi\"\]" route="/a"] slow request`
+	if !strings.HasPrefix(msg, prefix) || !strings.HasSuffix(msg, sd) {
+		t.Fatalf("unexpected syslog message: %q", msg)
+	}
+}
+
+func TestJournaldFormat(t *testing.T) {
+	w := &JournaldWriter{identifier: "api"}
+	got := w.format(parseLogRecord([]byte(`{"level":"error","request-id":"r1","stack":"a\nb","time":"x","message":"boom"}` + "\n")))
+
+	want := "MESSAGE=boom\nPRIORITY=3\nSYSLOG_IDENTIFIER=api\nREQUEST_ID=r1\nSTACK\n\x03\x00\x00\x00\x00\x00\x00\x00a\nb\n"
+	if string(got) != want {
+		t.Fatalf("journald fields:\n%q\nwant\n%q", got, want)
+	}
+	if name := journalFieldName("_1st"); name != "F_1ST" {
+		t.Fatalf("journalFieldName: %q", name)
+	}
+}
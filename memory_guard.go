@@ -0,0 +1,172 @@
+// memory_guard.go
+package meego
+
+import (
+	"math"
+	"net"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MemoryGuardConfig 内存压力保护配置
+type MemoryGuardConfig struct {
+	// Limit 内存上限（字节），默认使用 GOMEMLIMIT；两者都未设置时不启用
+	Limit int64
+	// SoftLimit 同时把 Limit 设置为运行时的软内存上限（debug.SetMemoryLimit），
+	// 接近上限时 GC 更积极
+	SoftLimit bool
+	// HighWater 进入压力状态的阈值（Limit 的比例），默认 0.85
+	HighWater float64
+	// LowWater 退出压力状态的阈值，默认 0.7
+	LowWater float64
+	// Interval 采样间隔，默认 1s
+	Interval time.Duration
+	// MaxBody 压力状态下允许的最大请求体，更大的请求返回 503，默认 64KB
+	MaxBody int64
+}
+
+// memoryGuard 内存压力状态
+type memoryGuard struct {
+	cfg      MemoryGuardConfig
+	pressure atomic.Bool
+	sample   func() int64 // 当前内存用量，测试中可替换
+
+	mu      sync.Mutex
+	relieve []func()
+}
+
+// MemoryGuard 启用内存压力保护：定期采样运行时内存，超过 HighWater 后拒绝大请求体、
+// 清空路由缓存并执行 OnMemoryPressure 注册的回调（如收缩应用缓存），
+// 低于 LowWater 后恢复。应在 Start 之前调用
+func (s *HTTPServer) MemoryGuard(cfg MemoryGuardConfig) {
+	if cfg.Limit <= 0 {
+		if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+			cfg.Limit = limit
+		}
+	}
+	if cfg.Limit <= 0 {
+		log.Warn().Msg("memory guard disabled: no Limit or GOMEMLIMIT set")
+		return
+	}
+	if cfg.SoftLimit {
+		debug.SetMemoryLimit(cfg.Limit)
+	}
+	if cfg.HighWater <= 0 || cfg.HighWater > 1 {
+		cfg.HighWater = 0.85
+	}
+	if cfg.LowWater <= 0 || cfg.LowWater >= cfg.HighWater {
+		cfg.LowWater = cfg.HighWater * 0.8
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = 64 << 10
+	}
+
+	g := &memoryGuard{cfg: cfg, sample: memoryInUse}
+	s.mu.Lock()
+	s.memGuard = g
+	s.mu.Unlock()
+
+	s.OnStart(func(net.Addr) error {
+		stop := make(chan struct{})
+		go func() {
+			ticker := clock().NewTicker(cfg.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					s.checkMemory()
+				case <-stop:
+					return
+				}
+			}
+		}()
+		s.OnShutdown(func() { close(stop) })
+		return nil
+	})
+}
+
+// OnMemoryPressure 注册进入内存压力状态时执行的回调，用于释放缓存和对象池
+func (s *HTTPServer) OnMemoryPressure(fn func()) {
+	s.mu.RLock()
+	g := s.memGuard
+	s.mu.RUnlock()
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.relieve = append(g.relieve, fn)
+}
+
+// UnderMemoryPressure 是否处于内存压力状态
+func (s *HTTPServer) UnderMemoryPressure() bool {
+	s.mu.RLock()
+	g := s.memGuard
+	s.mu.RUnlock()
+	return g != nil && g.pressure.Load()
+}
+
+// checkMemory 采样一次内存用量并更新压力状态
+func (s *HTTPServer) checkMemory() {
+	s.mu.RLock()
+	g := s.memGuard
+	s.mu.RUnlock()
+	if g == nil {
+		return
+	}
+
+	used := g.sample()
+	high := int64(float64(g.cfg.Limit) * g.cfg.HighWater)
+	low := int64(float64(g.cfg.Limit) * g.cfg.LowWater)
+	switch {
+	case used >= high && !g.pressure.Load():
+		g.pressure.Store(true)
+		log.Warn().Int64("used", used).Int64("limit", g.cfg.Limit).Msg("memory pressure: shedding large requests and shrinking caches")
+		s.router.dropCache()
+		g.mu.Lock()
+		relieve := append([]func(){}, g.relieve...)
+		g.mu.Unlock()
+		for _, fn := range relieve {
+			fn()
+		}
+		debug.FreeOSMemory()
+	case used < low && g.pressure.Load():
+		g.pressure.Store(false)
+		log.Info().Int64("used", used).Int64("limit", g.cfg.Limit).Msg("memory pressure relieved")
+	}
+}
+
+// pressureBodyLimit 压力状态下的请求体上限，不在压力状态时返回 0
+func (s *HTTPServer) pressureBodyLimit() int64 {
+	s.mu.RLock()
+	g := s.memGuard
+	s.mu.RUnlock()
+	if g == nil || !g.pressure.Load() {
+		return 0
+	}
+	return g.cfg.MaxBody
+}
+
+// memoryMetrics 计算用量的运行时指标
+var memoryMetrics = []metrics.Sample{
+	{Name: "/memory/classes/total:bytes"},
+	{Name: "/memory/classes/heap/released:bytes"},
+}
+
+var memoryMetricsMu sync.Mutex
+
+// memoryInUse 返回运行时向操作系统申请且未归还的内存
+func memoryInUse() int64 {
+	memoryMetricsMu.Lock()
+	defer memoryMetricsMu.Unlock()
+	metrics.Read(memoryMetrics)
+	return int64(memoryMetrics[0].Value.Uint64() - memoryMetrics[1].Value.Uint64())
+}
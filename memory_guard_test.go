@@ -0,0 +1,45 @@
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMemoryGuardShedsLargeBodies(t *testing.T) {
+	s := New()
+	s.MemoryGuard(MemoryGuardConfig{Limit: 1000, MaxBody: 16})
+	used := int64(100)
+	s.memGuard.sample = func() int64 { return used }
+	relieved := 0
+	s.OnMemoryPressure(func() { relieved++ })
+	s.POST("/upload", func(c *Context) { c.String(StatusOK, strconv.Itoa(len(c.BodyUnsafe()))) })
+
+	body := strings.Repeat("x", 100)
+	raw := "POST /upload HTTP/1.1\r\nHost: x\r\nContent-Length: 100\r\n\r\n" + body
+	if resp := doRaw(s, raw); !strings.HasSuffix(resp, "100") {
+		t.Fatalf("before pressure:\n%s", resp)
+	}
+
+	used = 900
+	s.checkMemory()
+	s.checkMemory()
+	if !s.UnderMemoryPressure() || relieved != 1 {
+		t.Fatalf("pressure = %v, relieved = %d", s.UnderMemoryPressure(), relieved)
+	}
+	if resp := doRaw(s, raw); !strings.HasPrefix(resp, "HTTP/1.1 503") {
+		t.Fatalf("large body under pressure:\n%s", resp)
+	}
+	if resp := doRaw(s, "POST /upload HTTP/1.1\r\nHost: x\r\nContent-Length: 4\r\n\r\nsmol"); !strings.HasSuffix(resp, "4") {
+		t.Fatalf("small body under pressure:\n%s", resp)
+	}
+
+	used = 500
+	s.checkMemory()
+	if s.UnderMemoryPressure() {
+		t.Fatal("pressure not relieved below low water")
+	}
+	if resp := doRaw(s, raw); !strings.HasSuffix(resp, "100") {
+		t.Fatalf("after pressure:\n%s", resp)
+	}
+}
@@ -0,0 +1,156 @@
+// metrics.go
+package meego
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultMetricsBuckets 请求耗时直方图的默认分桶（秒）
+var DefaultMetricsBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metricsKey 请求计数的标签
+type metricsKey struct {
+	method string
+	route  string
+	status int
+}
+
+// metricsHistogram 单个路由的耗时直方图
+type metricsHistogram struct {
+	counts []int64 // 与 buckets 对应，非累积
+	count  int64
+	sum    float64
+}
+
+// Metrics 以 Prometheus 文本格式导出的请求指标：
+// meego_http_requests_total、meego_http_request_duration_seconds、meego_http_requests_in_flight
+type Metrics struct {
+	buckets  []float64
+	inflight atomic.Int64
+
+	mu        sync.Mutex
+	requests  map[metricsKey]int64
+	durations map[string]*metricsHistogram // method + " " + route
+}
+
+// NewMetrics 创建指标收集器，buckets 为空时使用 DefaultMetricsBuckets
+func NewMetrics(buckets ...float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+	return &Metrics{
+		buckets:   buckets,
+		requests:  make(map[metricsKey]int64),
+		durations: make(map[string]*metricsHistogram),
+	}
+}
+
+// Middleware 记录请求数、耗时和进行中的请求数。路由标签使用路由模式，
+// 未匹配的请求记为 "unmatched"，避免路径参数导致标签基数爆炸
+func (m *Metrics) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			m.inflight.Add(1)
+			start := time.Now()
+			next(c)
+			m.inflight.Add(-1)
+
+			route := c.FullPath()
+			if route == "" {
+				route = "unmatched"
+			}
+			m.observe(c.Request.Method, route, c.Writer.StatusCode(), time.Since(start))
+		}
+	}
+}
+
+func (m *Metrics) observe(method, route string, status int, d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[metricsKey{method, route, status}]++
+
+	key := method + " " + route
+	h := m.durations[key]
+	if h == nil {
+		h = &metricsHistogram{counts: make([]int64, len(m.buckets))}
+		m.durations[key] = h
+	}
+	seconds := d.Seconds()
+	for i, le := range m.buckets {
+		if seconds <= le {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// Handler 返回 Prometheus 抓取端点
+func (m *Metrics) Handler() HandlerFunc {
+	return func(c *Context) {
+		c.Writer.Status(StatusOK)
+		c.Writer.writeBytes("text/plain; version=0.0.4; charset=utf-8", []byte(m.String()))
+	}
+}
+
+// String 以 Prometheus 文本格式输出所有指标
+func (m *Metrics) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP meego_http_requests_total Total number of HTTP requests.\n")
+	b.WriteString("# TYPE meego_http_requests_total counter\n")
+	keys := make([]metricsKey, 0, len(m.requests))
+	for k := range m.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+	for _, k := range keys {
+		fmt.Fprintf(&b, "meego_http_requests_total{method=%q,route=%q,status=\"%d\"} %d\n",
+			k.method, k.route, k.status, m.requests[k])
+	}
+
+	b.WriteString("# HELP meego_http_request_duration_seconds HTTP request latency.\n")
+	b.WriteString("# TYPE meego_http_request_duration_seconds histogram\n")
+	routes := make([]string, 0, len(m.durations))
+	for k := range m.durations {
+		routes = append(routes, k)
+	}
+	sort.Strings(routes)
+	for _, key := range routes {
+		h := m.durations[key]
+		method, route, _ := strings.Cut(key, " ")
+		labels := fmt.Sprintf("method=%q,route=%q", method, route)
+		var cumulative int64
+		for i, le := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "meego_http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+				labels, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "meego_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.count)
+		fmt.Fprintf(&b, "meego_http_request_duration_seconds_sum{%s} %g\n", labels, h.sum)
+		fmt.Fprintf(&b, "meego_http_request_duration_seconds_count{%s} %d\n", labels, h.count)
+	}
+
+	b.WriteString("# HELP meego_http_requests_in_flight Number of requests being served.\n")
+	b.WriteString("# TYPE meego_http_requests_in_flight gauge\n")
+	fmt.Fprintf(&b, "meego_http_requests_in_flight %d\n", m.inflight.Load())
+	return b.String()
+}
@@ -2,8 +2,12 @@
 package meego
 
 import (
+	"errors"
 	"fmt"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -26,22 +30,94 @@ func Logger() MiddlewareFunc {
 				c.Writer.status,
 				duration,
 			)
-			log.Info().Msg(cc)
+			timings := c.Timings()
+			event := log.Info()
+			if c.Writer.status >= 500 {
+				event = log.Error()
+				// 调试模式下附带复现请求的 curl 命令
+				if IsDebugging() {
+					event.Str("curl", c.CurlCommand())
+				}
+			}
+			event.
+				Dur("handler", timings.Handler).
+				Dur("serialize", timings.Serialize).
+				Dur("write", timings.Write).
+				Msg(cc)
 		}
 	}
 }
 
+// RecoveryConfig 恢复中间件配置
+type RecoveryConfig struct {
+	// Header 返回关联 ID 的响应头，默认 X-Correlation-ID
+	Header string
+	// Render 自定义错误响应，默认输出 {"error": ..., "code": 500, "correlation_id": ...}
+	Render func(c *Context, correlationID string, err interface{})
+}
+
 // Recovery 恢复中间件
 func Recovery() MiddlewareFunc {
+	return RecoveryWithConfig(RecoveryConfig{})
+}
+
+// RecoveryWithConfig 使用自定义配置的恢复中间件：panic 时生成关联 ID，
+// 连同调用栈写入日志，并在响应中返回，便于用户报告的错误在日志中定位
+func RecoveryWithConfig(cfg RecoveryConfig) MiddlewareFunc {
+	if cfg.Header == "" {
+		cfg.Header = "X-Correlation-ID"
+	}
+	if cfg.Render == nil {
+		cfg.Render = func(c *Context, correlationID string, err interface{}) {
+			c.Writer.Status(500).JSON(JSON{
+				"error":          "Internal Server Error",
+				"code":           500,
+				"correlation_id": correlationID,
+			})
+		}
+	}
+
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) {
 			defer func() {
 				if err := recover(); err != nil {
-					fmt.Printf("Panic recovered: %v\n", err)
-					c.Writer.Status(500).JSON(JSON{
-						"error": "Internal Server Error",
-						"code":  500,
-					})
+					if isAbortConnection(err) {
+						// 有意断开连接，交给服务器处理
+						panic(err)
+					}
+					// 优先使用请求 ID，方便与其它日志关联
+					correlationID := c.RequestID()
+					if correlationID == "" {
+						correlationID = newID(IDCorrelation)
+					}
+					if dw, ok := err.(*DoubleWriteError); ok {
+						// 响应已经写出，不能再渲染错误响应
+						c.Logger().Error().
+							Str("correlation_id", correlationID).
+							Str("first_write", dw.First).
+							Str("second_write", dw.Second).
+							Msg("response written twice")
+						c.Writer.keepAlive = false
+						return
+					}
+					c.Logger().Error().
+						Str("correlation_id", correlationID).
+						Interface("panic", err).
+						Str("stack", string(debug.Stack())).
+						Func(func(e *zerolog.Event) {
+							if IsDebugging() {
+								e.Str("curl", c.CurlCommand())
+							}
+						}).
+						Msg("panic recovered")
+
+					if c.Writer.Written() {
+						// 响应已经开始写出，只能关闭连接
+						c.Writer.keepAlive = false
+						return
+					}
+					c.Writer.SetHeader(cfg.Header, correlationID)
+					cfg.Render(c, correlationID, err)
 				}
 			}()
 			next(c)
@@ -49,16 +125,75 @@ func Recovery() MiddlewareFunc {
 	}
 }
 
+// CORSConfig 跨域配置
+type CORSConfig struct {
+	AllowOrigins     []string // 允许的来源，默认 "*"
+	AllowHeaders     []string // 默认 Content-Type, Authorization
+	ExposeHeaders    []string
+	AllowCredentials bool          // 需要显式列出 AllowOrigins，不能与 "*" 同时使用
+	MaxAge           time.Duration // 预检结果缓存时间
+}
+
 // CORS 跨域中间件
 func CORS() MiddlewareFunc {
+	return CORSWithConfig(CORSConfig{})
+}
+
+// CORSWithConfig 使用自定义配置的跨域中间件。
+// Access-Control-Allow-Methods 根据该路径实际注册的路由方法计算。
+// AllowCredentials 与允许任意来源同时配置时 panic，否则任何网站都能带凭据读取响应
+func CORSWithConfig(cfg CORSConfig) MiddlewareFunc {
+	if len(cfg.AllowOrigins) == 0 {
+		cfg.AllowOrigins = []string{"*"}
+	}
+	if cfg.AllowCredentials && containsString(cfg.AllowOrigins, "*") {
+		panic("meego: CORS AllowCredentials requires an explicit AllowOrigins list")
+	}
+	if len(cfg.AllowHeaders) == 0 {
+		cfg.AllowHeaders = []string{"Content-Type", "Authorization"}
+	}
+	allowHeaders := strings.Join(cfg.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(cfg.ExposeHeaders, ", ")
+	allowAll := containsString(cfg.AllowOrigins, "*")
+
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) {
-			c.Writer.SetHeader("Access-Control-Allow-Origin", "*")
-			c.Writer.SetHeader("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Writer.SetHeader("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			origin := c.Request.GetHeader("Origin")
+			switch {
+			case allowAll:
+				c.Writer.SetHeader("Access-Control-Allow-Origin", "*")
+			case origin != "" && containsString(cfg.AllowOrigins, origin):
+				c.Writer.SetHeader("Access-Control-Allow-Origin", origin)
+				c.Writer.AddVary("Origin")
+				if cfg.AllowCredentials {
+					c.Writer.SetHeader("Access-Control-Allow-Credentials", "true")
+				}
+			default:
+				// 不同来源得到不同的响应，缓存需要区分
+				c.Writer.AddVary("Origin")
+			}
+			if exposeHeaders != "" {
+				c.Writer.SetHeader("Access-Control-Expose-Headers", exposeHeaders)
+			}
 
 			if c.Request.Method == "OPTIONS" {
-				c.Writer.Status(200).String("")
+				methods, ok := corsAllowMethods(c)
+				if !ok {
+					// 路径不存在，交给 404 处理
+					next(c)
+					return
+				}
+				c.Writer.SetHeader("Access-Control-Allow-Methods", methods)
+				c.Writer.SetHeader("Access-Control-Allow-Headers", allowHeaders)
+				if cfg.MaxAge > 0 {
+					c.Writer.SetHeader("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+				}
+				if c.FullPath() != "" {
+					// 显式注册的 OPTIONS 路由自己响应
+					next(c)
+					return
+				}
+				c.Writer.Status(StatusNoContent).String("")
 				return
 			}
 
@@ -67,13 +202,51 @@ func CORS() MiddlewareFunc {
 	}
 }
 
+// corsAllowMethods 根据路由表计算允许的方法，路径没有任何路由时返回 false
+func corsAllowMethods(c *Context) (string, bool) {
+	if c.server == nil {
+		return "GET, POST, PUT, DELETE, OPTIONS", true
+	}
+	methods := c.server.router.AllowedMethods(c.Request.URL.Path)
+	if len(methods) == 0 {
+		return "", false
+	}
+	return allowHeader(methods), true
+}
+
+// allowHeader 允许的方法加上 OPTIONS（已显式注册时不重复）
+func allowHeader(allowed []string) string {
+	if !containsString(allowed, "OPTIONS") {
+		allowed = append(allowed, "OPTIONS")
+	}
+	return strings.Join(allowed, ", ")
+}
+
+// preflightHandler 自动合成的 OPTIONS 处理器
+func preflightHandler(allowed []string) HandlerFunc {
+	allow := allowHeader(allowed)
+	return func(c *Context) {
+		c.Writer.SetHeader("Allow", allow)
+		c.Writer.Status(StatusNoContent).String("")
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // Auth 认证中间件
 func Auth() MiddlewareFunc {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) {
-			token := c.Request.Headers["Authorization"]
-			if token == "" {
-				c.Writer.Status(401).JSON(JSON{
+			auth, err := c.Authorization()
+			if errors.Is(err, ErrNoAuthorization) {
+				c.AbortWithStatusJSON(401, JSON{
 					"error": "Unauthorized",
 					"code":  401,
 				})
@@ -81,8 +254,8 @@ func Auth() MiddlewareFunc {
 			}
 
 			// 简单的 token 验证
-			if !strings.HasPrefix(token, "Bearer ") {
-				c.Writer.Status(401).JSON(JSON{
+			if err != nil || auth.Scheme != "Bearer" {
+				c.AbortWithStatusJSON(401, JSON{
 					"error": "Invalid token format",
 					"code":  401,
 				})
@@ -96,25 +269,39 @@ func Auth() MiddlewareFunc {
 	}
 }
 
-// Timeout 超时中间件
+// Timeout 超时中间件：处理器超过 timeout 未返回时先响应 503，处理器之后的写入被丢弃。
+// 上下文在处理器返回之前不会放回对象池，连接也会等待处理器返回后才关闭
 func Timeout(timeout time.Duration) MiddlewareFunc {
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) {
-			done := make(chan bool, 1)
+			// 处理器的 panic 带回请求协程，交给 Recovery 和服务器处理
+			done := make(chan interface{}, 1)
 
 			go func() {
+				defer func() {
+					done <- recover()
+				}()
 				next(c)
-				done <- true
 			}()
 
+			var p interface{}
 			select {
-			case <-done:
-				return
-			case <-time.After(timeout):
-				c.Writer.Status(503).JSON(JSON{
+			case p = <-done:
+			case <-clock().After(timeout):
+				body, _ := c.Writer.json.Marshal(JSON{
 					"error": "Request timeout",
 					"code":  503,
 				})
+				sent := c.Writer.writeTimeout(body)
+				p = <-done
+				if sent {
+					c.Writer.status = StatusServiceUnavailable
+					c.Writer.size = len(body)
+					c.Writer.keepAlive = false
+				}
+			}
+			if p != nil {
+				panic(p)
 			}
 		}
 	}
@@ -0,0 +1,157 @@
+package meego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecoveryCorrelationID(t *testing.T) {
+	var logs syncBuffer
+	s := New()
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			// 请求日志写入 logs，不修改全局 log.Logger
+			l := c.Logger().Output(&logs)
+			c.logger = &l
+			next(c)
+		}
+	})
+	s.Use(RecoveryWithConfig(RecoveryConfig{
+		Header: "X-Error-ID",
+		Render: func(c *Context, correlationID string, err interface{}) {
+			c.String(StatusServiceUnavailable, fmt.Sprintf("ref %s: %v", correlationID, err))
+		},
+	}))
+	s.GET("/boom", func(c *Context) { panic("kaboom") })
+
+	resp := doRaw(s, "GET /boom HTTP/1.1\r\nX-Request-ID: req-7\r\nConnection: close\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 503") || !strings.Contains(resp, "X-Error-ID: req-7") || !strings.HasSuffix(resp, "ref req-7: kaboom") {
+		t.Fatalf("custom render:\n%s", resp)
+	}
+	out := logs.String()
+	if !strings.Contains(out, `"correlation_id":"req-7"`) || !strings.Contains(out, `"stack":"goroutine `) || !strings.Contains(out, `"message":"panic recovered"`) {
+		t.Fatalf("panic log missing correlation id or stack:\n%s", out)
+	}
+
+	// 没有请求 ID 时生成关联 ID，默认以 JSON 返回
+	d := New()
+	d.Use(Recovery())
+	d.GET("/boom", func(c *Context) { panic("kaboom") })
+	res, body := readRawResponse(t, doRaw(d, "GET /boom HTTP/1.1\r\nConnection: close\r\n\r\n"))
+	id := res.Header.Get("X-Correlation-ID")
+	if res.StatusCode != StatusInternalServerError || id == "" || !strings.Contains(string(body), `"correlation_id":"`+id+`"`) {
+		t.Fatalf("default render: %d %q %s", res.StatusCode, id, body)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	s := New()
+	s.Use(CORS())
+	s.GET("/items/:id", func(c *Context) { c.String(StatusOK, "ok") })
+	s.DELETE("/items/:id", func(c *Context) { c.String(StatusOK, "ok") })
+
+	resp := doRaw(s, "OPTIONS /items/1 HTTP/1.1\r\nOrigin: http://a.test\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 204") {
+		t.Fatalf("expected 204, got %q", resp)
+	}
+	if !strings.Contains(resp, "Access-Control-Allow-Methods: DELETE, GET, HEAD, OPTIONS") {
+		t.Fatalf("allow methods not computed from routes: %q", resp)
+	}
+	if resp := doRaw(s, "OPTIONS /missing HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 404") {
+		t.Fatalf("expected 404 for unknown path, got %q", resp)
+	}
+
+	// 显式注册的 OPTIONS 路由由处理器响应，Allow 中的 OPTIONS 不重复
+	s.GET("/docs", func(c *Context) { c.String(StatusOK, "docs") })
+	s.OPTIONS("/docs", func(c *Context) { c.String(StatusOK, "custom options") })
+	resp = doRaw(s, "OPTIONS /docs HTTP/1.1\r\nOrigin: http://a.test\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.HasSuffix(resp, "custom options") ||
+		!strings.Contains(resp, "Access-Control-Allow-Methods: GET, HEAD, OPTIONS\r\n") {
+		t.Fatalf("explicit OPTIONS route: %q", resp)
+	}
+	resp = doRaw(s, "POST /docs HTTP/1.1\r\nContent-Length: 0\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 405") || !strings.Contains(resp, "Allow: GET, HEAD, OPTIONS\r\n") {
+		t.Fatalf("405 with explicit OPTIONS route: %q", resp)
+	}
+}
+
+func TestCORSCredentials(t *testing.T) {
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("AllowCredentials with wildcard origin should panic")
+			}
+		}()
+		CORSWithConfig(CORSConfig{AllowCredentials: true})
+	}()
+
+	s := New()
+	s.Use(CORSWithConfig(CORSConfig{AllowOrigins: []string{"https://app.test"}, AllowCredentials: true}))
+	s.GET("/me", func(c *Context) { c.String(StatusOK, "ok") })
+
+	resp := doRaw(s, "GET /me HTTP/1.1\r\nOrigin: https://evil.test\r\n\r\n")
+	if strings.Contains(resp, "Access-Control-Allow-Origin") || strings.Contains(resp, "Access-Control-Allow-Credentials") {
+		t.Fatalf("arbitrary origin got credentialed reflection:\n%s", resp)
+	}
+	resp = doRaw(s, "GET /me HTTP/1.1\r\nOrigin: https://app.test\r\n\r\n")
+	if !strings.Contains(resp, "Access-Control-Allow-Origin: https://app.test") || !strings.Contains(resp, "Access-Control-Allow-Credentials: true") {
+		t.Fatalf("listed origin not reflected:\n%s", resp)
+	}
+}
+
+func TestUnmatchedRunsMiddleware(t *testing.T) {
+	s := New()
+	var seen []int
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			next(c)
+			seen = append(seen, c.Writer.StatusCode())
+		}
+	})
+	s.GET("/a", func(c *Context) { c.String(StatusOK, "ok") })
+
+	if resp := doRaw(s, "GET /missing HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 404") {
+		t.Fatalf("expected 404, got %q", resp)
+	}
+	resp := doRaw(s, "POST /a HTTP/1.1\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 405") || !strings.Contains(resp, "Allow: GET, HEAD, OPTIONS") {
+		t.Fatalf("expected 405 with Allow, got %q", resp)
+	}
+	if len(seen) != 2 || seen[0] != StatusNotFound || seen[1] != StatusMethodNotAllowed {
+		t.Fatalf("middleware did not observe unmatched requests: %v", seen)
+	}
+}
+
+func TestTimeoutLateWrite(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+
+	var late error
+	s := New()
+	s.Use(Timeout(20 * time.Millisecond))
+	s.GET("/slow", func(c *Context) {
+		time.Sleep(100 * time.Millisecond)
+		c.Writer.SetHeader("X-Late", "1")
+		late = c.Writer.Status(StatusOK).JSON(JSON{"late": true})
+	})
+	s.GET("/fast", func(c *Context) { c.String(StatusOK, "fast") })
+
+	// 超时后先响应 503；处理器返回前上下文不会放回对象池，迟到的写入被丢弃而不是 panic
+	resp := doRaw(s, "GET /slow HTTP/1.1\r\nHost: x\r\n\r\nGET /fast HTTP/1.1\r\nHost: x\r\n\r\n")
+	if strings.Count(resp, "HTTP/1.1 ") != 1 || !strings.HasPrefix(resp, "HTTP/1.1 503 ") ||
+		!strings.Contains(resp, "Request timeout") || !strings.Contains(resp, "Connection: close") ||
+		strings.Contains(resp, "late") || strings.Contains(resp, "X-Late") {
+		t.Fatalf("unexpected response:\n%q", resp)
+	}
+	if late != errResponseFinished {
+		t.Fatalf("late write: %v", late)
+	}
+
+	// 未超时的处理器照常响应并保持连接
+	resp = doRaw(s, "GET /fast HTTP/1.1\r\nHost: x\r\n\r\nGET /fast HTTP/1.1\r\nHost: x\r\n\r\n")
+	if strings.Count(resp, "HTTP/1.1 200 ") != 2 {
+		t.Fatalf("fast requests:\n%q", resp)
+	}
+}
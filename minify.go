@@ -0,0 +1,126 @@
+// minify.go
+package meego
+
+import (
+	"bytes"
+	"mime"
+	"regexp"
+	"strings"
+)
+
+// Minifier 响应压缩器，mediaType 不含参数，如 "text/html"
+type Minifier interface {
+	Minify(mediaType string, src []byte) ([]byte, error)
+}
+
+// MinifierFunc 函数形式的 Minifier，便于接入第三方库：
+//
+//	m := minify.New() // github.com/tdewolff/minify
+//	m.AddFunc("text/html", html.Minify)
+//	meego.MinifierFunc(func(t string, src []byte) ([]byte, error) { return m.Bytes(t, src) })
+type MinifierFunc func(mediaType string, src []byte) ([]byte, error)
+
+func (f MinifierFunc) Minify(mediaType string, src []byte) ([]byte, error) {
+	return f(mediaType, src)
+}
+
+// MinifyConfig 响应压缩配置
+type MinifyConfig struct {
+	// Minifier 默认 BasicMinifier，只处理 HTML 和 CSS
+	Minifier Minifier
+	// MediaTypes 需要压缩的媒体类型，默认 text/html、text/css、text/javascript
+	MediaTypes []string
+}
+
+// Minify 使用 BasicMinifier 压缩 HTML/CSS 响应
+func Minify() MiddlewareFunc {
+	return MinifyWithConfig(MinifyConfig{})
+}
+
+// MinifyWithConfig 响应压缩中间件：在响应写出之前（以及之后的编码、压缩之前）
+// 去掉文本响应中多余的空白和注释。压缩失败时原样输出，流式响应不处理
+func MinifyWithConfig(cfg MinifyConfig) MiddlewareFunc {
+	if cfg.Minifier == nil {
+		cfg.Minifier = BasicMinifier{}
+	}
+	if len(cfg.MediaTypes) == 0 {
+		cfg.MediaTypes = []string{"text/html", "text/css", "text/javascript", "application/javascript"}
+	}
+
+	transform := func(w *ResponseWriter, body []byte) []byte {
+		if len(body) == 0 || w.header["Content-Encoding"] != "" {
+			return body
+		}
+		mediaType, _, err := mime.ParseMediaType(w.header["Content-Type"])
+		if err != nil || !containsString(cfg.MediaTypes, mediaType) {
+			return body
+		}
+		out, err := cfg.Minifier.Minify(mediaType, body)
+		if err != nil {
+			return body
+		}
+		if w.header["Content-Length"] != "" {
+			delete(w.header, "Content-Length")
+		}
+		return out
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Writer.transforms = append(c.Writer.transforms, transform)
+			next(c)
+		}
+	}
+}
+
+// BasicMinifier 不依赖第三方库的保守压缩：
+//   - HTML：去掉注释（保留条件注释），连续空白合并为一个空格，
+//     <pre>、<textarea>、<script>、<style> 的内容保持不变；
+//   - CSS：去掉注释，合并空白，去掉符号两侧的空白。
+//
+// 其它类型原样返回；JavaScript 需要完整的词法分析，请通过 MinifierFunc 接入专门的库
+type BasicMinifier struct{}
+
+var (
+	htmlRawBlock  = regexp.MustCompile(`(?is)<(pre|textarea|script|style)\b.*?</(?:pre|textarea|script|style)\s*>`)
+	htmlComment   = regexp.MustCompile(`(?s)<!--(?:[^\[].*?)?-->`)
+	whitespaceRun = regexp.MustCompile(`\s+`)
+	cssComment    = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssSymbol     = regexp.MustCompile(`\s*([{};,>])\s*`)
+)
+
+func (BasicMinifier) Minify(mediaType string, src []byte) ([]byte, error) {
+	switch mediaType {
+	case "text/html":
+		return minifyHTML(src), nil
+	case "text/css":
+		return minifyCSS(src), nil
+	}
+	return src, nil
+}
+
+func minifyHTML(src []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(src))
+	last := 0
+	for _, loc := range htmlRawBlock.FindAllIndex(src, -1) {
+		out.Write(collapseHTML(src[last:loc[0]]))
+		out.Write(src[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	out.Write(collapseHTML(src[last:]))
+	return bytes.TrimSpace(out.Bytes())
+}
+
+func collapseHTML(b []byte) []byte {
+	b = htmlComment.ReplaceAll(b, nil)
+	return whitespaceRun.ReplaceAll(b, []byte(" "))
+}
+
+func minifyCSS(src []byte) []byte {
+	s := cssComment.ReplaceAllString(string(src), "")
+	s = whitespaceRun.ReplaceAllString(s, " ")
+	s = cssSymbol.ReplaceAllString(s, "$1")
+	s = strings.ReplaceAll(s, ";}", "}")
+	return []byte(strings.TrimSpace(s))
+}
@@ -0,0 +1,27 @@
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestMinify(t *testing.T) {
+	s := New()
+	s.Use(Minify())
+	s.GET("/page", func(c *Context) {
+		c.HTML(StatusOK, "<html>\n  <!-- note -->\n  <body>\n    <p>Hello   world</p>\n    <pre>  keep\n  this</pre>\n  </body>\n</html>\n")
+	})
+	s.GET("/site.css", func(c *Context) {
+		c.Data(StatusOK, "text/css", []byte("/* main */\nbody {\n  color: red;\n  margin: 0;\n}\n"))
+	})
+
+	resp := doRaw(s, "GET /page HTTP/1.1\r\n\r\n")
+	want := "<html> <body> <p>Hello world</p> <pre>  keep\n  this</pre> </body> </html>"
+	if !strings.HasSuffix(resp, "\r\n\r\n"+want) || !strings.Contains(resp, "Content-Length: "+strconv.Itoa(len(want))) {
+		t.Fatalf("unexpected html: %q", resp)
+	}
+	if resp := doRaw(s, "GET /site.css HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "\r\n\r\nbody{color: red;margin: 0}") {
+		t.Fatalf("unexpected css: %q", resp)
+	}
+}
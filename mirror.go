@@ -0,0 +1,119 @@
+// mirror.go
+package meego
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HeaderShadow 标记镜像请求的请求头
+const HeaderShadow = "X-Meego-Shadow"
+
+// MirrorConfig 流量镜像配置
+type MirrorConfig struct {
+	Upstream    string        // 影子服务地址，如 http://shadow.internal:8080
+	Percent     float64       // 镜像比例 0-100
+	Client      *Client       // 出站客户端，默认 NewClient()
+	Timeout     time.Duration // 镜像请求超时，默认 5s
+	MaxInFlight int           // 同时进行的镜像请求上限，超出时丢弃，默认 100
+}
+
+// Mirror 流量镜像中间件：按比例把请求异步复制到影子服务，忽略其响应，
+// 不影响主请求的处理和延迟
+func Mirror(cfg MirrorConfig) MiddlewareFunc {
+	cfg.Upstream = strings.TrimRight(cfg.Upstream, "/")
+	if cfg.Client == nil {
+		cfg.Client = NewClient()
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = 100
+	}
+	inflight := make(chan struct{}, cfg.MaxInFlight)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if cfg.Upstream != "" && rand.Float64()*100 < cfg.Percent {
+				select {
+				case inflight <- struct{}{}:
+					req := newMirrorRequest(c, cfg.Upstream)
+					c.Go(func() {
+						defer func() { <-inflight }()
+						sendMirrorRequest(cfg, req)
+					})
+				default:
+					// 镜像请求积压，直接丢弃
+				}
+			}
+			next(c)
+		}
+	}
+}
+
+// mirrorRequest 请求快照；c 在请求结束后会被复用，协程中只能使用快照
+type mirrorRequest struct {
+	method  string
+	url     string
+	headers map[string]string
+	body    []byte
+}
+
+func newMirrorRequest(c *Context, upstream string) *mirrorRequest {
+	headers := make(map[string]string, len(c.Request.Headers)+4)
+	for k, v := range c.Request.Headers {
+		if !isHopByHopHeader(k) && !strings.EqualFold(k, "Host") {
+			headers[k] = v
+		}
+	}
+	for k, v := range c.PropagationHeaders() {
+		headers[k] = v
+	}
+	headers[HeaderShadow] = "1"
+
+	return &mirrorRequest{
+		method:  c.Request.Method,
+		url:     upstream + c.Request.URL.RequestURI(),
+		headers: headers,
+		body:    c.BodyBytes(),
+	}
+}
+
+func sendMirrorRequest(cfg MirrorConfig, m *mirrorRequest) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, m.method, m.url, bytes.NewReader(m.body))
+	if err != nil {
+		return
+	}
+	for k, v := range m.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := cfg.Client.Do(nil, req)
+	if err != nil {
+		log.Debug().Err(err).Str("url", m.url).Msg("mirror request failed")
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// isHopByHopHeader 逐跳头部不应转发（RFC 7230 6.1）
+func isHopByHopHeader(key string) bool {
+	switch strings.ToLower(key) {
+	case "connection", "keep-alive", "proxy-authenticate", "proxy-authorization",
+		"te", "trailer", "transfer-encoding", "upgrade", "proxy-connection":
+		return true
+	}
+	return false
+}
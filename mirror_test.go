@@ -0,0 +1,59 @@
+package meego
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMirror(t *testing.T) {
+	received := make(chan *http.Request, 4)
+	bodies := make(chan string, 4)
+	release := make(chan struct{})
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- string(body)
+		<-release
+		w.WriteHeader(StatusInternalServerError)
+	}))
+	defer shadow.Close()
+	defer close(release)
+
+	s := New()
+	s.Use(Mirror(MirrorConfig{Upstream: shadow.URL + "/", Percent: 100}))
+	s.POST("/orders", func(c *Context) { c.String(StatusCreated, "primary") })
+
+	// 影子服务阻塞并返回 500，主请求不受影响
+	resp := doRaw(s, "POST /orders?v=2 HTTP/1.1\r\nConnection: close\r\nX-Tenant: t1\r\nContent-Length: 4\r\n\r\n{\"a\"")
+	if !strings.HasPrefix(resp, "HTTP/1.1 201") || !strings.HasSuffix(resp, "primary") {
+		t.Fatalf("primary response:\n%s", resp)
+	}
+	select {
+	case r := <-received:
+		if r.Method != "POST" || r.URL.RequestURI() != "/orders?v=2" || r.Header.Get(HeaderShadow) != "1" || r.Header.Get("X-Tenant") != "t1" || r.Header.Get("Connection") != "" {
+			t.Fatalf("unexpected mirrored request: %s %s %v", r.Method, r.URL, r.Header)
+		}
+		if body := <-bodies; body != `{"a"` {
+			t.Fatalf("mirrored body: %q", body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("request was not mirrored")
+	}
+
+	// Percent 为 0 时不镜像
+	off := New()
+	off.Use(Mirror(MirrorConfig{Upstream: shadow.URL, Percent: 0}))
+	off.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	for i := 0; i < 20; i++ {
+		doRaw(off, "GET / HTTP/1.1\r\nConnection: close\r\n\r\n")
+	}
+	select {
+	case r := <-received:
+		t.Fatalf("mirrored at 0%%: %s", r.URL)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,74 @@
+// mode.go
+package meego
+
+import (
+	"os"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// 运行模式
+const (
+	DebugMode   = "debug"
+	ReleaseMode = "release"
+	TestMode    = "test"
+)
+
+// EnvMeegoMode 通过环境变量设置运行模式
+const EnvMeegoMode = "MEEGO_MODE"
+
+const (
+	debugCode = iota
+	releaseCode
+	testCode
+)
+
+var meegoMode int32 = debugCode
+
+func init() {
+	SetMode(os.Getenv(EnvMeegoMode))
+}
+
+// SetMode 设置运行模式，空字符串表示 DebugMode
+func SetMode(value string) {
+	switch value {
+	case DebugMode, "":
+		atomic.StoreInt32(&meegoMode, debugCode)
+	case ReleaseMode:
+		atomic.StoreInt32(&meegoMode, releaseCode)
+	case TestMode:
+		atomic.StoreInt32(&meegoMode, testCode)
+	default:
+		panic("meego mode unknown: " + value + " (available mode: debug release test)")
+	}
+}
+
+// Mode 返回当前运行模式
+func Mode() string {
+	switch atomic.LoadInt32(&meegoMode) {
+	case releaseCode:
+		return ReleaseMode
+	case testCode:
+		return TestMode
+	default:
+		return DebugMode
+	}
+}
+
+// IsDebugging 是否处于 DebugMode
+func IsDebugging() bool {
+	return atomic.LoadInt32(&meegoMode) == debugCode
+}
+
+// debugPrint 调试模式下以 debug 级别写入日志，其它模式不输出。
+// 每个请求都会经过的位置先检查 IsDebugging，发布模式下不产生格式化参数的开销
+func debugPrint(format string, args ...interface{}) {
+	if IsDebugging() {
+		log.Debug().Msgf(format, args...)
+	}
+}
+
+func isTestMode() bool {
+	return atomic.LoadInt32(&meegoMode) == testCode
+}
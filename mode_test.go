@@ -0,0 +1,36 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+func TestDebugPrintMode(t *testing.T) {
+	var logs syncBuffer
+	defer func(l zerolog.Logger) { log.Logger = l }(log.Logger)
+	log.Logger = zerolog.New(&logs)
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+
+	s := New()
+	s.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+
+	doRaw(s, "GET / HTTP/1.1\r\nConnection: close\r\n\r\n")
+	if !strings.Contains(logs.String(), `"level":"debug"`) || !strings.Contains(logs.String(), "Processing: GET /") {
+		t.Fatalf("debug mode should log diagnostics:\n%s", logs.String())
+	}
+
+	logs.Reset()
+	SetMode(ReleaseMode)
+	if resp := doRaw(s, "GET / HTTP/1.1\r\nConnection: close\r\n\r\n"); !strings.HasSuffix(resp, "ok") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("release mode should not log diagnostics:\n%s", logs.String())
+	}
+}
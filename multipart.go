@@ -0,0 +1,94 @@
+// multipart.go
+package meego
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"strings"
+)
+
+// DefaultMultipartMemory MultipartForm 默认保存在内存中的字节数，超出部分写入临时文件
+const DefaultMultipartMemory = 32 << 20
+
+// ErrNotMultipart 请求不是 multipart 请求
+var ErrNotMultipart = errors.New("request Content-Type isn't multipart/form-data")
+
+// ErrMissingFile 表单中没有指定的文件
+var ErrMissingFile = errors.New("no such file")
+
+// StreamBody 声明路由流式读取请求体：解析器不再把请求体读入内存，
+// 处理器通过 c.MultipartReader() 或 c.BodyReader() 直接从连接读取，
+// 适合 GB 级上传直接转存，不产生临时文件。BodyLimits 仍然生效
+func (r *Route) StreamBody() *Route {
+	r.streamBody = true
+	return r
+}
+
+// streamBodyFor 解析器回调：是否开启了 StreamBodies 或请求命中流式路由
+func (s *HTTPServer) streamBodyFor(req *HTTPRequest) bool {
+	s.mu.RLock()
+	all := s.streamBodies
+	s.mu.RUnlock()
+	if all {
+		return true
+	}
+	route, _ := s.router.findRoute(req.Method, req.URL.Path)
+	return route != nil && route.streamBody
+}
+
+// BodyReader 返回请求体读取器：流式路由直接读取连接，其它路由读取已缓冲的请求体
+func (c *Context) BodyReader() io.Reader {
+	c.guard.check("Context", "BodyReader")
+	return c.Request.BodyReader()
+}
+
+// MultipartReader 按顺序逐个返回 multipart 请求的各个部分。
+// 在流式路由上不会缓冲整个请求体，每个 Part 读完后才会读取下一个
+func (c *Context) MultipartReader() (*multipart.Reader, error) {
+	mediaType, params, err := mime.ParseMediaType(c.Request.GetHeader("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, ErrNotMultipart
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, ErrNotMultipart
+	}
+	return multipart.NewReader(c.BodyReader(), boundary), nil
+}
+
+// MultipartForm 解析整个 multipart 表单，maxMemory 以内的文件保存在内存中，
+// 其余写入临时文件并在请求结束后删除；maxMemory <= 0 时使用 DefaultMultipartMemory
+func (c *Context) MultipartForm(maxMemory int64) (*multipart.Form, error) {
+	if c.multipartForm != nil {
+		return c.multipartForm, nil
+	}
+	if maxMemory <= 0 {
+		maxMemory = DefaultMultipartMemory
+	}
+
+	mr, err := c.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	form, err := mr.ReadForm(maxMemory)
+	if err != nil {
+		return nil, err
+	}
+	c.multipartForm = form
+	return form, nil
+}
+
+// FormFile 返回 multipart 表单中名为 name 的第一个文件
+func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	form, err := c.MultipartForm(0)
+	if err != nil {
+		return nil, err
+	}
+	files := form.File[name]
+	if len(files) == 0 {
+		return nil, ErrMissingFile
+	}
+	return files[0], nil
+}
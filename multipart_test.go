@@ -0,0 +1,76 @@
+package meego
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStreamingMultipart(t *testing.T) {
+	s := New()
+	var got []string
+	s.POST("/upload", func(c *Context) {
+		mr, err := c.MultipartReader()
+		if err != nil {
+			c.String(StatusBadRequest, err.Error())
+			return
+		}
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				c.String(StatusBadRequest, err.Error())
+				return
+			}
+			data, _ := io.ReadAll(part)
+			got = append(got, part.FormName()+"="+string(data))
+		}
+		c.String(StatusOK, strconv.Itoa(len(c.Request.Body)))
+	}).StreamBody()
+
+	body := "--xx\r\nContent-Disposition: form-data; name=\"a\"\r\n\r\nhello\r\n" +
+		"--xx\r\nContent-Disposition: form-data; name=\"f\"; filename=\"f.bin\"\r\n\r\nworld\r\n--xx--\r\n"
+	resp := doRaw(s, "POST /upload HTTP/1.1\r\nContent-Type: multipart/form-data; boundary=xx\r\n"+
+		"Content-Length: "+strconv.Itoa(len(body))+"\r\n\r\n"+body)
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.HasSuffix(resp, "\r\n\r\n0") {
+		t.Fatalf("body should not be buffered: %q", resp)
+	}
+	if strings.Join(got, ",") != "a=hello,f=world" {
+		t.Fatalf("unexpected parts: %v", got)
+	}
+}
+
+func TestSaveUploadedFile(t *testing.T) {
+	dir := t.TempDir()
+	s := New()
+	s.POST("/upload", func(c *Context) {
+		f, err := c.UploadedFile("doc")
+		if err != nil {
+			c.String(StatusBadRequest, err.Error())
+			return
+		}
+		data, _ := io.ReadAll(f)
+		fh, _ := c.FormFile("doc")
+		if err := c.SaveUploadedFile(fh, filepath.Join(dir, "saved", f.Filename)); err != nil {
+			c.String(StatusInternalServerError, err.Error())
+			return
+		}
+		c.String(StatusOK, f.Filename+"|"+f.ContentType+"|"+strconv.FormatInt(f.Size, 10)+"|"+string(data))
+	})
+
+	body := "--xx\r\nContent-Disposition: form-data; name=\"doc\"; filename=\"C:\\\\tmp\\\\report.txt\"\r\n" +
+		"Content-Type: text/plain\r\n\r\nquarterly\r\n--xx--\r\n"
+	resp := doRaw(s, "POST /upload HTTP/1.1\r\nContent-Type: multipart/form-data; boundary=xx\r\n"+
+		"Content-Length: "+strconv.Itoa(len(body))+"\r\n\r\n"+body)
+	if !strings.HasSuffix(resp, "report.txt|text/plain|9|quarterly") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "saved", "report.txt")); err != nil || string(data) != "quarterly" {
+		t.Fatalf("saved file: %q %v", data, err)
+	}
+}
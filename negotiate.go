@@ -0,0 +1,106 @@
+// negotiate.go
+package meego
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Offer 内容协商中可选的一种响应表示
+type Offer struct {
+	// MediaType 媒体类型。application/json、application/xml、application/yaml、
+	// application/x-protobuf、text/html 和 text/plain 使用对应的渲染方法，
+	// 其它类型要求 Data 为 []byte
+	MediaType string
+	// Data 渲染的数据；text/html 和 text/plain 为字符串或按 fmt.Sprint 格式化
+	Data interface{}
+}
+
+// Negotiate 按 Accept 头（含 q 值）在 offers 中选择客户端最偏好的表示并写出，
+// 同等偏好时按 offers 的顺序。没有 Accept 头时使用第一个，都不可接受时返回 406
+func (c *Context) Negotiate(code int, offers ...Offer) {
+	c.guard.check("Context", "Negotiate")
+	w := c.Writer
+	w.AddVary("Accept")
+	offer := negotiateOffer(c.Request.GetHeader("Accept"), offers)
+	if offer == nil {
+		w.Status(StatusNotAcceptable).JSON(JSON{"error": "Not Acceptable", "code": StatusNotAcceptable})
+		return
+	}
+
+	w.Status(code)
+	switch offer.MediaType {
+	case "application/json":
+		w.JSON(offer.Data)
+	case "application/xml", "text/xml":
+		w.XML(offer.Data)
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		w.YAML(offer.Data)
+	case "application/x-protobuf", "application/protobuf":
+		w.ProtoBuf(offer.Data)
+	case "text/html":
+		w.HTML(offerText(offer.Data))
+	case "text/plain":
+		w.String(offerText(offer.Data))
+	default:
+		body, _ := offer.Data.([]byte)
+		w.Data(offer.MediaType, body)
+	}
+}
+
+// offerText 文本表示的内容
+func offerText(data interface{}) string {
+	if s, ok := data.(string); ok {
+		return s
+	}
+	return fmt.Sprint(data)
+}
+
+// acceptRange Accept 头中的一项
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// negotiateOffer 选择 q 值最高的表示，每个表示取匹配它的最具体的媒体范围的 q 值
+func negotiateOffer(accept string, offers []Offer) *Offer {
+	if len(offers) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(accept) == "" {
+		return &offers[0]
+	}
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		name, q := parseQuality(part)
+		typ, subtype, _ := strings.Cut(name, "/")
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+
+	var best *Offer
+	bestQ := 0.0
+	for i := range offers {
+		typ, subtype, _ := strings.Cut(strings.ToLower(offers[i].MediaType), "/")
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			var s int
+			switch {
+			case r.typ == typ && r.subtype == subtype:
+				s = 2
+			case r.typ == typ && r.subtype == "*":
+				s = 1
+			case r.typ == "*" && r.subtype == "*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = &offers[i], q
+		}
+	}
+	return best
+}
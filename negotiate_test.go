@@ -0,0 +1,50 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+type testProto struct{ b []byte }
+
+func (m testProto) Marshal() ([]byte, error) { return m.b, nil }
+
+func TestNegotiate(t *testing.T) {
+	s := New()
+	type user struct {
+		Name string `json:"name" xml:"name" yaml:"name"`
+	}
+	s.GET("/user", func(c *Context) {
+		u := user{Name: "ann"}
+		c.Negotiate(StatusOK,
+			Offer{MediaType: "application/json", Data: u},
+			Offer{MediaType: "application/xml", Data: u},
+			Offer{MediaType: "application/yaml", Data: u},
+			Offer{MediaType: "text/plain", Data: "ann"},
+		)
+	})
+	s.GET("/proto", func(c *Context) { c.ProtoBuf(StatusOK, testProto{[]byte{8, 1}}) })
+
+	for accept, want := range map[string]string{
+		"":                                    `{"name":"ann"}`,
+		"text/html, application/xml;q=0.9":    "<user><name>ann</name></user>",
+		"application/*;q=0.5, text/plain":     "\r\n\r\nann",
+		"*/*;q=0.1, application/yaml":         "name: ann",
+		"text/*, text/plain;q=0, */*;q=0.2":   `{"name":"ann"}`,
+		"application/json;q=0, application/*": "<user>",
+	} {
+		raw := "GET /user HTTP/1.1\r\n"
+		if accept != "" {
+			raw += "Accept: " + accept + "\r\n"
+		}
+		if resp := doRaw(s, raw+"\r\n"); !strings.Contains(resp, want) || !strings.Contains(resp, "Vary: Accept") {
+			t.Fatalf("Accept %q: expected %q in %q", accept, want, resp)
+		}
+	}
+	if resp := doRaw(s, "GET /user HTTP/1.1\r\nAccept: image/png\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 406") {
+		t.Fatalf("expected 406: %q", resp)
+	}
+	if resp := doRaw(s, "GET /proto HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "application/x-protobuf") || !strings.HasSuffix(resp, "\x08\x01") {
+		t.Fatalf("unexpected protobuf response: %q", resp)
+	}
+}
@@ -0,0 +1,129 @@
+// normalize.go
+package meego
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DuplicatePolicy 同名查询参数和表单字段的处理方式
+type DuplicatePolicy int
+
+const (
+	// DuplicatesKeepAll 保留所有值（默认）
+	DuplicatesKeepAll DuplicatePolicy = iota
+	// DuplicatesFirst 只保留第一个值
+	DuplicatesFirst
+	// DuplicatesLast 只保留最后一个值
+	DuplicatesLast
+	// DuplicatesJoin 以逗号合并为一个值
+	DuplicatesJoin
+)
+
+// NormalizeConfig 请求规范化配置
+type NormalizeConfig struct {
+	// KeepWhitespace 不去掉查询参数和表单值首尾的空白
+	KeepWhitespace bool
+	// LowercaseKeys 查询参数和表单字段名转为小写，?ID=1 与 ?id=1 等价
+	LowercaseKeys bool
+	// Duplicates 同名参数的处理方式，默认保留所有值
+	Duplicates DuplicatePolicy
+	// KeepHeaderCase 不把请求头名称规范为 Content-Type 形式
+	KeepHeaderCase bool
+}
+
+// Normalize 使用默认配置的请求规范化中间件：去掉查询参数和表单值首尾空白，规范请求头名称
+func Normalize() MiddlewareFunc {
+	return NormalizeWithConfig(NormalizeConfig{})
+}
+
+// NormalizeWithConfig 使用自定义配置的请求规范化中间件。查询参数直接改写 URL，
+// application/x-www-form-urlencoded 请求体改写后同步更新 Content-Length，
+// 之后的中间件和处理器看到的都是规范化后的输入
+func NormalizeWithConfig(cfg NormalizeConfig) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			req := c.Request
+			if !cfg.KeepHeaderCase {
+				normalizeHeaderKeys(req.Headers)
+			}
+			if req.URL != nil && req.URL.RawQuery != "" {
+				if query, err := cfg.normalize(req.URL.RawQuery); err == nil {
+					req.URL.RawQuery = query
+				}
+			}
+			if req.ContentType() == "application/x-www-form-urlencoded" && (len(req.Body) > 0 || req.Streaming()) {
+				// 表单体积小，流式请求体直接读入内存
+				req.ReadBody()
+				if form, err := cfg.normalize(string(req.Body)); err == nil {
+					req.Body = append(req.Body[:0], form...)
+					req.contentLength = len(req.Body)
+					for key := range req.Headers {
+						if strings.EqualFold(key, "Content-Length") {
+							req.Headers[key] = strconv.Itoa(len(req.Body))
+						}
+					}
+				}
+			}
+			next(c)
+		}
+	}
+}
+
+// normalize 按配置去空白、转换键名并处理重复值，同名参数按原始顺序合并
+func (cfg NormalizeConfig) normalize(raw string) (string, error) {
+	out := make(url.Values)
+	for raw != "" {
+		var pair string
+		pair, raw, _ = strings.Cut(raw, "&")
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(key)
+		if err != nil {
+			return "", err
+		}
+		if value, err = url.QueryUnescape(value); err != nil {
+			return "", err
+		}
+		if cfg.LowercaseKeys {
+			key = strings.ToLower(key)
+		}
+		if !cfg.KeepWhitespace {
+			value = strings.TrimSpace(value)
+		}
+		out[key] = append(out[key], value)
+	}
+	for key, vs := range out {
+		if len(vs) < 2 {
+			continue
+		}
+		switch cfg.Duplicates {
+		case DuplicatesFirst:
+			out[key] = vs[:1]
+		case DuplicatesLast:
+			out[key] = vs[len(vs)-1:]
+		case DuplicatesJoin:
+			out[key] = []string{strings.Join(vs, ",")}
+		}
+	}
+	return out.Encode(), nil
+}
+
+// normalizeHeaderKeys 把请求头名称规范为 Content-Type 形式，仅大小写不同的同名头以逗号合并
+func normalizeHeaderKeys(headers map[string]string) {
+	for key, value := range headers {
+		canonical := http.CanonicalHeaderKey(key)
+		if canonical == key {
+			continue
+		}
+		delete(headers, key)
+		if existing, ok := headers[canonical]; ok {
+			value = existing + ", " + value
+		}
+		headers[canonical] = value
+	}
+}
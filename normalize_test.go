@@ -0,0 +1,24 @@
+package meego
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	s := New()
+	s.Use(NormalizeWithConfig(NormalizeConfig{LowercaseKeys: true, Duplicates: DuplicatesLast}))
+	s.POST("/n", func(c *Context) {
+		c.String(StatusOK, fmt.Sprintf("%q %q %q %q", c.Query("name"), c.QueryArray("tag"), c.BodyString(), c.Request.Headers["X-Api-Key"]))
+	})
+
+	body := "Note=++hi++&note=+last+"
+	raw := "POST /n?Name=%20bob%20&tag=a&TAG=b HTTP/1.1\r\nx-api-key: k1\r\nContent-Type: application/x-www-form-urlencoded\r\n" +
+		"Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n" + body
+	resp := doRaw(s, raw)
+	if !strings.HasSuffix(resp, `"bob" ["b"] "note=last" "k1"`) {
+		t.Fatalf("normalized request:\n%s", resp)
+	}
+}
@@ -0,0 +1,207 @@
+// openapi.go
+package meego
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIDocument OpenAPI 3.0 文档中 meego 使用的部分
+type OpenAPIDocument struct {
+	OpenAPI string                                  `json:"openapi" yaml:"openapi"`
+	Info    OpenAPIInfo                             `json:"info" yaml:"info"`
+	Paths   map[string]map[string]*OpenAPIOperation `json:"paths" yaml:"paths"`
+
+	Components *OpenAPIComponents `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+// OpenAPIInfo 文档标题和版本
+type OpenAPIInfo struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// OpenAPIOperation 单个方法和路径上的操作
+type OpenAPIOperation struct {
+	Summary     string                      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                      `json:"description,omitempty" yaml:"description,omitempty"`
+	OperationID string                      `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Tags        []string                    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []OpenAPIParameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIBody                `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses" yaml:"responses"`
+}
+
+// OpenAPIParameter 路径、查询或请求头参数
+type OpenAPIParameter struct {
+	Name     string                 `json:"name" yaml:"name"`
+	In       string                 `json:"in" yaml:"in"`
+	Required bool                   `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   map[string]interface{} `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// OpenAPIBody 请求体
+type OpenAPIBody struct {
+	Content map[string]*OpenAPIMediaType `json:"content" yaml:"content"`
+}
+
+// OpenAPIResponse 响应
+type OpenAPIResponse struct {
+	Description string                       `json:"description" yaml:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// OpenAPIMediaType 某个内容类型的结构和示例
+type OpenAPIMediaType struct {
+	Schema   map[string]interface{}     `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  interface{}                `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples map[string]*OpenAPIExample `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+// OpenAPIExample 命名示例
+type OpenAPIExample struct {
+	Summary string      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Value   interface{} `json:"value" yaml:"value"`
+}
+
+// OpenAPI 根据已注册的路由生成 OpenAPI 文档：元数据 summary、description、operationId、
+// tag 对应同名字段，路由示例（见 Route.Example）写入请求体和响应的 examples 并推断结构
+func (s *HTTPServer) OpenAPI(info OpenAPIInfo) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+	for _, route := range s.Routes() {
+		path := openAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = openAPIOperation(route)
+	}
+	return doc
+}
+
+// ExportOpenAPI 导出 OpenAPI 文档，format 为 "json" 或 "yaml"
+func (s *HTTPServer) ExportOpenAPI(info OpenAPIInfo, format string) ([]byte, error) {
+	doc := s.OpenAPI(info)
+	switch strings.ToLower(format) {
+	case "json":
+		return jsoniter.ConfigCompatibleWithStandardLibrary.MarshalIndent(doc, "", "  ")
+	case "yaml", "yml":
+		return yaml.Marshal(doc)
+	default:
+		return nil, fmt.Errorf("unsupported openapi format %q", format)
+	}
+}
+
+func openAPIOperation(route RouteInfo) *OpenAPIOperation {
+	op := &OpenAPIOperation{
+		Summary:     route.Metadata["summary"],
+		Description: route.Metadata["description"],
+		OperationID: route.Metadata["operationId"],
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+	if tag := route.Metadata["tag"]; tag != "" {
+		op.Tags = []string{tag}
+	}
+	for _, name := range route.Params {
+		op.Parameters = append(op.Parameters, OpenAPIParameter{
+			Name: name, In: "path", Required: true, Schema: map[string]interface{}{"type": "string"},
+		})
+	}
+
+	for i, ex := range route.Examples {
+		name := ex.Name
+		if name == "" {
+			name = "example" + strconv.Itoa(i+1)
+		}
+		if ex.Request.Body != nil {
+			if op.RequestBody == nil {
+				op.RequestBody = &OpenAPIBody{Content: make(map[string]*OpenAPIMediaType)}
+			}
+			addOpenAPIExample(op.RequestBody.Content, ex.Request.contentType(), name, ex.Request.Body)
+		}
+
+		status := strconv.Itoa(ex.status())
+		resp := op.Responses[status]
+		if resp == nil {
+			resp = &OpenAPIResponse{Description: getStatusText(ex.status())}
+			op.Responses[status] = resp
+		}
+		if ex.Response != nil {
+			if resp.Content == nil {
+				resp.Content = make(map[string]*OpenAPIMediaType)
+			}
+			addOpenAPIExample(resp.Content, ex.contentType(), name, ex.Response)
+		}
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = &OpenAPIResponse{Description: getStatusText(StatusOK)}
+	}
+	return op
+}
+
+// addOpenAPIExample 添加命名示例，第一个示例决定结构
+func addOpenAPIExample(content map[string]*OpenAPIMediaType, contentType, name string, value interface{}) {
+	value = exampleValue(value)
+	media := content[contentType]
+	if media == nil {
+		media = &OpenAPIMediaType{Schema: schemaOf(value), Examples: make(map[string]*OpenAPIExample)}
+		content[contentType] = media
+	}
+	media.Examples[name] = &OpenAPIExample{Value: value}
+}
+
+// openAPIPath 把 /users/:id 和 /files/*path 转换为 /users/{id}、/files/{path}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// schemaOf 从示例值推断 JSON Schema
+func schemaOf(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		props := make(map[string]interface{}, len(v))
+		required := make([]string, 0, len(v))
+		for key, field := range v {
+			props[key] = schemaOf(field)
+			required = append(required, key)
+		}
+		sort.Strings(required)
+		schema := map[string]interface{}{"type": "object", "properties": props}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	case []interface{}:
+		schema := map[string]interface{}{"type": "array"}
+		if len(v) > 0 {
+			schema["items"] = schemaOf(v[0])
+		} else {
+			schema["items"] = map[string]interface{}{}
+		}
+		return schema
+	case string:
+		return map[string]interface{}{"type": "string"}
+	case float64:
+		if v == float64(int64(v)) {
+			return map[string]interface{}{"type": "integer"}
+		}
+		return map[string]interface{}{"type": "number"}
+	case bool:
+		return map[string]interface{}{"type": "boolean"}
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,126 @@
+package meego
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRouteExamplesAndOpenAPI(t *testing.T) {
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	s := New()
+	s.GET("/users/:id", func(c *Context) {
+		if c.Param("id") == "0" {
+			c.Writer.Status(StatusNotFound).JSON(JSON{"error": "Not Found", "code": 404})
+			return
+		}
+		c.JSON(StatusOK, JSON{"id": 7, "name": "ann", "tags": []string{"a"}})
+	}).Meta("summary", "Get user").Meta("tag", "users").
+		Example(RouteExample{Name: "found", Request: ExampleRequest{Params: map[string]string{"id": "7"}},
+			Response: user{ID: 7, Name: "ann"}}).
+		Example(RouteExample{Name: "missing", Request: ExampleRequest{Params: map[string]string{"id": "0"}},
+			Status: StatusNotFound, Response: JSON{"error": "Not Found", "code": 404}})
+	s.POST("/users", func(c *Context) {
+		c.JSON(StatusCreated, JSON{"id": "oops"})
+	}).Example(RouteExample{Request: ExampleRequest{Body: JSON{"name": "bob"}}, Status: StatusCreated, Response: user{ID: 1, Name: "bob"}})
+
+	doc := s.OpenAPI(OpenAPIInfo{Title: "users", Version: "1.0"})
+	get := doc.Paths["/users/{id}"]["get"]
+	if get == nil || get.Summary != "Get user" || get.Tags[0] != "users" || len(get.Parameters) != 1 {
+		t.Fatalf("operation: %+v", get)
+	}
+	media := get.Responses["200"].Content["application/json"]
+	if media.Schema["type"] != "object" || media.Examples["found"] == nil || get.Responses["404"] == nil {
+		t.Fatalf("response examples: %+v", get.Responses)
+	}
+	if post := doc.Paths["/users"]["post"]; post.RequestBody.Content["application/json"].Examples["example1"] == nil {
+		t.Fatalf("request body example missing: %+v", post.RequestBody)
+	}
+	if data, err := s.ExportOpenAPI(OpenAPIInfo{Title: "users"}, "yaml"); err != nil || !strings.Contains(string(data), "/users/{id}:") {
+		t.Fatalf("yaml export: %v\n%s", err, data)
+	}
+
+	ts := NewTestServer(s)
+	defer ts.Close()
+	err := ts.VerifyExamples()
+	var contract *ContractError
+	if !errors.As(err, &contract) || len(contract.Failures) != 1 ||
+		!strings.Contains(contract.Failures[0], "POST /users") || !strings.Contains(contract.Failures[0], "$.id: expected number, got string") {
+		t.Fatalf("contract failures: %v", err)
+	}
+}
+
+const petstore = `
+openapi: 3.0.3
+info: {title: pets, version: "1"}
+paths:
+  /pets:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: "#/components/schemas/Pet"}
+  /pets/{id}:
+    parameters:
+      - {name: id, in: path, required: true}
+    get:
+      summary: Get pet
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              examples:
+                rex: {value: {id: 1, name: rex}}
+        "404":
+          description: missing
+          content:
+            application/json:
+              example: {error: Not Found}
+    delete:
+      responses:
+        "204": {description: deleted}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id: {type: integer}
+        name: {type: string, example: tom}
+`
+
+func TestStubFromOpenAPI(t *testing.T) {
+	doc, err := LoadOpenAPI([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New()
+	s.DELETE("/pets/:id", func(c *Context) {
+		c.String(StatusOK, "real")
+	})
+	routes := s.Stub(doc)
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 stub routes, got %d", len(routes))
+	}
+
+	for raw, want := range map[string]string{
+		"GET /pets HTTP/1.1\r\n\r\n":                           `[{"id":0,"name":"tom"}]`,
+		"GET /pets/9 HTTP/1.1\r\n\r\n":                         `{"id":1,"name":"rex"}`,
+		"GET /pets/9 HTTP/1.1\r\nPrefer: code=404\r\n\r\n":     `{"error":"Not Found"}`,
+		"DELETE /pets/9 HTTP/1.1\r\n\r\n":                      "real",
+		"GET /pets/9 HTTP/1.1\r\nPrefer: code=500\r\n\r\n":     `"code":501`,
+		"GET /pets/9 HTTP/1.1\r\nPrefer: example=rex\r\n\r\n":  `{"id":1,"name":"rex"}`,
+		"GET /pets/9 HTTP/1.1\r\nPrefer: code=404;x=1\r\n\r\n": `Not Found`,
+	} {
+		if resp := doRaw(s, raw); !strings.Contains(resp, want) {
+			t.Fatalf("%q: expected %s:\n%s", raw, want, resp)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// outbox.go
+package meego
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// OutboxEvent 待发送的领域事件
+type OutboxEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Payload   []byte    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OutboxStore 事件存储。Save 必须使用传入的事务写入，保证事件与业务数据一起提交或回滚；
+// 通常是业务数据库中的一张表
+type OutboxStore interface {
+	// Save 在事务中保存事件
+	Save(tx Tx, events []OutboxEvent) error
+	// Pending 按写入顺序返回最多 limit 个未发送的事件
+	Pending(limit int) ([]OutboxEvent, error)
+	// MarkSent 标记事件已发送
+	MarkSent(ids []string) error
+}
+
+// OutboxConfig 事件发件箱配置
+type OutboxConfig struct {
+	// Store 事件存储
+	Store OutboxStore
+	// Publish 发送一个事件（写入消息队列、调用 webhook 等），返回错误时稍后重试
+	Publish func(ctx context.Context, event OutboxEvent) error
+	// Interval 轮询未发送事件的间隔，默认 1s；事务提交后会立即触发一次发送
+	Interval time.Duration
+	// BatchSize 每次读取的事件数，默认 100
+	BatchSize int
+}
+
+// Outbox 事件发件箱：Transaction 中间件提交事务时把 c.Emit 记录的事件写入同一个事务，
+// 后台在协程池中按顺序发送并标记。发送失败的事件保留在存储中，下次轮询重试，
+// 因此事件至少发送一次，Publish 的接收方需要按事件 ID 去重
+type Outbox struct {
+	cfg     OutboxConfig
+	server  *HTTPServer
+	notify  chan struct{}
+	running atomic.Bool
+}
+
+// Outbox 创建事件发件箱，服务器启动后开始发送，应在 Start 之前调用
+func (s *HTTPServer) Outbox(cfg OutboxConfig) *Outbox {
+	if cfg.Store == nil || cfg.Publish == nil {
+		panic("meego: Outbox requires Store and Publish")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	o := &Outbox{cfg: cfg, server: s, notify: make(chan struct{}, 1)}
+
+	s.OnStart(func(net.Addr) error {
+		stop := make(chan struct{})
+		go func() {
+			ticker := clock().NewTicker(cfg.Interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+				case <-o.notify:
+				case <-stop:
+					return
+				}
+				o.schedule()
+			}
+		}()
+		s.OnShutdown(func() { close(stop) })
+		return nil
+	})
+	return o
+}
+
+// Notify 触发一次发送，事务提交后自动调用
+func (o *Outbox) Notify() {
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// schedule 在协程池中执行一次发送，上一次还没结束时跳过
+func (o *Outbox) schedule() {
+	if !o.running.CompareAndSwap(false, true) {
+		return
+	}
+	if err := o.server.pool.Submit(func() {
+		defer o.running.Store(false)
+		o.Dispatch()
+	}); err != nil {
+		o.running.Store(false)
+	}
+}
+
+// Dispatch 发送所有未发送的事件，返回成功发送的数量。
+// 某个事件发送失败时停止，保证同一个存储中的事件按顺序发送
+func (o *Outbox) Dispatch() int {
+	ctx := context.Background()
+	if o.server != nil {
+		ctx = o.server.serverCtx
+	}
+	total := 0
+	for {
+		events, err := o.cfg.Store.Pending(o.cfg.BatchSize)
+		if err != nil {
+			log.Error().Err(err).Msg("outbox store error")
+			return total
+		}
+		sent := make([]string, 0, len(events))
+		var failed error
+		for _, event := range events {
+			if failed = o.cfg.Publish(ctx, event); failed != nil {
+				log.Warn().Err(failed).Str("event", event.ID).Str("type", event.Type).Msg("outbox publish failed")
+				break
+			}
+			sent = append(sent, event.ID)
+		}
+		if len(sent) > 0 {
+			if err := o.cfg.Store.MarkSent(sent); err != nil {
+				log.Error().Err(err).Msg("outbox store error")
+				return total
+			}
+			total += len(sent)
+		}
+		if failed != nil || len(events) < o.cfg.BatchSize {
+			return total
+		}
+	}
+}
+
+// MemoryOutboxStore 进程内事件存储，不参与事务（Save 忽略 tx），
+// 进程退出时未发送的事件丢失，只适用于测试和开发
+type MemoryOutboxStore struct {
+	mu     sync.Mutex
+	seq    int64
+	events map[string]memoryOutboxEntry
+}
+
+type memoryOutboxEntry struct {
+	seq   int64
+	event OutboxEvent
+}
+
+// NewMemoryOutboxStore 创建进程内事件存储
+func NewMemoryOutboxStore() *MemoryOutboxStore {
+	return &MemoryOutboxStore{events: make(map[string]memoryOutboxEntry)}
+}
+
+// Save 保存事件
+func (m *MemoryOutboxStore) Save(_ Tx, events []OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, event := range events {
+		m.seq++
+		m.events[event.ID] = memoryOutboxEntry{seq: m.seq, event: event}
+	}
+	return nil
+}
+
+// Pending 按写入顺序返回未发送的事件
+func (m *MemoryOutboxStore) Pending(limit int) ([]OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entries := make([]memoryOutboxEntry, 0, len(m.events))
+	for _, entry := range m.events {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	events := make([]OutboxEvent, len(entries))
+	for i, entry := range entries {
+		events[i] = entry.event
+	}
+	return events, nil
+}
+
+// MarkSent 删除已发送的事件
+func (m *MemoryOutboxStore) MarkSent(ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.events, id)
+	}
+	return nil
+}
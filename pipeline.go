@@ -0,0 +1,92 @@
+// pipeline.go
+package meego
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+// errResponseFinished 响应已经结束后仍有写入（如超时后仍在运行的处理器、再次写出完整响应）
+var errResponseFinished = errors.New("response already finished")
+
+// responseQueue 由连接处理器持有，保证流水线请求的响应严格按请求顺序写出：
+// 第 n 个响应结束前，第 n+1 个响应的写入会等待；已结束的响应再写入时返回
+// errResponseFinished，不会混入后续响应
+type responseQueue struct {
+	conn   net.Conn
+	mu     sync.Mutex
+	cond   *sync.Cond
+	issued uint64 // 已分配的响应序号
+	seq    uint64 // 当前允许写出的响应序号
+}
+
+func newResponseQueue(conn net.Conn) *responseQueue {
+	q := &responseQueue{conn: conn}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// next 按请求顺序为下一个响应分配写出视图
+func (q *responseQueue) next() *queuedConn {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	rc := &queuedConn{Conn: q.conn, queue: q, seq: q.issued}
+	q.issued++
+	return rc
+}
+
+// finish 结束响应，轮到下一个响应写出
+func (q *responseQueue) finish(rc *queuedConn) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.seq == rc.seq {
+		q.seq++
+		q.cond.Broadcast()
+	}
+}
+
+// acquire 等待轮到 rc 写出，返回时持有锁
+func (q *responseQueue) acquire(rc *queuedConn) error {
+	q.mu.Lock()
+	for rc.seq > q.seq {
+		q.cond.Wait()
+	}
+	if rc.seq < q.seq {
+		q.mu.Unlock()
+		return errResponseFinished
+	}
+	return nil
+}
+
+// queuedConn 单个响应对连接的写出视图，其余方法（超时、地址等）直接使用底层连接
+type queuedConn struct {
+	net.Conn
+	queue *responseQueue
+	seq   uint64
+}
+
+func (rc *queuedConn) Write(p []byte) (int, error) {
+	if err := rc.queue.acquire(rc); err != nil {
+		return 0, err
+	}
+	defer rc.queue.mu.Unlock()
+	return rc.Conn.Write(p)
+}
+
+// writeBuffers 一次写出多段数据，保留底层连接的 writev 优化
+func (rc *queuedConn) writeBuffers(buffers net.Buffers) (int64, error) {
+	if err := rc.queue.acquire(rc); err != nil {
+		return 0, err
+	}
+	defer rc.queue.mu.Unlock()
+	return buffers.WriteTo(rc.Conn)
+}
+
+// writeBuffers 向响应连接写出多段数据
+func writeBuffers(conn net.Conn, buffers net.Buffers) (int64, error) {
+	if rc, ok := conn.(*queuedConn); ok {
+		return rc.writeBuffers(buffers)
+	}
+	return buffers.WriteTo(conn)
+}
@@ -0,0 +1,41 @@
+package meego
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestResponseQueueOrder(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	q := newResponseQueue(server)
+	first, second := q.next(), q.next()
+
+	// 第二个响应先写，必须等第一个响应结束
+	done := make(chan error, 1)
+	go func() {
+		_, err := second.Write([]byte("second;"))
+		q.finish(second)
+		server.Close()
+		done <- err
+	}()
+	go func() {
+		first.Write([]byte("first;"))
+		q.finish(first)
+		// 已结束的响应不能再写入
+		if _, err := first.Write([]byte("late;")); !errors.Is(err, errResponseFinished) {
+			done <- fmt.Errorf("late write: %v", err)
+		}
+	}()
+
+	out, _ := io.ReadAll(client)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "first;second;" {
+		t.Fatalf("unexpected order: %q", out)
+	}
+}
@@ -0,0 +1,72 @@
+// pool_guard.go
+package meego
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PoolMisuseError DebugMode 和 TestMode 下检测到的对象池误用：重复释放或释放后继续使用
+type PoolMisuseError struct {
+	Kind       string // Context、ResponseWriter、HTTPRequest
+	Op         string // 触发检测的操作
+	Generation uint32 // 对象被释放的次数
+	ReleasedAt string // 上一次释放时的调用栈，仅 TestMode 下记录
+}
+
+func (e *PoolMisuseError) Error() string {
+	return fmt.Sprintf("meego: %s %s (generation %d); previously released at:\n%s",
+		e.Kind, e.Op, e.Generation, e.ReleasedAt)
+}
+
+// poolGuard 嵌入到池化对象中，记录代数和释放状态
+type poolGuard struct {
+	gen          uint32
+	released     bool
+	releaseStack []byte
+}
+
+func (g *poolGuard) acquire() {
+	g.released = false
+	g.releaseStack = nil
+}
+
+// poolChecking 是否检测对象池误用
+func poolChecking() bool {
+	return IsDebugging() || isTestMode()
+}
+
+// release 标记对象已释放，DebugMode 和 TestMode 下重复释放会 panic
+func (g *poolGuard) release(kind string) {
+	if g.released && poolChecking() {
+		panic(&PoolMisuseError{
+			Kind:       kind,
+			Op:         "released twice",
+			Generation: g.gen,
+			ReleasedAt: string(g.releaseStack),
+		})
+	}
+	g.released = true
+	g.gen++
+	// 每个请求释放多个池化对象，只在 TestMode 下承担记录调用栈的开销
+	if isTestMode() {
+		g.releaseStack = debug.Stack()
+	}
+}
+
+// check DebugMode 和 TestMode 下检测释放后继续使用
+func (g *poolGuard) check(kind, op string) {
+	if g.released && poolChecking() {
+		panic(&PoolMisuseError{
+			Kind:       kind,
+			Op:         "used after release: " + op,
+			Generation: g.gen,
+			ReleasedAt: string(g.releaseStack),
+		})
+	}
+}
+
+// Generation 返回对象被放回对象池的次数，可在协程中比较以确认上下文未被复用
+func (c *Context) Generation() uint32 {
+	return c.guard.gen
+}
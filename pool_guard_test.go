@@ -0,0 +1,41 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPoolGuard(t *testing.T) {
+	SetMode(DebugMode)
+
+	c := acquireContext()
+	releaseContext(c)
+
+	mustPanic := func(name string, fn func()) {
+		t.Helper()
+		defer func() {
+			if _, ok := recover().(*PoolMisuseError); !ok {
+				t.Fatalf("%s: expected *PoolMisuseError panic", name)
+			}
+		}()
+		fn()
+	}
+	mustPanic("use after release", func() { c.Set("k", "v") })
+	mustPanic("double release", func() { releaseContext(c) })
+	if c.guard.releaseStack != nil {
+		t.Fatal("release stack captured outside TestMode")
+	}
+
+	// TestMode 下记录上一次释放的位置
+	SetMode(TestMode)
+	defer SetMode(DebugMode)
+	c = acquireContext()
+	releaseContext(c)
+	defer func() {
+		err, _ := recover().(*PoolMisuseError)
+		if err == nil || !strings.Contains(err.ReleasedAt, "releaseContext") {
+			t.Fatalf("expected release stack in misuse error, got %v", err)
+		}
+	}()
+	c.Set("k", "v")
+}
@@ -0,0 +1,48 @@
+package meego
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestApplyPreset(t *testing.T) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	s := New()
+	s.ApplyPreset(PresetLowMemory)
+	if s.pool.Cap() != PresetLowMemory.PoolSize || s.router.cacheSize != PresetLowMemory.RouteCacheSize ||
+		s.readBufferSize != PresetLowMemory.ReadBufferSize {
+		t.Fatalf("preset not applied: pool=%d cache=%d buffer=%d", s.pool.Cap(), s.router.cacheSize, s.readBufferSize)
+	}
+	if gc := debug.SetGCPercent(100); gc != PresetLowMemory.GCPercent {
+		t.Fatalf("GOGC = %d", gc)
+	}
+
+	s.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+	if resp := doRaw(s, "GET / HTTP/1.1\r\nHost: x\r\n\r\n"); !strings.HasSuffix(resp, "ok") {
+		t.Fatalf("request with small read buffer:\n%s", resp)
+	}
+}
+
+func BenchmarkPreset(b *testing.B) {
+	defer debug.SetGCPercent(debug.SetGCPercent(100))
+
+	presets := []Preset{{Name: "default"}, PresetLowLatency, PresetHighThroughput, PresetLowMemory}
+	for _, p := range presets {
+		b.Run(p.Name, func(b *testing.B) {
+			debug.SetGCPercent(100)
+			s := New()
+			s.ApplyPreset(p)
+			s.GET("/users/:id", func(c *Context) {
+				c.JSON(StatusOK, JSON{"id": c.Param("id"), "name": "meego"})
+			})
+			raw := "GET /users/42 HTTP/1.1\r\nHost: x\r\n\r\n"
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				doRaw(s, raw)
+			}
+		})
+	}
+}
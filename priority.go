@@ -0,0 +1,221 @@
+// priority.go
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 请求紧急程度（RFC 9218），数值越小越紧急
+const (
+	UrgencyHighest = 0
+	UrgencyDefault = 3
+	UrgencyLowest  = 7
+)
+
+// Priority 解析后的 Priority 请求头（RFC 9218）
+type Priority struct {
+	// Urgency 紧急程度 0-7，默认 3；交互请求通常更小，后台同步通常更大
+	Urgency int
+	// Incremental 响应是否可以增量处理
+	Incremental bool
+}
+
+// DefaultPriority 没有 Priority 头或头部无法解析时的优先级
+var DefaultPriority = Priority{Urgency: UrgencyDefault}
+
+const priorityKey = "meego.priority"
+
+// ParsePriority 解析 Priority 头（结构化字段字典，如 "u=1, i"）。
+// 未知的成员和格式错误的值被忽略，对应字段保持默认值
+func ParsePriority(header string) Priority {
+	p := DefaultPriority
+	for _, member := range strings.Split(header, ",") {
+		// 丢弃成员参数
+		if i := strings.IndexByte(member, ';'); i >= 0 {
+			member = member[:i]
+		}
+		key, value, hasValue := strings.Cut(strings.TrimSpace(member), "=")
+		switch key {
+		case "u":
+			if u, err := strconv.Atoi(value); err == nil && u >= UrgencyHighest && u <= UrgencyLowest {
+				p.Urgency = u
+			}
+		case "i":
+			switch {
+			case !hasValue || value == "?1":
+				p.Incremental = true
+			case value == "?0":
+				p.Incremental = false
+			}
+		}
+	}
+	return p
+}
+
+// Priority 返回请求的优先级，结果在本次请求内缓存
+func (c *Context) Priority() Priority {
+	if p, ok := c.Get(priorityKey).(Priority); ok {
+		return p
+	}
+	p := ParsePriority(c.Request.GetHeader("Priority"))
+	c.Set(priorityKey, p)
+	return p
+}
+
+// OverloadConfig 过载调度配置
+type OverloadConfig struct {
+	// MaxConcurrent 同时执行的请求数，默认 100
+	MaxConcurrent int
+	// MaxQueue 等待执行的请求数，默认等于 MaxConcurrent，负数表示不排队直接拒绝
+	MaxQueue int
+	// QueueTimeout 排队的最长时间，超时返回 503，默认 1s
+	QueueTimeout time.Duration
+	// Urgency 请求的紧急程度，默认使用 Priority 头的 u 参数
+	Urgency func(c *Context) int
+}
+
+// Overload 过载调度中间件，最多同时执行 maxConcurrent 个请求
+func Overload(maxConcurrent int) MiddlewareFunc {
+	return OverloadWithConfig(OverloadConfig{MaxConcurrent: maxConcurrent})
+}
+
+// OverloadWithConfig 使用自定义配置的过载调度中间件。并发已满时请求排队，空出的名额优先交给
+// 最紧急的请求（同等紧急程度按到达顺序）；队列已满时挤掉最不紧急的排队请求，
+// 新请求不比它们更紧急时直接拒绝。被拒绝或排队超时的请求返回 503 和 Retry-After
+func OverloadWithConfig(cfg OverloadConfig) MiddlewareFunc {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = 100
+	}
+	switch {
+	case cfg.MaxQueue == 0:
+		cfg.MaxQueue = cfg.MaxConcurrent
+	case cfg.MaxQueue < 0:
+		cfg.MaxQueue = 0
+	}
+	if cfg.QueueTimeout <= 0 {
+		cfg.QueueTimeout = time.Second
+	}
+	if cfg.Urgency == nil {
+		cfg.Urgency = func(c *Context) int { return c.Priority().Urgency }
+	}
+	sched := newOverloadScheduler(cfg.MaxConcurrent, cfg.MaxQueue)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if !sched.acquire(cfg.Urgency(c), cfg.QueueTimeout) {
+				c.Writer.SetHeader("Retry-After", "1")
+				c.Writer.Status(StatusServiceUnavailable).JSON(JSON{
+					"error": "Service Overloaded",
+					"code":  StatusServiceUnavailable,
+				})
+				return
+			}
+			defer sched.release()
+			next(c)
+		}
+	}
+}
+
+// overloadScheduler 按紧急程度分配执行名额
+type overloadScheduler struct {
+	mu       sync.Mutex
+	active   int
+	max      int
+	maxQueue int
+	seq      uint64
+	waiters  []*overloadWaiter
+}
+
+// overloadWaiter 排队的请求，ready 收到 true 表示获得名额，false 表示被挤出队列
+type overloadWaiter struct {
+	urgency int
+	seq     uint64
+	ready   chan bool
+}
+
+func newOverloadScheduler(max, maxQueue int) *overloadScheduler {
+	return &overloadScheduler{max: max, maxQueue: maxQueue}
+}
+
+// acquire 获取执行名额，被拒绝或排队超时时返回 false
+func (s *overloadScheduler) acquire(urgency int, timeout time.Duration) bool {
+	s.mu.Lock()
+	if s.active < s.max {
+		s.active++
+		s.mu.Unlock()
+		return true
+	}
+	if len(s.waiters) >= s.maxQueue {
+		i := s.leastUrgent()
+		if i < 0 || s.waiters[i].urgency <= urgency {
+			s.mu.Unlock()
+			return false
+		}
+		s.remove(i).ready <- false
+	}
+	s.seq++
+	w := &overloadWaiter{urgency: urgency, seq: s.seq, ready: make(chan bool, 1)}
+	s.waiters = append(s.waiters, w)
+	s.mu.Unlock()
+
+	select {
+	case ok := <-w.ready:
+		return ok
+	case <-clock().After(timeout):
+	}
+	s.mu.Lock()
+	for i, other := range s.waiters {
+		if other == w {
+			s.remove(i)
+			s.mu.Unlock()
+			return false
+		}
+	}
+	s.mu.Unlock()
+	// 超时的同时已经获得名额或被挤出
+	return <-w.ready
+}
+
+// release 归还名额，有排队请求时直接交给最紧急的一个
+func (s *overloadScheduler) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.mostUrgent(); i >= 0 {
+		s.remove(i).ready <- true
+		return
+	}
+	s.active--
+}
+
+// mostUrgent 最紧急、最早到达的排队请求
+func (s *overloadScheduler) mostUrgent() int {
+	best := -1
+	for i, w := range s.waiters {
+		if best < 0 || w.urgency < s.waiters[best].urgency ||
+			(w.urgency == s.waiters[best].urgency && w.seq < s.waiters[best].seq) {
+			best = i
+		}
+	}
+	return best
+}
+
+// leastUrgent 最不紧急、最晚到达的排队请求
+func (s *overloadScheduler) leastUrgent() int {
+	worst := -1
+	for i, w := range s.waiters {
+		if worst < 0 || w.urgency > s.waiters[worst].urgency ||
+			(w.urgency == s.waiters[worst].urgency && w.seq > s.waiters[worst].seq) {
+			worst = i
+		}
+	}
+	return worst
+}
+
+func (s *overloadScheduler) remove(i int) *overloadWaiter {
+	w := s.waiters[i]
+	s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+	return w
+}
@@ -0,0 +1,81 @@
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPriority(t *testing.T) {
+	cases := map[string]Priority{
+		"":               DefaultPriority,
+		"u=1":            {Urgency: 1},
+		"u=5, i":         {Urgency: 5, Incremental: true},
+		"i=?0, u=9":      {Urgency: UrgencyDefault},
+		"u=0;x=1, i=?1":  {Urgency: 0, Incremental: true},
+		"u=abc, foo=bar": DefaultPriority,
+	}
+	for header, want := range cases {
+		if got := ParsePriority(header); got != want {
+			t.Errorf("ParsePriority(%q) = %+v, want %+v", header, got, want)
+		}
+	}
+
+	s := New()
+	s.GET("/p", func(c *Context) {
+		c.String(StatusOK, strconv.Itoa(c.Priority().Urgency))
+	})
+	if resp := doRaw(s, "GET /p HTTP/1.1\r\nPriority: u=1, i\r\n\r\n"); !strings.HasSuffix(resp, "\r\n\r\n1") {
+		t.Fatalf("priority: %q", resp)
+	}
+}
+
+func TestOverloadScheduler(t *testing.T) {
+	sched := newOverloadScheduler(1, 2)
+	if !sched.acquire(UrgencyDefault, time.Second) {
+		t.Fatal("first request should run")
+	}
+
+	queued := func(n int) {
+		for i := 0; i < 100; i++ {
+			sched.mu.Lock()
+			l := len(sched.waiters)
+			sched.mu.Unlock()
+			if l == n {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("expected %d waiters", n)
+	}
+	wait := func(urgency int) chan bool {
+		ch := make(chan bool, 1)
+		go func() { ch <- sched.acquire(urgency, time.Minute) }()
+		return ch
+	}
+
+	background := wait(UrgencyLowest)
+	queued(1)
+	syncReq := wait(5)
+	queued(2)
+	// 队列已满：更紧急的请求挤掉最不紧急的后台请求
+	interactive := wait(UrgencyHighest)
+	if ok := <-background; ok {
+		t.Fatal("background request should be shed")
+	}
+	queued(2)
+	// 不比排队请求更紧急时直接拒绝
+	if sched.acquire(UrgencyLowest, time.Minute) {
+		t.Fatal("low urgency request should be rejected")
+	}
+
+	sched.release()
+	if ok := <-interactive; !ok {
+		t.Fatal("interactive request should run first")
+	}
+	sched.release()
+	if ok := <-syncReq; !ok {
+		t.Fatal("sync request should run next")
+	}
+}
@@ -0,0 +1,425 @@
+// proxy.go
+package meego
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ProxyConfig 反向代理配置
+type ProxyConfig struct {
+	// Target 上游地址，如 http://files.internal:8080；主机名也可以是
+	// Client.AddUpstream 注册的逻辑上游
+	Target string
+	// Client 出站客户端，默认为带连接池、不跟随重定向、不设置总超时、不自动解压的客户端
+	Client *Client
+	// StripPrefix 转发前去掉的路径前缀
+	StripPrefix string
+	// Retries 响应体传输中途上游断开时，使用 Range 续传的最大次数，默认 2，负数表示不续传
+	Retries int
+	// IdleTimeout 向客户端写出数据的空闲超时，替代服务器的写超时，
+	// 大文件下载只要持续有数据就不会被中断，默认 60s
+	IdleTimeout time.Duration
+	// Upstream 设置后以 Target 的主机名注册到 Client，按其解析器、负载均衡和健康状态选择地址
+	Upstream *Upstream
+	// HashKey 会话保持的键，配合 ConsistentHash 使用，如 CookieHashKey("session_id")
+	HashKey func(c *Context) string
+	// TLS https 上游的 TLS 选项（客户端证书、自定义根证书等），通过 Client.SetHostTLS 按 Target 主机设置
+	TLS *UpstreamTLS
+	// Forwarded 向上游传递客户端地址、协议和主机的方式，默认 ForwardedXHeaders
+	Forwarded ForwardedMode
+	// ForwardedBy Forwarded 头的 by 值，如 "_gateway"，默认使用本机监听地址
+	ForwardedBy string
+	// Host 发送给上游的 Host 头，默认为 Target 的主机
+	Host string
+	// PreserveHost 使用客户端请求的 Host 头，优先于 Host，适用于按域名路由的上游
+	PreserveHost bool
+}
+
+// CookieHashKey 以 Cookie 作为会话保持的键
+func CookieHashKey(name string) func(c *Context) string {
+	return func(c *Context) string {
+		value, _ := c.Cookie(name)
+		return value
+	}
+}
+
+// HeaderHashKey 以请求头作为会话保持的键
+func HeaderHashKey(name string) func(c *Context) string {
+	return func(c *Context) string {
+		return c.Request.GetHeader(name)
+	}
+}
+
+// hopHeaders 逐跳头部，不转发（RFC 9110 7.6.1）
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Connection", "Proxy-Authenticate", "Proxy-Authorization",
+	"Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+func isHopHeader(key string) bool {
+	for _, h := range hopHeaders {
+		if strings.EqualFold(h, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// connectionHeaders 返回 Connection 头列出的头部名（规范化），它们同样是逐跳头部，不转发
+func connectionHeaders(values ...string) map[string]bool {
+	var names map[string]bool
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				if names == nil {
+					names = make(map[string]bool)
+				}
+				names[http.CanonicalHeaderKey(name)] = true
+			}
+		}
+	}
+	return names
+}
+
+// defaultProxyClient 代理专用客户端：复用上游连接，响应原样转发，由 IdleTimeout 控制超时
+func defaultProxyClient() *Client {
+	cl := NewClientWithConfig(ClientConfig{Timeout: -1, DisableCompression: true})
+	cl.HTTPClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return cl
+}
+
+// ReverseProxy 反向代理处理器。响应体边读边写，不在内存中缓冲；Range/If-Range
+// 请求原样透传。GET 响应支持续传（200/206、带强 ETag 或 Last-Modified）时，
+// 上游在传输中途断开会以 Range 请求剩余部分继续发送，客户端感知不到中断
+func ReverseProxy(cfg ProxyConfig) HandlerFunc {
+	target, err := url.Parse(strings.TrimRight(cfg.Target, "/"))
+	if err != nil || target.Host == "" {
+		panic("meego: invalid proxy target " + cfg.Target)
+	}
+	if cfg.Client == nil {
+		cfg.Client = defaultProxyClient()
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = 2
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 60 * time.Second
+	}
+	if cfg.Upstream != nil {
+		cfg.Client.AddUpstream(target.Host, cfg.Upstream)
+	}
+	if cfg.TLS != nil {
+		if err := cfg.Client.SetHostTLS(target.Host, cfg.TLS); err != nil {
+			panic(err.Error())
+		}
+	}
+
+	return func(c *Context) {
+		p := &proxyRequest{cfg: cfg, c: c}
+		p.serve(target)
+	}
+}
+
+// Proxy 把请求原样转发到 target 的反向代理处理器，使用默认配置
+func Proxy(target *url.URL) HandlerFunc {
+	return ReverseProxy(ProxyConfig{Target: target.String()})
+}
+
+// ProxyPass 把 prefix 下所有方法的请求转发到 target，转发前去掉 prefix，
+// 如 s.ProxyPass("/api", target) 把 /api/users 转发到 target 的 /users。
+// 请求体不在内存中缓冲，直接流式发送到上游
+func (s *HTTPServer) ProxyPass(prefix string, target *url.URL) []*Route {
+	prefix = strings.TrimRight(prefix, "/")
+	return proxyPassRoutes(s.Any(prefix+"/*proxypath", proxyPassHandler(prefix, target)))
+}
+
+// ProxyPass 在路由组内注册转发路由，去掉的前缀包括路由组前缀
+func (g *RouteGroup) ProxyPass(prefix string, target *url.URL) []*Route {
+	prefix = strings.TrimRight(prefix, "/")
+	return proxyPassRoutes(g.Any(prefix+"/*proxypath", proxyPassHandler(g.prefix+prefix, target)))
+}
+
+func proxyPassHandler(prefix string, target *url.URL) HandlerFunc {
+	return ReverseProxy(ProxyConfig{Target: target.String(), StripPrefix: prefix})
+}
+
+func proxyPassRoutes(routes []*Route) []*Route {
+	for _, route := range routes {
+		route.StreamBody()
+	}
+	return routes
+}
+
+type proxyRequest struct {
+	cfg ProxyConfig
+	c   *Context
+	ctx context.Context
+}
+
+func (p *proxyRequest) serve(target *url.URL) {
+	c := p.c
+	parent := context.Background()
+	if c.server != nil {
+		parent = c.server.serverCtx
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	if p.cfg.HashKey != nil {
+		if key := p.cfg.HashKey(c); key != "" {
+			ctx = WithBalanceKey(ctx, key)
+		}
+	}
+	p.ctx = ctx
+	// 由 IdleTimeout 接管超时，不再向上游传递剩余处理时间
+	c.deadline = time.Time{}
+
+	out, err := p.newRequest(target)
+	if err != nil {
+		c.Writer.Status(StatusBadRequest).JSON(JSON{"error": "Bad Request", "code": StatusBadRequest})
+		return
+	}
+	resp, err := p.do(out)
+	if err != nil {
+		p.upstreamError(err)
+		return
+	}
+
+	dropped := connectionHeaders(resp.Header.Values("Connection")...)
+	for key, values := range resp.Header {
+		switch {
+		case isHopHeader(key) || dropped[key]:
+		case key == "Set-Cookie":
+			// 每个 Cookie 单独一行，合并后带 Expires 的 Cookie 无法解析
+			c.Writer.cookies = append(c.Writer.cookies, values...)
+		default:
+			c.Writer.SetHeader(key, strings.Join(values, ", "))
+		}
+	}
+	if resp.ContentLength >= 0 {
+		c.Writer.SetHeader("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+	} else {
+		delete(c.Writer.header, "Content-Length")
+	}
+	c.Writer.Status(resp.StatusCode)
+	p.stream(out, resp)
+}
+
+// newRequest 构造转发到上游的请求
+func (p *proxyRequest) newRequest(target *url.URL) (*http.Request, error) {
+	c := p.c
+	path := c.Request.URL.Path
+	if p.cfg.StripPrefix != "" {
+		path = strings.TrimPrefix(path, p.cfg.StripPrefix)
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+	}
+	u := *target
+	u.Path = target.Path + path
+	u.RawPath = ""
+	u.RawQuery = c.Request.URL.RawQuery
+
+	var body io.Reader
+	if c.Request.bodyReader != nil {
+		body = c.Request.bodyReader
+	} else if len(c.Request.Body) > 0 {
+		body = bytes.NewReader(c.Request.Body)
+	}
+	out, err := http.NewRequestWithContext(p.ctx, c.Request.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	dropped := connectionHeaders(c.Request.GetHeader("Connection"))
+	for key, value := range c.Request.Headers {
+		if !isHopHeader(key) && !dropped[http.CanonicalHeaderKey(key)] &&
+			!strings.EqualFold(key, "Host") && !strings.EqualFold(key, "Content-Length") {
+			out.Header.Set(key, value)
+		}
+	}
+	setForwardedHeaders(c, out.Header, p.cfg.Forwarded, p.cfg.ForwardedBy)
+	if p.cfg.PreserveHost && c.Request.Host != "" {
+		out.Host = c.Request.Host
+	} else if p.cfg.Host != "" {
+		out.Host = p.cfg.Host
+	}
+	if n := c.Request.ContentLength(); n > 0 && c.Request.bodyReader != nil {
+		out.ContentLength = int64(n)
+	}
+	return out, nil
+}
+
+// do 发送请求；启用了 Hedging 中间件时，没有请求体的 GET 和 HEAD 请求使用对冲
+func (p *proxyRequest) do(out *http.Request) (*http.Response, error) {
+	c := p.c
+	h, _ := c.Get(hedgingKey).(*Hedging)
+	if h == nil || (out.Method != "GET" && out.Method != "HEAD") || out.Body != nil {
+		return p.cfg.Client.Do(c, out)
+	}
+	// 两次请求在各自的协程中发送，先在这里注入透传头部
+	for key, value := range c.PropagationHeaders() {
+		if out.Header.Get(key) == "" {
+			out.Header.Set(key, value)
+		}
+	}
+	return h.do(p.ctx, p.cfg.Client, out)
+}
+
+// upstreamError 上游不可用时返回 502，超时返回 504
+func (p *proxyRequest) upstreamError(err error) {
+	c := p.c
+	code, msg := StatusBadGateway, "Bad Gateway"
+	if errors.Is(err, context.DeadlineExceeded) || isTimeoutError(err) {
+		code, msg = StatusGatewayTimeout, "Gateway Timeout"
+	}
+	c.Logger().Warn().Err(err).Msg("proxy upstream request failed")
+	c.Writer.Status(code).JSON(JSON{"error": msg, "code": code})
+}
+
+// errClientWrite 向客户端写出失败，不再续传
+var errClientWrite = errors.New("proxy: client write failed")
+
+// stream 转发响应体，上游中途断开时尝试续传
+func (p *proxyRequest) stream(out *http.Request, resp *http.Response) {
+	w := p.c.Writer
+	resume := p.resumeState(out, resp)
+
+	var written int64
+	for attempt := 0; ; attempt++ {
+		n, err := p.copyBody(resp.Body)
+		resp.Body.Close()
+		written += n
+		if err == nil {
+			if written == 0 {
+				// 空响应体也要发送头部
+				w.startStream()
+			}
+			return
+		}
+		if err == errClientWrite || resume == nil || attempt >= p.cfg.Retries || p.ctx.Err() != nil {
+			p.c.Logger().Warn().Err(err).Int64("written", written).Msg("proxy response aborted")
+			w.abortStream()
+			return
+		}
+
+		next, rerr := p.resumeRequest(out, resume, written)
+		if rerr != nil {
+			p.c.Logger().Warn().Err(rerr).Int64("written", written).Msg("proxy resume failed")
+			w.abortStream()
+			return
+		}
+		p.c.Logger().Info().Int64("offset", resume.start+written).Int("attempt", attempt+1).Msg("proxy resumed upstream transfer")
+		resp = next
+	}
+}
+
+// copyBody 从上游读取并写给客户端，每次写之前刷新写超时
+func (p *proxyRequest) copyBody(body io.Reader) (int64, error) {
+	buf := make([]byte, 32*1024)
+	var total int64
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			p.c.Conn.SetWriteDeadline(time.Now().Add(p.cfg.IdleTimeout))
+			if werr := p.c.Writer.WriteChunk(buf[:n]); werr != nil {
+				return total, errClientWrite
+			}
+			total += int64(n)
+		}
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// proxyResume 续传所需的信息
+type proxyResume struct {
+	start, end int64  // 首个响应覆盖的字节范围，end 为 -1 表示到结尾
+	validator  string // If-Range 使用的强 ETag 或 Last-Modified
+}
+
+func (p *proxyRequest) resumeState(out *http.Request, resp *http.Response) *proxyResume {
+	if p.cfg.Retries < 0 || out.Method != "GET" {
+		return nil
+	}
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = resp.Header.Get("Last-Modified")
+	}
+	if validator == "" {
+		return nil
+	}
+
+	switch resp.StatusCode {
+	case StatusOK:
+		if resp.Header.Get("Accept-Ranges") != "bytes" {
+			return nil
+		}
+		return &proxyResume{start: 0, end: -1, validator: validator}
+	case StatusPartialContent:
+		start, end, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok {
+			return nil
+		}
+		return &proxyResume{start: start, end: end, validator: validator}
+	}
+	return nil
+}
+
+// resumeRequest 请求剩余部分，上游必须以 206 返回从断点开始的数据
+func (p *proxyRequest) resumeRequest(out *http.Request, r *proxyResume, written int64) (*http.Response, error) {
+	req := out.Clone(p.ctx)
+	offset := r.start + written
+	rng := "bytes=" + strconv.FormatInt(offset, 10) + "-"
+	if r.end >= 0 {
+		rng += strconv.FormatInt(r.end, 10)
+	}
+	req.Header.Set("Range", rng)
+	req.Header.Set("If-Range", r.validator)
+
+	resp, err := p.cfg.Client.Do(nil, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("upstream returned %d for resume", resp.StatusCode)
+	}
+	if start, _, ok := parseContentRange(resp.Header.Get("Content-Range")); !ok || start != offset {
+		resp.Body.Close()
+		return nil, fmt.Errorf("upstream resumed at unexpected range %q", resp.Header.Get("Content-Range"))
+	}
+	return resp, nil
+}
+
+// parseContentRange 解析 "bytes 100-199/1000"
+func parseContentRange(value string) (start, end int64, ok bool) {
+	spec, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	spec, _, _ = strings.Cut(spec, "/")
+	a, b, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err1 := strconv.ParseInt(a, 10, 64)
+	end, err2 := strconv.ParseInt(b, 10, 64)
+	if err1 != nil || err2 != nil || start > end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
@@ -0,0 +1,223 @@
+package meego
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReverseProxyResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 10000)
+	var requests []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("Range"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("Range") == "" {
+			// 第一次请求发送一半后断开
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", "100000")
+			w.Write(content[:40000])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, bytes.NewReader(content))
+	}))
+	defer upstream.Close()
+
+	s := New()
+	s.GET("/files/*path", ReverseProxy(ProxyConfig{Target: upstream.URL, StripPrefix: "/files"}))
+
+	resp := doRaw(s, "GET /files/file.bin HTTP/1.1\r\n\r\n")
+	head, body, _ := strings.Cut(resp, "\r\n\r\n")
+	if !strings.HasPrefix(head, "HTTP/1.1 200") || !strings.Contains(head, "Content-Length: 100000") {
+		t.Fatalf("unexpected head: %q", head)
+	}
+	if body != string(content) {
+		t.Fatalf("body length %d, want %d", len(body), len(content))
+	}
+	if len(requests) != 2 || requests[1] != "/file.bin bytes=40000-" {
+		t.Fatalf("unexpected upstream requests: %q", requests)
+	}
+
+	// 客户端的 Range 请求原样透传
+	resp = doRaw(s, "GET /files/file.bin HTTP/1.1\r\nRange: bytes=10-19\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 206") || !strings.Contains(resp, "Content-Range: bytes 10-19/100000") ||
+		!strings.HasSuffix(resp, "\r\n\r\n0123456789") {
+		t.Fatalf("unexpected range response: %q", resp)
+	}
+}
+
+func TestReverseProxyHeaders(t *testing.T) {
+	var clientHop string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientHop = r.Header.Get("X-Client-Hop")
+		w.Header().Add("Set-Cookie", "a=1; Expires=Wed, 21 Oct 2026 07:28:00 GMT")
+		w.Header().Add("Set-Cookie", "b=2")
+		w.Header().Set("Connection", "X-Upstream-Hop")
+		w.Header().Set("X-Upstream-Hop", "secret")
+		w.Header().Set("X-Kept", "yes")
+		w.Write([]byte("ok"))
+	}))
+	defer upstream.Close()
+
+	s := New()
+	s.GET("/p", ReverseProxy(ProxyConfig{Target: upstream.URL}))
+
+	resp := doRaw(s, "GET /p HTTP/1.1\r\nConnection: X-Client-Hop\r\nX-Client-Hop: 1\r\n\r\n")
+	// 多个 Set-Cookie 逐行转发，不合并
+	if !strings.Contains(resp, "\r\nSet-Cookie: a=1; Expires=Wed, 21 Oct 2026 07:28:00 GMT\r\n") ||
+		!strings.Contains(resp, "\r\nSet-Cookie: b=2\r\n") || strings.Count(resp, "Set-Cookie:") != 2 {
+		t.Fatalf("cookies not forwarded one per line:\n%s", resp)
+	}
+	// Connection 列出的头部是逐跳的，两个方向都不转发
+	if strings.Contains(resp, "X-Upstream-Hop") || !strings.Contains(resp, "X-Kept: yes") {
+		t.Fatalf("connection-listed response header forwarded:\n%s", resp)
+	}
+	if clientHop != "" {
+		t.Fatalf("connection-listed request header forwarded: %q", clientHop)
+	}
+}
+
+func TestReverseProxyUnavailable(t *testing.T) {
+	s := New()
+	s.GET("/down", ReverseProxy(ProxyConfig{Target: "http://127.0.0.1:1"}))
+	if resp := doRaw(s, "GET /down HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 502") {
+		t.Fatalf("expected 502: %q", resp)
+	}
+}
+
+func TestProxyPass(t *testing.T) {
+	var got []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, r.Method+" "+r.Host+" "+r.URL.RequestURI()+" "+string(body))
+		w.Write([]byte("upstream"))
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL)
+
+	s := New()
+	s.ProxyPass("/api/", target)
+	s.Group("/v2").ProxyPass("/svc", target)
+	s.GET("/keep", ReverseProxy(ProxyConfig{Target: upstream.URL, PreserveHost: true}))
+
+	resp := doRaw(s, "POST /api/users?x=1 HTTP/1.1\r\nHost: gw.test\r\nContent-Length: 4\r\n\r\ndata")
+	if !strings.HasSuffix(resp, "\r\n\r\nupstream") {
+		t.Fatalf("proxy pass response: %q", resp)
+	}
+	doRaw(s, "DELETE /v2/svc/items/1 HTTP/1.1\r\nHost: gw.test\r\n\r\n")
+	doRaw(s, "GET /keep HTTP/1.1\r\nHost: gw.test\r\n\r\n")
+
+	want := []string{
+		"POST " + target.Host + " /users?x=1 data",
+		"DELETE " + target.Host + " /items/1 ",
+		"GET gw.test /keep ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("upstream requests:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestReverseProxyForwarded(t *testing.T) {
+	var got http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	s := New()
+	s.GET("/x", ReverseProxy(ProxyConfig{Target: upstream.URL}))
+	s.GET("/std", ReverseProxy(ProxyConfig{Target: upstream.URL, Forwarded: ForwardedRFC7239, ForwardedBy: "_gw"}))
+
+	doRaw(s, "GET /x HTTP/1.1\r\nHost: example.com\r\nX-Forwarded-For: 203.0.113.7\r\n\r\n")
+	if !strings.HasPrefix(got.Get("X-Forwarded-For"), "203.0.113.7, ") || got.Get("X-Forwarded-Proto") != "http" ||
+		got.Get("X-Forwarded-Host") != "example.com" || got.Get("Forwarded") != "" {
+		t.Fatalf("x-forwarded headers: %v", got)
+	}
+
+	doRaw(s, "GET /std HTTP/1.1\r\nHost: example.com:8443\r\nForwarded: for=\"[2001:db8::1]:4711\"\r\n\r\n")
+	fwd := got.Get("Forwarded")
+	if !strings.HasPrefix(fwd, `for="[2001:db8::1]:4711", for=`) || !strings.HasSuffix(fwd, `;by=_gw;proto=http;host="example.com:8443"`) ||
+		got.Get("X-Forwarded-For") != "" {
+		t.Fatalf("forwarded header: %q", fwd)
+	}
+	if hops := parseForwardedFor(fwd); len(hops) != 2 || hops[0] != "2001:db8::1" {
+		t.Fatalf("parsed hops: %q", hops)
+	}
+}
+
+// proxiedConn 直连地址为受信任代理的连接
+type proxiedConn struct{ net.Conn }
+
+func (proxiedConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.ParseIP("10.0.0.5"), Port: 5000}
+}
+
+func TestClientIPFromForwarded(t *testing.T) {
+	s := New()
+	if err := s.ApplyRuntimeConfig(&RuntimeConfig{TrustedProxies: []string{"10.0.0.0/8"}}); err != nil {
+		t.Fatal(err)
+	}
+	s.GET("/ip", func(c *Context) {
+		c.String(StatusOK, c.ClientIP())
+	})
+
+	for forwarded, want := range map[string]string{
+		`for=198.51.100.17;proto=https, for=10.0.0.9`:  "198.51.100.17",
+		`For="[2001:db8:cafe::17]:4711"`:               "2001:db8:cafe::17",
+		`for=unknown, for="203.0.113.60:80";by=_proxy`: "203.0.113.60",
+	} {
+		client, server := net.Pipe()
+		raw := "GET /ip HTTP/1.1\r\nHost: x\r\nForwarded: " + forwarded + "\r\nX-Forwarded-For: 192.0.2.1\r\n\r\n"
+		go s.handleConnectionFast(proxiedConn{&rawConn{Conn: server, r: strings.NewReader(raw)}})
+		resp, _ := io.ReadAll(client)
+		if !strings.HasSuffix(string(resp), "\r\n\r\n"+want) {
+			t.Fatalf("%s: expected %s:\n%s", forwarded, want, resp)
+		}
+	}
+}
+
+func TestProxyHedging(t *testing.T) {
+	var calls atomic.Int32
+	canceled := make(chan struct{}, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第二个请求（对冲前的第一次尝试）一直不返回，直到被取消
+		if calls.Add(1) == 2 {
+			select {
+			case <-r.Context().Done():
+				canceled <- struct{}{}
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		w.Write([]byte("fast"))
+	}))
+	defer upstream.Close()
+
+	h := NewHedging(HedgeConfig{MinSamples: 1, Budget: 1, MinDelay: 20 * time.Millisecond})
+	s := New()
+	s.Use(h.Middleware())
+	s.GET("/h", ReverseProxy(ProxyConfig{Target: upstream.URL}))
+
+	for i := 0; i < 2; i++ {
+		if resp := doRaw(s, "GET /h HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "\r\n\r\nfast") {
+			t.Fatalf("request %d: %q", i, resp)
+		}
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("slow attempt was not canceled")
+	}
+	stats := h.Stats()
+	if stats.Requests != 2 || stats.Hedged != 1 || stats.Wins != 1 || stats.Canceled != 1 || stats.Delay != 20*time.Millisecond {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,117 @@
+// quota.go
+package meego
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// QuotaStore 配额计数存储，MemoryStore 和 RedisStore 均已实现
+type QuotaStore interface {
+	Incr(key string, delta int64, window time.Duration) (count int64, reset time.Duration, err error)
+}
+
+// QuotaPeriod 配额周期
+type QuotaPeriod int
+
+const (
+	QuotaDaily QuotaPeriod = iota
+	QuotaMonthly
+)
+
+// QuotaConfig 配额配置
+type QuotaConfig struct {
+	Period      QuotaPeriod
+	MaxRequests int64 // 每周期请求数上限，0 表示不限制
+	MaxBytes    int64 // 每周期流量上限（请求体+响应体），0 表示不限制
+	Store       QuotaStore
+	KeyFunc     func(*Context) string // 配额归属，默认 TenantKey
+	// LimitFunc 按租户/API Key 返回不同套餐的上限，返回 0 表示使用默认值
+	LimitFunc func(c *Context) (maxRequests, maxBytes int64)
+	// ExceededStatus 超出配额时的状态码，默认 429，付费套餐可使用 402
+	ExceededStatus int
+}
+
+// Quota 按日/月统计请求数和流量的配额中间件，写出 X-Quota-* 响应头
+func Quota(cfg QuotaConfig) MiddlewareFunc {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = TenantKey
+	}
+	if cfg.ExceededStatus == 0 {
+		cfg.ExceededStatus = StatusTooManyRequests
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			maxRequests, maxBytes := cfg.MaxRequests, cfg.MaxBytes
+			if cfg.LimitFunc != nil {
+				if r, b := cfg.LimitFunc(c); r > 0 || b > 0 {
+					maxRequests, maxBytes = r, b
+				}
+			}
+
+			now := now().UTC()
+			period, ttl := quotaPeriod(cfg.Period, now)
+			prefix := "quota:" + period + ":" + cfg.KeyFunc(c)
+			c.Writer.SetHeader("X-Quota-Reset", strconv.FormatInt(int64(ttl.Seconds()), 10))
+
+			exceeded := false
+			if maxRequests > 0 {
+				used, _, err := cfg.Store.Incr(prefix+":requests", 1, ttl)
+				if err != nil {
+					log.Error().Err(err).Msg("quota store error")
+					next(c)
+					return
+				}
+				c.Writer.SetHeader("X-Quota-Limit", strconv.FormatInt(maxRequests, 10))
+				c.Writer.SetHeader("X-Quota-Remaining", strconv.FormatInt(max(maxRequests-used, 0), 10))
+				exceeded = used > maxRequests
+			}
+
+			if maxBytes > 0 {
+				usedBytes, _, err := cfg.Store.Incr(prefix+":bytes", 0, ttl)
+				if err != nil {
+					log.Error().Err(err).Msg("quota store error")
+					next(c)
+					return
+				}
+				c.Writer.SetHeader("X-Quota-Bytes-Limit", strconv.FormatInt(maxBytes, 10))
+				c.Writer.SetHeader("X-Quota-Bytes-Remaining", strconv.FormatInt(max(maxBytes-usedBytes, 0), 10))
+				exceeded = exceeded || usedBytes >= maxBytes
+			}
+
+			if exceeded {
+				c.Writer.SetHeader("Retry-After", strconv.FormatInt(int64(ttl.Seconds()), 10))
+				c.Writer.Status(cfg.ExceededStatus).JSON(JSON{
+					"error": "quota exceeded",
+					"code":  cfg.ExceededStatus,
+				})
+				return
+			}
+
+			next(c)
+
+			if maxBytes > 0 {
+				transferred := int64(len(c.Request.Body) + c.Writer.size)
+				if _, _, err := cfg.Store.Incr(prefix+":bytes", transferred, ttl); err != nil {
+					log.Error().Err(err).Msg("quota store error")
+				}
+			}
+		}
+	}
+}
+
+// quotaPeriod 返回周期标识和到周期结束的剩余时间（UTC）
+func quotaPeriod(p QuotaPeriod, now time.Time) (string, time.Duration) {
+	if p == QuotaMonthly {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return now.Format("2006-01"), start.AddDate(0, 1, 0).Sub(now)
+	}
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return now.Format("2006-01-02"), start.AddDate(0, 0, 1).Sub(now)
+}
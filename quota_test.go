@@ -0,0 +1,69 @@
+package meego
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestQuota(t *testing.T) {
+	fake := NewFakeClock(time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC))
+	defer SetClock(fake)()
+
+	s := New()
+	s.Use(Quota(QuotaConfig{
+		MaxRequests: 2,
+		KeyFunc:     func(c *Context) string { return c.Request.GetHeader("X-API-Key") },
+		LimitFunc: func(c *Context) (int64, int64) {
+			if c.Request.GetHeader("X-API-Key") == "metered" {
+				return 0, 10
+			}
+			return 0, 0
+		},
+	}))
+	s.GET("/", func(c *Context) { c.String(StatusOK, "8 bytes!") })
+
+	get := func(key string) *http.Response {
+		res, _ := readRawResponse(t, doRaw(s, "GET / HTTP/1.1\r\nX-API-Key: "+key+"\r\n\r\n"))
+		return res
+	}
+	for i, want := range []string{"1", "0"} {
+		res := get("a")
+		if res.StatusCode != StatusOK || res.Header.Get("X-Quota-Limit") != "2" || res.Header.Get("X-Quota-Remaining") != want || res.Header.Get("X-Quota-Reset") != "3600" {
+			t.Fatalf("request %d: %d %v", i, res.StatusCode, res.Header)
+		}
+	}
+	if res := get("a"); res.StatusCode != StatusTooManyRequests || res.Header.Get("Retry-After") != "3600" {
+		t.Fatalf("expected 429: %d %v", res.StatusCode, res.Header)
+	}
+	// 其它 Key 单独计数，新的一天重新计数
+	if res := get("b"); res.StatusCode != StatusOK {
+		t.Fatalf("separate key: %d", res.StatusCode)
+	}
+	// LimitFunc 按套餐替换默认上限
+	if res := get("metered"); res.StatusCode != StatusOK || res.Header.Get("X-Quota-Limit") != "" || res.Header.Get("X-Quota-Bytes-Limit") != "10" {
+		t.Fatalf("per-plan limits: %d %v", res.StatusCode, res.Header)
+	}
+	fake.Advance(time.Hour)
+	if res := get("a"); res.StatusCode != StatusOK || res.Header.Get("X-Quota-Remaining") != "1" {
+		t.Fatalf("new period: %d %v", res.StatusCode, res.Header)
+	}
+
+	// 流量配额，付费套餐超出时返回 402
+	p := New()
+	p.Use(Quota(QuotaConfig{MaxBytes: 10, ExceededStatus: StatusPaymentRequired}))
+	p.GET("/", func(c *Context) { c.String(StatusOK, "8 bytes!") })
+	for i, want := range []string{"10", "2"} {
+		res, _ := readRawResponse(t, doRaw(p, "GET / HTTP/1.1\r\n\r\n"))
+		if res.StatusCode != StatusOK || res.Header.Get("X-Quota-Bytes-Limit") != "10" || res.Header.Get("X-Quota-Bytes-Remaining") != want {
+			t.Fatalf("bytes request %d: %d %v", i, res.StatusCode, res.Header)
+		}
+	}
+	if res, _ := readRawResponse(t, doRaw(p, "GET / HTTP/1.1\r\n\r\n")); res.StatusCode != StatusPaymentRequired || res.Header.Get("X-Quota-Bytes-Remaining") != "0" {
+		t.Fatalf("expected 402: %d %v", res.StatusCode, res.Header)
+	}
+
+	if period, ttl := quotaPeriod(QuotaMonthly, time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC)); period != "2026-10" || ttl != 14*24*time.Hour+time.Hour {
+		t.Fatalf("monthly period: %s %v", period, ttl)
+	}
+}
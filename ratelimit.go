@@ -0,0 +1,91 @@
+// ratelimit.go
+package meego
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Limit   int64                 // 每个窗口允许的请求数
+	Window  time.Duration         // 窗口长度
+	Store   RateLimitStore        // 计数存储，默认内存存储；多实例部署时使用 RedisStore
+	KeyFunc func(*Context) string // 限流键，默认按客户端 IP
+	// Name 非空时可以通过热加载配置的 rate_limits.<Name> 覆盖 Limit 和 Window
+	Name string
+}
+
+// RateLimit 固定窗口限流中间件
+func RateLimit(cfg RateLimitConfig) MiddlewareFunc {
+	if cfg.Limit <= 0 {
+		cfg.Limit = 100
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = clientIPKey
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			limit, window := cfg.Limit, cfg.Window
+			if cfg.Name != "" && c.server != nil {
+				if state := c.server.runtimeState(); state != nil {
+					if o, ok := state.config.RateLimits[cfg.Name]; ok {
+						if o.Limit > 0 {
+							limit = o.Limit
+						}
+						if o.window > 0 {
+							window = o.window
+						}
+					}
+				}
+			}
+
+			key := "ratelimit:" + cfg.KeyFunc(c)
+			count, reset, err := cfg.Store.Incr(key, 1, window)
+			if err != nil {
+				// 存储故障时放行，避免限流组件拖垮整个服务
+				log.Error().Err(err).Str("key", key).Msg("rate limit store error")
+				next(c)
+				return
+			}
+
+			remaining := limit - count
+			if remaining < 0 {
+				remaining = 0
+			}
+			c.Writer.SetHeader("X-RateLimit-Limit", strconv.FormatInt(limit, 10))
+			c.Writer.SetHeader("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+			c.Writer.SetHeader("X-RateLimit-Reset", strconv.FormatInt(int64(reset.Seconds()+0.5), 10))
+
+			if count > limit {
+				c.Writer.SetHeader("Retry-After", strconv.FormatInt(int64(reset.Seconds()+0.5), 10))
+				c.Writer.Status(StatusTooManyRequests).JSON(JSON{
+					"error": "Too Many Requests",
+					"code":  StatusTooManyRequests,
+				})
+				return
+			}
+
+			next(c)
+		}
+	}
+}
+
+// clientIPKey 默认限流键：去掉端口的客户端 IP
+func clientIPKey(c *Context) string {
+	ip := c.ClientIP()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		return host
+	}
+	return ip
+}
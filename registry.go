@@ -0,0 +1,280 @@
+// registry.go
+package meego
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+)
+
+// ServiceInstance 注册到服务注册中心的实例信息
+type ServiceInstance struct {
+	ID      string // 实例 ID，默认 Name-主机名-端口
+	Name    string
+	Address string // 对外地址，默认本机第一个非回环 IPv4
+	Port    int    // 默认监听端口
+	Tags    []string
+	Meta    map[string]string
+	// HealthPath 健康检查路径，如 "/healthz"；Consul 会据此配置 HTTP 检查
+	HealthPath string
+	// TTL 心跳超时，默认 15s，心跳间隔为 TTL/3
+	TTL time.Duration
+}
+
+// Registrar 服务注册中心
+type Registrar interface {
+	Register(ctx context.Context, inst *ServiceInstance) error
+	// Heartbeat 续约，TTL/3 调用一次
+	Heartbeat(ctx context.Context, inst *ServiceInstance) error
+	Deregister(ctx context.Context, inst *ServiceInstance) error
+}
+
+// RegisterService 在 Start 监听之后注册实例并定时续约，Shutdown 时注销
+func (s *HTTPServer) RegisterService(r Registrar, inst ServiceInstance) {
+	if inst.TTL <= 0 {
+		inst.TTL = 15 * time.Second
+	}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	s.OnStart(func(addr net.Addr) error {
+		if err := fillInstance(&inst, addr); err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := r.Register(ctx, &inst); err != nil {
+			return fmt.Errorf("register service %s: %w", inst.Name, err)
+		}
+		log.Info().Str("service", inst.Name).Str("id", inst.ID).Msg("service registered")
+
+		go func() {
+			defer close(stopped)
+			ticker := clock().NewTicker(inst.TTL / 3)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C():
+					ctx, cancel := context.WithTimeout(context.Background(), inst.TTL/3)
+					if err := r.Heartbeat(ctx, &inst); err != nil {
+						log.Warn().Err(err).Str("service", inst.Name).Msg("service heartbeat failed")
+					}
+					cancel()
+				case <-stop:
+					return
+				}
+			}
+		}()
+
+		s.OnShutdown(func() {
+			close(stop)
+			<-stopped
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := r.Deregister(ctx, &inst); err != nil {
+				log.Warn().Err(err).Str("service", inst.Name).Msg("service deregister failed")
+			}
+		})
+		return nil
+	})
+}
+
+// fillInstance 根据监听地址补全实例信息
+func fillInstance(inst *ServiceInstance, addr net.Addr) error {
+	if tcp, ok := addr.(*net.TCPAddr); ok && inst.Port == 0 {
+		inst.Port = tcp.Port
+	}
+	if inst.Address == "" {
+		ip, err := localIP()
+		if err != nil {
+			return err
+		}
+		inst.Address = ip
+	}
+	if inst.ID == "" {
+		host, _ := os.Hostname()
+		inst.ID = inst.Name + "-" + host + "-" + strconv.Itoa(inst.Port)
+	}
+	return nil
+}
+
+// localIP 返回本机第一个非回环 IPv4 地址
+func localIP() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", err
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return ipnet.IP.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no non-loopback IPv4 address found")
+}
+
+// ConsulRegistrar 通过 Consul Agent HTTP API 注册服务，使用 TTL 检查续约
+type ConsulRegistrar struct {
+	Addr   string // Agent 地址，默认 http://127.0.0.1:8500
+	Token  string // ACL Token
+	Client *http.Client
+	// DeregisterAfter 检查持续失败多久后 Consul 自动注销，默认 1m
+	DeregisterAfter time.Duration
+}
+
+func (r *ConsulRegistrar) Register(ctx context.Context, inst *ServiceInstance) error {
+	deregisterAfter := r.DeregisterAfter
+	if deregisterAfter <= 0 {
+		deregisterAfter = time.Minute
+	}
+	checks := []JSON{{
+		"CheckID":                        "service:" + inst.ID,
+		"TTL":                            inst.TTL.String(),
+		"DeregisterCriticalServiceAfter": deregisterAfter.String(),
+	}}
+	if inst.HealthPath != "" {
+		checks = append(checks, JSON{
+			"HTTP":     "http://" + net.JoinHostPort(inst.Address, strconv.Itoa(inst.Port)) + inst.HealthPath,
+			"Interval": inst.TTL.String(),
+		})
+	}
+	body := JSON{
+		"ID":      inst.ID,
+		"Name":    inst.Name,
+		"Address": inst.Address,
+		"Port":    inst.Port,
+		"Tags":    inst.Tags,
+		"Meta":    inst.Meta,
+		"Checks":  checks,
+	}
+	if err := r.do(ctx, "/v1/agent/service/register", body, nil); err != nil {
+		return err
+	}
+	return r.Heartbeat(ctx, inst)
+}
+
+func (r *ConsulRegistrar) Heartbeat(ctx context.Context, inst *ServiceInstance) error {
+	return r.do(ctx, "/v1/agent/check/pass/service:"+inst.ID, nil, nil)
+}
+
+func (r *ConsulRegistrar) Deregister(ctx context.Context, inst *ServiceInstance) error {
+	return r.do(ctx, "/v1/agent/service/deregister/"+inst.ID, nil, nil)
+}
+
+func (r *ConsulRegistrar) do(ctx context.Context, path string, body, out interface{}) error {
+	addr := r.Addr
+	if addr == "" {
+		addr = "http://127.0.0.1:8500"
+	}
+	headers := map[string]string{}
+	if r.Token != "" {
+		headers["X-Consul-Token"] = r.Token
+	}
+	return registryCall(ctx, r.Client, "PUT", strings.TrimRight(addr, "/")+path, headers, body, out)
+}
+
+// EtcdRegistrar 通过 etcd v3 HTTP 网关注册服务：实例信息写入
+// <Prefix>/<Name>/<ID>，绑定租约并定时续约，注销时撤销租约。
+// 每个 EtcdRegistrar 只用于注册一个实例
+type EtcdRegistrar struct {
+	Endpoint string // 默认 http://127.0.0.1:2379
+	Prefix   string // 默认 "/services"
+	Client   *http.Client
+
+	leaseID string
+}
+
+func (r *EtcdRegistrar) Register(ctx context.Context, inst *ServiceInstance) error {
+	var lease struct {
+		ID string `json:"ID"`
+	}
+	ttl := int64(inst.TTL.Seconds())
+	if err := r.do(ctx, "/v3/lease/grant", JSON{"TTL": ttl}, &lease); err != nil {
+		return err
+	}
+	r.leaseID = lease.ID
+
+	value, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(inst)
+	if err != nil {
+		return err
+	}
+	return r.do(ctx, "/v3/kv/put", JSON{
+		"key":   base64.StdEncoding.EncodeToString([]byte(r.key(inst))),
+		"value": base64.StdEncoding.EncodeToString(value),
+		"lease": r.leaseID,
+	}, nil)
+}
+
+func (r *EtcdRegistrar) Heartbeat(ctx context.Context, inst *ServiceInstance) error {
+	return r.do(ctx, "/v3/lease/keepalive", JSON{"ID": r.leaseID}, nil)
+}
+
+func (r *EtcdRegistrar) Deregister(ctx context.Context, inst *ServiceInstance) error {
+	return r.do(ctx, "/v3/lease/revoke", JSON{"ID": r.leaseID}, nil)
+}
+
+func (r *EtcdRegistrar) key(inst *ServiceInstance) string {
+	prefix := r.Prefix
+	if prefix == "" {
+		prefix = "/services"
+	}
+	return strings.TrimRight(prefix, "/") + "/" + inst.Name + "/" + inst.ID
+}
+
+func (r *EtcdRegistrar) do(ctx context.Context, path string, body, out interface{}) error {
+	endpoint := r.Endpoint
+	if endpoint == "" {
+		endpoint = "http://127.0.0.1:2379"
+	}
+	return registryCall(ctx, r.Client, "POST", strings.TrimRight(endpoint, "/")+path, nil, body, out)
+}
+
+// registryCall 发送 JSON 请求，非 2xx 时返回错误
+func registryCall(ctx context.Context, client *http.Client, method, url string, headers map[string]string, body, out interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %d %s", method, url, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,55 @@
+package meego
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConsulRegistration(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+	}))
+	defer consul.Close()
+
+	s := NewHTTPServer("127.0.0.1:0")
+	s.RegisterService(&ConsulRegistrar{Addr: consul.URL}, ServiceInstance{
+		ID: "api-1", Name: "api", Address: "10.0.0.1",
+	})
+	started := make(chan struct{})
+	s.OnStart(func(addr net.Addr) error {
+		close(started)
+		return nil
+	})
+	go s.Start()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not start")
+	}
+	s.Shutdown()
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"PUT /v1/agent/service/register",
+		"PUT /v1/agent/check/pass/service:api-1",
+		"PUT /v1/agent/service/deregister/api-1",
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("unexpected consul calls: %v", calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("unexpected consul calls: %v", calls)
+		}
+	}
+}
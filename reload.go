@@ -0,0 +1,249 @@
+// reload.go
+package meego
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// RuntimeConfig 可在运行时热加载的配置（JSON 文件）：
+//
+//	{
+//	  "log_level": "info",
+//	  "rate_limits": {"api": {"limit": 100, "window": "1m"}},
+//	  "maintenance": {"enabled": false, "message": "upgrading", "allow": ["/livez", "/readyz"]},
+//	  "trusted_proxies": ["10.0.0.0/8"],
+//	  "tls": {"cert_file": "server.crt", "key_file": "server.key"}
+//	}
+type RuntimeConfig struct {
+	LogLevel       string                       `json:"log_level"`
+	RateLimits     map[string]RateLimitOverride `json:"rate_limits"`
+	Maintenance    MaintenanceConfig            `json:"maintenance"`
+	TrustedProxies []string                     `json:"trusted_proxies"`
+	TLS            *TLSFiles                    `json:"tls"`
+}
+
+// RateLimitOverride 覆盖 RateLimitConfig.Name 相同的限流配置
+type RateLimitOverride struct {
+	Limit  int64  `json:"limit"`
+	Window string `json:"window"` // time.ParseDuration 格式，为空时保持原值
+
+	window time.Duration
+}
+
+// MaintenanceConfig 维护模式：除 Allow 中的路径前缀外，所有请求返回 503
+type MaintenanceConfig struct {
+	Enabled    bool     `json:"enabled"`
+	Message    string   `json:"message"`
+	RetryAfter int      `json:"retry_after"` // 秒
+	Allow      []string `json:"allow"`
+}
+
+// TLSFiles 证书文件
+type TLSFiles struct {
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// runtimeState 解析后的运行时配置，整体原子替换
+type runtimeState struct {
+	config         RuntimeConfig
+	trustedProxies []*net.IPNet
+	certificate    *tls.Certificate
+}
+
+// runtimeState 返回当前运行时配置，未加载时返回 nil
+func (s *HTTPServer) runtimeState() *runtimeState {
+	return s.runtime.Load()
+}
+
+// LoadRuntimeConfig 读取并解析运行时配置文件
+func LoadRuntimeConfig(path string) (*RuntimeConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg RuntimeConfig
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// ApplyRuntimeConfig 校验并原子地应用运行时配置；任何一项无效时整体不生效，
+// 已有连接不受影响
+func (s *HTTPServer) ApplyRuntimeConfig(cfg *RuntimeConfig) error {
+	state := &runtimeState{config: *cfg}
+
+	var level zerolog.Level
+	if cfg.LogLevel != "" {
+		var err error
+		if level, err = zerolog.ParseLevel(cfg.LogLevel); err != nil {
+			return err
+		}
+	}
+
+	overrides := make(map[string]RateLimitOverride, len(cfg.RateLimits))
+	for name, o := range cfg.RateLimits {
+		if o.Window != "" {
+			d, err := time.ParseDuration(o.Window)
+			if err != nil {
+				return fmt.Errorf("rate_limits.%s.window: %w", name, err)
+			}
+			o.window = d
+		}
+		overrides[name] = o
+	}
+	state.config.RateLimits = overrides
+
+	for _, cidr := range cfg.TrustedProxies {
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("trusted_proxies: %w", err)
+		}
+		state.trustedProxies = append(state.trustedProxies, ipnet)
+	}
+
+	if cfg.TLS != nil {
+		cert, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return fmt.Errorf("tls: %w", err)
+		}
+		state.certificate = &cert
+	}
+
+	if cfg.LogLevel != "" {
+		zerolog.SetGlobalLevel(level)
+	}
+	s.runtime.Store(state)
+	return nil
+}
+
+// WatchConfig 加载运行时配置，并在收到 SIGHUP 或文件变化时重新加载。
+// 重新加载失败时保留旧配置并记录错误。首次加载失败时返回错误
+func (s *HTTPServer) WatchConfig(path string) error {
+	reload := func() error {
+		cfg, err := LoadRuntimeConfig(path)
+		if err == nil {
+			err = s.ApplyRuntimeConfig(cfg)
+		}
+		return err
+	}
+	if err := reload(); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// 监听目录：编辑器和 ConfigMap 通过替换文件更新，直接监听文件会丢失事件
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return err
+	}
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	// 关闭时等待监听协程退出，进行中的重载完成后 Shutdown 才返回
+	done := make(chan struct{})
+	s.OnShutdown(func() {
+		signal.Stop(hup)
+		watcher.Close()
+		<-done
+	})
+
+	name := filepath.Clean(path)
+	go func() {
+		defer close(done)
+		// 合并短时间内的多次文件事件
+		var debounce <-chan time.Time
+		for {
+			select {
+			case <-hup:
+				s.reloadLogged(path, reload)
+			case ev, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) == name || filepath.Base(ev.Name) == "..data" {
+					debounce = time.After(100 * time.Millisecond)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warn().Err(err).Msg("config watcher error")
+			case <-debounce:
+				debounce = nil
+				s.reloadLogged(path, reload)
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *HTTPServer) reloadLogged(path string, reload func() error) {
+	if err := reload(); err != nil {
+		log.Error().Err(err).Str("path", path).Msg("config reload failed, keeping previous config")
+		return
+	}
+	log.Info().Str("path", path).Msg("config reloaded")
+}
+
+// maintenanceHandler 维护模式下返回的处理器，不在维护中或路径放行时返回 nil
+func (s *HTTPServer) maintenanceHandler(path string) HandlerFunc {
+	state := s.runtimeState()
+	if state == nil || !state.config.Maintenance.Enabled {
+		return nil
+	}
+	m := state.config.Maintenance
+	for _, prefix := range m.Allow {
+		if strings.HasPrefix(path, prefix) {
+			return nil
+		}
+	}
+
+	return func(c *Context) {
+		msg := m.Message
+		if msg == "" {
+			msg = "Service Unavailable"
+		}
+		if m.RetryAfter > 0 {
+			c.Writer.SetHeader("Retry-After", fmt.Sprint(m.RetryAfter))
+		}
+		c.Writer.Status(StatusServiceUnavailable).JSON(JSON{
+			"error": msg,
+			"code":  StatusServiceUnavailable,
+		})
+	}
+}
+
+// isTrustedProxy 判断 IP 是否为受信任的代理
+func (st *runtimeState) isTrustedProxy(ip net.IP) bool {
+	for _, n := range st.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package meego
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuntimeConfigReload(t *testing.T) {
+	s := New()
+	s.Use(RateLimit(RateLimitConfig{Name: "api", Limit: 100}))
+	s.GET("/ip", func(c *Context) {
+		c.String(StatusOK, "ok")
+	})
+
+	path := filepath.Join(t.TempDir(), "runtime.json")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"rate_limits": {"api": {"limit": 5}}, "trusted_proxies": ["not-an-ip"]}`)
+	if err := s.WatchConfig(path); err == nil {
+		t.Fatal("invalid trusted proxy should be rejected")
+	}
+
+	write(`{"rate_limits": {"api": {"limit": 5}}, "maintenance": {"enabled": true, "allow": ["/ip"]}}`)
+	if err := s.WatchConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Shutdown()
+
+	resp := doRaw(s, "GET /ip HTTP/1.1\r\n\r\n")
+	if !strings.Contains(resp, "X-RateLimit-Limit: 5") {
+		t.Fatalf("rate limit override not applied: %q", resp)
+	}
+	if resp := doRaw(s, "GET /other HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 503") {
+		t.Fatalf("expected maintenance 503: %q", resp)
+	}
+
+	write(`{"maintenance": {"enabled": false}}`)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if st := s.runtimeState(); st != nil && !st.config.Maintenance.Enabled {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if resp := doRaw(s, "GET /other HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 404") {
+		t.Fatalf("maintenance should be off after reload: %q", resp)
+	}
+}
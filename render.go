@@ -0,0 +1,86 @@
+// render.go
+package meego
+
+import (
+	"encoding/xml"
+	"errors"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProtoMessage 可以序列化为 Protocol Buffers 的消息（gogo/protobuf、vtprotobuf 生成的类型）
+type ProtoMessage interface {
+	Marshal() ([]byte, error)
+}
+
+// ProtoMarshal 序列化没有实现 ProtoMessage 的消息，框架不依赖 protobuf 库，
+// 使用 google.golang.org/protobuf 时设置为：
+//
+//	meego.ProtoMarshal = func(v interface{}) ([]byte, error) { return proto.Marshal(v.(proto.Message)) }
+var ProtoMarshal func(v interface{}) ([]byte, error)
+
+// ErrNotProtoMessage 数据不能序列化为 Protocol Buffers
+var ErrNotProtoMessage = errors.New("value is not a protobuf message")
+
+// XML 写出 XML 响应，带 XML 声明
+func (w *ResponseWriter) XML(data interface{}) error {
+	start := time.Now()
+	body, err := xml.Marshal(data)
+	w.serializeTime += time.Since(start)
+	if err != nil {
+		return err
+	}
+	w.SetHeader("Content-Type", "application/xml; charset=utf-8")
+	return w.writeResponse(append([]byte(xml.Header), body...))
+}
+
+// YAML 写出 YAML 响应
+func (w *ResponseWriter) YAML(data interface{}) error {
+	start := time.Now()
+	body, err := yaml.Marshal(data)
+	w.serializeTime += time.Since(start)
+	if err != nil {
+		return err
+	}
+	w.SetHeader("Content-Type", "application/yaml; charset=utf-8")
+	return w.writeResponse(body)
+}
+
+// ProtoBuf 写出 Protocol Buffers 响应，msg 需实现 ProtoMessage 或已设置 ProtoMarshal
+func (w *ResponseWriter) ProtoBuf(msg interface{}) error {
+	start := time.Now()
+	var body []byte
+	var err error
+	switch {
+	case ProtoMarshal != nil:
+		body, err = ProtoMarshal(msg)
+	default:
+		m, ok := msg.(ProtoMessage)
+		if !ok {
+			return ErrNotProtoMessage
+		}
+		body, err = m.Marshal()
+	}
+	w.serializeTime += time.Since(start)
+	if err != nil {
+		return err
+	}
+	w.SetHeader("Content-Type", "application/x-protobuf")
+	return w.writeResponse(body)
+}
+
+func (c *Context) XML(code int, data interface{}) {
+	c.guard.check("Context", "XML")
+	c.Writer.Status(code).XML(data)
+}
+
+func (c *Context) YAML(code int, data interface{}) {
+	c.guard.check("Context", "YAML")
+	c.Writer.Status(code).YAML(data)
+}
+
+func (c *Context) ProtoBuf(code int, msg interface{}) {
+	c.guard.check("Context", "ProtoBuf")
+	c.Writer.Status(code).ProtoBuf(msg)
+}
@@ -0,0 +1,68 @@
+// response_limit.go
+package meego
+
+import (
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ResponseLimitAction 响应体超过上限时的处理方式
+type ResponseLimitAction int
+
+const (
+	// ResponseLimitError 丢弃响应，返回 500
+	ResponseLimitError ResponseLimitAction = iota
+	// ResponseLimitTruncate 截断到上限，X-Response-Truncated 头为原始字节数
+	ResponseLimitTruncate
+	// ResponseLimitStream 改用分块传输发送，不设置 Content-Length
+	ResponseLimitStream
+)
+
+// responseLimit 路由的响应体上限
+type responseLimit struct {
+	max    int
+	action ResponseLimitAction
+}
+
+// MaxResponseSize 设置路由的非流式响应体上限（字节），超过时按 action 处理，
+// 用于防止处理器意外序列化巨大的对象。上限按压缩前的大小计算，流式响应不受限制
+func (r *Route) MaxResponseSize(n int, action ResponseLimitAction) *Route {
+	r.maxResponse = responseLimit{max: n, action: action}
+	return r
+}
+
+// MaxResponseSize 设置路由组内之后注册的路由的响应体上限
+func (g *RouteGroup) MaxResponseSize(n int, action ResponseLimitAction) *RouteGroup {
+	g.maxResponse = responseLimit{max: n, action: action}
+	return g
+}
+
+// limitResponse 应用响应体上限，在 writeResponse 中持有 w.mu 时调用
+func (w *ResponseWriter) limitResponse(body []byte) []byte {
+	limit := w.maxResponse
+	if limit.max <= 0 || len(body) <= limit.max || !bodyAllowedForStatus(w.status) {
+		return body
+	}
+	switch limit.action {
+	case ResponseLimitTruncate:
+		log.Warn().Int("size", len(body)).Int("limit", limit.max).Msg("response truncated")
+		w.header["X-Response-Truncated"] = strconv.Itoa(len(body))
+		delete(w.header, "Content-Length")
+		return body[:limit.max]
+	case ResponseLimitStream:
+		if w.proto != "HTTP/1.0" {
+			w.header["Transfer-Encoding"] = "chunked"
+			delete(w.header, "Content-Length")
+		}
+		return body
+	}
+	log.Error().Int("size", len(body)).Int("limit", limit.max).Msg("response too large")
+	for k := range w.header {
+		delete(w.header, k)
+	}
+	w.cookies = w.cookies[:0]
+	w.status = StatusInternalServerError
+	w.header["Content-Type"] = "application/json; charset=utf-8"
+	return []byte(`{"code":500,"error":"Response Too Large"}`)
+}
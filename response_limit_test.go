@@ -0,0 +1,29 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	s := New()
+	big := strings.Repeat("x", 100)
+	s.GET("/error", func(c *Context) { c.String(StatusOK, big) }).MaxResponseSize(10, ResponseLimitError)
+	s.GET("/truncate", func(c *Context) { c.String(StatusOK, big) }).MaxResponseSize(10, ResponseLimitTruncate)
+	g := s.Group("/g").MaxResponseSize(10, ResponseLimitStream)
+	g.GET("/stream", func(c *Context) { c.String(StatusOK, big) })
+	s.GET("/small", func(c *Context) { c.String(StatusOK, "ok") }).MaxResponseSize(10, ResponseLimitError)
+
+	if resp := doRaw(s, "GET /error HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 500") || !strings.Contains(resp, "Response Too Large") || strings.Contains(resp, big) {
+		t.Fatalf("expected 500: %q", resp)
+	}
+	if resp := doRaw(s, "GET /truncate HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "X-Response-Truncated: 100") || !strings.HasSuffix(resp, "\r\n\r\n"+big[:10]) {
+		t.Fatalf("expected truncated body: %q", resp)
+	}
+	if resp := doRaw(s, "GET /g/stream HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "Transfer-Encoding: chunked") || strings.Contains(resp, "Content-Length") || !strings.Contains(resp, big) {
+		t.Fatalf("expected chunked body: %q", resp)
+	}
+	if resp := doRaw(s, "GET /small HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "ok") {
+		t.Fatalf("small response changed: %q", resp)
+	}
+}
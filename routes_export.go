@@ -0,0 +1,159 @@
+// routes_export.go
+package meego
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/yaml.v3"
+)
+
+// RouteInfo 导出的路由描述，供文档和测试生成工具使用
+type RouteInfo struct {
+	Method     string            `json:"method" yaml:"method"`
+	Path       string            `json:"path" yaml:"path"`
+	Params     []string          `json:"params,omitempty" yaml:"params,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Middleware []string          `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+	Examples   []RouteExample    `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+// RouteManifest 路由清单
+type RouteManifest struct {
+	// Middleware 全局中间件，对所有路由生效
+	Middleware []string    `json:"middleware,omitempty" yaml:"middleware,omitempty"`
+	Routes     []RouteInfo `json:"routes" yaml:"routes"`
+}
+
+// Meta 设置路由的文档元数据（如 summary、tag），随 ExportRoutes 导出
+func (r *Route) Meta(key, value string) *Route {
+	if r.metadata == nil {
+		r.metadata = make(map[string]string)
+	}
+	r.metadata[key] = value
+	return r
+}
+
+// Routes 返回当前注册的全部路由，按路径和方法排序
+func (s *HTTPServer) Routes() []RouteInfo {
+	s.router.mu.RLock()
+	defer s.router.mu.RUnlock()
+
+	var infos []RouteInfo
+	for _, routes := range s.router.routes {
+		for _, route := range routes {
+			info := RouteInfo{
+				Method:     route.method,
+				Path:       route.path,
+				Params:     append([]string(nil), route.paramNames...),
+				Middleware: append([]string(nil), route.middleware...),
+				Examples:   append([]RouteExample(nil), route.examples...),
+			}
+			if len(route.metadata) > 0 {
+				info.Metadata = make(map[string]string, len(route.metadata))
+				for k, v := range route.metadata {
+					info.Metadata[k] = v
+				}
+			}
+			infos = append(infos, info)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Path != infos[j].Path {
+			return infos[i].Path < infos[j].Path
+		}
+		return infos[i].Method < infos[j].Method
+	})
+	return infos
+}
+
+// ExportRoutes 导出路由清单，format 为 "json" 或 "yaml"
+func (s *HTTPServer) ExportRoutes(format string) ([]byte, error) {
+	manifest := RouteManifest{
+		Middleware: middlewareNames(s.middlewares),
+		Routes:     s.Routes(),
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		return jsoniter.ConfigCompatibleWithStandardLibrary.MarshalIndent(manifest, "", "  ")
+	case "yaml", "yml":
+		return yaml.Marshal(manifest)
+	default:
+		return nil, fmt.Errorf("unsupported route export format %q", format)
+	}
+}
+
+// RouteMismatchError 运行中的路由与清单不一致
+type RouteMismatchError struct {
+	Missing    []RouteInfo // 清单中有但未注册
+	Unexpected []RouteInfo // 已注册但清单中没有
+}
+
+func (e *RouteMismatchError) Error() string {
+	var b strings.Builder
+	b.WriteString("routes do not match manifest:")
+	for _, r := range e.Missing {
+		b.WriteString("\n  missing    " + r.Method + " " + r.Path)
+	}
+	for _, r := range e.Unexpected {
+		b.WriteString("\n  unexpected " + r.Method + " " + r.Path)
+	}
+	return b.String()
+}
+
+// ValidateRoutes 按方法和路径校验运行中的路由与清单（JSON 或 YAML）是否一致，
+// 不一致时返回 *RouteMismatchError
+func (s *HTTPServer) ValidateRoutes(manifest []byte) error {
+	var expected RouteManifest
+	// YAML 是 JSON 的超集，两种格式都可以直接解析
+	if err := yaml.Unmarshal(manifest, &expected); err != nil {
+		return fmt.Errorf("parse route manifest: %w", err)
+	}
+
+	key := func(r RouteInfo) string { return strings.ToUpper(r.Method) + " " + r.Path }
+	actual := s.Routes()
+	registered := make(map[string]bool, len(actual))
+	for _, r := range actual {
+		registered[key(r)] = true
+	}
+
+	mismatch := &RouteMismatchError{}
+	listed := make(map[string]bool, len(expected.Routes))
+	for _, r := range expected.Routes {
+		listed[key(r)] = true
+		if !registered[key(r)] {
+			mismatch.Missing = append(mismatch.Missing, r)
+		}
+	}
+	for _, r := range actual {
+		if !listed[key(r)] {
+			mismatch.Unexpected = append(mismatch.Unexpected, r)
+		}
+	}
+	if len(mismatch.Missing) > 0 || len(mismatch.Unexpected) > 0 {
+		return mismatch
+	}
+	return nil
+}
+
+// middlewareNames 返回中间件的函数名，如 "meego.Recovery"
+func middlewareNames(middlewares []MiddlewareFunc) []string {
+	names := make([]string, 0, len(middlewares))
+	for _, m := range middlewares {
+		name := "unknown"
+		if fn := runtime.FuncForPC(reflect.ValueOf(m).Pointer()); fn != nil {
+			name = fn.Name()
+			// 去掉包路径和闭包后缀：github.com/x/meego.RateLimit.func1 -> meego.RateLimit
+			name = name[strings.LastIndex(name, "/")+1:]
+			if i := strings.Index(name, ".func"); i > 0 {
+				name = name[:i]
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
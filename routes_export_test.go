@@ -0,0 +1,38 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportRoutes(t *testing.T) {
+	s := New()
+	s.Use(Recovery())
+	api := s.Group("/api", RequestID())
+	api.GET("/users/:id", func(c *Context) {}).Meta("summary", "get user")
+	s.POST("/login", func(c *Context) {})
+
+	data, err := s.ExportRoutes("yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"meego.Recovery", "path: /api/users/:id", "- id", "summary: get user", "- meego.RequestID"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("export missing %q:\n%s", want, data)
+		}
+	}
+	if err := s.ValidateRoutes(data); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := `{"routes": [{"method": "GET", "path": "/api/users/:id"}, {"method": "GET", "path": "/health"}]}`
+	err = s.ValidateRoutes([]byte(manifest))
+	mismatch, ok := err.(*RouteMismatchError)
+	if !ok {
+		t.Fatalf("expected RouteMismatchError, got %v", err)
+	}
+	if len(mismatch.Missing) != 1 || mismatch.Missing[0].Path != "/health" ||
+		len(mismatch.Unexpected) != 1 || mismatch.Unexpected[0].Path != "/login" {
+		t.Fatalf("unexpected mismatch: %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+// secure_headers.go
+package meego
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+)
+
+// SecureHeadersConfig 安全响应头配置，字符串字段设置为 "-" 时不输出对应响应头
+type SecureHeadersConfig struct {
+	// ContentSecurityPolicy 默认 "default-src 'self'"
+	ContentSecurityPolicy string
+	// ReportOnly 使用 Content-Security-Policy-Report-Only 只上报不拦截，便于逐步上线 CSP
+	ReportOnly bool
+	// ReportURI 违规报告地址，通常为 EnableCSPReports 注册的路径
+	ReportURI string
+
+	FrameOptions       string        // X-Frame-Options，默认 DENY
+	ContentTypeOptions string        // X-Content-Type-Options，默认 nosniff
+	ReferrerPolicy     string        // 默认 strict-origin-when-cross-origin
+	HSTSMaxAge         time.Duration // Strict-Transport-Security，0 表示不输出
+	HSTSSubdomains     bool
+}
+
+// SecureHeaders 使用默认配置的安全响应头中间件
+func SecureHeaders() MiddlewareFunc {
+	return SecureHeadersWithConfig(SecureHeadersConfig{})
+}
+
+// SecureHeadersWithConfig 使用自定义配置的安全响应头中间件
+func SecureHeadersWithConfig(cfg SecureHeadersConfig) MiddlewareFunc {
+	if cfg.ContentSecurityPolicy == "" {
+		cfg.ContentSecurityPolicy = "default-src 'self'"
+	}
+	if cfg.FrameOptions == "" {
+		cfg.FrameOptions = "DENY"
+	}
+	if cfg.ContentTypeOptions == "" {
+		cfg.ContentTypeOptions = "nosniff"
+	}
+	if cfg.ReferrerPolicy == "" {
+		cfg.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+
+	policy := cfg.ContentSecurityPolicy
+	if policy != "-" && cfg.ReportURI != "" {
+		policy = strings.TrimRight(policy, "; ") + "; report-uri " + cfg.ReportURI
+	}
+	cspHeader := "Content-Security-Policy"
+	if cfg.ReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+
+	headers := make([][2]string, 0, 5)
+	add := func(key, value string) {
+		if value != "-" {
+			headers = append(headers, [2]string{key, value})
+		}
+	}
+	add(cspHeader, policy)
+	add("X-Frame-Options", cfg.FrameOptions)
+	add("X-Content-Type-Options", cfg.ContentTypeOptions)
+	add("Referrer-Policy", cfg.ReferrerPolicy)
+	if cfg.HSTSMaxAge > 0 {
+		hsts := "max-age=" + strconv.FormatInt(int64(cfg.HSTSMaxAge.Seconds()), 10)
+		if cfg.HSTSSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		add("Strict-Transport-Security", hsts)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			for _, h := range headers {
+				c.Writer.SetHeader(h[0], h[1])
+			}
+			next(c)
+		}
+	}
+}
+
+// maxCSPViolationKeys 聚合的违规类别上限，超过后计入 other，避免恶意报告撑爆内存
+const maxCSPViolationKeys = 1000
+
+// CSPViolation 按指令和被拦截来源聚合的违规报告
+type CSPViolation struct {
+	Directive string    `json:"directive"`
+	Blocked   string    `json:"blocked"`
+	Count     int64     `json:"count"`
+	LastSeen  time.Time `json:"last_seen"`
+	Document  string    `json:"document"` // 最近一次报告的页面
+}
+
+// CSPReportCollector 收集浏览器上报的 CSP 违规，聚合后写入日志并以 Prometheus 格式导出
+type CSPReportCollector struct {
+	mu         sync.Mutex
+	violations map[[2]string]*CSPViolation
+}
+
+// NewCSPReportCollector 创建违规报告收集器
+func NewCSPReportCollector() *CSPReportCollector {
+	return &CSPReportCollector{violations: make(map[[2]string]*CSPViolation)}
+}
+
+// EnableCSPReports 注册违规报告端点（如 /csp-report）并返回收集器
+func (s *HTTPServer) EnableCSPReports(path string) *CSPReportCollector {
+	collector := NewCSPReportCollector()
+	s.POST(path, collector.Handler()).MaxBodySize(64 * 1024)
+	return collector
+}
+
+// cspReport report-uri 上报的格式（application/csp-report）
+type cspReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport Reporting API 上报的格式（application/reports+json）
+type reportingAPIReport struct {
+	Type string `json:"type"`
+	Body struct {
+		DocumentURL        string `json:"documentURL"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+	} `json:"body"`
+}
+
+// Handler 接收违规报告，同时支持 report-uri 和 Reporting API 两种格式
+func (rc *CSPReportCollector) Handler() HandlerFunc {
+	return func(c *Context) {
+		json := jsoniter.ConfigCompatibleWithStandardLibrary
+		body := c.BodyUnsafe()
+
+		if c.Request.ContentType() == "application/reports+json" {
+			var reports []reportingAPIReport
+			if err := json.Unmarshal(body, &reports); err != nil {
+				c.Writer.Status(StatusBadRequest).JSON(JSON{"error": "invalid report", "code": StatusBadRequest})
+				return
+			}
+			for _, r := range reports {
+				if r.Type == "csp-violation" {
+					rc.Record(r.Body.EffectiveDirective, r.Body.BlockedURL, r.Body.DocumentURL)
+				}
+			}
+		} else {
+			var r cspReport
+			if err := json.Unmarshal(body, &r); err != nil {
+				c.Writer.Status(StatusBadRequest).JSON(JSON{"error": "invalid report", "code": StatusBadRequest})
+				return
+			}
+			directive := r.Report.EffectiveDirective
+			if directive == "" {
+				directive = r.Report.ViolatedDirective
+			}
+			rc.Record(directive, r.Report.BlockedURI, r.Report.DocumentURI)
+		}
+		c.Writer.Status(StatusNoContent).String("")
+	}
+}
+
+// Record 记录一次违规。blocked 只保留来源（scheme://host），控制聚合的基数
+func (rc *CSPReportCollector) Record(directive, blocked, document string) {
+	directive, _, _ = strings.Cut(strings.TrimSpace(directive), " ")
+	blocked = cspBlockedOrigin(blocked)
+
+	rc.mu.Lock()
+	key := [2]string{directive, blocked}
+	v := rc.violations[key]
+	if v == nil {
+		if len(rc.violations) >= maxCSPViolationKeys {
+			key = [2]string{"other", "other"}
+			v = rc.violations[key]
+		}
+		if v == nil {
+			v = &CSPViolation{Directive: key[0], Blocked: key[1]}
+			rc.violations[key] = v
+		}
+	}
+	v.Count++
+	v.LastSeen = time.Now()
+	v.Document = document
+	first := v.Count == 1
+	rc.mu.Unlock()
+
+	// 同一类别只在首次出现时记录警告，之后只计数
+	if first {
+		log.Warn().
+			Str("directive", directive).
+			Str("blocked", blocked).
+			Str("document", document).
+			Msg("csp violation")
+	}
+}
+
+// cspBlockedOrigin 将被拦截的 URL 归一化为来源，inline/eval 等关键字保持不变
+func cspBlockedOrigin(blocked string) string {
+	if blocked == "" {
+		return "none"
+	}
+	u, err := url.Parse(blocked)
+	if err != nil || u.Host == "" {
+		return blocked
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Violations 返回聚合后的违规，按次数从多到少排序
+func (rc *CSPReportCollector) Violations() []CSPViolation {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	list := make([]CSPViolation, 0, len(rc.violations))
+	for _, v := range rc.violations {
+		list = append(list, *v)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		if list[i].Directive != list[j].Directive {
+			return list[i].Directive < list[j].Directive
+		}
+		return list[i].Blocked < list[j].Blocked
+	})
+	return list
+}
+
+// String 以 Prometheus 文本格式输出 meego_csp_violations_total
+func (rc *CSPReportCollector) String() string {
+	var b strings.Builder
+	b.WriteString("# HELP meego_csp_violations_total Content Security Policy violation reports.\n")
+	b.WriteString("# TYPE meego_csp_violations_total counter\n")
+	for _, v := range rc.Violations() {
+		fmt.Fprintf(&b, "meego_csp_violations_total{directive=%q,blocked=%q} %d\n", v.Directive, v.Blocked, v.Count)
+	}
+	return b.String()
+}
@@ -0,0 +1,32 @@
+package meego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCSPReportOnly(t *testing.T) {
+	s := New()
+	s.Use(SecureHeadersWithConfig(SecureHeadersConfig{ReportOnly: true, ReportURI: "/csp-report"}))
+	collector := s.EnableCSPReports("/csp-report")
+	s.GET("/", func(c *Context) { c.String(StatusOK, "ok") })
+
+	resp := doRaw(s, "GET / HTTP/1.1\r\n\r\n")
+	if !strings.Contains(resp, "Content-Security-Policy-Report-Only: default-src 'self'; report-uri /csp-report") ||
+		!strings.Contains(resp, "X-Frame-Options: DENY") {
+		t.Fatalf("missing security headers: %q", resp)
+	}
+
+	report := `{"csp-report":{"document-uri":"https://app/","blocked-uri":"https://cdn.evil.com/x.js","violated-directive":"script-src-elem"}}`
+	for i := 0; i < 2; i++ {
+		raw := fmt.Sprintf("POST /csp-report HTTP/1.1\r\nContent-Type: application/csp-report\r\nContent-Length: %d\r\n\r\n%s", len(report), report)
+		if resp := doRaw(s, raw); !strings.HasPrefix(resp, "HTTP/1.1 204") {
+			t.Fatalf("report rejected: %q", resp)
+		}
+	}
+	want := `meego_csp_violations_total{directive="script-src-elem",blocked="https://cdn.evil.com"} 2`
+	if !strings.Contains(collector.String(), want) {
+		t.Fatalf("unexpected metrics:\n%s", collector.String())
+	}
+}
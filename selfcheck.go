@@ -0,0 +1,188 @@
+// selfcheck.go
+package meego
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// 自检问题级别
+const (
+	SeverityError   = "error"   // 阻止启动
+	SeverityWarning = "warning" // 只记录
+)
+
+// StartupIssue 启动自检发现的问题
+type StartupIssue struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"` // routes、tls、timeouts、middleware
+	Message  string `json:"message"`
+}
+
+// StartupReport 启动自检报告
+type StartupReport struct {
+	Addr         string         `json:"addr"`
+	TLS          bool           `json:"tls"`
+	Routes       int            `json:"routes"`
+	Middleware   []string       `json:"middleware"`
+	ReadTimeout  time.Duration  `json:"read_timeout"`
+	WriteTimeout time.Duration  `json:"write_timeout"`
+	Issues       []StartupIssue `json:"issues,omitempty"`
+}
+
+// StartupError 自检发现错误时 Start/ListenTLS 返回的错误
+type StartupError struct {
+	Issues []StartupIssue
+}
+
+func (e *StartupError) Error() string {
+	var b strings.Builder
+	b.WriteString("meego: startup check failed:")
+	for _, issue := range e.Issues {
+		fmt.Fprintf(&b, "\n  - %s: %s", issue.Check, issue.Message)
+	}
+	return b.String()
+}
+
+// Err 报告中有错误时返回 *StartupError
+func (r *StartupReport) Err() error {
+	var errs []StartupIssue
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			errs = append(errs, issue)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &StartupError{Issues: errs}
+}
+
+func (r *StartupReport) add(severity, check, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, StartupIssue{Severity: severity, Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate 检查当前配置：路由冲突、超时、全局中间件顺序。Start 和 ListenTLS
+// 在监听之前自动执行，有错误时不启动
+func (s *HTTPServer) Validate() *StartupReport {
+	return s.validate(nil)
+}
+
+// validate tlsFiles 不为空时同时检查 HTTPS 证书
+func (s *HTTPServer) validate(tlsFiles *TLSFiles) *StartupReport {
+	s.mu.RLock()
+	report := &StartupReport{
+		Addr:         s.addr,
+		TLS:          tlsFiles != nil,
+		Middleware:   middlewareNames(s.middlewares),
+		ReadTimeout:  s.readTimeout,
+		WriteTimeout: s.writeTimeout,
+	}
+	hasTLSCert := s.tlsConfig != nil && (len(s.tlsConfig.Certificates) > 0 || s.tlsConfig.GetCertificate != nil)
+	s.mu.RUnlock()
+
+	s.checkRoutes(report)
+
+	if report.ReadTimeout <= 0 {
+		report.add(SeverityError, "timeouts", "read timeout is %v; every connection would time out immediately, set it with SetTimeout", report.ReadTimeout)
+	}
+	if report.WriteTimeout <= 0 {
+		report.add(SeverityError, "timeouts", "write timeout is %v; every response would time out immediately, set it with SetTimeout", report.WriteTimeout)
+	}
+
+	if tlsFiles != nil {
+		switch {
+		case tlsFiles.CertFile != "":
+			for _, file := range []string{tlsFiles.CertFile, tlsFiles.KeyFile} {
+				if _, err := os.Stat(file); err != nil {
+					report.add(SeverityError, "tls", "cannot read %q: %v", file, err)
+				}
+			}
+		case !hasTLSCert && s.tlsCert.Load() == nil:
+			if state := s.runtimeState(); state == nil || state.certificate == nil {
+				report.add(SeverityError, "tls", "no certificate: pass certFile/keyFile, SetTLSConfig with Certificates, or a runtime config tls section")
+			}
+		}
+	}
+
+	checkMiddlewareOrder(report)
+	return report
+}
+
+// checkRoutes 同一方法下模式相同（参数名不同也算）的路由，后注册的永远不会匹配
+func (s *HTTPServer) checkRoutes(report *StartupReport) {
+	s.router.mu.RLock()
+	defer s.router.mu.RUnlock()
+
+	for method, routes := range s.router.routes {
+		seen := make(map[string]string, len(routes))
+		for _, route := range routes {
+			report.Routes++
+			key := strings.Join(route.segments, "/")
+			if first, ok := seen[key]; ok {
+				report.add(SeverityError, "routes", "%s %s conflicts with %s %s registered earlier; the later route is unreachable", method, route.path, method, first)
+				continue
+			}
+			seen[key] = route.path
+		}
+	}
+}
+
+// middlewareOrderRules 全局中间件的推荐顺序：before 应在 after 之前注册
+var middlewareOrderRules = []struct {
+	before, after, reason string
+}{
+	{"meego.RequestID", "meego.Logger", "access logs will not include the request ID"},
+	{"meego.RequestID", "meego.AccessLog", "access logs will not include the request ID"},
+	{"meego.CORS", "meego.Auth", "CORS preflight requests will be rejected as unauthorized"},
+	{"meego.Recovery", "meego.Auth", "panics in Auth will not be recovered"},
+	{"meego.Recovery", "meego.Timeout", "panics in timed-out handlers will not be recovered"},
+}
+
+// checkMiddlewareOrder 按 middlewareOrderRules 检查全局中间件顺序
+func checkMiddlewareOrder(report *StartupReport) {
+	index := make(map[string]int, len(report.Middleware))
+	for i, name := range report.Middleware {
+		name = strings.TrimSuffix(name, "WithConfig")
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+	for _, rule := range middlewareOrderRules {
+		b, okB := index[rule.before]
+		a, okA := index[rule.after]
+		if okB && okA && b > a {
+			report.add(SeverityWarning, "middleware", "%s is registered after %s: %s", rule.before, rule.after, rule.reason)
+		}
+	}
+}
+
+// startupCheck 执行自检并记录报告，有错误时返回 *StartupError
+func (s *HTTPServer) startupCheck(tlsFiles *TLSFiles) error {
+	report := s.validate(tlsFiles)
+	for _, issue := range report.Issues {
+		level := zerolog.WarnLevel
+		if issue.Severity == SeverityError {
+			level = zerolog.ErrorLevel
+		}
+		log.WithLevel(level).Str("check", issue.Check).Msg(issue.Message)
+	}
+	if err := report.Err(); err != nil {
+		return err
+	}
+	log.Info().
+		Str("addr", report.Addr).
+		Bool("tls", report.TLS).
+		Int("routes", report.Routes).
+		Strs("middleware", report.Middleware).
+		Dur("read_timeout", report.ReadTimeout).
+		Dur("write_timeout", report.WriteTimeout).
+		Int("warnings", len(report.Issues)).
+		Msg("startup check passed")
+	return nil
+}
@@ -0,0 +1,43 @@
+package meego
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStartupSelfCheck(t *testing.T) {
+	s := New()
+	s.Use(Auth())
+	s.Use(CORS())
+	s.GET("/users/:id", func(c *Context) {})
+	s.GET("/users/:name", func(c *Context) {})
+	s.POST("/users/:name", func(c *Context) {})
+	s.SetTimeout(0, time.Second)
+
+	report := s.Validate()
+	if report.Routes != 3 {
+		t.Fatalf("routes = %d", report.Routes)
+	}
+	var checks []string
+	for _, issue := range report.Issues {
+		checks = append(checks, issue.Severity+":"+issue.Check)
+	}
+	if got := strings.Join(checks, ","); got != "error:routes,error:timeouts,warning:middleware" {
+		t.Fatalf("issues = %s\n%+v", got, report.Issues)
+	}
+
+	err := s.Listen("127.0.0.1:0")
+	var startupErr *StartupError
+	if !errors.As(err, &startupErr) || len(startupErr.Issues) != 2 || !strings.Contains(err.Error(), "/users/:name conflicts with GET /users/:id") {
+		t.Fatalf("Listen error = %v", err)
+	}
+
+	tlsServer := New()
+	err = tlsServer.ListenTLS("127.0.0.1:0", filepath.Join(t.TempDir(), "missing.crt"), "missing.key")
+	if !errors.As(err, &startupErr) || startupErr.Issues[0].Check != "tls" {
+		t.Fatalf("ListenTLS error = %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+// session.go
+package meego
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+)
+
+// SessionConfig 会话配置
+type SessionConfig struct {
+	// Store 会话存储，默认内存存储；多实例部署时使用 RedisStore 或自定义 SessionStore
+	Store SessionStore
+	// CookieName 保存会话 ID 的 Cookie，默认 "meego_session"
+	CookieName string
+	// MaxAge 会话空闲过期时间，每次访问后顺延，默认 24h
+	MaxAge time.Duration
+	// Cookie 属性，Path 默认 "/"，SameSite 默认 Lax；Cookie 总是 HttpOnly
+	Path     string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// sessionContextKey 会话在 Context 中的键
+const sessionContextKey = "meego.session"
+
+// Sessions 使用内存存储的会话中间件
+func Sessions() MiddlewareFunc {
+	return SessionsWithConfig(SessionConfig{})
+}
+
+// SessionsWithConfig 会话中间件：根据 Cookie 中的会话 ID 加载会话，处理器通过
+// SessionFromContext 读写。修改后的会话在处理器返回后自动保存；新会话的 Cookie
+// 在首次修改时设置，因此需要在写出响应之前修改会话或调用 Save
+func SessionsWithConfig(cfg SessionConfig) MiddlewareFunc {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "meego_session"
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 24 * time.Hour
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			sess := &Session{cfg: &cfg, c: c}
+			c.Writer.AddVary("Cookie")
+			if id, err := c.Cookie(cfg.CookieName); err == nil && id != "" {
+				if err := sess.load(id); err != nil {
+					log.Error().Err(err).Msg("session store error")
+				}
+			}
+			if sess.id == "" {
+				sess.id = newID(IDSession)
+				sess.isNew = true
+				sess.values = make(map[string]interface{})
+			}
+			c.Set(sessionContextKey, sess)
+
+			next(c)
+
+			sess.mu.Lock()
+			defer sess.mu.Unlock()
+			if sess.dirty {
+				if err := sess.saveLocked(); err != nil {
+					c.Logger().Error().Err(err).Msg("session save failed")
+				}
+			}
+			sess.c = nil
+		}
+	}
+}
+
+// SessionFromContext 返回当前请求的会话，未使用 Sessions 中间件时返回 nil
+func SessionFromContext(c *Context) *Session {
+	sess, _ := c.Get(sessionContextKey).(*Session)
+	return sess
+}
+
+// Session 请求会话，方法可以并发调用。值以 JSON 保存，读回时数字为 float64、
+// 对象为 map[string]interface{}。同一会话的并发请求以最后保存的为准
+type Session struct {
+	mu      sync.RWMutex
+	cfg     *SessionConfig
+	c       *Context
+	id      string
+	values  map[string]interface{}
+	touched time.Time // 上次写入存储的时间
+
+	isNew     bool
+	dirty     bool // 有未保存的修改
+	destroyed bool
+}
+
+// sessionRecord 会话的存储格式
+type sessionRecord struct {
+	Values  map[string]interface{} `json:"v"`
+	Touched int64                  `json:"t"`
+}
+
+// load 从存储加载会话，会话不存在或已过期时保持为空
+func (s *Session) load(id string) error {
+	data, found, err := s.cfg.Store.Get("session:" + id)
+	if err != nil || !found {
+		return err
+	}
+	var rec sessionRecord
+	if err := jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(data, &rec); err != nil {
+		return err
+	}
+	if rec.Values == nil {
+		rec.Values = make(map[string]interface{})
+	}
+	s.id = id
+	s.values = rec.Values
+	s.touched = time.Unix(rec.Touched, 0)
+	// 存储中的过期时间已过半时顺延
+	if now().Sub(s.touched) > s.cfg.MaxAge/2 {
+		s.dirty = true
+	}
+	return nil
+}
+
+// ID 返回会话 ID
+func (s *Session) ID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+// IsNew 会话是否在本次请求中创建
+func (s *Session) IsNew() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.isNew
+}
+
+// Get 读取会话值
+func (s *Session) Get(key string) interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.values[key]
+}
+
+// Set 设置会话值
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.markDirtyLocked()
+}
+
+// Delete 删除会话值
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	s.markDirtyLocked()
+}
+
+// Clear 清空会话值
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k := range s.values {
+		delete(s.values, k)
+	}
+	s.markDirtyLocked()
+}
+
+// Save 立即保存会话并设置 Cookie
+func (s *Session) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveLocked()
+}
+
+// Regenerate 更换会话 ID 并保留数据，登录等权限变化后调用以防止会话固定攻击
+func (s *Session) Regenerate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isNew {
+		if err := s.cfg.Store.Delete("session:" + s.id); err != nil {
+			return err
+		}
+	}
+	s.id = newID(IDSession)
+	s.isNew = true
+	s.destroyed = false
+	return s.saveLocked()
+}
+
+// Destroy 删除会话并清除 Cookie
+func (s *Session) Destroy() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]interface{})
+	s.dirty = false
+	s.destroyed = true
+	if s.c != nil {
+		s.c.SetCookie(s.cookie("", -1))
+	}
+	return s.cfg.Store.Delete("session:" + s.id)
+}
+
+func (s *Session) markDirtyLocked() {
+	if s.destroyed {
+		// 销毁后再次写入时使用新 ID
+		s.id = newID(IDSession)
+		s.isNew = true
+		s.destroyed = false
+	}
+	if s.isNew && !s.dirty && s.c != nil {
+		s.c.SetCookie(s.cookie(s.id, int(s.cfg.MaxAge.Seconds())))
+	}
+	s.dirty = true
+}
+
+func (s *Session) saveLocked() error {
+	if s.destroyed {
+		return nil
+	}
+	now := now()
+	data, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(sessionRecord{
+		Values:  s.values,
+		Touched: now.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.cfg.Store.Set("session:"+s.id, data, s.cfg.MaxAge); err != nil {
+		return err
+	}
+	if s.c != nil {
+		s.c.SetCookie(s.cookie(s.id, int(s.cfg.MaxAge.Seconds())))
+	}
+	s.touched = now
+	s.dirty = false
+	return nil
+}
+
+func (s *Session) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.cfg.CookieName,
+		Value:    value,
+		Path:     s.cfg.Path,
+		Domain:   s.cfg.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.cfg.Secure,
+		HttpOnly: true,
+		SameSite: s.cfg.SameSite,
+	}
+}
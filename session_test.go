@@ -0,0 +1,58 @@
+package meego
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSessions(t *testing.T) {
+	store := NewMemoryStore()
+	s := New()
+	s.Use(SessionsWithConfig(SessionConfig{Store: store}))
+	s.GET("/count", func(c *Context) {
+		sess := SessionFromContext(c)
+		n, _ := sess.Get("n").(float64)
+		sess.Set("n", n+1)
+		c.String(StatusOK, strconv.Itoa(int(n+1)))
+	})
+	s.GET("/logout", func(c *Context) {
+		SessionFromContext(c).Destroy()
+		c.String(StatusOK, "bye")
+	})
+	s.GET("/peek", func(c *Context) {
+		c.String(StatusOK, fmt.Sprint(SessionFromContext(c).IsNew()))
+	})
+
+	resp := doRaw(s, "GET /count HTTP/1.1\r\nHost: x\r\n\r\n")
+	start := strings.Index(resp, "Set-Cookie: meego_session=")
+	if start < 0 || !strings.HasSuffix(resp, "\r\n\r\n1") || !strings.Contains(resp, "HttpOnly") {
+		t.Fatalf("first response:\n%s", resp)
+	}
+	id := resp[start+len("Set-Cookie: meego_session="):]
+	id = id[:strings.Index(id, ";")]
+
+	cookie := "Cookie: other=1; meego_session=" + id + "\r\n"
+	for want := 2; want <= 3; want++ {
+		resp = doRaw(s, "GET /count HTTP/1.1\r\nHost: x\r\n"+cookie+"\r\n")
+		if !strings.HasSuffix(resp, strconv.Itoa(want)) {
+			t.Fatalf("want %d:\n%s", want, resp)
+		}
+	}
+
+	// 只读访问不创建会话也不设置 Cookie
+	resp = doRaw(s, "GET /peek HTTP/1.1\r\nHost: x\r\n\r\n")
+	if strings.Contains(resp, "Set-Cookie") || store.Len() != 1 {
+		t.Fatalf("read-only request created a session:\n%s", resp)
+	}
+
+	resp = doRaw(s, "GET /logout HTTP/1.1\r\nHost: x\r\n"+cookie+"\r\n")
+	if !strings.Contains(resp, "Set-Cookie: meego_session=; Path=/; Max-Age=0") {
+		t.Fatalf("logout:\n%s", resp)
+	}
+	resp = doRaw(s, "GET /count HTTP/1.1\r\nHost: x\r\n"+cookie+"\r\n")
+	if !strings.HasSuffix(resp, "\r\n\r\n1") || strings.Contains(resp, id) {
+		t.Fatalf("destroyed session was reused:\n%s", resp)
+	}
+}
@@ -0,0 +1,215 @@
+// slo.go
+package meego
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// SLOObjective 单个路由的服务等级目标
+type SLOObjective struct {
+	Availability     float64       // 可用性目标，如 0.999（5xx 视为失败）
+	LatencyThreshold time.Duration // 延迟阈值，如 300ms
+	LatencyTarget    float64       // 低于阈值的请求比例目标，如 0.99
+}
+
+// SLOConfig SLO 跟踪配置
+type SLOConfig struct {
+	Default       SLOObjective            // 默认目标
+	Routes        map[string]SLOObjective // 按路由覆盖，键为 "GET /api/users/:id"
+	Window        time.Duration           // 滚动窗口，默认 1 小时
+	Buckets       int                     // 窗口分桶数，默认 60
+	AlarmBurnRate float64                 // 触发告警的燃烧率，默认 14.4（1 小时耗尽 2% 月度预算）
+	MinRequests   int64                   // 窗口内请求数低于该值时不告警，默认 100
+	OnAlarm       func(SLOStatus)         // 告警回调，同一路由每个分桶周期最多触发一次
+}
+
+// SLOStatus 路由在当前窗口内的 SLO 状态
+type SLOStatus struct {
+	Route                string       `json:"route"`
+	Requests             int64        `json:"requests"`
+	Errors               int64        `json:"errors"`
+	Slow                 int64        `json:"slow"`
+	Availability         float64      `json:"availability"`
+	LatencyCompliance    float64      `json:"latency_compliance"`
+	AvailabilityBurnRate float64      `json:"availability_burn_rate"`
+	LatencyBurnRate      float64      `json:"latency_burn_rate"`
+	Objective            SLOObjective `json:"objective"`
+}
+
+// sloBucket 一个时间分桶的计数
+type sloBucket struct {
+	index    int64
+	requests int64
+	errors   int64
+	slow     int64
+}
+
+// sloRoute 单个路由的滚动窗口
+type sloRoute struct {
+	objective SLOObjective
+	buckets   []sloBucket
+	lastAlarm int64
+}
+
+// SLOTracker 按路由计算滚动可用性、延迟达标率和错误预算燃烧率
+type SLOTracker struct {
+	cfg        SLOConfig
+	bucketSize time.Duration
+
+	mu     sync.Mutex
+	routes map[string]*sloRoute
+}
+
+// NewSLOTracker 创建 SLO 跟踪器
+func NewSLOTracker(cfg SLOConfig) *SLOTracker {
+	if cfg.Default.Availability <= 0 {
+		cfg.Default.Availability = 0.999
+	}
+	if cfg.Default.LatencyThreshold <= 0 {
+		cfg.Default.LatencyThreshold = 500 * time.Millisecond
+	}
+	if cfg.Default.LatencyTarget <= 0 {
+		cfg.Default.LatencyTarget = 0.99
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = time.Hour
+	}
+	if cfg.Buckets <= 0 {
+		cfg.Buckets = 60
+	}
+	if cfg.AlarmBurnRate <= 0 {
+		cfg.AlarmBurnRate = 14.4
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 100
+	}
+
+	return &SLOTracker{
+		cfg:        cfg,
+		bucketSize: cfg.Window / time.Duration(cfg.Buckets),
+		routes:     make(map[string]*sloRoute),
+	}
+}
+
+// SLO 创建 SLO 跟踪中间件；需要读取指标时使用 NewSLOTracker
+func SLO(cfg SLOConfig) MiddlewareFunc {
+	return NewSLOTracker(cfg).Middleware()
+}
+
+// Middleware 返回记录请求结果的中间件
+func (t *SLOTracker) Middleware() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			start := time.Now()
+			next(c)
+			t.Record(c.Request.Method+" "+c.FullPath(), c.Writer.status, time.Since(start))
+		}
+	}
+}
+
+// Record 记录一次请求结果
+func (t *SLOTracker) Record(route string, status int, latency time.Duration) {
+	now := time.Now()
+	index := now.UnixNano() / int64(t.bucketSize)
+
+	t.mu.Lock()
+	r := t.routeLocked(route)
+	b := &r.buckets[index%int64(len(r.buckets))]
+	if b.index != index {
+		*b = sloBucket{index: index}
+	}
+	b.requests++
+	if status >= 500 {
+		b.errors++
+	}
+	if latency > r.objective.LatencyThreshold {
+		b.slow++
+	}
+
+	var alarm *SLOStatus
+	if t.cfg.OnAlarm != nil && r.lastAlarm != index {
+		status := t.statusLocked(route, r, index)
+		if status.Requests >= t.cfg.MinRequests &&
+			(status.AvailabilityBurnRate >= t.cfg.AlarmBurnRate || status.LatencyBurnRate >= t.cfg.AlarmBurnRate) {
+			r.lastAlarm = index
+			alarm = &status
+		}
+	}
+	t.mu.Unlock()
+
+	if alarm != nil {
+		t.cfg.OnAlarm(*alarm)
+	}
+}
+
+// Snapshot 返回所有路由当前窗口的 SLO 状态，按路由排序
+func (t *SLOTracker) Snapshot() []SLOStatus {
+	index := time.Now().UnixNano() / int64(t.bucketSize)
+
+	t.mu.Lock()
+	result := make([]SLOStatus, 0, len(t.routes))
+	for route, r := range t.routes {
+		result = append(result, t.statusLocked(route, r, index))
+	}
+	t.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Route < result[j].Route
+	})
+	return result
+}
+
+// Handler 以 JSON 输出 SLO 状态，可注册为调试端点
+func (t *SLOTracker) Handler() HandlerFunc {
+	return func(c *Context) {
+		c.JSON(StatusOK, JSON{"slo": t.Snapshot()})
+	}
+}
+
+func (t *SLOTracker) routeLocked(route string) *sloRoute {
+	r, ok := t.routes[route]
+	if !ok {
+		objective, found := t.cfg.Routes[route]
+		if !found {
+			objective = t.cfg.Default
+		}
+		r = &sloRoute{
+			objective: objective,
+			buckets:   make([]sloBucket, t.cfg.Buckets),
+		}
+		t.routes[route] = r
+	}
+	return r
+}
+
+// statusLocked 汇总窗口内仍有效的分桶
+func (t *SLOTracker) statusLocked(route string, r *sloRoute, index int64) SLOStatus {
+	status := SLOStatus{Route: route, Objective: r.objective}
+	oldest := index - int64(len(r.buckets)) + 1
+	for _, b := range r.buckets {
+		if b.index < oldest || b.index > index {
+			continue
+		}
+		status.Requests += b.requests
+		status.Errors += b.errors
+		status.Slow += b.slow
+	}
+
+	status.Availability = 1
+	status.LatencyCompliance = 1
+	if status.Requests > 0 {
+		status.Availability = 1 - float64(status.Errors)/float64(status.Requests)
+		status.LatencyCompliance = 1 - float64(status.Slow)/float64(status.Requests)
+	}
+
+	// 燃烧率 = 实际错误率 / 允许的错误率
+	if budget := 1 - r.objective.Availability; budget > 0 {
+		status.AvailabilityBurnRate = (1 - status.Availability) / budget
+	}
+	if budget := 1 - r.objective.LatencyTarget; budget > 0 {
+		status.LatencyBurnRate = (1 - status.LatencyCompliance) / budget
+	}
+	return status
+}
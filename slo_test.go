@@ -0,0 +1,51 @@
+package meego
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSLOBurnRateAlarm(t *testing.T) {
+	var alarms []SLOStatus
+	tracker := NewSLOTracker(SLOConfig{
+		Default:       SLOObjective{Availability: 0.9},
+		Window:        24 * time.Hour,
+		Buckets:       1,
+		AlarmBurnRate: 2,
+		MinRequests:   10,
+		OnAlarm:       func(s SLOStatus) { alarms = append(alarms, s) },
+	})
+	s := New()
+	s.Use(tracker.Middleware())
+	s.GET("/items/:id", func(c *Context) {
+		if c.Param("id") == "bad" {
+			c.String(StatusInternalServerError, "boom")
+			return
+		}
+		c.String(StatusOK, "ok")
+	})
+
+	// 10 个请求中 4 个失败，错误率 40%，燃烧率 4；请求数达到 MinRequests 前不告警
+	for i := 0; i < 6; i++ {
+		doRaw(s, "GET /items/1 HTTP/1.1\r\nConnection: close\r\n\r\n")
+	}
+	for i := 0; i < 4; i++ {
+		doRaw(s, "GET /items/bad HTTP/1.1\r\nConnection: close\r\n\r\n")
+		if i < 3 && len(alarms) != 0 {
+			t.Fatalf("alarm fired below MinRequests: %+v", alarms)
+		}
+	}
+	// 同一分桶周期内只告警一次
+	doRaw(s, "GET /items/bad HTTP/1.1\r\nConnection: close\r\n\r\n")
+
+	if len(alarms) != 1 {
+		t.Fatalf("expected a single alarm, got %+v", alarms)
+	}
+	a := alarms[0]
+	if a.Route != "GET /items/:id" || a.Requests != 10 || a.Errors != 4 || a.AvailabilityBurnRate < 3.99 || a.AvailabilityBurnRate > 4.01 {
+		t.Fatalf("unexpected alarm status: %+v", a)
+	}
+	if snap := tracker.Snapshot(); len(snap) != 1 || snap[0].Requests != 11 {
+		t.Fatalf("unexpected snapshot: %+v", snap)
+	}
+}
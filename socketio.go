@@ -0,0 +1,772 @@
+// socketio.go
+package meego
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog/log"
+)
+
+// engine.io v4 数据包类型
+const (
+	eioOpen    = '0'
+	eioClose   = '1'
+	eioPing    = '2'
+	eioPong    = '3'
+	eioMessage = '4'
+	eioUpgrade = '5'
+	eioNoop    = '6'
+)
+
+// socket.io v5 数据包类型
+const (
+	sioConnect      = 0
+	sioDisconnect   = 1
+	sioEvent        = 2
+	sioAck          = 3
+	sioConnectError = 4
+)
+
+// eioSeparator polling 传输中多个数据包之间的分隔符
+const eioSeparator = "\x1e"
+
+var sioJSON = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// SocketIOConfig Socket.IO 兼容层配置
+type SocketIOConfig struct {
+	PingInterval time.Duration // 默认 25s
+	PingTimeout  time.Duration // 默认 20s
+	MaxPayload   int           // 单次 polling 请求体上限，默认 1MB
+	// CheckOrigin 校验 WebSocket 传输的 Origin，为空时只允许同源
+	CheckOrigin func(c *Context) bool
+}
+
+// SocketIOHandler 事件处理器
+type SocketIOHandler func(so *SocketIOSocket, ev *SocketIOEvent)
+
+// SocketIO engine.io v4 / Socket.IO v5 兼容服务，支持 polling 和 websocket 传输，
+// 现有的 Socket.IO 客户端可以直接连接：
+//
+//	sio := meego.NewSocketIO(meego.SocketIOConfig{})
+//	sio.On("/", "chat", func(so *meego.SocketIOSocket, ev *meego.SocketIOEvent) {
+//		so.Join("lobby")
+//		sio.Emit("/", "lobby", "chat", ev.Args[0])
+//	})
+//	sio.Mount(server, "/socket.io/")
+//
+// 握手请求经过正常的中间件链，中间件通过 c.Set 设置的值可以用 so.Get 读取。
+// 暂不支持二进制附件
+type SocketIO struct {
+	cfg SocketIOConfig
+	ws  HandlerFunc
+
+	mu           sync.RWMutex
+	sessions     map[string]*eioSession
+	namespaces   map[string]bool
+	onConnect    map[string]func(so *SocketIOSocket) error
+	onDisconnect map[string]func(so *SocketIOSocket, reason string)
+	handlers     map[string]map[string]SocketIOHandler
+	rooms        map[string]map[*SocketIOSocket]struct{} // nsp + "\x00" + room
+}
+
+// NewSocketIO 创建 Socket.IO 兼容服务，默认命名空间 "/" 始终可用
+func NewSocketIO(cfg SocketIOConfig) *SocketIO {
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = 25 * time.Second
+	}
+	if cfg.PingTimeout <= 0 {
+		cfg.PingTimeout = 20 * time.Second
+	}
+	if cfg.MaxPayload <= 0 {
+		cfg.MaxPayload = 1 << 20
+	}
+
+	sio := &SocketIO{
+		cfg:          cfg,
+		sessions:     make(map[string]*eioSession),
+		namespaces:   map[string]bool{"/": true},
+		onConnect:    make(map[string]func(*SocketIOSocket) error),
+		onDisconnect: make(map[string]func(*SocketIOSocket, string)),
+		handlers:     make(map[string]map[string]SocketIOHandler),
+		rooms:        make(map[string]map[*SocketIOSocket]struct{}),
+	}
+	// engine.io 自己发送心跳，关闭 WebSocket 层的 Ping
+	sio.ws = WebSocketWithConfig(WebSocketConfig{
+		CheckOrigin:  cfg.CheckOrigin,
+		ReadLimit:    int64(cfg.MaxPayload),
+		PingInterval: -1,
+		PongTimeout:  -1,
+	}, sio.serveWebSocket)
+	return sio
+}
+
+// Mount 在 path（通常是 "/socket.io/"）上注册 polling 和 websocket 传输
+func (sio *SocketIO) Mount(s *HTTPServer, path string) {
+	s.GET(path, sio.Handler())
+	s.POST(path, sio.Handler())
+}
+
+// Handler 返回处理 engine.io 请求的处理器
+func (sio *SocketIO) Handler() HandlerFunc {
+	return func(c *Context) {
+		if c.Query("EIO") != "4" {
+			sio.fail(c, "unsupported protocol version")
+			return
+		}
+		switch c.Query("transport") {
+		case "websocket":
+			sio.ws(c)
+		case "polling":
+			sio.servePolling(c)
+		default:
+			sio.fail(c, "transport unknown")
+		}
+	}
+}
+
+// OnConnect 客户端连接命名空间时调用，返回错误时拒绝连接
+func (sio *SocketIO) OnConnect(nsp string, fn func(so *SocketIOSocket) error) {
+	sio.mu.Lock()
+	defer sio.mu.Unlock()
+	sio.namespaces[nsp] = true
+	sio.onConnect[nsp] = fn
+}
+
+// OnDisconnect 客户端断开命名空间时调用
+func (sio *SocketIO) OnDisconnect(nsp string, fn func(so *SocketIOSocket, reason string)) {
+	sio.mu.Lock()
+	defer sio.mu.Unlock()
+	sio.namespaces[nsp] = true
+	sio.onDisconnect[nsp] = fn
+}
+
+// On 注册命名空间内的事件处理器
+func (sio *SocketIO) On(nsp, event string, handler SocketIOHandler) {
+	sio.mu.Lock()
+	defer sio.mu.Unlock()
+	sio.namespaces[nsp] = true
+	if sio.handlers[nsp] == nil {
+		sio.handlers[nsp] = make(map[string]SocketIOHandler)
+	}
+	sio.handlers[nsp][event] = handler
+}
+
+// Emit 向命名空间内房间的所有连接发送事件，room 为空时发送给命名空间内所有连接
+func (sio *SocketIO) Emit(nsp, room, event string, args ...interface{}) error {
+	return sio.broadcast(nsp, room, nil, event, args)
+}
+
+func (sio *SocketIO) broadcast(nsp, room string, except *SocketIOSocket, event string, args []interface{}) error {
+	packet, err := encodeSIOEvent(nsp, -1, event, args)
+	if err != nil {
+		return err
+	}
+
+	sio.mu.RLock()
+	var targets []*SocketIOSocket
+	if room != "" {
+		for so := range sio.rooms[nsp+"\x00"+room] {
+			targets = append(targets, so)
+		}
+	} else {
+		for _, sess := range sio.sessions {
+			if so := sess.socket(nsp); so != nil {
+				targets = append(targets, so)
+			}
+		}
+	}
+	sio.mu.RUnlock()
+
+	for _, so := range targets {
+		if so != except {
+			so.session.send(string(eioMessage) + packet)
+		}
+	}
+	return nil
+}
+
+func (sio *SocketIO) fail(c *Context, msg string) {
+	c.Writer.Status(StatusBadRequest).JSON(JSON{
+		"error": msg,
+		"code":  StatusBadRequest,
+	})
+}
+
+// servePolling 处理 polling 传输：GET 长轮询取数据包，POST 提交数据包
+func (sio *SocketIO) servePolling(c *Context) {
+	sid := c.Query("sid")
+	if sid == "" {
+		if c.Request.Method != "GET" {
+			sio.fail(c, "bad handshake method")
+			return
+		}
+		sess := sio.newSession(c)
+		c.Writer.writeBytes("text/plain; charset=UTF-8", []byte(sess.openPacket()))
+		return
+	}
+
+	sio.mu.RLock()
+	sess := sio.sessions[sid]
+	sio.mu.RUnlock()
+	if sess == nil {
+		sio.fail(c, "session ID unknown")
+		return
+	}
+
+	if c.Request.Method == "POST" {
+		body := c.BodyUnsafe()
+		if len(body) > sio.cfg.MaxPayload {
+			sio.fail(c, "payload too large")
+			return
+		}
+		for _, packet := range strings.Split(string(body), eioSeparator) {
+			sess.handlePacket(packet)
+		}
+		c.Writer.writeBytes("text/plain; charset=UTF-8", []byte("ok"))
+		return
+	}
+
+	// 长轮询可能超过服务器写超时
+	wait := sio.cfg.PingInterval + sio.cfg.PingTimeout
+	c.Conn.SetWriteDeadline(time.Now().Add(wait + 5*time.Second))
+	packets, ok := sess.poll(wait)
+	if !ok {
+		sio.fail(c, "overlapping poll")
+		return
+	}
+	c.Writer.writeBytes("text/plain; charset=UTF-8", []byte(strings.Join(packets, eioSeparator)))
+}
+
+// serveWebSocket 处理 websocket 传输：直接连接，或从 polling 升级
+func (sio *SocketIO) serveWebSocket(ws *WebSocketConn) {
+	c := ws.Context()
+	var sess *eioSession
+	if sid := c.Query("sid"); sid != "" {
+		sio.mu.RLock()
+		sess = sio.sessions[sid]
+		sio.mu.RUnlock()
+		if sess == nil || !sio.upgrade(sess, ws) {
+			return
+		}
+	} else {
+		sess = sio.newSession(c)
+		sess.attach(ws)
+		ws.WriteMessage(TextMessage, []byte(sess.openPacket()))
+	}
+
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			sess.close("transport close")
+			return
+		}
+		sess.handlePacket(string(data))
+	}
+}
+
+// upgrade 完成 polling 到 websocket 的升级：2probe -> 3probe -> 5
+func (sio *SocketIO) upgrade(sess *eioSession, ws *WebSocketConn) bool {
+	_, data, err := ws.ReadMessage()
+	if err != nil || string(data) != "2probe" {
+		return false
+	}
+	if ws.WriteMessage(TextMessage, []byte("3probe")) != nil {
+		return false
+	}
+	_, data, err = ws.ReadMessage()
+	if err != nil || string(data) != string(eioUpgrade) {
+		return false
+	}
+	sess.attach(ws)
+	return true
+}
+
+func (sio *SocketIO) newSession(c *Context) *eioSession {
+	values := make(map[string]interface{}, len(c.Values))
+	for k, v := range c.Values {
+		values[k] = v
+	}
+	headers := make(map[string]string, len(c.Request.Headers))
+	for k, v := range c.Request.Headers {
+		headers[k] = v
+	}
+
+	sess := &eioSession{
+		id:       newID(IDConnection),
+		sio:      sio,
+		values:   values,
+		headers:  headers,
+		notify:   make(chan struct{}, 1),
+		closed:   make(chan struct{}),
+		sockets:  make(map[string]*SocketIOSocket),
+		lastPong: time.Now(),
+	}
+	sio.mu.Lock()
+	sio.sessions[sess.id] = sess
+	sio.mu.Unlock()
+
+	go sess.heartbeat()
+	return sess
+}
+
+// eioSession engine.io 会话，polling 和 websocket 之间共享
+type eioSession struct {
+	id      string
+	sio     *SocketIO
+	values  map[string]interface{} // 握手请求中间件设置的值
+	headers map[string]string      // 握手请求头
+
+	mu       sync.Mutex
+	queue    []string
+	polling  bool
+	ws       *WebSocketConn
+	sockets  map[string]*SocketIOSocket
+	lastPong time.Time
+
+	notify    chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *eioSession) openPacket() string {
+	data, _ := sioJSON.Marshal(map[string]interface{}{
+		"sid":          s.id,
+		"upgrades":     []string{"websocket"},
+		"pingInterval": s.sio.cfg.PingInterval.Milliseconds(),
+		"pingTimeout":  s.sio.cfg.PingTimeout.Milliseconds(),
+		"maxPayload":   s.sio.cfg.MaxPayload,
+	})
+	return string(eioOpen) + string(data)
+}
+
+// send 发送一个 engine.io 数据包
+func (s *eioSession) send(packet string) {
+	s.mu.Lock()
+	ws := s.ws
+	if ws == nil {
+		s.queue = append(s.queue, packet)
+	}
+	s.mu.Unlock()
+
+	if ws != nil {
+		if err := ws.Send(TextMessage, []byte(packet)); err != nil {
+			s.close("transport error")
+		}
+		return
+	}
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// poll 等待并取出排队的数据包，同一时间只允许一个长轮询
+func (s *eioSession) poll(wait time.Duration) ([]string, bool) {
+	s.mu.Lock()
+	if s.polling || s.ws != nil {
+		s.mu.Unlock()
+		return nil, false
+	}
+	s.polling = true
+	s.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			packets := s.queue
+			s.queue = nil
+			s.polling = false
+			s.mu.Unlock()
+			return packets, true
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.notify:
+		case <-s.closed:
+			s.mu.Lock()
+			s.polling = false
+			s.mu.Unlock()
+			return []string{string(eioClose)}, true
+		case <-timer.C:
+			s.mu.Lock()
+			s.polling = false
+			s.mu.Unlock()
+			return []string{string(eioNoop)}, true
+		}
+	}
+}
+
+// attach 切换到 websocket 传输，未完成的长轮询收到 noop 后结束
+func (s *eioSession) attach(ws *WebSocketConn) {
+	s.mu.Lock()
+	pending := s.queue
+	s.queue = []string{string(eioNoop)}
+	s.ws = ws
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	for _, packet := range pending {
+		ws.Send(TextMessage, []byte(packet))
+	}
+}
+
+// heartbeat 定时发送 ping，超时未收到 pong 时关闭会话
+func (s *eioSession) heartbeat() {
+	ticker := time.NewTicker(s.sio.cfg.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			last := s.lastPong
+			s.mu.Unlock()
+			if time.Since(last) > s.sio.cfg.PingInterval+s.sio.cfg.PingTimeout {
+				s.close("ping timeout")
+				return
+			}
+			s.send(string(eioPing))
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+func (s *eioSession) handlePacket(packet string) {
+	if packet == "" {
+		return
+	}
+	switch packet[0] {
+	case eioPong:
+		s.mu.Lock()
+		s.lastPong = time.Now()
+		s.mu.Unlock()
+	case eioMessage:
+		s.handleSIOPacket(packet[1:])
+	case eioClose:
+		s.close("client close")
+	}
+}
+
+// close 关闭会话，断开所有命名空间
+func (s *eioSession) close(reason string) {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+
+		s.sio.mu.Lock()
+		delete(s.sio.sessions, s.id)
+		s.sio.mu.Unlock()
+
+		s.mu.Lock()
+		sockets := s.sockets
+		s.sockets = make(map[string]*SocketIOSocket)
+		ws := s.ws
+		s.mu.Unlock()
+
+		for _, so := range sockets {
+			so.disconnected(reason)
+		}
+		if ws != nil {
+			ws.Close()
+		}
+	})
+}
+
+func (s *eioSession) socket(nsp string) *SocketIOSocket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sockets[nsp]
+}
+
+// handleSIOPacket 处理 socket.io 数据包
+func (s *eioSession) handleSIOPacket(raw string) {
+	typ, nsp, ackID, data, err := decodeSIOPacket(raw)
+	if err != nil {
+		log.Debug().Err(err).Str("sid", s.id).Msg("invalid socket.io packet")
+		return
+	}
+
+	switch typ {
+	case sioConnect:
+		s.connect(nsp, data)
+	case sioDisconnect:
+		s.mu.Lock()
+		so := s.sockets[nsp]
+		delete(s.sockets, nsp)
+		s.mu.Unlock()
+		if so != nil {
+			so.disconnected("client namespace disconnect")
+		}
+	case sioEvent:
+		so := s.socket(nsp)
+		if so == nil {
+			return
+		}
+		var args []jsoniter.RawMessage
+		if err := sioJSON.Unmarshal(data, &args); err != nil || len(args) == 0 {
+			return
+		}
+		ev := &SocketIOEvent{Args: args[1:], socket: so, ackID: ackID}
+		if err := sioJSON.Unmarshal(args[0], &ev.Name); err != nil {
+			return
+		}
+
+		s.sio.mu.RLock()
+		handler := s.sio.handlers[nsp][ev.Name]
+		s.sio.mu.RUnlock()
+		if handler != nil {
+			handler(so, ev)
+		}
+	case sioAck:
+		// 暂不支持服务器发起的 ack
+	}
+}
+
+func (s *eioSession) connect(nsp string, auth []byte) {
+	s.sio.mu.RLock()
+	known := s.sio.namespaces[nsp]
+	onConnect := s.sio.onConnect[nsp]
+	s.sio.mu.RUnlock()
+
+	if !known {
+		s.sendSIO(sioConnectError, nsp, JSON{"message": "Invalid namespace"})
+		return
+	}
+
+	so := &SocketIOSocket{
+		ID:        newID(IDConnection),
+		Namespace: nsp,
+		Auth:      auth,
+		session:   s,
+		rooms:     make(map[string]struct{}),
+	}
+	if onConnect != nil {
+		if err := onConnect(so); err != nil {
+			s.sendSIO(sioConnectError, nsp, JSON{"message": err.Error()})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	s.sockets[nsp] = so
+	s.mu.Unlock()
+	s.sendSIO(sioConnect, nsp, JSON{"sid": so.ID})
+}
+
+func (s *eioSession) sendSIO(typ int, nsp string, data interface{}) {
+	packet, err := encodeSIOPacket(typ, nsp, -1, data)
+	if err != nil {
+		return
+	}
+	s.send(string(eioMessage) + packet)
+}
+
+// SocketIOSocket 客户端在一个命名空间上的连接
+type SocketIOSocket struct {
+	ID        string
+	Namespace string
+	Auth      jsoniter.RawMessage // CONNECT 时携带的 auth 数据
+
+	session *eioSession
+	mu      sync.Mutex
+	rooms   map[string]struct{}
+}
+
+// Get 读取握手请求上中间件设置的值（如认证用户）
+func (so *SocketIOSocket) Get(key string) interface{} {
+	return so.session.values[key]
+}
+
+// Header 读取握手请求头
+func (so *SocketIOSocket) Header(key string) string {
+	for k, v := range so.session.headers {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// Emit 向客户端发送事件
+func (so *SocketIOSocket) Emit(event string, args ...interface{}) error {
+	packet, err := encodeSIOEvent(so.Namespace, -1, event, args)
+	if err != nil {
+		return err
+	}
+	so.session.send(string(eioMessage) + packet)
+	return nil
+}
+
+// BroadcastTo 向房间内除自己以外的连接发送事件
+func (so *SocketIOSocket) BroadcastTo(room, event string, args ...interface{}) error {
+	return so.session.sio.broadcast(so.Namespace, room, so, event, args)
+}
+
+// Join 加入房间
+func (so *SocketIOSocket) Join(room string) {
+	sio := so.session.sio
+	key := so.Namespace + "\x00" + room
+	sio.mu.Lock()
+	if sio.rooms[key] == nil {
+		sio.rooms[key] = make(map[*SocketIOSocket]struct{})
+	}
+	sio.rooms[key][so] = struct{}{}
+	sio.mu.Unlock()
+
+	so.mu.Lock()
+	so.rooms[room] = struct{}{}
+	so.mu.Unlock()
+}
+
+// Leave 离开房间
+func (so *SocketIOSocket) Leave(room string) {
+	sio := so.session.sio
+	key := so.Namespace + "\x00" + room
+	sio.mu.Lock()
+	if members := sio.rooms[key]; members != nil {
+		delete(members, so)
+		if len(members) == 0 {
+			delete(sio.rooms, key)
+		}
+	}
+	sio.mu.Unlock()
+
+	so.mu.Lock()
+	delete(so.rooms, room)
+	so.mu.Unlock()
+}
+
+// Disconnect 断开命名空间连接
+func (so *SocketIOSocket) Disconnect() {
+	s := so.session
+	s.mu.Lock()
+	delete(s.sockets, so.Namespace)
+	s.mu.Unlock()
+	s.sendSIO(sioDisconnect, so.Namespace, nil)
+	so.disconnected("server namespace disconnect")
+}
+
+func (so *SocketIOSocket) disconnected(reason string) {
+	so.mu.Lock()
+	rooms := make([]string, 0, len(so.rooms))
+	for room := range so.rooms {
+		rooms = append(rooms, room)
+	}
+	so.mu.Unlock()
+	for _, room := range rooms {
+		so.Leave(room)
+	}
+
+	sio := so.session.sio
+	sio.mu.RLock()
+	fn := sio.onDisconnect[so.Namespace]
+	sio.mu.RUnlock()
+	if fn != nil {
+		fn(so, reason)
+	}
+}
+
+// SocketIOEvent 客户端发送的事件
+type SocketIOEvent struct {
+	Name string
+	Args []jsoniter.RawMessage
+
+	socket *SocketIOSocket
+	ackID  int
+}
+
+// Bind 把第 i 个参数解析到 v
+func (ev *SocketIOEvent) Bind(i int, v interface{}) error {
+	if i >= len(ev.Args) {
+		return errors.New("socket.io: argument index out of range")
+	}
+	return sioJSON.Unmarshal(ev.Args[i], v)
+}
+
+// Ack 回复客户端的确认回调，客户端没有请求确认时忽略
+func (ev *SocketIOEvent) Ack(args ...interface{}) error {
+	if ev.ackID < 0 {
+		return nil
+	}
+	if args == nil {
+		args = []interface{}{}
+	}
+	packet, err := encodeSIOPacket(sioAck, ev.socket.Namespace, ev.ackID, args)
+	if err != nil {
+		return err
+	}
+	ev.ackID = -1
+	ev.socket.session.send(string(eioMessage) + packet)
+	return nil
+}
+
+func encodeSIOEvent(nsp string, ackID int, event string, args []interface{}) (string, error) {
+	return encodeSIOPacket(sioEvent, nsp, ackID, append([]interface{}{event}, args...))
+}
+
+// encodeSIOPacket 编码 socket.io 数据包：<type>[<nsp>,][<ackId>][<json>]
+func encodeSIOPacket(typ int, nsp string, ackID int, data interface{}) (string, error) {
+	var b strings.Builder
+	b.WriteString(strconv.Itoa(typ))
+	if nsp != "" && nsp != "/" {
+		b.WriteString(nsp)
+		b.WriteByte(',')
+	}
+	if ackID >= 0 {
+		b.WriteString(strconv.Itoa(ackID))
+	}
+	if data != nil {
+		payload, err := sioJSON.Marshal(data)
+		if err != nil {
+			return "", err
+		}
+		b.Write(payload)
+	}
+	return b.String(), nil
+}
+
+// decodeSIOPacket 解析 socket.io 数据包，ackID 为 -1 表示没有确认
+func decodeSIOPacket(raw string) (typ int, nsp string, ackID int, data []byte, err error) {
+	if raw == "" || raw[0] < '0' || raw[0] > '6' {
+		return 0, "", -1, nil, errors.New("socket.io: invalid packet type")
+	}
+	typ = int(raw[0] - '0')
+	if typ > sioConnectError {
+		return 0, "", -1, nil, errors.New("socket.io: binary packets not supported")
+	}
+	rest := raw[1:]
+
+	nsp = "/"
+	if strings.HasPrefix(rest, "/") {
+		end := strings.IndexByte(rest, ',')
+		if end < 0 {
+			nsp, rest = rest, ""
+		} else {
+			nsp, rest = rest[:end], rest[end+1:]
+		}
+	}
+
+	ackID = -1
+	i := 0
+	for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+		i++
+	}
+	if i > 0 {
+		ackID, _ = strconv.Atoi(rest[:i])
+		rest = rest[i:]
+	}
+	if rest != "" {
+		data = []byte(rest)
+	}
+	return typ, nsp, ackID, data, nil
+}
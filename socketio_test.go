@@ -0,0 +1,46 @@
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSocketIOPolling(t *testing.T) {
+	s := New()
+	sio := NewSocketIO(SocketIOConfig{})
+	sio.On("/", "ping", func(so *SocketIOSocket, ev *SocketIOEvent) {
+		var msg string
+		ev.Bind(0, &msg)
+		ev.Ack("pong:" + msg)
+	})
+	sio.Mount(s, "/socket.io/")
+
+	body := func(resp string) string {
+		_, b, _ := strings.Cut(resp, "\r\n\r\n")
+		return b
+	}
+	post := func(sid, payload string) {
+		doRaw(s, "POST /socket.io/?EIO=4&transport=polling&sid="+sid+" HTTP/1.1\r\n"+
+			"Content-Length: "+strconv.Itoa(len(payload))+"\r\n\r\n"+payload)
+	}
+
+	open := body(doRaw(s, "GET /socket.io/?EIO=4&transport=polling HTTP/1.1\r\n\r\n"))
+	if !strings.HasPrefix(open, `0{"maxPayload"`) {
+		t.Fatalf("unexpected open packet: %q", open)
+	}
+	sid := open[strings.Index(open, `"sid":"`)+7:]
+	sid = sid[:strings.IndexByte(sid, '"')]
+
+	post(sid, "40")
+	poll := "GET /socket.io/?EIO=4&transport=polling&sid=" + sid + " HTTP/1.1\r\n\r\n"
+	if got := body(doRaw(s, poll)); !strings.HasPrefix(got, `40{"sid":"`) {
+		t.Fatalf("expected connect packet, got %q", got)
+	}
+
+	post(sid, `421["ping","x"]`)
+	if got := body(doRaw(s, poll)); got != `431["pong:x"]` {
+		t.Fatalf("expected ack packet, got %q", got)
+	}
+	post(sid, "1")
+}
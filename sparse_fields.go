@@ -0,0 +1,108 @@
+// sparse_fields.go
+package meego
+
+import (
+	"bytes"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Fields 为路由启用稀疏字段集：请求带 ?fields=name,email 时，2xx JSON 响应只保留这些顶层字段
+// （数组响应对每个对象元素过滤），减少移动端的响应大小。字段名为序列化后的名称，即 json 标签。
+// allowed 为允许请求的字段，不在其中的字段被忽略；为空时允许所有字段
+func (r *Route) Fields(allowed ...string) *Route {
+	r.fields = append([]string{}, allowed...)
+	return r
+}
+
+// Fields 为路由组内之后注册的路由启用稀疏字段集
+func (g *RouteGroup) Fields(allowed ...string) *RouteGroup {
+	g.fields = append([]string{}, allowed...)
+	return g
+}
+
+// requestedFields 解析请求的 fields 参数，去掉不允许的字段；没有可用的字段时返回 nil，不过滤
+func requestedFields(req *HTTPRequest, allowed []string) []string {
+	if req.URL == nil {
+		return nil
+	}
+	param := req.URL.Query().Get("fields")
+	if param == "" {
+		return nil
+	}
+	fields := make([]string, 0, 4)
+	for _, f := range strings.Split(param, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" || containsString(fields, f) || (len(allowed) > 0 && !containsString(allowed, f)) {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// filterFields 按请求的字段过滤 JSON 响应体，无法过滤（不是对象或对象数组）时原样返回
+func (w *ResponseWriter) filterFields(body []byte) []byte {
+	if w.fields == nil || w.status < 200 || w.status >= 300 {
+		return body
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return body
+	}
+	switch trimmed[0] {
+	case '{':
+		if out, ok := w.filterObject(trimmed); ok {
+			return out
+		}
+	case '[':
+		var items []jsoniter.RawMessage
+		if err := w.json.Unmarshal(trimmed, &items); err != nil {
+			return body
+		}
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if out, ok := w.filterObject(item); ok {
+				buf.Write(out)
+			} else {
+				buf.Write(item)
+			}
+		}
+		buf.WriteByte(']')
+		return buf.Bytes()
+	}
+	return body
+}
+
+// filterObject 只保留请求的字段，按请求的顺序输出
+func (w *ResponseWriter) filterObject(data []byte) ([]byte, bool) {
+	var obj map[string]jsoniter.RawMessage
+	if err := w.json.Unmarshal(data, &obj); err != nil || obj == nil {
+		return nil, false
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, f := range w.fields {
+		value, ok := obj[f]
+		if !ok {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := w.json.Marshal(f)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), true
+}
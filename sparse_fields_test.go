@@ -0,0 +1,33 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSparseFields(t *testing.T) {
+	s := New()
+	type user struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Password string `json:"password,omitempty"`
+	}
+	users := []user{{1, "ann", "ann@example.com", ""}, {2, "bob", "bob@example.com", ""}}
+	s.GET("/users", func(c *Context) { c.JSON(StatusOK, users) }).Fields("id", "name", "email")
+	s.GET("/users/1", func(c *Context) { c.JSON(StatusOK, users[0]) }).Fields()
+	s.GET("/missing", func(c *Context) { c.JSON(StatusNotFound, JSON{"error": "Not Found", "code": 404}) }).Fields()
+	s.GET("/plain", func(c *Context) { c.JSON(StatusOK, users[0]) })
+
+	for path, want := range map[string]string{
+		"/users?fields=name,password,name": `[{"name":"ann"},{"name":"bob"}]`,
+		"/users?fields=password":           `"email":"bob@example.com"}]`,
+		"/users/1?fields=email,id":         `{"email":"ann@example.com","id":1}`,
+		"/missing?fields=code":             `"error":"Not Found"`,
+		"/plain?fields=id":                 `"name":"ann"`,
+	} {
+		if resp := doRaw(s, "GET "+path+" HTTP/1.1\r\n\r\n"); !strings.Contains(resp, want) {
+			t.Fatalf("%s: expected %q in %q", path, want, resp)
+		}
+	}
+}
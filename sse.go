@@ -0,0 +1,244 @@
+// sse.go
+package meego
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrStreamClosed 请求已结束，事件流不能再写入
+var ErrStreamClosed = errors.New("event stream closed")
+
+// SSEEvent 服务器发送事件（text/event-stream）
+type SSEEvent struct {
+	ID    string // 事件 ID，客户端重连时通过 Last-Event-ID 发回
+	Event string // 事件类型，为空时客户端按 message 处理
+	Data  string // 事件数据，可以包含多行
+}
+
+// SSEReplay 事件重放缓冲，客户端带着 Last-Event-ID 重连时补发错过的事件。
+// 多实例部署时可以基于 Redis Stream 等实现
+type SSEReplay interface {
+	// Append 保存事件，ID 为空时分配一个，返回保存的事件
+	Append(event SSEEvent) SSEEvent
+	// Since 返回 lastID 之后的事件；lastID 已不在缓冲中时 ok 为 false，
+	// 此时尽量补发缓冲中现有的事件
+	Since(lastID string) (events []SSEEvent, ok bool)
+}
+
+// SSEConfig 事件流配置
+type SSEConfig struct {
+	// Replay 重放缓冲，为空时不补发事件
+	Replay SSEReplay
+	// Retry 建议客户端的重连间隔，0 表示使用客户端默认值
+	Retry time.Duration
+}
+
+// SSEStream 单个客户端的事件流。服务器关闭时客户端收到注释 ": server shutting down"，
+// Done 被关闭，处理器应随之返回；排空窗口结束后连接被强制关闭
+type SSEStream struct {
+	c      *Context
+	conn   net.Conn
+	replay SSEReplay
+	lastID string
+	gap    bool
+
+	mu       sync.Mutex // 串行化处理器和关闭通知的写入
+	finished bool       // 请求已结束，不能再写入
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// SSE 开始不带重放缓冲的事件流
+func (c *Context) SSE() (*SSEStream, error) {
+	return c.SSEWithConfig(SSEConfig{})
+}
+
+// SSEWithConfig 开始事件流：发送响应头，请求带有 Last-Event-ID 且配置了 Replay 时
+// 先补发缓冲中该 ID 之后的事件
+func (c *Context) SSEWithConfig(cfg SSEConfig) (*SSEStream, error) {
+	w := c.Writer
+	w.SetHeader("Content-Type", "text/event-stream; charset=utf-8")
+	w.SetHeader("Cache-Control", "no-cache")
+	w.SetHeader("X-Accel-Buffering", "no")
+	delete(w.header, "Content-Length")
+	s := &SSEStream{
+		c:      c,
+		conn:   c.Conn,
+		replay: cfg.Replay,
+		lastID: c.Request.GetHeader("Last-Event-ID"),
+		done:   make(chan struct{}),
+	}
+
+	if cfg.Retry > 0 {
+		if err := w.WriteChunk([]byte("retry: " + strconv.FormatInt(cfg.Retry.Milliseconds(), 10) + "\n\n")); err != nil {
+			return nil, err
+		}
+	} else if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	if s.lastID != "" && s.replay != nil {
+		events, ok := s.replay.Since(s.lastID)
+		s.gap = !ok
+		for _, event := range events {
+			if err := s.Forward(event); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if c.server != nil {
+		untrack := c.server.trackLongLived(s)
+		c.onRelease(func() {
+			s.mu.Lock()
+			s.finished = true
+			s.mu.Unlock()
+			untrack()
+		})
+	}
+	return s, nil
+}
+
+// Done 服务器开始关闭时关闭
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// write 发送一段事件流数据
+func (s *SSEStream) write(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.finished {
+		return ErrStreamClosed
+	}
+	return s.c.Writer.WriteChunk(p)
+}
+
+// drain 服务器关闭时通知客户端
+func (s *SSEStream) drain() {
+	s.Comment("server shutting down")
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+// forceClose 排空窗口结束后关闭连接，处理器之后的写入返回错误
+func (s *SSEStream) forceClose() {
+	s.doneOnce.Do(func() { close(s.done) })
+	s.conn.Close()
+}
+
+// Send 发送事件；配置了 Replay 时先保存到缓冲（并分配 ID）
+func (s *SSEStream) Send(event SSEEvent) error {
+	if s.replay != nil {
+		event = s.replay.Append(event)
+	}
+	return s.Forward(event)
+}
+
+// Forward 发送已经保存到缓冲的事件，不再保存。多个客户端共享同一缓冲时，
+// 发布方调用一次 Append，再对每个事件流调用 Forward
+func (s *SSEStream) Forward(event SSEEvent) error {
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: ")
+		b.WriteString(event.ID)
+		b.WriteByte('\n')
+	}
+	if event.Event != "" {
+		b.WriteString("event: ")
+		b.WriteString(event.Event)
+		b.WriteByte('\n')
+	}
+	for _, line := range strings.Split(event.Data, "\n") {
+		b.WriteString("data: ")
+		b.WriteString(strings.TrimSuffix(line, "\r"))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	if err := s.write([]byte(b.String())); err != nil {
+		return err
+	}
+	if event.ID != "" {
+		s.lastID = event.ID
+	}
+	return nil
+}
+
+// Comment 发送注释行，可作为心跳防止中间代理断开空闲连接
+func (s *SSEStream) Comment(text string) error {
+	return s.write([]byte(": " + text + "\n\n"))
+}
+
+// LastEventID 最近发送的事件 ID；还没有发送时为客户端的 Last-Event-ID
+func (s *SSEStream) LastEventID() string {
+	return s.lastID
+}
+
+// Gap 客户端的 Last-Event-ID 已不在重放缓冲中，有事件无法补发，
+// 处理器可以改为发送完整状态
+func (s *SSEStream) Gap() bool {
+	return s.gap
+}
+
+// SSEBuffer 进程内的环形重放缓冲，保留最近 size 个事件，分配递增的十进制 ID
+type SSEBuffer struct {
+	mu     sync.Mutex
+	events []SSEEvent
+	start  int // 最早事件在 events 中的位置
+	seq    uint64
+}
+
+// NewSSEBuffer 创建保留最近 size 个事件的重放缓冲，size <= 0 时为 1000
+func NewSSEBuffer(size int) *SSEBuffer {
+	if size <= 0 {
+		size = 1000
+	}
+	return &SSEBuffer{events: make([]SSEEvent, 0, size)}
+}
+
+// Append 保存事件，ID 为空时分配下一个序号
+func (b *SSEBuffer) Append(event SSEEvent) SSEEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	if event.ID == "" {
+		event.ID = strconv.FormatUint(b.seq, 10)
+	}
+	if len(b.events) < cap(b.events) {
+		b.events = append(b.events, event)
+	} else {
+		b.events[b.start] = event
+		b.start = (b.start + 1) % len(b.events)
+	}
+	return event
+}
+
+// Since 返回 lastID 之后的事件
+func (b *SSEBuffer) Since(lastID string) ([]SSEEvent, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(b.events)
+	for i := n - 1; i >= 0; i-- {
+		if b.events[(b.start+i)%n].ID == lastID {
+			events := make([]SSEEvent, 0, n-1-i)
+			for j := i + 1; j < n; j++ {
+				events = append(events, b.events[(b.start+j)%n])
+			}
+			return events, true
+		}
+	}
+	return b.all(), false
+}
+
+// all 按顺序返回缓冲中的全部事件
+func (b *SSEBuffer) all() []SSEEvent {
+	n := len(b.events)
+	events := make([]SSEEvent, 0, n)
+	for i := 0; i < n; i++ {
+		events = append(events, b.events[(b.start+i)%n])
+	}
+	return events
+}
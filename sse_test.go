@@ -0,0 +1,42 @@
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEResume(t *testing.T) {
+	buf := NewSSEBuffer(3)
+	for i := 1; i <= 4; i++ {
+		buf.Append(SSEEvent{Data: "e" + strconv.Itoa(i)})
+	}
+	s := New()
+	s.GET("/events", func(c *Context) {
+		stream, err := c.SSEWithConfig(SSEConfig{Replay: buf, Retry: 2 * time.Second})
+		if err != nil {
+			return
+		}
+		if stream.Gap() {
+			stream.Comment("gap")
+		}
+		stream.Send(SSEEvent{Event: "live", Data: "line1\nline2"})
+	})
+
+	resp := doRaw(s, "GET /events HTTP/1.1\r\nLast-Event-ID: 2\r\n\r\n")
+	if !strings.Contains(resp, "Content-Type: text/event-stream") || !strings.Contains(resp, "retry: 2000\n\n") {
+		t.Fatalf("unexpected stream: %q", resp)
+	}
+	want := "id: 3\ndata: e3\n\n\r\n"
+	if !strings.Contains(resp, want) || !strings.Contains(resp, "id: 4\ndata: e4\n\n") ||
+		!strings.Contains(resp, "id: 5\nevent: live\ndata: line1\ndata: line2\n\n") || strings.Contains(resp, "data: e2") {
+		t.Fatalf("unexpected replay: %q", resp)
+	}
+
+	// 最早的事件已被挤出缓冲
+	resp = doRaw(s, "GET /events HTTP/1.1\r\nLast-Event-ID: 1\r\n\r\n")
+	if !strings.Contains(resp, ": gap\n\n") || !strings.Contains(resp, "data: e4") || !strings.Contains(resp, "id: 6\n") {
+		t.Fatalf("unexpected gap replay: %q", resp)
+	}
+}
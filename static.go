@@ -0,0 +1,254 @@
+// static.go
+package meego
+
+import (
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticConfig 静态文件配置
+type StaticConfig struct {
+	Root   string // 磁盘目录，与 FS 二选一
+	FS     fs.FS
+	Index  string // 目录的默认文件，默认 index.html，"-" 表示不使用
+	Browse bool   // 目录没有默认文件时列出目录内容
+	// Precompressed 存在同名的 .br 或 .gz 文件且客户端 Accept-Encoding 接受时直接发送，
+	// 不在运行时压缩；响应带有 Vary: Accept-Encoding
+	Precompressed bool
+}
+
+// precompressedEncodings 预压缩文件的扩展名，权重相同时按顺序优先
+var precompressedEncodings = []struct{ encoding, ext string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Static 在 prefix 下提供 root 目录中的文件，如 s.Static("/assets", "./public")
+func (s *HTTPServer) Static(prefix, root string) *Route {
+	return s.StaticWithConfig(prefix, StaticConfig{Root: root})
+}
+
+// StaticFS 在 prefix 下提供 fsys 中的文件，可用于 embed.FS
+func (s *HTTPServer) StaticFS(prefix string, fsys fs.FS) *Route {
+	return s.StaticWithConfig(prefix, StaticConfig{FS: fsys})
+}
+
+// StaticWithConfig 使用自定义配置提供静态文件。请求路径经过清理并由 fs.FS 校验，
+// 包含 ".." 的路径无法访问 root 之外的文件
+func (s *HTTPServer) StaticWithConfig(prefix string, cfg StaticConfig) *Route {
+	fsys := cfg.FS
+	if fsys == nil {
+		fsys = os.DirFS(cfg.Root)
+	}
+	if cfg.Index == "" {
+		cfg.Index = "index.html"
+	}
+	return s.GET(strings.TrimRight(prefix, "/")+"/*filepath", func(c *Context) {
+		serveStatic(c, fsys, c.Param("filepath"), cfg)
+	})
+}
+
+// StaticFile 把单个磁盘文件注册为路由，如 s.StaticFile("/favicon.ico", "./public/favicon.ico")
+func (s *HTTPServer) StaticFile(route, file string) *Route {
+	dir, name := path.Split(file)
+	if dir == "" {
+		dir = "."
+	}
+	fsys := os.DirFS(dir)
+	return s.GET(route, func(c *Context) {
+		serveStatic(c, fsys, name, StaticConfig{Index: "-"})
+	})
+}
+
+func serveStatic(c *Context, fsys fs.FS, urlPath string, cfg StaticConfig) {
+	name := strings.TrimPrefix(path.Clean("/"+urlPath), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		notFoundHandler(c)
+		return
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		notFoundHandler(c)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		notFoundHandler(c)
+		return
+	}
+
+	if info.IsDir() {
+		// 目录需要以 / 结尾，页面中的相对链接才能正确解析
+		if !strings.HasSuffix(c.Request.URL.Path, "/") {
+			c.Writer.SetHeader("Location", c.Request.URL.Path+"/")
+			c.Writer.Status(StatusMovedPermanently).String("")
+			return
+		}
+		if cfg.Index != "-" {
+			index := path.Join(name, cfg.Index)
+			if idx, err := fsys.Open(index); err == nil {
+				if idxInfo, err := idx.Stat(); err == nil && !idxInfo.IsDir() {
+					defer idx.Close()
+					serveFile(c, fsys, index, idx, idxInfo, cfg)
+					return
+				}
+				idx.Close()
+			}
+		}
+		if cfg.Browse {
+			listDirectory(c, fsys, name)
+			return
+		}
+		notFoundHandler(c)
+		return
+	}
+	serveFile(c, fsys, name, f, info, cfg)
+}
+
+// serveFile 发送文件，开启 Precompressed 时优先发送客户端接受的预压缩文件
+func serveFile(c *Context, fsys fs.FS, name string, f fs.File, info fs.FileInfo, cfg StaticConfig) {
+	if cfg.Precompressed {
+		c.Writer.AddVary("Accept-Encoding")
+		if encoding, cf, cinfo := openPrecompressed(fsys, name, c.Request.GetHeader("Accept-Encoding")); cf != nil {
+			defer cf.Close()
+			// 压缩后的内容无法嗅探，类型只能按原文件扩展名确定
+			contentType := mime.TypeByExtension(path.Ext(name))
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+			c.Writer.SetHeader("Content-Type", contentType)
+			c.Writer.SetHeader("Content-Encoding", encoding)
+			serveContent(c, cf, cinfo)
+			return
+		}
+	}
+	serveContent(c, f, info)
+}
+
+// openPrecompressed 按 Accept-Encoding 的权重打开存在的预压缩文件，没有可用文件时返回 nil
+func openPrecompressed(fsys fs.FS, name, acceptEncoding string) (string, fs.File, fs.FileInfo) {
+	if acceptEncoding == "" {
+		return "", nil, nil
+	}
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		if encoding, q := parseQuality(part); encoding != "" {
+			accepted[encoding] = q
+		}
+	}
+
+	// 按权重从高到低尝试，权重相同时按 precompressedEncodings 的顺序
+	type candidate struct {
+		encoding, ext string
+		q             float64
+	}
+	var candidates []candidate
+	for _, pc := range precompressedEncodings {
+		q, ok := accepted[pc.encoding]
+		if !ok {
+			q = accepted["*"]
+		}
+		if q > 0 {
+			candidates = append(candidates, candidate{pc.encoding, pc.ext, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, cand := range candidates {
+		f, err := fsys.Open(name + cand.ext)
+		if err != nil {
+			continue
+		}
+		if info, err := f.Stat(); err == nil && !info.IsDir() {
+			return cand.encoding, f, info
+		}
+		f.Close()
+	}
+	return "", nil, nil
+}
+
+// serveContent 处理 If-Modified-Since 并以固定长度流式发送文件，不整体读入内存
+func serveContent(c *Context, f fs.File, info fs.FileInfo) {
+	modTime := info.ModTime().UTC().Truncate(time.Second)
+	if !modTime.IsZero() {
+		c.Writer.SetHeader("Last-Modified", modTime.Format(http.TimeFormat))
+		if since, err := http.ParseTime(c.Request.GetHeader("If-Modified-Since")); err == nil && !modTime.After(since) {
+			c.Writer.Status(StatusNotModified).String("")
+			return
+		}
+	}
+
+	buf := make([]byte, 32*1024)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		c.Writer.Status(StatusInternalServerError).JSON(JSON{"error": "Internal Server Error", "code": StatusInternalServerError})
+		return
+	}
+	if c.Writer.Header()["Content-Type"] == "" {
+		contentType := mime.TypeByExtension(path.Ext(info.Name()))
+		if contentType == "" {
+			contentType = http.DetectContentType(buf[:n])
+		}
+		c.Writer.SetHeader("Content-Type", contentType)
+	}
+	c.Writer.SetHeader("Content-Length", strconv.FormatInt(info.Size(), 10))
+	c.Writer.Status(StatusOK)
+
+	for n > 0 {
+		if err := c.Writer.WriteChunk(buf[:n]); err != nil {
+			return
+		}
+		n, err = f.Read(buf)
+		if err != nil && err != io.EOF {
+			c.Writer.abortStream()
+			return
+		}
+	}
+	if info.Size() == 0 {
+		c.Writer.startStream()
+	}
+}
+
+// listDirectory 输出目录列表，子目录在前
+func listDirectory(c *Context, fsys fs.FS, name string) {
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		notFoundHandler(c)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	var b strings.Builder
+	title := html.EscapeString(c.Request.URL.Path)
+	b.WriteString("<!doctype html>\n<meta charset=\"utf-8\">\n<title>Index of " + title + "</title>\n")
+	b.WriteString("<h1>Index of " + title + "</h1>\n<pre>\n<a href=\"../\">../</a>\n")
+	for _, e := range entries {
+		n, href := e.Name(), url.PathEscape(e.Name())
+		if e.IsDir() {
+			n += "/"
+			href += "/"
+		}
+		b.WriteString("<a href=\"" + href + "\">" + html.EscapeString(n) + "</a>\n")
+	}
+	b.WriteString("</pre>\n")
+	c.HTML(StatusOK, b.String())
+}
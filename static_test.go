@@ -0,0 +1,76 @@
+package meego
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaticFiles(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "docs"), 0o755)
+	os.WriteFile(filepath.Join(root, "app.css"), []byte("body{}"), 0o644)
+	os.WriteFile(filepath.Join(root, "docs", "a b.txt"), []byte("hello"), 0o644)
+	os.WriteFile(filepath.Join(root, "secret.txt"), []byte("secret"), 0o644)
+
+	s := New()
+	s.StaticWithConfig("/assets", StaticConfig{Root: root, Browse: true})
+	s.StaticFile("/favicon.css", filepath.Join(root, "app.css"))
+
+	resp := doRaw(s, "GET /assets/app.css HTTP/1.1\r\n\r\n")
+	if !strings.Contains(resp, "Content-Type: text/css") || !strings.Contains(resp, "Content-Length: 6") ||
+		!strings.HasSuffix(resp, "\r\n\r\nbody{}") {
+		t.Fatalf("unexpected response: %q", resp)
+	}
+	lastModified := time.Now().Add(time.Hour).UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT")
+	if resp := doRaw(s, "GET /assets/app.css HTTP/1.1\r\nIf-Modified-Since: "+lastModified+"\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 304") {
+		t.Fatalf("expected 304: %q", resp)
+	}
+	if resp := doRaw(s, "GET /favicon.css HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "body{}") {
+		t.Fatalf("unexpected static file: %q", resp)
+	}
+	if resp := doRaw(s, "GET /assets/docs HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "Location: /assets/docs/") {
+		t.Fatalf("expected redirect: %q", resp)
+	}
+	if resp := doRaw(s, "GET /assets/docs/ HTTP/1.1\r\n\r\n"); !strings.Contains(resp, `<a href="a%20b.txt">a b.txt</a>`) {
+		t.Fatalf("expected listing: %q", resp)
+	}
+	if resp := doRaw(s, "GET /assets/docs/../../etc/passwd HTTP/1.1\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 404") {
+		t.Fatalf("expected 404: %q", resp)
+	}
+}
+
+func TestStaticPrecompressed(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "app.js"), []byte("plain"), 0o644)
+	os.WriteFile(filepath.Join(root, "app.js.gz"), []byte("gzipped"), 0o644)
+	os.WriteFile(filepath.Join(root, "app.js.br"), []byte("brotli"), 0o644)
+	os.WriteFile(filepath.Join(root, "only.css"), []byte("css"), 0o644)
+	os.WriteFile(filepath.Join(root, "only.css.gz"), []byte("gz-css"), 0o644)
+
+	s := New()
+	s.StaticWithConfig("/assets", StaticConfig{Root: root, Precompressed: true})
+
+	cases := []struct{ path, accept, encoding, body string }{
+		{"/assets/app.js", "gzip, br", "br", "brotli"},
+		{"/assets/app.js", "gzip;q=1, br;q=0.5", "gzip", "gzipped"},
+		{"/assets/app.js", "br;q=0, gzip", "gzip", "gzipped"},
+		{"/assets/app.js", "identity", "", "plain"},
+		{"/assets/app.js", "", "", "plain"},
+		{"/assets/only.css", "br, gzip;q=0.1", "gzip", "gz-css"},
+	}
+	for _, tc := range cases {
+		resp := doRaw(s, "GET "+tc.path+" HTTP/1.1\r\nAccept-Encoding: "+tc.accept+"\r\n\r\n")
+		if !strings.HasSuffix(resp, "\r\n\r\n"+tc.body) || !strings.Contains(resp, "Vary: Accept-Encoding") ||
+			strings.Contains(resp, "Content-Encoding") != (tc.encoding != "") ||
+			(tc.encoding != "" && !strings.Contains(resp, "Content-Encoding: "+tc.encoding+"\r\n")) {
+			t.Errorf("%s with %q: %q", tc.path, tc.accept, resp)
+		}
+	}
+	if resp := doRaw(s, "GET /assets/app.js HTTP/1.1\r\nAccept-Encoding: gzip\r\n\r\n"); !strings.Contains(resp, "Content-Type: text/javascript") ||
+		!strings.Contains(resp, "Content-Length: 7\r\n") {
+		t.Errorf("precompressed headers: %q", resp)
+	}
+}
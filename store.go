@@ -0,0 +1,173 @@
+// store.go
+package meego
+
+import (
+	"sync"
+	"time"
+)
+
+// KVStore 带过期时间的键值存储，是各子系统存储接口的公共部分
+type KVStore interface {
+	// Get 读取键值，键不存在或已过期时 found 为 false
+	Get(key string) (value []byte, found bool, err error)
+	// Set 写入键值，ttl <= 0 表示永不过期
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete 删除键
+	Delete(key string) error
+}
+
+// SessionStore 会话存储
+type SessionStore interface {
+	KVStore
+}
+
+// CacheStore 响应缓存存储
+type CacheStore interface {
+	KVStore
+}
+
+// RateLimitStore 限流计数存储
+type RateLimitStore interface {
+	// Incr 将计数器增加 delta 并返回新值及窗口剩余时间，
+	// 计数器首次创建时以 window 作为过期时间
+	Incr(key string, delta int64, window time.Duration) (count int64, reset time.Duration, err error)
+}
+
+// IdempotencyStore 幂等键存储
+type IdempotencyStore interface {
+	KVStore
+	// SetNX 仅当键不存在时写入，返回是否写入成功
+	SetNX(key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+//--------------------------------------------
+
+// memoryEntry 内存存储条目
+type memoryEntry struct {
+	value    []byte
+	counter  int64
+	expireAt time.Time
+}
+
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && !now.Before(e.expireAt)
+}
+
+// MemoryStore 内存存储实现（单实例默认存储），实现全部存储接口
+type MemoryStore struct {
+	mu     sync.Mutex
+	items  map[string]*memoryEntry
+	writes int
+}
+
+// memoryStoreSweepEvery 每写入多少次清理一次过期条目
+const memoryStoreSweepEvery = 1024
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]*memoryEntry, 64),
+	}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if e.expired(now()) {
+		delete(s.items, key)
+		return nil, false, nil
+	}
+
+	// 返回副本，避免调用方修改内部数据
+	value := make([]byte, len(e.value))
+	copy(value, e.value)
+	return value, true, nil
+}
+
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setLocked(key, value, ttl)
+	return nil
+}
+
+func (s *MemoryStore) setLocked(key string, value []byte, ttl time.Duration) {
+	e := &memoryEntry{value: make([]byte, len(value))}
+	copy(e.value, value)
+	if ttl > 0 {
+		e.expireAt = now().Add(ttl)
+	}
+	s.items[key] = e
+	s.afterWriteLocked()
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, key)
+	return nil
+}
+
+func (s *MemoryStore) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.items[key]; ok && !e.expired(now()) {
+		return false, nil
+	}
+	s.setLocked(key, value, ttl)
+	return true, nil
+}
+
+func (s *MemoryStore) Incr(key string, delta int64, window time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := now()
+	e, ok := s.items[key]
+	if !ok || e.expired(now) {
+		e = &memoryEntry{}
+		if window > 0 {
+			e.expireAt = now.Add(window)
+		}
+		s.items[key] = e
+		s.afterWriteLocked()
+	}
+	e.counter += delta
+
+	var reset time.Duration
+	if !e.expireAt.IsZero() {
+		reset = e.expireAt.Sub(now)
+	}
+	return e.counter, reset, nil
+}
+
+// Len 返回当前条目数（包含尚未清理的过期条目）
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+// afterWriteLocked 定期清理过期条目，调用方需持有锁
+func (s *MemoryStore) afterWriteLocked() {
+	s.writes++
+	if s.writes < memoryStoreSweepEvery {
+		return
+	}
+	s.writes = 0
+
+	now := now()
+	for k, e := range s.items {
+		if e.expired(now) {
+			delete(s.items, k)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+// store_redis.go
+package meego
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisOptions Redis 存储配置
+type RedisOptions struct {
+	Addr        string        // 地址，默认 127.0.0.1:6379
+	Password    string        // 密码，为空则不认证
+	DB          int           // 数据库编号
+	Prefix      string        // 键前缀，用于多个应用共享同一个 Redis
+	PoolSize    int           // 最大空闲连接数，默认 16
+	DialTimeout time.Duration // 连接超时，默认 3s
+	ReadTimeout time.Duration // 读写超时，默认 3s
+}
+
+// RedisStore 基于 Redis 的存储实现，实现全部存储接口，
+// 多实例部署时共享会话、限流、幂等和缓存状态
+type RedisStore struct {
+	opts RedisOptions
+	pool chan *redisConn
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// RedisError Redis 返回的错误应答
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// ErrRedisClosed 存储已关闭
+var ErrRedisClosed = errors.New("redis: store closed")
+
+// redisIncrScript 原子地增加计数器并在首次创建时设置过期时间
+const redisIncrScript = `local v = redis.call('INCRBY', KEYS[1], ARGV[1])
+if v == tonumber(ARGV[1]) and tonumber(ARGV[2]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return {v, redis.call('PTTL', KEYS[1])}`
+
+// redisMillis 将时长转换为 PX/PEXPIRE 的毫秒数，不足 1ms 的正数向上取整，
+// 避免发送 Redis 拒绝的 PX 0
+func redisMillis(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Millisecond - 1) / time.Millisecond)
+}
+
+// NewRedisStore 创建 Redis 存储，连接按需建立
+func NewRedisStore(opts RedisOptions) *RedisStore {
+	if opts.Addr == "" {
+		opts.Addr = "127.0.0.1:6379"
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 16
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 3 * time.Second
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = 3 * time.Second
+	}
+	return &RedisStore{
+		opts: opts,
+		pool: make(chan *redisConn, opts.PoolSize),
+	}
+}
+
+func (s *RedisStore) Get(key string) ([]byte, bool, error) {
+	reply, err := s.Do("GET", s.opts.Prefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	if reply == nil {
+		return nil, false, nil
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected GET reply %T", reply)
+	}
+	return value, true, nil
+}
+
+func (s *RedisStore) Set(key string, value []byte, ttl time.Duration) error {
+	args := []interface{}{"SET", s.opts.Prefix + key, value}
+	if ttl > 0 {
+		args = append(args, "PX", redisMillis(ttl))
+	}
+	_, err := s.Do(args...)
+	return err
+}
+
+func (s *RedisStore) Delete(key string) error {
+	_, err := s.Do("DEL", s.opts.Prefix+key)
+	return err
+}
+
+func (s *RedisStore) SetNX(key string, value []byte, ttl time.Duration) (bool, error) {
+	args := []interface{}{"SET", s.opts.Prefix + key, value}
+	if ttl > 0 {
+		args = append(args, "PX", redisMillis(ttl))
+	}
+	args = append(args, "NX")
+
+	reply, err := s.Do(args...)
+	if err != nil {
+		return false, err
+	}
+	// NX 未写入时返回空应答
+	return reply != nil, nil
+}
+
+func (s *RedisStore) Incr(key string, delta int64, window time.Duration) (int64, time.Duration, error) {
+	reply, err := s.Do("EVAL", redisIncrScript, 1, s.opts.Prefix+key, delta, redisMillis(window))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return 0, 0, fmt.Errorf("redis: unexpected EVAL reply %v", reply)
+	}
+	count, _ := values[0].(int64)
+	pttl, _ := values[1].(int64)
+
+	var reset time.Duration
+	if pttl > 0 {
+		reset = time.Duration(pttl) * time.Millisecond
+	}
+	return count, reset, nil
+}
+
+// Do 执行任意 Redis 命令并返回解析后的应答：
+// 简单字符串为 string，整数为 int64，批量字符串为 []byte，数组为 []interface{}，空值为 nil
+func (s *RedisStore) Do(args ...interface{}) (interface{}, error) {
+	conn, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.do(s.opts.ReadTimeout, args...)
+	if err != nil {
+		if _, ok := err.(RedisError); !ok {
+			// 网络或协议错误，连接不可复用
+			conn.close()
+			return nil, err
+		}
+	}
+	s.put(conn)
+	return reply, err
+}
+
+// Close 关闭所有空闲连接
+func (s *RedisStore) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	for {
+		select {
+		case conn := <-s.pool:
+			conn.close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (s *RedisStore) get() (*redisConn, error) {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		return nil, ErrRedisClosed
+	}
+
+	select {
+	case conn := <-s.pool:
+		return conn, nil
+	default:
+		return s.dial()
+	}
+}
+
+func (s *RedisStore) put(conn *redisConn) {
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if closed {
+		conn.close()
+		return
+	}
+
+	select {
+	case s.pool <- conn:
+	default:
+		// 空闲连接已满
+		conn.close()
+	}
+}
+
+func (s *RedisStore) dial() (*redisConn, error) {
+	nc, err := net.DialTimeout("tcp", s.opts.Addr, s.opts.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{
+		conn:   nc,
+		reader: bufio.NewReader(nc),
+		writer: bufio.NewWriter(nc),
+	}
+
+	if s.opts.Password != "" {
+		if _, err := conn.do(s.opts.ReadTimeout, "AUTH", s.opts.Password); err != nil {
+			conn.close()
+			return nil, err
+		}
+	}
+	if s.opts.DB != 0 {
+		if _, err := conn.do(s.opts.ReadTimeout, "SELECT", s.opts.DB); err != nil {
+			conn.close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+//--------------------------------------------
+
+// redisConn 单个 RESP 连接
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+func (c *redisConn) close() {
+	c.conn.Close()
+}
+
+func (c *redisConn) do(timeout time.Duration, args ...interface{}) (interface{}, error) {
+	c.conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := writeRESPCommand(c.writer, args); err != nil {
+		return nil, err
+	}
+	if err := c.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return readRESPReply(c.reader)
+}
+
+// writeRESPCommand 以 RESP 数组格式编码命令
+func writeRESPCommand(w *bufio.Writer, args []interface{}) error {
+	w.WriteString("*")
+	w.WriteString(strconv.Itoa(len(args)))
+	w.WriteString("\r\n")
+
+	for _, arg := range args {
+		var b []byte
+		switch v := arg.(type) {
+		case string:
+			b = []byte(v)
+		case []byte:
+			b = v
+		case int:
+			b = strconv.AppendInt(nil, int64(v), 10)
+		case int64:
+			b = strconv.AppendInt(nil, v, 10)
+		default:
+			return fmt.Errorf("redis: unsupported argument type %T", arg)
+		}
+
+		w.WriteString("$")
+		w.WriteString(strconv.Itoa(len(b)))
+		w.WriteString("\r\n")
+		w.Write(b)
+		w.WriteString("\r\n")
+	}
+	return nil
+}
+
+// readRESPReply 读取一个 RESP 应答
+func readRESPReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	payload := string(line[1 : len(line)-2])
+
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, RedisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			if values[i], err = readRESPReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+	}
+}
@@ -0,0 +1,76 @@
+package meego
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+
+	if err := s.Set("a", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok, _ := s.Get("a"); !ok || string(v) != "1" {
+		t.Fatalf("Get(a) = %q, %v", v, ok)
+	}
+
+	if ok, _ := s.SetNX("a", []byte("2"), 0); ok {
+		t.Fatal("SetNX on existing key should fail")
+	}
+
+	s.Set("b", []byte("x"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := s.Get("b"); ok {
+		t.Fatal("expired key should not be found")
+	}
+
+	for i := int64(1); i <= 3; i++ {
+		n, reset, _ := s.Incr("c", 1, time.Minute)
+		if n != i || reset <= 0 {
+			t.Fatalf("Incr = %d, %v", n, reset)
+		}
+	}
+}
+
+func TestRESPRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	writeRESPCommand(w, []interface{}{"SET", "k", []byte("v"), "PX", int64(10)})
+	w.Flush()
+
+	want := "*5\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n$2\r\nPX\r\n$2\r\n10\r\n"
+	if buf.String() != want {
+		t.Fatalf("encoded %q", buf.String())
+	}
+
+	reply, err := readRESPReply(bufio.NewReader(bytes.NewBufferString("*2\r\n:3\r\n$-1\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := reply.([]interface{})
+	if values[0].(int64) != 3 || values[1] != nil {
+		t.Fatalf("decoded %v", values)
+	}
+
+	if _, err := readRESPReply(bufio.NewReader(bytes.NewBufferString("-ERR boom\r\n"))); err == nil {
+		t.Fatal("expected redis error")
+	}
+}
+
+func TestRedisMillis(t *testing.T) {
+	for d, want := range map[time.Duration]int64{
+		0:                       0,
+		-time.Second:            0,
+		time.Microsecond:        1,
+		time.Millisecond:        1,
+		1500 * time.Microsecond: 2,
+		time.Minute:             60000,
+	} {
+		if got := redisMillis(d); got != want {
+			t.Fatalf("redisMillis(%v) = %d, want %d", d, got, want)
+		}
+	}
+}
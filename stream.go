@@ -0,0 +1,163 @@
+// stream.go
+package meego
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+)
+
+// startStream 发送状态行和头部，之后的响应体按块发送。
+// 已设置 Content-Length 时按该长度直接发送（如代理转发的文件）；否则 HTTP/1.1
+// 使用分块传输，HTTP/1.0 没有长度，以关闭连接结束响应体（此时不保持连接）
+func (w *ResponseWriter) startStream() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.hijacked {
+		return ErrHijacked
+	}
+	if w.streaming {
+		return nil
+	}
+	if w.written {
+		// 完整响应已经写出，不能再开始流式响应
+		w.keepAlive = false
+		return errResponseFinished
+	}
+	w.streaming = true
+	if w.compression != nil {
+		w.compression.startStream(w)
+	}
+	_, fixedLength := w.header["Content-Length"]
+	w.chunked = !fixedLength && w.proto != "HTTP/1.0" && bodyAllowedForStatus(w.status)
+
+	if !fixedLength {
+		delete(w.header, "Content-Length")
+	}
+	if w.chunked {
+		w.header["Transfer-Encoding"] = "chunked"
+	} else {
+		delete(w.header, "Transfer-Encoding")
+	}
+	if !fixedLength && !w.chunked && bodyAllowedForStatus(w.status) && w.method != "HEAD" {
+		// 没有长度也不分块时以关闭连接结束响应体
+		w.keepAlive = false
+	}
+	w.setConnectionHeader()
+	w.setDateHeader()
+	w.written = true
+
+	w.buffer.Reset()
+	w.buffer.WriteString(fmt.Sprintf("HTTP/1.1 %d %s\r\n", w.status, getStatusText(w.status)))
+	w.writeHeaderLines()
+	w.buffer.WriteString("\r\n")
+	_, err := w.conn.Write([]byte(w.buffer.String()))
+	return err
+}
+
+// WriteChunk 流式发送一段响应体，首次调用时先发送状态行和头部。没有设置
+// Content-Length 时 HTTP/1.1 自动使用 Transfer-Encoding: chunked，处理器返回后发送结束块
+func (w *ResponseWriter) WriteChunk(p []byte) error {
+	w.guard.check("ResponseWriter", "WriteChunk")
+	if err := w.startStream(); err != nil {
+		return err
+	}
+	if len(p) == 0 || w.method == "HEAD" || !bodyAllowedForStatus(w.status) {
+		return nil
+	}
+	if w.compression != nil && w.compression.stream != nil {
+		// 压缩后立即发送，保持流式语义（如 SSE）
+		if _, err := w.compression.stream.Write(p); err != nil {
+			return err
+		}
+		return w.compression.stream.Flush()
+	}
+	return w.writeChunk(p)
+}
+
+// writeChunk 发送一段已编码的响应体
+func (w *ResponseWriter) writeChunk(p []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	start := time.Now()
+	defer func() {
+		w.writeTime += time.Since(start)
+	}()
+
+	w.size += len(p)
+	if !w.chunked {
+		_, err := w.conn.Write(p)
+		return err
+	}
+	size := strconv.AppendInt(make([]byte, 0, 18), int64(len(p)), 16)
+	buffers := net.Buffers{append(size, "\r\n"...), p, []byte("\r\n")}
+	_, err := writeBuffers(w.conn, buffers)
+	return err
+}
+
+// Write 实现 io.Writer，等同于 WriteChunk，可以配合 io.Copy、fmt.Fprintf 等使用
+func (w *ResponseWriter) Write(p []byte) (int, error) {
+	if err := w.WriteChunk(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush 立即发送状态行和头部（尚未发送时），之后只能流式写入响应体。
+// WriteChunk 不做缓冲，已写入的数据块已经发送到连接
+func (w *ResponseWriter) Flush() error {
+	w.guard.check("ResponseWriter", "Flush")
+	return w.startStream()
+}
+
+// Streaming 响应头是否已经发送，之后不能再修改状态码和响应头
+func (w *ResponseWriter) Streaming() bool {
+	return w.streaming
+}
+
+// finishStream 发送结束块。请求处理结束时自动调用
+func (w *ResponseWriter) finishStream() error {
+	if w.compression != nil && !w.hijacked {
+		w.compression.finish()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.streaming || !w.chunked || w.hijacked {
+		return nil
+	}
+	w.chunked = false
+	_, err := w.conn.Write([]byte("0\r\n\r\n"))
+	return err
+}
+
+// abortStream 放弃未完成的流式响应：不发送结束块，客户端在连接关闭时
+// 能够发现响应体不完整，而不是把截断的内容当作完整响应
+func (w *ResponseWriter) abortStream() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.chunked = false
+	if w.compression != nil {
+		w.compression.stream = nil
+	}
+}
+
+// streamWriter 缓冲写入，flush 时作为一个数据块发送
+type streamWriter struct {
+	w   *ResponseWriter
+	buf []byte
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	s.buf = append(s.buf, p...)
+	return len(p), nil
+}
+
+func (s *streamWriter) flush() error {
+	if len(s.buf) == 0 {
+		return s.w.startStream()
+	}
+	err := s.w.WriteChunk(s.buf)
+	s.buf = s.buf[:0]
+	return err
+}
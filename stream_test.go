@@ -0,0 +1,34 @@
+package meego
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestStreamingWriter(t *testing.T) {
+	s := New()
+	s.GET("/chunks", func(c *Context) {
+		c.Writer.SetHeader("Content-Type", "text/plain")
+		c.Writer.Flush()
+		c.Writer.WriteChunk([]byte("hello "))
+		fmt.Fprintf(c.Writer, "world %d", 42)
+	})
+	s.GET("/sized", func(c *Context) {
+		c.Writer.SetHeader("Content-Length", "5")
+		io.Copy(c.Writer, strings.NewReader("fixed"))
+	})
+
+	resp := doRaw(s, "GET /chunks HTTP/1.1\r\nHost: x\r\n\r\nGET /sized HTTP/1.1\r\nHost: x\r\n\r\n")
+	want := "\r\n\r\n6\r\nhello \r\n8\r\nworld 42\r\n0\r\n\r\nHTTP/1.1 200"
+	if !strings.Contains(resp, "Transfer-Encoding: chunked\r\n") || !strings.Contains(resp, want) || !strings.Contains(resp, "Content-Length: 5\r\n") || !strings.HasSuffix(resp, "\r\n\r\nfixed") {
+		t.Fatalf("streamed responses:\n%q", resp)
+	}
+
+	// HTTP/1.0 没有分块传输，以关闭连接结束响应体
+	resp = doRaw(s, "GET /chunks HTTP/1.0\r\nConnection: keep-alive\r\n\r\n")
+	if strings.Contains(resp, "chunked") || !strings.Contains(resp, "Connection: close") || !strings.HasSuffix(resp, "hello world 42") {
+		t.Fatalf("http/1.0 stream:\n%q", resp)
+	}
+}
@@ -0,0 +1,290 @@
+// stub.go
+package meego
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIComponents 可复用的组件，桩服务器用于解析 $ref
+type OpenAPIComponents struct {
+	Schemas map[string]map[string]interface{} `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+}
+
+// openAPIMethods 路径项中表示操作的键
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+
+// LoadOpenAPI 解析 JSON 或 YAML 格式的 OpenAPI 3 文档，路径项中的公共参数等非操作字段被忽略
+func LoadOpenAPI(data []byte) (*OpenAPIDocument, error) {
+	var raw struct {
+		OpenAPI    string                          `yaml:"openapi"`
+		Info       OpenAPIInfo                     `yaml:"info"`
+		Paths      map[string]map[string]yaml.Node `yaml:"paths"`
+		Components *OpenAPIComponents              `yaml:"components"`
+	}
+	// YAML 是 JSON 的超集，两种格式都可以直接解析
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+	if !strings.HasPrefix(raw.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported openapi version %q", raw.OpenAPI)
+	}
+
+	doc := &OpenAPIDocument{
+		OpenAPI:    raw.OpenAPI,
+		Info:       raw.Info,
+		Paths:      make(map[string]map[string]*OpenAPIOperation, len(raw.Paths)),
+		Components: raw.Components,
+	}
+	for path, item := range raw.Paths {
+		for _, method := range openAPIMethods {
+			node, ok := item[method]
+			if !ok {
+				continue
+			}
+			op := &OpenAPIOperation{}
+			if err := node.Decode(op); err != nil {
+				return nil, fmt.Errorf("openapi %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = make(map[string]*OpenAPIOperation)
+			}
+			doc.Paths[path][method] = op
+		}
+	}
+	return doc, nil
+}
+
+// Stub 按 OpenAPI 文档注册桩路由，在处理器实现之前提供可运行的模拟 API。已注册的
+// 方法和路径保持不变，实现一个替换一个。桩路由返回文档中的示例，没有示例时按结构生成；
+// 默认使用最小的 2xx 状态码，客户端可以用 "Prefer: code=404" 或 "Prefer: example=名称"
+// 选择其它响应。桩路由带有元数据 stub=true
+func (s *HTTPServer) Stub(doc *OpenAPIDocument) []*Route {
+	registered := make(map[string]bool)
+	for _, r := range s.Routes() {
+		registered[r.Method+" "+r.Path] = true
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var routes []*Route
+	for _, path := range paths {
+		routePath := meegoPath(path)
+		for _, method := range openAPIMethods {
+			op := doc.Paths[path][method]
+			method = strings.ToUpper(method)
+			if op == nil || registered[method+" "+routePath] {
+				continue
+			}
+			route := s.router.AddRoute(method, routePath, stubHandler(doc, op)).Meta("stub", "true")
+			if op.Summary != "" {
+				route.Meta("summary", op.Summary)
+			}
+			routes = append(routes, route)
+		}
+	}
+	return routes
+}
+
+// StubFile 读取 OpenAPI 文件并注册桩路由
+func (s *HTTPServer) StubFile(path string) ([]*Route, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := LoadOpenAPI(data)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stub(doc), nil
+}
+
+// meegoPath 把 /users/{id} 转换为 /users/:id
+func meegoPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			segments[i] = ":" + seg[1:len(seg)-1]
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// stubHandler 返回 op 描述的示例响应
+func stubHandler(doc *OpenAPIDocument, op *OpenAPIOperation) HandlerFunc {
+	return func(c *Context) {
+		prefer := parsePrefer(c.Request.GetHeader("Prefer"))
+		status, resp := stubResponse(op, prefer["code"])
+		if resp == nil {
+			c.Writer.Status(StatusNotImplemented).JSON(JSON{"error": "No response for requested code", "code": StatusNotImplemented})
+			return
+		}
+		contentType, media := stubMedia(resp.Content)
+		if media == nil {
+			c.Writer.Status(status).String("")
+			return
+		}
+		body := stubExample(doc, media, prefer["example"])
+		if text, ok := body.(string); ok && !strings.Contains(contentType, "json") {
+			c.Writer.Status(status).Data(contentType, []byte(text))
+			return
+		}
+		c.Writer.Status(status).JSON(body)
+	}
+}
+
+// stubResponse 选择状态码：指定 code 时使用该响应，否则使用最小的 2xx（或 default）
+func stubResponse(op *OpenAPIOperation, code string) (int, *OpenAPIResponse) {
+	if code != "" {
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			return 0, nil
+		}
+		if resp := op.Responses[code]; resp != nil {
+			return status, resp
+		}
+		return status, op.Responses[code[:1]+"XX"]
+	}
+
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if code[0] == '2' {
+			status, err := strconv.Atoi(code)
+			if err != nil {
+				status = StatusOK // 2XX
+			}
+			return status, op.Responses[code]
+		}
+	}
+	if resp := op.Responses["default"]; resp != nil {
+		return StatusOK, resp
+	}
+	return StatusOK, &OpenAPIResponse{}
+}
+
+// stubMedia 优先使用 JSON 内容
+func stubMedia(content map[string]*OpenAPIMediaType) (string, *OpenAPIMediaType) {
+	if media := content["application/json"]; media != nil {
+		return "application/json", media
+	}
+	types := make([]string, 0, len(content))
+	for ct := range content {
+		types = append(types, ct)
+	}
+	sort.Strings(types)
+	if len(types) == 0 {
+		return "", nil
+	}
+	return types[0], content[types[0]]
+}
+
+// stubExample 依次使用指定名称的示例、example、按名称排序的第一个示例和按结构生成的值
+func stubExample(doc *OpenAPIDocument, media *OpenAPIMediaType, name string) interface{} {
+	if ex := media.Examples[name]; name != "" && ex != nil {
+		return ex.Value
+	}
+	if media.Example != nil {
+		return media.Example
+	}
+	if len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for n := range media.Examples {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return media.Examples[names[0]].Value
+	}
+	return sampleFromSchema(doc, media.Schema, 0)
+}
+
+// sampleFromSchema 按 JSON Schema 生成示例值
+func sampleFromSchema(doc *OpenAPIDocument, schema map[string]interface{}, depth int) interface{} {
+	if schema == nil || depth > 8 {
+		return nil
+	}
+	if example, ok := schema["example"]; ok {
+		return example
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		if doc.Components == nil || doc.Components.Schemas[name] == nil {
+			return nil
+		}
+		return sampleFromSchema(doc, doc.Components.Schemas[name], depth+1)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options, ok := schema[key].([]interface{}); ok && len(options) > 0 {
+			option, _ := options[0].(map[string]interface{})
+			return sampleFromSchema(doc, option, depth+1)
+		}
+	}
+	if parts, ok := schema["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		for _, part := range parts {
+			p, _ := part.(map[string]interface{})
+			if obj, ok := sampleFromSchema(doc, p, depth+1).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+
+	switch schema["type"] {
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		return []interface{}{sampleFromSchema(doc, items, depth+1)}
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "email":
+			return "user@example.com"
+		}
+		return "string"
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	props, _ := schema["properties"].(map[string]interface{})
+	obj := make(map[string]interface{}, len(props))
+	for key, prop := range props {
+		p, _ := prop.(map[string]interface{})
+		obj[key] = sampleFromSchema(doc, p, depth+1)
+	}
+	return obj
+}
+
+// parsePrefer 解析 Prefer 头中的 key=value 偏好，如 "code=404, example=missing"
+func parsePrefer(header string) map[string]string {
+	prefs := make(map[string]string)
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			prefs[strings.ToLower(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return prefs
+}
@@ -0,0 +1,71 @@
+// template.go
+package meego
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// TemplateFuncs 模板辅助函数，解析模板前注册：
+//
+//	tmpl := template.Must(template.New("page").Funcs(meego.TemplateFuncs()).ParseFiles("page.html"))
+//
+// 模板中的 {{flush}} 是流式渲染的刷新点，非流式渲染时不产生任何输出。
+// formatDate、formatDateTime、formatNumber 第一个参数为语言，通常传入 c.Locale()：
+//
+//	{{formatDate $.Locale .CreatedAt}} {{formatNumber $.Locale .Total}}
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"flush":          func() template.HTML { return "" },
+		"formatDate":     FormatDate,
+		"formatDateTime": FormatDateTime,
+		"formatNumber":   FormatNumber,
+	}
+}
+
+// HTMLTemplate 渲染模板 name 并一次性写出
+func (c *Context) HTMLTemplate(code int, t *template.Template, name string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	c.Writer.Status(code)
+	return c.Writer.writeBytes("text/html; charset=utf-8", buf.Bytes())
+}
+
+// HTMLStream 流式渲染模板：执行到 {{flush}} 时把已渲染的内容作为一个数据块发送，
+// 例如在 </head> 之后刷新，浏览器可以在页面主体渲染完成之前开始加载样式和脚本。
+// 第一次刷新之后状态码和响应头已发送，模板出错时只能中断响应
+func (c *Context) HTMLStream(code int, t *template.Template, name string, data interface{}) error {
+	sw := &streamWriter{w: c.Writer}
+	var flushErr error
+	tmpl, err := t.Clone()
+	if err != nil {
+		return err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"flush": func() template.HTML {
+			if flushErr == nil {
+				flushErr = sw.flush()
+			}
+			return ""
+		},
+	})
+
+	c.Writer.Status(code)
+	c.Writer.SetHeader("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(sw, name, data); err != nil {
+		if !c.Writer.streaming {
+			c.Writer.Status(StatusInternalServerError).JSON(JSON{
+				"error": "Internal Server Error",
+				"code":  StatusInternalServerError,
+			})
+		}
+		c.Logger().Error().Err(err).Str("template", name).Msg("template render failed")
+		return err
+	}
+	if flushErr != nil {
+		return flushErr
+	}
+	return sw.flush()
+}
@@ -0,0 +1,22 @@
+package meego
+
+import (
+	"html/template"
+	"strings"
+	"testing"
+)
+
+func TestHTMLStream(t *testing.T) {
+	tmpl := template.Must(template.New("page").Funcs(TemplateFuncs()).
+		Parse(`<head>{{.}}</head>{{flush}}<body></body>`))
+	s := New()
+	s.GET("/page", func(c *Context) {
+		c.HTMLStream(StatusOK, tmpl, "page", "t")
+	})
+
+	resp := doRaw(s, "GET /page HTTP/1.1\r\n\r\n")
+	if !strings.Contains(resp, "Transfer-Encoding: chunked") ||
+		!strings.HasSuffix(resp, "\r\n\r\ne\r\n<head>t</head>\r\nd\r\n<body></body>\r\n0\r\n\r\n") {
+		t.Fatalf("unexpected streamed response: %q", resp)
+	}
+}
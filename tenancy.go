@@ -0,0 +1,188 @@
+// tenancy.go
+package meego
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+const tenantKey = "meego.tenant"
+
+// Tenant 租户信息
+type Tenant struct {
+	ID   string            `json:"id"`
+	Name string            `json:"name,omitempty"`
+	Plan string            `json:"plan,omitempty"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// TenantStore 租户存储，租户不存在时返回 nil, nil
+type TenantStore interface {
+	Lookup(id string) (*Tenant, error)
+}
+
+// StaticTenantStore 内存中的租户表
+type StaticTenantStore struct {
+	mu      sync.RWMutex
+	tenants map[string]*Tenant
+}
+
+// NewStaticTenantStore 创建内存租户表
+func NewStaticTenantStore(tenants ...*Tenant) *StaticTenantStore {
+	s := &StaticTenantStore{tenants: make(map[string]*Tenant, len(tenants))}
+	for _, t := range tenants {
+		s.tenants[t.ID] = t
+	}
+	return s
+}
+
+func (s *StaticTenantStore) Lookup(id string) (*Tenant, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tenants[id], nil
+}
+
+// Put 添加或更新租户
+func (s *StaticTenantStore) Put(t *Tenant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tenants[t.ID] = t
+}
+
+// TenantResolver 从请求中提取租户 ID，提取不到时返回空字符串
+type TenantResolver func(c *Context) string
+
+// TenantFromSubdomain 从子域名提取租户：acme.example.com -> acme
+func TenantFromSubdomain(baseDomain string) TenantResolver {
+	suffix := "." + strings.TrimPrefix(baseDomain, ".")
+	return func(c *Context) string {
+		host := c.Request.Host
+		if i := strings.LastIndexByte(host, ':'); i > 0 && !strings.Contains(host[i:], "]") {
+			host = host[:i]
+		}
+		if !strings.HasSuffix(host, suffix) {
+			return ""
+		}
+		sub := strings.TrimSuffix(host, suffix)
+		if strings.Contains(sub, ".") {
+			return ""
+		}
+		return sub
+	}
+}
+
+// TenantFromHeader 从请求头提取租户，如 X-Tenant-ID
+func TenantFromHeader(name string) TenantResolver {
+	return func(c *Context) string {
+		return c.Request.GetHeader(name)
+	}
+}
+
+// TenantFromPathPrefix 从路径参数 :tenant 提取租户，没有该参数时取路径第一段
+func TenantFromPathPrefix() TenantResolver {
+	return func(c *Context) string {
+		if id := c.Param("tenant"); id != "" {
+			return id
+		}
+		path := strings.TrimPrefix(c.Request.URL.Path, "/")
+		id, _, _ := strings.Cut(path, "/")
+		return id
+	}
+}
+
+// TenancyConfig 多租户配置
+type TenancyConfig struct {
+	Resolvers []TenantResolver // 依次尝试，使用第一个非空结果
+	Store     TenantStore      // 租户校验，为空时不校验，只记录 ID
+	Optional  bool             // 无法解析租户时是否放行
+}
+
+// Tenancy 多租户中间件：解析并校验租户，之后可通过 c.Tenant() 获取，
+// c.Logger() 会自动带上 tenant 字段
+func Tenancy(cfg TenancyConfig) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			var id string
+			for _, resolve := range cfg.Resolvers {
+				if id = resolve(c); id != "" {
+					break
+				}
+			}
+
+			if id == "" {
+				if cfg.Optional {
+					next(c)
+					return
+				}
+				c.Writer.Status(StatusBadRequest).JSON(JSON{
+					"error": "tenant required",
+					"code":  StatusBadRequest,
+				})
+				return
+			}
+
+			tenant := &Tenant{ID: id}
+			if cfg.Store != nil {
+				found, err := cfg.Store.Lookup(id)
+				if err != nil {
+					log.Error().Err(err).Str("tenant", id).Msg("tenant lookup failed")
+					c.Writer.Status(StatusServiceUnavailable).JSON(JSON{
+						"error": "tenant lookup failed",
+						"code":  StatusServiceUnavailable,
+					})
+					return
+				}
+				if found == nil {
+					c.Writer.Status(StatusNotFound).JSON(JSON{
+						"error": "unknown tenant",
+						"code":  StatusNotFound,
+					})
+					return
+				}
+				tenant = found
+			}
+
+			c.Set(tenantKey, tenant)
+			// 重新生成请求日志，带上租户标签
+			c.logger = nil
+			next(c)
+		}
+	}
+}
+
+// Tenant 返回当前请求的租户，未启用 Tenancy 或未解析到时返回 nil
+func (c *Context) Tenant() *Tenant {
+	t, _ := c.Get(tenantKey).(*Tenant)
+	return t
+}
+
+// TenantKey 按租户划分的限流/配额键，可用作 RateLimitConfig.KeyFunc；
+// 没有租户时退回客户端 IP
+func TenantKey(c *Context) string {
+	if t := c.Tenant(); t != nil {
+		return "tenant:" + t.ID
+	}
+	return "ip:" + clientIPKey(c)
+}
+
+// RequireTenant 限制路由组只对指定租户开放
+func RequireTenant(ids ...string) MiddlewareFunc {
+	allowed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		allowed[id] = true
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			if t := c.Tenant(); t == nil || !allowed[t.ID] {
+				c.Writer.Status(StatusForbidden).JSON(JSON{
+					"error": "Forbidden",
+					"code":  StatusForbidden,
+				})
+				return
+			}
+			next(c)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package meego
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTenancy(t *testing.T) {
+	store := NewStaticTenantStore(&Tenant{ID: "acme", Plan: "pro"}, &Tenant{ID: "globex"})
+	s := New()
+	s.Use(Tenancy(TenancyConfig{
+		Resolvers: []TenantResolver{TenantFromSubdomain("example.com"), TenantFromHeader("X-Tenant-ID")},
+		Store:     store,
+	}))
+	s.GET("/me", func(c *Context) {
+		c.String(StatusOK, c.Tenant().ID+" "+c.Tenant().Plan+" "+TenantKey(c))
+	})
+	s.Group("/admin", RequireTenant("acme")).GET("/stats", func(c *Context) { c.String(StatusOK, "stats") })
+
+	cases := []struct{ headers, want string }{
+		{"Host: acme.example.com:8080\r\n", "acme pro tenant:acme"},
+		{"Host: api.other.com\r\nX-Tenant-ID: globex\r\n", "globex  tenant:globex"},
+		{"Host: a.b.example.com\r\n", "HTTP/1.1 400"},
+		{"Host: initech.example.com\r\n", "HTTP/1.1 404"},
+		{"", "HTTP/1.1 400"},
+	}
+	for _, tc := range cases {
+		resp := doRaw(s, "GET /me HTTP/1.1\r\n"+tc.headers+"\r\n")
+		if !strings.HasPrefix(resp, tc.want) && !strings.HasSuffix(resp, "\r\n\r\n"+tc.want) {
+			t.Fatalf("%q: expected %q:\n%s", tc.headers, tc.want, resp)
+		}
+	}
+
+	if resp := doRaw(s, "GET /admin/stats HTTP/1.1\r\nX-Tenant-ID: globex\r\n\r\n"); !strings.HasPrefix(resp, "HTTP/1.1 403") {
+		t.Fatalf("tenant-scoped group:\n%s", resp)
+	}
+	if resp := doRaw(s, "GET /admin/stats HTTP/1.1\r\nX-Tenant-ID: acme\r\n\r\n"); !strings.HasSuffix(resp, "stats") {
+		t.Fatalf("tenant-scoped group:\n%s", resp)
+	}
+
+	// 路径前缀解析，Optional 时无法解析也放行
+	p := New()
+	p.Use(Tenancy(TenancyConfig{Resolvers: []TenantResolver{TenantFromPathPrefix()}, Optional: true}))
+	p.GET("/:tenant/items", func(c *Context) { c.String(StatusOK, c.Tenant().ID) })
+	p.GET("/", func(c *Context) { c.String(StatusOK, fmt.Sprintf("%v %s", c.Tenant() == nil, TenantKey(c))) })
+	if resp := doRaw(p, "GET /acme/items HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "\r\n\r\nacme") {
+		t.Fatalf("path prefix:\n%s", resp)
+	}
+	if resp := doRaw(p, "GET / HTTP/1.1\r\n\r\n"); !strings.HasSuffix(resp, "true ip:pipe") {
+		t.Fatalf("optional tenant:\n%s", resp)
+	}
+}
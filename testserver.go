@@ -0,0 +1,59 @@
+// testserver.go
+package meego
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// TestServer 在内存中运行服务器，供测试使用：Client 发出的请求经内存管道交给服务器处理，
+// 不占用端口，也不需要调用 Start
+//
+//	ts := meego.NewTestServer(server)
+//	defer ts.Close()
+//	resp, err := ts.Client().Get(ts.URL + "/users/1")
+type TestServer struct {
+	// URL 请求地址，主机名只用于 Host 头
+	URL string
+
+	server *HTTPServer
+	client *http.Client
+	conns  sync.WaitGroup
+}
+
+// NewTestServer 为 s 创建内存测试服务器
+func NewTestServer(s *HTTPServer) *TestServer {
+	ts := &TestServer{URL: "http://meego.test", server: s}
+	ts.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				client, server := net.Pipe()
+				ts.conns.Add(1)
+				go func() {
+					defer ts.conns.Done()
+					s.handleConnectionFast(server)
+				}()
+				return client, nil
+			},
+			DisableCompression: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return ts
+}
+
+// Client 返回连接到测试服务器的客户端，不跟随重定向
+func (ts *TestServer) Client() *http.Client {
+	return ts.client
+}
+
+// Close 关闭客户端保持的空闲连接，并等待服务端的连接处理协程全部退出；
+// 仍有未读完的响应时会阻塞到其连接关闭
+func (ts *TestServer) Close() {
+	ts.client.CloseIdleConnections()
+	ts.conns.Wait()
+}
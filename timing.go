@@ -0,0 +1,62 @@
+// timing.go
+package meego
+
+import "time"
+
+// Timings 单个请求在框架内各阶段的耗时
+type Timings struct {
+	Parse      time.Duration `json:"parse"`      // 读取并解析请求（包含等待客户端数据的时间）
+	Route      time.Duration `json:"route"`      // 路由查找
+	Middleware time.Duration `json:"middleware"` // 中间件链自身耗时（不含路由处理器）
+	Handler    time.Duration `json:"handler"`    // 路由处理器耗时（不含序列化和写出）
+	Serialize  time.Duration `json:"serialize"`  // 响应序列化（JSON 等）
+	Write      time.Duration `json:"write"`      // 写出响应到连接
+	Total      time.Duration `json:"total"`      // 路由查找开始到处理链结束
+}
+
+// requestTimings Context 内部记录的原始耗时
+type requestTimings struct {
+	parse   time.Duration
+	route   time.Duration
+	chain   time.Duration
+	handler time.Duration // 包含处理器内发生的序列化和写出
+}
+
+// Timings 返回当前请求各阶段耗时；在处理链执行过程中调用时只包含已完成的阶段
+func (c *Context) Timings() Timings {
+	t := Timings{
+		Parse: c.timings.parse,
+		Route: c.timings.route,
+	}
+	if c.Writer != nil {
+		t.Serialize = c.Writer.serializeTime
+		t.Write = c.Writer.writeTime
+	}
+
+	t.Handler = c.timings.handler - t.Serialize - t.Write
+	if t.Handler < 0 {
+		t.Handler = 0
+	}
+	t.Middleware = c.timings.chain - c.timings.handler
+	if t.Middleware < 0 {
+		t.Middleware = 0
+	}
+	t.Total = c.timings.route + c.timings.chain
+	return t
+}
+
+// OnTimings 注册请求完成后的耗时回调，用于导出到指标系统
+func (s *HTTPServer) OnTimings(fn func(c *Context, t Timings)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timingHooks = append(s.timingHooks, fn)
+}
+
+// timedHandler 包装路由处理器以记录处理器耗时
+func timedHandler(handler HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		start := time.Now()
+		handler(c)
+		c.timings.handler += time.Since(start)
+	}
+}
@@ -0,0 +1,44 @@
+package meego
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimingHooks(t *testing.T) {
+	s := New()
+	var got []Timings
+	var route string
+	s.OnTimings(func(c *Context, t Timings) {
+		got = append(got, t)
+		route = c.FullPath()
+	})
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			time.Sleep(10 * time.Millisecond)
+			next(c)
+		}
+	})
+	s.GET("/t/:id", func(c *Context) {
+		time.Sleep(20 * time.Millisecond)
+		c.JSON(StatusOK, JSON{"id": c.Param("id")})
+	})
+
+	doRaw(s, "GET /t/1 HTTP/1.1\r\nConnection: close\r\n\r\n")
+	if len(got) != 1 || route != "/t/:id" {
+		t.Fatalf("expected one timing callback for /t/:id, got %d (%q)", len(got), route)
+	}
+	tm := got[0]
+	if tm.Handler < 20*time.Millisecond || tm.Middleware < 10*time.Millisecond {
+		t.Fatalf("phases not attributed: %+v", tm)
+	}
+	if tm.Serialize <= 0 || tm.Write <= 0 || tm.Total < tm.Route+tm.Middleware+tm.Handler+tm.Serialize+tm.Write {
+		t.Fatalf("serialize/write/total not recorded: %+v", tm)
+	}
+
+	// 未匹配的请求同样回调，路由处理器耗时为 0
+	doRaw(s, "GET /missing HTTP/1.1\r\nConnection: close\r\n\r\n")
+	if len(got) != 2 || got[1].Handler != 0 {
+		t.Fatalf("unmatched request timings: %+v", got)
+	}
+}
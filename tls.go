@@ -0,0 +1,112 @@
+// tls.go
+package meego
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+)
+
+// SetTLSConfig 设置 HTTPS 使用的 tls.Config（最低版本、密码套件、客户端证书校验等）。
+// 未设置 Certificates/GetCertificate 时使用 ListenTLS 加载或热加载的证书
+func (s *HTTPServer) SetTLSConfig(cfg *tls.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tlsConfig = cfg.Clone()
+}
+
+// HandleALPN 注册 ALPN 协议处理器：客户端协商到 proto（如 "h2"）时，握手完成的连接
+// 交给 handler 处理，handler 负责关闭连接。未注册的协议和未协商时按 HTTP/1.1 处理
+func (s *HTTPServer) HandleALPN(proto string, handler func(conn *tls.Conn)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.alpnHandlers == nil {
+		s.alpnHandlers = make(map[string]func(*tls.Conn))
+	}
+	s.alpnHandlers[proto] = handler
+}
+
+// RunTLS 以 HTTPS 启动服务器，ListenTLS 的别名
+func (s *HTTPServer) RunTLS(addr, certFile, keyFile string) error {
+	return s.ListenTLS(addr, certFile, keyFile)
+}
+
+// ListenTLS 以 HTTPS 启动服务器。证书可以通过热加载配置的 tls 段替换，
+// 新证书只对新连接生效，已有连接不受影响。certFile 为空时只使用 SetTLSConfig
+// 或热加载配置提供的证书
+func (s *HTTPServer) ListenTLS(addr, certFile, keyFile string) error {
+	s.addr = addr
+	if err := s.startupCheck(&TLSFiles{CertFile: certFile, KeyFile: keyFile}); err != nil {
+		return err
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return err
+		}
+		s.tlsCert.Store(&cert)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.serveHTTP(ln, s.handleTLSConnection(s.buildTLSConfig()))
+}
+
+// buildTLSConfig 合并用户配置、证书来源和 ALPN 协议列表
+func (s *HTTPServer) buildTLSConfig() *tls.Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if s.tlsConfig != nil {
+		cfg = s.tlsConfig.Clone()
+	}
+	if len(cfg.Certificates) == 0 && cfg.GetCertificate == nil {
+		cfg.GetCertificate = s.getCertificate
+	}
+	for proto := range s.alpnHandlers {
+		if !containsString(cfg.NextProtos, proto) {
+			cfg.NextProtos = append(cfg.NextProtos, proto)
+		}
+	}
+	if len(cfg.NextProtos) > 0 && !containsString(cfg.NextProtos, "http/1.1") {
+		cfg.NextProtos = append(cfg.NextProtos, "http/1.1")
+	}
+	return cfg
+}
+
+// handleTLSConnection 在工作协程中完成握手，按协商的协议分发连接
+func (s *HTTPServer) handleTLSConnection(cfg *tls.Config) func(net.Conn) {
+	return func(conn net.Conn) {
+		tlsConn := tls.Server(conn, cfg)
+		tlsConn.SetDeadline(time.Now().Add(s.readTimeout))
+		if err := tlsConn.Handshake(); err != nil {
+			conn.Close()
+			return
+		}
+		tlsConn.SetDeadline(time.Time{})
+
+		s.mu.RLock()
+		handler := s.alpnHandlers[tlsConn.ConnectionState().NegotiatedProtocol]
+		s.mu.RUnlock()
+		if handler != nil {
+			handler(tlsConn)
+			return
+		}
+		s.handleConnectionFast(tlsConn)
+	}
+}
+
+// getCertificate 优先使用热加载的证书
+func (s *HTTPServer) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if state := s.runtimeState(); state != nil && state.certificate != nil {
+		return state.certificate, nil
+	}
+	if cert := s.tlsCert.Load(); cert != nil {
+		return cert, nil
+	}
+	return nil, errors.New("no TLS certificate configured")
+}
@@ -0,0 +1,155 @@
+package meego
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// selfSignedCert 生成 127.0.0.1 的自签名证书
+func selfSignedCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "meego-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestListenTLSWithALPN(t *testing.T) {
+	s := New()
+	s.SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}})
+	s.GET("/", func(c *Context) { c.String(StatusOK, "secure") })
+	s.HandleALPN("meego-test", func(conn *tls.Conn) {
+		conn.Write([]byte("custom protocol"))
+		conn.Close()
+	})
+
+	addrCh := make(chan net.Addr, 1)
+	s.OnStart(func(addr net.Addr) error {
+		addrCh <- addr
+		return nil
+	})
+	go s.ListenTLS("127.0.0.1:0", "", "")
+	defer s.Shutdown()
+	addr := (<-addrCh).String()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + addr + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "secure" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"meego-test"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if proto := conn.ConnectionState().NegotiatedProtocol; proto != "meego-test" {
+		t.Fatalf("negotiated %q", proto)
+	}
+	data, _ := io.ReadAll(conn)
+	if string(data) != "custom protocol" {
+		t.Fatalf("unexpected data %q", data)
+	}
+}
+
+func TestClientUpstreamMTLS(t *testing.T) {
+	serverCert, clientCert := selfSignedCert(t), selfSignedCert(t)
+	clientCAs := x509.NewCertPool()
+	leaf, _ := x509.ParseCertificate(clientCert.Certificate[0])
+	clientCAs.AddCert(leaf)
+
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	backend.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	backend.StartTLS()
+	defer backend.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Certificate[0]}), 0o600)
+
+	// 未配置根证书和客户端证书时握手失败
+	if _, err := NewClient().Get(nil, backend.URL); err == nil {
+		t.Fatal("expected verification failure without custom roots")
+	}
+
+	s := New()
+	s.GET("/*path", ReverseProxy(ProxyConfig{
+		Target: backend.URL,
+		TLS:    &UpstreamTLS{CAFile: caFile, Certificates: []tls.Certificate{clientCert}},
+	}))
+	resp := doRaw(s, "GET /x HTTP/1.1\r\nHost: example.com\r\n\r\n")
+	if !strings.HasPrefix(resp, "HTTP/1.1 200") || !strings.HasSuffix(resp, "meego-test") {
+		t.Fatalf("proxy over mTLS:\n%s", resp)
+	}
+
+	cl := NewClient()
+	if err := cl.SetHostTLS(strings.TrimPrefix(backend.URL, "https://"), &UpstreamTLS{
+		InsecureSkipVerify: true,
+		Certificates:       []tls.Certificate{clientCert},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	r, err := cl.Get(nil, backend.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Body.Close()
+
+	if err := NewClient().SetHostTLS("x", &UpstreamTLS{CAFile: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Fatal("expected error for missing CA file")
+	}
+}
+
+func TestConnValuesTLSState(t *testing.T) {
+	s := New()
+	s.SetTLSConfig(&tls.Config{Certificates: []tls.Certificate{selfSignedCert(t)}})
+	s.GET("/", func(c *Context) {
+		state := c.ConnValues().TLS()
+		c.String(StatusOK, tls.VersionName(state.Version))
+	})
+
+	client, server := net.Pipe()
+	go s.handleTLSConnection(s.buildTLSConfig())(server)
+	conn := tls.Client(client, &tls.Config{InsecureSkipVerify: true})
+	io.WriteString(conn, "GET / HTTP/1.1\r\nHost: x\r\nConnection: close\r\n\r\n")
+	resp, _ := io.ReadAll(conn)
+	if !strings.HasSuffix(string(resp), "TLS 1.3") {
+		t.Fatalf("tls state:\n%s", resp)
+	}
+}
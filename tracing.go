@@ -0,0 +1,94 @@
+// tracing.go
+package meego
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	HeaderRequestID      = "X-Request-ID"
+	HeaderRequestTimeout = "X-Request-Timeout" // 剩余处理时间（毫秒）
+	HeaderTraceParent    = "traceparent"       // W3C Trace Context
+	HeaderTraceState     = "tracestate"
+	HeaderBaggage        = "baggage" // W3C Baggage
+
+	requestIDKey = "meego.request_id"
+)
+
+// 默认透传的请求头
+var defaultPropagateHeaders = []string{
+	HeaderTraceParent,
+	HeaderTraceState,
+	HeaderBaggage,
+	HeaderRequestID,
+}
+
+// PropagateHeaders 追加出站调用时需要透传的请求头（内置客户端和反向代理使用）
+func (s *HTTPServer) PropagateHeaders(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.propagateHeaders = append(s.propagateHeaders, keys...)
+}
+
+// RequestID 请求 ID 中间件：沿用请求中的 X-Request-ID，没有则生成，并写回响应头
+func RequestID() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			id := c.Request.GetHeader(HeaderRequestID)
+			if id == "" {
+				id = newID(IDRequest)
+			}
+			c.Set(requestIDKey, id)
+			c.Writer.SetHeader(HeaderRequestID, id)
+			next(c)
+		}
+	}
+}
+
+// RequestID 返回当前请求 ID，未启用 RequestID 中间件时返回请求头中的值
+func (c *Context) RequestID() string {
+	if id, ok := c.Get(requestIDKey).(string); ok {
+		return id
+	}
+	return c.Request.GetHeader(HeaderRequestID)
+}
+
+// PropagationHeaders 返回出站调用需要携带的请求头：
+// 白名单中的追踪头、请求 ID 以及剩余处理时间
+func (c *Context) PropagationHeaders() map[string]string {
+	keys := defaultPropagateHeaders
+	if c.server != nil {
+		c.server.mu.RLock()
+		keys = c.server.propagateHeaders
+		c.server.mu.RUnlock()
+	}
+
+	headers := make(map[string]string, len(keys)+2)
+	for _, key := range keys {
+		if value := c.Request.GetHeader(key); value != "" {
+			headers[key] = value
+		}
+	}
+	if id := c.RequestID(); id != "" {
+		headers[HeaderRequestID] = id
+	}
+	if !c.deadline.IsZero() {
+		if remaining := c.deadline.Sub(now()); remaining > 0 {
+			headers[HeaderRequestTimeout] = strconv.FormatInt(remaining.Milliseconds(), 10)
+		}
+	}
+	return headers
+}
+
+// requestDeadline 计算请求期限：上游传入的 X-Request-Timeout 更短时以其为准
+func requestDeadline(req *HTTPRequest, deadline time.Time) time.Time {
+	timeout, ok := ParseHeaderDuration(req.GetHeader(HeaderRequestTimeout))
+	if !ok {
+		return deadline
+	}
+	if upstream := now().Add(timeout); upstream.Before(deadline) {
+		return upstream
+	}
+	return deadline
+}
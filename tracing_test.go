@@ -0,0 +1,30 @@
+package meego
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPropagationHeaders(t *testing.T) {
+	s := New()
+	s.Use(RequestID())
+	s.PropagateHeaders("X-Tenant")
+
+	var got map[string]string
+	s.GET("/p", func(c *Context) {
+		got = c.PropagationHeaders()
+		c.String(StatusOK, "ok")
+	})
+
+	resp := doRaw(s, "GET /p HTTP/1.1\r\nHost: x\r\ntraceparent: 00-abc-def-01\r\nx-tenant: t1\r\nX-Request-Timeout: 500\r\n\r\n")
+	if !strings.Contains(resp, "X-Request-ID: ") {
+		t.Fatalf("missing request id in response: %q", resp)
+	}
+	if got["traceparent"] != "00-abc-def-01" || got["X-Tenant"] != "t1" || got[HeaderRequestID] == "" {
+		t.Fatalf("unexpected propagation headers: %v", got)
+	}
+	if ms, _ := strconv.Atoi(got[HeaderRequestTimeout]); ms <= 0 || ms > 500 {
+		t.Fatalf("deadline not propagated: %v", got[HeaderRequestTimeout])
+	}
+}
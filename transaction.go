@@ -0,0 +1,162 @@
+// transaction.go
+package meego
+
+import (
+	"errors"
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Tx 请求级事务，*sql.Tx 等数据库事务都满足该接口
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// ErrNoTransaction 请求没有经过 Transaction 中间件
+var ErrNoTransaction = errors.New("no transaction in request context")
+
+// TransactionConfig 事务中间件配置
+type TransactionConfig struct {
+	// Begin 为请求开启事务
+	Begin func(c *Context) (Tx, error)
+	// Outbox 设置后 c.Emit 记录的事件在提交前写入同一个事务，提交后由 Outbox 后台发送
+	Outbox *Outbox
+}
+
+// requestTx 请求的事务状态
+type requestTx struct {
+	tx     Tx
+	outbox *Outbox
+	events []OutboxEvent
+	done   bool
+}
+
+const transactionKey = "meego.transaction"
+
+// Transaction 请求级事务中间件
+func Transaction(begin func(c *Context) (Tx, error)) MiddlewareFunc {
+	return TransactionWithConfig(TransactionConfig{Begin: begin})
+}
+
+// TransactionWithConfig 使用自定义配置的事务中间件：处理器通过 c.Tx() 使用事务，
+// 响应状态码小于 400 时提交，否则回滚，处理器 panic 时回滚后继续 panic。
+// 非流式响应在写出之前提交，提交失败时改为返回 500；流式响应在处理器返回后提交
+func TransactionWithConfig(cfg TransactionConfig) MiddlewareFunc {
+	if cfg.Begin == nil {
+		panic("meego: Transaction requires Begin")
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			tx, err := cfg.Begin(c)
+			if err != nil {
+				c.Logger().Error().Err(err).Msg("begin transaction failed")
+				c.Writer.Status(StatusInternalServerError).JSON(JSON{
+					"error": "Internal Server Error",
+					"code":  StatusInternalServerError,
+				})
+				return
+			}
+			rt := &requestTx{tx: tx, outbox: cfg.Outbox}
+			c.Set(transactionKey, rt)
+
+			defer func() {
+				if r := recover(); r != nil {
+					rt.rollback(c)
+					panic(r)
+				}
+			}()
+
+			c.Writer.transforms = append(c.Writer.transforms, func(w *ResponseWriter, body []byte) []byte {
+				if w.status >= 400 {
+					rt.rollback(c)
+					return body
+				}
+				if err := rt.commit(c); err != nil {
+					w.status = StatusInternalServerError
+					w.header["Content-Type"] = "application/json"
+					return []byte(`{"code":500,"error":"Internal Server Error"}`)
+				}
+				return body
+			})
+			next(c)
+
+			if c.Writer.StatusCode() >= 400 || !c.Writer.Written() {
+				rt.rollback(c)
+				return
+			}
+			rt.commit(c)
+		}
+	}
+}
+
+// commit 保存事件并提交事务，提交后通知 Outbox 发送
+func (rt *requestTx) commit(c *Context) error {
+	if rt.done {
+		return nil
+	}
+	rt.done = true
+	if len(rt.events) > 0 {
+		if err := rt.outbox.cfg.Store.Save(rt.tx, rt.events); err != nil {
+			rt.tx.Rollback()
+			c.Logger().Error().Err(err).Msg("save outbox events failed")
+			return err
+		}
+	}
+	if err := rt.tx.Commit(); err != nil {
+		c.Logger().Error().Err(err).Msg("commit transaction failed")
+		return err
+	}
+	if len(rt.events) > 0 {
+		rt.outbox.Notify()
+	}
+	return nil
+}
+
+func (rt *requestTx) rollback(c *Context) {
+	if rt.done {
+		return
+	}
+	rt.done = true
+	if err := rt.tx.Rollback(); err != nil {
+		c.Logger().Warn().Err(err).Msg("rollback transaction failed")
+	}
+}
+
+// Tx 返回 Transaction 中间件开启的事务，没有时返回 nil
+func (c *Context) Tx() Tx {
+	if rt, ok := c.Get(transactionKey).(*requestTx); ok {
+		return rt.tx
+	}
+	return nil
+}
+
+// Emit 记录一个领域事件，事件在事务提交前与业务数据一起写入 Outbox 存储，
+// 提交成功后才会发送，回滚时丢弃。payload 为 []byte 时原样使用，其它值编码为 JSON
+func (c *Context) Emit(eventType string, payload interface{}) error {
+	rt, ok := c.Get(transactionKey).(*requestTx)
+	if !ok {
+		return ErrNoTransaction
+	}
+	if rt.outbox == nil {
+		return errors.New("meego: transaction has no outbox")
+	}
+	if rt.done {
+		return errors.New("meego: transaction already finished")
+	}
+	data, ok := payload.([]byte)
+	if !ok {
+		var err error
+		if data, err = jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(payload); err != nil {
+			return fmt.Errorf("encode event %s: %w", eventType, err)
+		}
+	}
+	rt.events = append(rt.events, OutboxEvent{
+		ID:        newID(IDEvent),
+		Type:      eventType,
+		Payload:   data,
+		CreatedAt: now(),
+	})
+	return nil
+}
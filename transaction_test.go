@@ -0,0 +1,54 @@
+package meego
+
+import (
+	"context"
+	"testing"
+)
+
+type testTx struct{ committed, rolledBack bool }
+
+func (tx *testTx) Commit() error   { tx.committed = true; return nil }
+func (tx *testTx) Rollback() error { tx.rolledBack = true; return nil }
+
+func TestTransactionOutbox(t *testing.T) {
+	var txs []*testTx
+	var published []string
+	s := New()
+	outbox := s.Outbox(OutboxConfig{
+		Store: NewMemoryOutboxStore(),
+		Publish: func(_ context.Context, event OutboxEvent) error {
+			published = append(published, event.Type+" "+string(event.Payload))
+			return nil
+		},
+	})
+	s.Use(TransactionWithConfig(TransactionConfig{
+		Begin: func(c *Context) (Tx, error) {
+			tx := &testTx{}
+			txs = append(txs, tx)
+			return tx, nil
+		},
+		Outbox: outbox,
+	}))
+	s.POST("/orders", func(c *Context) {
+		if err := c.Emit("order.created", JSON{"id": 1}); err != nil {
+			t.Error(err)
+		}
+		c.JSON(StatusCreated, JSON{"id": 1})
+	})
+	s.POST("/invalid", func(c *Context) {
+		c.Emit("order.created", JSON{"id": 2})
+		c.JSON(StatusBadRequest, JSON{"error": "invalid"})
+	})
+
+	doRaw(s, "POST /orders HTTP/1.1\r\n\r\n")
+	doRaw(s, "POST /invalid HTTP/1.1\r\n\r\n")
+	if len(txs) != 2 || !txs[0].committed || txs[0].rolledBack || txs[1].committed || !txs[1].rolledBack {
+		t.Fatalf("unexpected transactions: %+v %+v", txs[0], txs[1])
+	}
+	if n := outbox.Dispatch(); n != 1 || len(published) != 1 || published[0] != `order.created {"id":1}` {
+		t.Fatalf("dispatched %d: %q", n, published)
+	}
+	if n := outbox.Dispatch(); n != 0 {
+		t.Fatalf("events sent twice: %d", n)
+	}
+}
@@ -0,0 +1,65 @@
+// udp_probe.go
+package meego
+
+import (
+	"bytes"
+	"errors"
+	"net"
+
+	"github.com/rs/zerolog/log"
+)
+
+// UDPProbeConfig UDP 健康探针配置
+type UDPProbeConfig struct {
+	Addr string // 监听地址，如 ":8081"
+	// Request 非空时只响应内容完全相同的探测包，其它包忽略
+	Request []byte
+	// Payload 健康时的响应，默认 "OK"
+	Payload []byte
+	// DrainingPayload 排空/关闭过程中的响应，默认 "DRAINING"；
+	// 设置为空切片时排空期间不响应，由探测方按超时判定失败
+	DrainingPayload []byte
+}
+
+// EnableUDPProbe 启动 UDP 健康探针：收到探测包时回复 Payload，
+// 服务器排空（Drain/Shutdown）期间回复 DrainingPayload。
+// 探针随 Shutdown 关闭
+func (s *HTTPServer) EnableUDPProbe(cfg UDPProbeConfig) (net.Addr, error) {
+	if cfg.Payload == nil {
+		cfg.Payload = []byte("OK")
+	}
+	if cfg.DrainingPayload == nil {
+		cfg.DrainingPayload = []byte("DRAINING")
+	}
+
+	pc, err := net.ListenPacket("udp", cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	s.OnShutdown(func() { pc.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					log.Error().Err(err).Msg("udp probe stopped")
+				}
+				return
+			}
+			if cfg.Request != nil && !bytes.Equal(buf[:n], cfg.Request) {
+				continue
+			}
+
+			payload := cfg.Payload
+			if s.Draining() {
+				payload = cfg.DrainingPayload
+			}
+			if len(payload) > 0 {
+				pc.WriteTo(payload, addr)
+			}
+		}
+	}()
+	return pc.LocalAddr(), nil
+}
@@ -0,0 +1,34 @@
+package meego
+
+import (
+	"net"
+	"testing"
+)
+
+func TestUDPProbe(t *testing.T) {
+	s := New()
+	addr, err := s.EnableUDPProbe(UDPProbeConfig{Addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("udp", addr.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	probe := func() string {
+		conn.Write([]byte("?"))
+		buf := make([]byte, 16)
+		n, _ := conn.Read(buf)
+		return string(buf[:n])
+	}
+	if got := probe(); got != "OK" {
+		t.Fatalf("expected OK, got %q", got)
+	}
+	s.Drain()
+	if got := probe(); got != "DRAINING" {
+		t.Fatalf("expected DRAINING, got %q", got)
+	}
+	s.Shutdown()
+}
@@ -0,0 +1,111 @@
+// upload.go
+package meego
+
+import (
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UploadedFile 上传的文件，可以直接作为 io.Reader 读取内容，
+// 打开的文件在请求结束时自动关闭
+type UploadedFile struct {
+	Filename    string // 客户端提供的文件名，已去掉路径部分
+	Size        int64
+	ContentType string
+	Header      textproto.MIMEHeader
+
+	fh   *multipart.FileHeader
+	file multipart.File
+}
+
+// Read 实现 io.Reader，首次读取时打开文件
+func (f *UploadedFile) Read(p []byte) (int, error) {
+	if f.file == nil {
+		file, err := f.fh.Open()
+		if err != nil {
+			return 0, err
+		}
+		f.file = file
+	}
+	return f.file.Read(p)
+}
+
+// Close 关闭已打开的文件
+func (f *UploadedFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// Save 把文件保存到 dst
+func (f *UploadedFile) Save(dst string) error {
+	return saveFileHeader(f.fh, dst)
+}
+
+// UploadedFile 返回表单中名为 name 的第一个文件
+func (c *Context) UploadedFile(name string) (*UploadedFile, error) {
+	files, err := c.UploadedFiles(name)
+	if err != nil {
+		return nil, err
+	}
+	return files[0], nil
+}
+
+// UploadedFiles 返回表单中名为 name 的全部文件（多文件上传）
+func (c *Context) UploadedFiles(name string) ([]*UploadedFile, error) {
+	form, err := c.MultipartForm(0)
+	if err != nil {
+		return nil, err
+	}
+	headers := form.File[name]
+	if len(headers) == 0 {
+		return nil, ErrMissingFile
+	}
+	files := make([]*UploadedFile, len(headers))
+	for i, fh := range headers {
+		files[i] = &UploadedFile{
+			Filename:    filepath.Base(strings.ReplaceAll(fh.Filename, `\`, "/")),
+			Size:        fh.Size,
+			ContentType: fh.Header.Get("Content-Type"),
+			Header:      fh.Header,
+			fh:          fh,
+		}
+	}
+	c.uploads = append(c.uploads, files...)
+	return files, nil
+}
+
+// SaveUploadedFile 把上传的文件保存到 dst，目录不存在时自动创建。
+// dst 由调用方决定，不要直接使用客户端提供的文件名拼接路径
+func (c *Context) SaveUploadedFile(fh *multipart.FileHeader, dst string) error {
+	return saveFileHeader(fh, dst)
+}
+
+func saveFileHeader(fh *multipart.FileHeader, dst string) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, src); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,52 @@
+// vary.go
+package meego
+
+import (
+	"net/textproto"
+	"strings"
+)
+
+// AddVary 把请求头名称合并到 Vary 响应头：已有的名称不重复添加（不区分大小写），
+// "*" 表示响应随任意请求变化，覆盖其它名称。按请求头协商内容的中间件都应使用它，
+// 而不是直接设置 Vary，避免互相覆盖
+func (w *ResponseWriter) AddVary(fields ...string) {
+	current := w.header["Vary"]
+	if current == "*" {
+		return
+	}
+	existing := strings.Split(current, ",")
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if field == "*" {
+			w.SetHeader("Vary", "*")
+			return
+		}
+		field = textproto.CanonicalMIMEHeaderKey(field)
+		if !containsFold(existing, field) {
+			existing = append(existing, field)
+		}
+	}
+
+	names := existing[:0]
+	for _, name := range existing {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		w.SetHeader("Vary", strings.Join(names, ", "))
+	}
+}
+
+// containsFold 不区分大小写地查找 name，元素两端的空白忽略
+func containsFold(list []string, name string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package meego
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddVary(t *testing.T) {
+	s := New()
+	s.Use(CORSWithConfig(CORSConfig{AllowOrigins: []string{"http://a.test"}}))
+	s.Use(Sessions())
+	s.GET("/v", func(c *Context) {
+		c.Writer.AddVary("accept-encoding", "origin", "Accept-Encoding")
+		c.String(StatusOK, "ok")
+	})
+	s.GET("/any", func(c *Context) {
+		c.Writer.AddVary("*")
+		c.Writer.AddVary("Accept")
+		c.String(StatusOK, "ok")
+	})
+
+	if resp := doRaw(s, "GET /v HTTP/1.1\r\nOrigin: http://a.test\r\n\r\n"); !strings.Contains(resp, "Vary: Origin, Cookie, Accept-Encoding\r\n") {
+		t.Fatalf("accumulated vary: %q", resp)
+	}
+	if resp := doRaw(s, "GET /any HTTP/1.1\r\n\r\n"); !strings.Contains(resp, "Vary: *\r\n") {
+		t.Fatalf("vary *: %q", resp)
+	}
+}
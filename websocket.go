@@ -0,0 +1,637 @@
+// websocket.go
+package meego
+
+import (
+	"bufio"
+	"compress/flate"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebSocket 消息类型（RFC 6455 5.2）
+const (
+	continuationFrame = 0
+	TextMessage       = 1
+	BinaryMessage     = 2
+	CloseMessage      = 8
+	PingMessage       = 9
+	PongMessage       = 10
+)
+
+// WebSocket 关闭码（RFC 6455 7.4.1）
+const (
+	CloseNormalClosure    = 1000
+	CloseGoingAway        = 1001
+	CloseProtocolError    = 1002
+	CloseUnsupportedData  = 1003
+	CloseMessageTooBig    = 1009
+	CloseInternalError    = 1011
+	closeNoStatusReceived = 1005
+)
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	// ErrSendQueueFull 连接的发送队列已满（慢消费者）
+	ErrSendQueueFull = errors.New("websocket send queue full")
+	// ErrWebSocketClosed 连接已关闭
+	ErrWebSocketClosed = errors.New("websocket closed")
+	// ErrReadLimit 消息超过 ReadLimit
+	ErrReadLimit = errors.New("websocket message exceeds read limit")
+)
+
+// DefaultWebSocketReadLimit 默认单条消息（解压后）大小上限
+const DefaultWebSocketReadLimit = 16 << 20
+
+// CloseError 对端发送的关闭帧
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("websocket closed: %d %s", e.Code, e.Text)
+}
+
+// WebSocketConfig WebSocket 配置
+type WebSocketConfig struct {
+	// CheckOrigin 校验 Origin，为空时只允许同源请求（或没有 Origin 的非浏览器客户端）
+	CheckOrigin   func(c *Context) bool
+	Subprotocols  []string      // 支持的子协议，按优先级排列
+	SendQueueSize int           // 每个连接的发送队列长度，默认 256
+	WriteTimeout  time.Duration // 单帧写超时，默认 10s
+
+	// ReadLimit 单条消息（合并分片、解压后）的大小上限，超出时以 1009 关闭，
+	// 默认 DefaultWebSocketReadLimit
+	ReadLimit int64
+	// FragmentSize 发送时超过该大小的消息拆分为多个分片，0 表示不拆分
+	FragmentSize int
+
+	// EnableCompression 客户端支持时协商 permessage-deflate（无上下文接管）
+	EnableCompression bool
+	// CompressionThreshold 小于该大小的消息不压缩，默认 256
+	CompressionThreshold int
+	// CompressionLevel flate 压缩级别，默认 flate.BestSpeed
+	CompressionLevel int
+
+	// PingInterval 自动发送 Ping 的间隔，默认 30s，负数表示关闭
+	PingInterval time.Duration
+	// PongTimeout 在该时间内没有收到任何帧（包括 Pong）时关闭连接，默认 60s，负数表示关闭
+	PongTimeout time.Duration
+}
+
+// WebSocketHandler 处理升级后的连接，返回时连接关闭
+type WebSocketHandler func(ws *WebSocketConn)
+
+// WebSocket 返回升级到 WebSocket 的路由处理器。它是普通的处理器，
+// 全局和路由组中间件（认证、日志等）在升级之前照常执行：
+//
+//	api.Use(Auth(token))
+//	api.GET("/ws", meego.WebSocket(func(ws *meego.WebSocketConn) { ... }))
+func WebSocket(handler WebSocketHandler) HandlerFunc {
+	return WebSocketWithConfig(WebSocketConfig{}, handler)
+}
+
+// WebSocketWithConfig 使用自定义配置的 WebSocket 处理器
+func WebSocketWithConfig(cfg WebSocketConfig, handler WebSocketHandler) HandlerFunc {
+	if cfg.CheckOrigin == nil {
+		cfg.CheckOrigin = sameOrigin
+	}
+	if cfg.SendQueueSize <= 0 {
+		cfg.SendQueueSize = 256
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	if cfg.ReadLimit <= 0 {
+		cfg.ReadLimit = DefaultWebSocketReadLimit
+	}
+	if cfg.CompressionThreshold <= 0 {
+		cfg.CompressionThreshold = 256
+	}
+	if cfg.CompressionLevel == 0 {
+		cfg.CompressionLevel = flate.BestSpeed
+	}
+	if cfg.PingInterval == 0 {
+		cfg.PingInterval = 30 * time.Second
+	}
+	if cfg.PongTimeout == 0 {
+		cfg.PongTimeout = 60 * time.Second
+	}
+
+	return func(c *Context) {
+		ws, err := upgradeWebSocket(c, &cfg)
+		if err != nil {
+			c.Logger().Debug().Err(err).Msg("websocket upgrade failed")
+			return
+		}
+		defer ws.shutdown()
+		if c.server != nil {
+			defer c.server.trackLongLived(ws)()
+		}
+		handler(ws)
+	}
+}
+
+// sameOrigin 默认的 Origin 校验
+func sameOrigin(c *Context) bool {
+	origin := c.Request.GetHeader("Origin")
+	if origin == "" {
+		return true
+	}
+	_, host, ok := strings.Cut(origin, "://")
+	return ok && strings.EqualFold(host, c.Request.Host)
+}
+
+// upgradeWebSocket 校验握手请求并发送 101 响应
+func upgradeWebSocket(c *Context, cfg *WebSocketConfig) (*WebSocketConn, error) {
+	req := c.Request
+	fail := func(code int, msg string) (*WebSocketConn, error) {
+		if code == StatusUpgradeRequired {
+			c.Writer.SetHeader("Sec-WebSocket-Version", "13")
+		}
+		c.Writer.Status(code).JSON(JSON{"error": msg, "code": code})
+		return nil, errors.New(msg)
+	}
+
+	if req.Method != "GET" ||
+		!headerContainsToken(req.GetHeader("Connection"), "upgrade") ||
+		!headerContainsToken(req.GetHeader("Upgrade"), "websocket") {
+		return fail(StatusBadRequest, "not a websocket handshake")
+	}
+	if req.GetHeader("Sec-WebSocket-Version") != "13" {
+		return fail(StatusUpgradeRequired, "unsupported websocket version")
+	}
+	key := req.GetHeader("Sec-WebSocket-Key")
+	if key == "" {
+		return fail(StatusBadRequest, "missing Sec-WebSocket-Key")
+	}
+	if !cfg.CheckOrigin(c) {
+		return fail(StatusForbidden, "origin not allowed")
+	}
+
+	subprotocol := selectSubprotocol(req.GetHeader("Sec-WebSocket-Protocol"), cfg.Subprotocols)
+	compress := cfg.EnableCompression && offersDeflate(req.GetHeader("Sec-WebSocket-Extensions"))
+
+	conn, reader, err := c.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	b.WriteString("Sec-WebSocket-Accept: " + websocketAccept(key) + "\r\n")
+	if subprotocol != "" {
+		b.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	if compress {
+		// 不保留压缩上下文，每条消息独立压缩，连接不需要常驻压缩状态
+		b.WriteString("Sec-WebSocket-Extensions: permessage-deflate; server_no_context_takeover; client_no_context_takeover\r\n")
+	}
+	b.WriteString("\r\n")
+
+	conn.SetWriteDeadline(time.Now().Add(cfg.WriteTimeout))
+	if _, err := conn.Write([]byte(b.String())); err != nil {
+		return nil, err
+	}
+	conn.SetWriteDeadline(time.Time{})
+
+	ws := &WebSocketConn{
+		ID:          newID(IDConnection),
+		Subprotocol: subprotocol,
+		ctx:         c,
+		conn:        conn,
+		reader:      reader,
+		cfg:         cfg,
+		compress:    compress,
+		send:        make(chan wsMessage, cfg.SendQueueSize),
+		closed:      make(chan struct{}),
+		writerDone:  make(chan struct{}),
+	}
+	ws.extendReadDeadline()
+	go ws.writeLoop()
+	return ws, nil
+}
+
+// websocketAccept 计算 Sec-WebSocket-Accept
+func websocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func selectSubprotocol(header string, supported []string) string {
+	for _, want := range supported {
+		for _, p := range strings.Split(header, ",") {
+			if strings.TrimSpace(p) == want {
+				return want
+			}
+		}
+	}
+	return ""
+}
+
+// offersDeflate 客户端是否提供 permessage-deflate 扩展
+func offersDeflate(header string) bool {
+	for _, ext := range strings.Split(header, ",") {
+		name, _, _ := strings.Cut(ext, ";")
+		if strings.EqualFold(strings.TrimSpace(name), "permessage-deflate") {
+			return true
+		}
+	}
+	return false
+}
+
+// headerContainsToken 判断逗号分隔的头部值是否包含 token（大小写不敏感）
+func headerContainsToken(value, token string) bool {
+	for _, v := range strings.Split(value, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), token) {
+			return true
+		}
+	}
+	return false
+}
+
+type wsMessage struct {
+	opcode byte
+	data   []byte
+}
+
+// WebSocketConn WebSocket 连接。ReadMessage 只能在一个协程中调用；
+// WriteMessage、Send、Close 可以并发调用
+type WebSocketConn struct {
+	ID          string // 连接标识
+	Subprotocol string // 协商的子协议
+
+	ctx    *Context
+	conn   net.Conn
+	reader *bufio.Reader
+	cfg    *WebSocketConfig
+	// compress 已协商 permessage-deflate
+	compress bool
+
+	writeMu sync.Mutex // 单帧写锁
+	msgMu   sync.Mutex // 分片消息写锁，保证同一消息的分片连续发送
+	send    chan wsMessage
+
+	closeOnce  sync.Once
+	closed     chan struct{}
+	writerDone chan struct{}
+
+	mu      sync.Mutex
+	onClose []func()
+}
+
+// Context 返回升级请求的上下文，中间件设置的值（如认证用户）可以通过它读取，
+// 只在处理器返回之前有效
+func (ws *WebSocketConn) Context() *Context {
+	return ws.ctx
+}
+
+// RemoteAddr 对端地址
+func (ws *WebSocketConn) RemoteAddr() net.Addr {
+	return ws.conn.RemoteAddr()
+}
+
+// ReadMessage 读取下一条数据消息：合并分片、解压缩，自动回复 Ping；
+// 收到关闭帧时返回 *CloseError，超过 PongTimeout 没有收到任何帧时关闭连接
+func (ws *WebSocketConn) ReadMessage() (messageType int, data []byte, err error) {
+	var (
+		msgType    byte
+		compressed bool
+		buf        []byte
+	)
+	for {
+		f, err := ws.readFrame(ws.cfg.ReadLimit - int64(len(buf)))
+		if err != nil {
+			if isTimeoutError(err) {
+				ws.CloseWithCode(CloseGoingAway, "pong timeout")
+			}
+			return 0, nil, err
+		}
+		ws.extendReadDeadline()
+
+		switch f.opcode {
+		case PingMessage:
+			ws.writeFrame(true, false, PongMessage, f.payload)
+			continue
+		case PongMessage:
+			continue
+		case CloseMessage:
+			ce := parseCloseFrame(f.payload)
+			code := ce.Code
+			if code == closeNoStatusReceived {
+				code = CloseNormalClosure
+			}
+			ws.CloseWithCode(code, "")
+			return 0, nil, ce
+		case TextMessage, BinaryMessage:
+			if msgType != 0 {
+				return 0, nil, ws.protocolError("new message before previous one finished")
+			}
+			if f.rsv1 && !ws.compress {
+				return 0, nil, ws.protocolError("unexpected RSV1")
+			}
+			msgType, compressed, buf = f.opcode, f.rsv1, f.payload
+		case continuationFrame:
+			if msgType == 0 || f.rsv1 {
+				return 0, nil, ws.protocolError("unexpected continuation frame")
+			}
+			buf = append(buf, f.payload...)
+		default:
+			return 0, nil, ws.protocolError("unexpected opcode")
+		}
+
+		if !f.fin {
+			continue
+		}
+		if compressed {
+			if buf, err = ws.inflate(buf); err != nil {
+				return 0, nil, err
+			}
+		}
+		return int(msgType), buf, nil
+	}
+}
+
+// ReadJSON 读取一条消息并解析为 JSON
+func (ws *WebSocketConn) ReadJSON(v interface{}) error {
+	_, data, err := ws.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return ws.ctx.Writer.json.Unmarshal(data, v)
+}
+
+// WriteMessage 同步写出一条消息
+func (ws *WebSocketConn) WriteMessage(messageType int, data []byte) error {
+	select {
+	case <-ws.closed:
+		if messageType != CloseMessage {
+			return ErrWebSocketClosed
+		}
+	default:
+	}
+	if messageType >= CloseMessage {
+		return ws.writeFrame(true, false, byte(messageType), data)
+	}
+	return ws.writeData(byte(messageType), data)
+}
+
+// WriteJSON 同步写出一条 JSON 文本消息
+func (ws *WebSocketConn) WriteJSON(v interface{}) error {
+	data, err := ws.ctx.Writer.json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return ws.WriteMessage(TextMessage, data)
+}
+
+// Send 把消息放入发送队列，由后台协程写出，不阻塞调用方；
+// 队列已满时返回 ErrSendQueueFull。data 在调用后不能再修改
+func (ws *WebSocketConn) Send(messageType int, data []byte) error {
+	select {
+	case <-ws.closed:
+		return ErrWebSocketClosed
+	default:
+	}
+	select {
+	case ws.send <- wsMessage{opcode: byte(messageType), data: data}:
+		return nil
+	default:
+		return ErrSendQueueFull
+	}
+}
+
+// Close 正常关闭连接
+func (ws *WebSocketConn) Close() error {
+	return ws.CloseWithCode(CloseNormalClosure, "")
+}
+
+// CloseWithCode 发送关闭帧并关闭连接
+func (ws *WebSocketConn) CloseWithCode(code int, reason string) error {
+	var err error
+	ws.closeOnce.Do(func() {
+		payload := make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+		err = ws.writeFrame(true, false, CloseMessage, payload)
+
+		close(ws.closed)
+		ws.conn.Close()
+
+		ws.mu.Lock()
+		callbacks := ws.onClose
+		ws.onClose = nil
+		ws.mu.Unlock()
+		for _, fn := range callbacks {
+			fn()
+		}
+	})
+	return err
+}
+
+// OnClose 注册连接关闭时的回调
+func (ws *WebSocketConn) OnClose(fn func()) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.onClose = append(ws.onClose, fn)
+}
+
+// shutdown 处理器返回后关闭连接并等待写协程退出
+func (ws *WebSocketConn) shutdown() {
+	ws.CloseWithCode(CloseGoingAway, "")
+	<-ws.writerDone
+}
+
+// drain 服务器关闭时发送 Going Away 关闭帧，ReadMessage 随之返回错误，处理器退出
+func (ws *WebSocketConn) drain() {
+	ws.CloseWithCode(CloseGoingAway, "server shutting down")
+}
+
+// forceClose 排空窗口结束后直接关闭底层连接
+func (ws *WebSocketConn) forceClose() {
+	ws.conn.Close()
+}
+
+// writeLoop 写出发送队列中的消息，并定时发送 Ping
+func (ws *WebSocketConn) writeLoop() {
+	defer close(ws.writerDone)
+
+	var ping <-chan time.Time
+	if ws.cfg.PingInterval > 0 {
+		ticker := clock().NewTicker(ws.cfg.PingInterval)
+		defer ticker.Stop()
+		ping = ticker.C()
+	}
+
+	for {
+		select {
+		case msg := <-ws.send:
+			if err := ws.writeData(msg.opcode, msg.data); err != nil {
+				ws.CloseWithCode(CloseInternalError, "")
+				return
+			}
+		case <-ping:
+			if err := ws.writeFrame(true, false, PingMessage, nil); err != nil {
+				ws.CloseWithCode(CloseGoingAway, "")
+				return
+			}
+		case <-ws.closed:
+			return
+		}
+	}
+}
+
+// extendReadDeadline 收到帧后延长读超时
+func (ws *WebSocketConn) extendReadDeadline() {
+	if ws.cfg.PongTimeout > 0 {
+		ws.conn.SetReadDeadline(wallTime(now().Add(ws.cfg.PongTimeout)))
+	}
+}
+
+func (ws *WebSocketConn) protocolError(reason string) error {
+	ws.CloseWithCode(CloseProtocolError, reason)
+	return ErrWebSocketClosed
+}
+
+type wsFrame struct {
+	fin     bool
+	rsv1    bool
+	opcode  byte
+	payload []byte
+}
+
+// readFrame 读取一帧，负载超过 limit 时以 1009 关闭。客户端发来的帧必须带掩码
+func (ws *WebSocketConn) readFrame(limit int64) (f wsFrame, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(ws.reader, head[:]); err != nil {
+		return
+	}
+	f.fin = head[0]&0x80 != 0
+	f.rsv1 = head[0]&0x40 != 0
+	f.opcode = head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+	length := uint64(head[1] & 0x7f)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(ws.reader, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(ws.reader, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if !masked {
+		return f, ws.protocolError("client frames must be masked")
+	}
+	if head[0]&0x30 != 0 {
+		return f, ws.protocolError("unexpected RSV bits")
+	}
+	if f.opcode >= CloseMessage && (length > 125 || !f.fin || f.rsv1) {
+		return f, ws.protocolError("invalid control frame")
+	}
+	if f.opcode < CloseMessage && length > uint64(max(limit, 0)) {
+		ws.CloseWithCode(CloseMessageTooBig, "")
+		return f, ErrReadLimit
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(ws.reader, mask[:]); err != nil {
+		return
+	}
+	f.payload = make([]byte, length)
+	if _, err = io.ReadFull(ws.reader, f.payload); err != nil {
+		return
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+// writeData 写出数据消息，按配置压缩和分片
+func (ws *WebSocketConn) writeData(opcode byte, data []byte) error {
+	rsv1 := false
+	if ws.compress && len(data) >= ws.cfg.CompressionThreshold {
+		compressed, err := deflateMessage(data, ws.cfg.CompressionLevel)
+		if err != nil {
+			return err
+		}
+		data, rsv1 = compressed, true
+	}
+
+	size := ws.cfg.FragmentSize
+	if size <= 0 || len(data) <= size {
+		return ws.writeFrame(true, rsv1, opcode, data)
+	}
+
+	// 分片之间允许插入控制帧，但不能插入其它数据消息
+	ws.msgMu.Lock()
+	defer ws.msgMu.Unlock()
+	for first := true; len(data) > 0; first = false {
+		n := min(size, len(data))
+		op := byte(continuationFrame)
+		if first {
+			op = opcode
+		}
+		if err := ws.writeFrame(n == len(data), rsv1 && first, op, data[:n]); err != nil {
+			return err
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// writeFrame 写出一个不带掩码的帧
+func (ws *WebSocketConn) writeFrame(fin, rsv1 bool, opcode byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = opcode
+	if fin {
+		header[0] |= 0x80
+	}
+	if rsv1 {
+		header[0] |= 0x40
+	}
+	switch n := len(payload); {
+	case n < 126:
+		header[1] = byte(n)
+	case n <= 0xffff:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	ws.conn.SetWriteDeadline(time.Now().Add(ws.cfg.WriteTimeout))
+	buffers := net.Buffers{header, payload}
+	_, err := buffers.WriteTo(ws.conn)
+	return err
+}
+
+func parseCloseFrame(payload []byte) *CloseError {
+	if len(payload) < 2 {
+		return &CloseError{Code: closeNoStatusReceived}
+	}
+	return &CloseError{
+		Code: int(binary.BigEndian.Uint16(payload)),
+		Text: string(payload[2:]),
+	}
+}
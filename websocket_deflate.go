@@ -0,0 +1,67 @@
+// websocket_deflate.go
+package meego
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"sync"
+)
+
+// deflateTail 每条压缩消息末尾被省略的空块（RFC 7692 7.2.1）
+var deflateTail = []byte{0x00, 0x00, 0xff, 0xff}
+
+var (
+	flateWriterPools sync.Map // level -> *sync.Pool
+	flateReaderPool  sync.Pool
+)
+
+// deflateMessage 压缩一条消息并去掉末尾的空块
+func deflateMessage(data []byte, level int) ([]byte, error) {
+	poolAny, _ := flateWriterPools.LoadOrStore(level, &sync.Pool{})
+	pool := poolAny.(*sync.Pool)
+
+	var buf bytes.Buffer
+	fw, _ := pool.Get().(*flate.Writer)
+	if fw == nil {
+		var err error
+		if fw, err = flate.NewWriter(&buf, level); err != nil {
+			return nil, err
+		}
+	} else {
+		fw.Reset(&buf)
+	}
+	defer pool.Put(fw)
+
+	if _, err := fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := fw.Flush(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), deflateTail), nil
+}
+
+// inflate 解压一条消息，结果超过 ReadLimit 时以 1009 关闭
+func (ws *WebSocketConn) inflate(data []byte) ([]byte, error) {
+	src := io.MultiReader(bytes.NewReader(data), bytes.NewReader(deflateTail))
+
+	fr, _ := flateReaderPool.Get().(io.ReadCloser)
+	if fr == nil {
+		fr = flate.NewReader(src)
+	} else {
+		fr.(flate.Resetter).Reset(src, nil)
+	}
+	defer flateReaderPool.Put(fr)
+
+	out, err := io.ReadAll(io.LimitReader(fr, ws.cfg.ReadLimit+1))
+	if err != nil && err != io.ErrUnexpectedEOF {
+		ws.CloseWithCode(CloseProtocolError, "invalid compressed data")
+		return nil, err
+	}
+	if int64(len(out)) > ws.cfg.ReadLimit {
+		ws.CloseWithCode(CloseMessageTooBig, "")
+		return nil, ErrReadLimit
+	}
+	return out, nil
+}
@@ -0,0 +1,134 @@
+// websocket_hub.go
+package meego
+
+import (
+	"sync"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Hub 管理 WebSocket 房间（频道），连接关闭时自动退出所有房间
+type Hub struct {
+	mu    sync.RWMutex
+	rooms map[string]map[*WebSocketConn]struct{}
+	// joined 记录连接所在的房间，用于关闭时清理
+	joined map[*WebSocketConn]map[string]struct{}
+}
+
+// NewHub 创建房间管理器
+func NewHub() *Hub {
+	return &Hub{
+		rooms:  make(map[string]map[*WebSocketConn]struct{}),
+		joined: make(map[*WebSocketConn]map[string]struct{}),
+	}
+}
+
+// Join 加入房间
+func (h *Hub) Join(room string, ws *WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	members := h.rooms[room]
+	if members == nil {
+		members = make(map[*WebSocketConn]struct{})
+		h.rooms[room] = members
+	}
+	members[ws] = struct{}{}
+
+	rooms := h.joined[ws]
+	if rooms == nil {
+		rooms = make(map[string]struct{})
+		h.joined[ws] = rooms
+		ws.OnClose(func() { h.LeaveAll(ws) })
+	}
+	rooms[room] = struct{}{}
+}
+
+// Leave 离开房间
+func (h *Hub) Leave(room string, ws *WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leaveLocked(room, ws)
+}
+
+// LeaveAll 离开所有房间
+func (h *Hub) LeaveAll(ws *WebSocketConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for room := range h.joined[ws] {
+		h.leaveLocked(room, ws)
+	}
+}
+
+func (h *Hub) leaveLocked(room string, ws *WebSocketConn) {
+	if members := h.rooms[room]; members != nil {
+		delete(members, ws)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+	if rooms := h.joined[ws]; rooms != nil {
+		delete(rooms, room)
+		if len(rooms) == 0 {
+			delete(h.joined, ws)
+		}
+	}
+}
+
+// Broadcast 向房间内除 except 外的所有连接发送消息，返回成功入队的连接数。
+// 消息通过各连接的发送队列异步写出，慢消费者不会阻塞广播
+func (h *Hub) Broadcast(room string, messageType int, data []byte, except ...*WebSocketConn) int {
+	h.mu.RLock()
+	targets := make([]*WebSocketConn, 0, len(h.rooms[room]))
+	for ws := range h.rooms[room] {
+		targets = append(targets, ws)
+	}
+	h.mu.RUnlock()
+
+	sent := 0
+	for _, ws := range targets {
+		if isExcepted(ws, except) {
+			continue
+		}
+		if ws.Send(messageType, data) == nil {
+			sent++
+		}
+	}
+	return sent
+}
+
+// BroadcastJSON 向房间广播 JSON 文本消息
+func (h *Hub) BroadcastJSON(room string, v interface{}, except ...*WebSocketConn) (int, error) {
+	data, err := jsoniter.ConfigCompatibleWithStandardLibrary.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+	return h.Broadcast(room, TextMessage, data, except...), nil
+}
+
+// Members 返回房间内的连接数
+func (h *Hub) Members(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Rooms 返回连接所在的房间
+func (h *Hub) Rooms(ws *WebSocketConn) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	rooms := make([]string, 0, len(h.joined[ws]))
+	for room := range h.joined[ws] {
+		rooms = append(rooms, room)
+	}
+	return rooms
+}
+
+func isExcepted(ws *WebSocketConn, except []*WebSocketConn) bool {
+	for _, e := range except {
+		if e == ws {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package meego
+
+import (
+	"bufio"
+	"compress/flate"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// maskedFrame 构造客户端帧
+func maskedFrame(opcode byte, payload []byte) []byte {
+	return rawFrame(0x80|opcode, payload)
+}
+
+// rawFrame 构造带掩码的帧，first 为第一个字节（FIN/RSV/opcode）
+func rawFrame(first byte, payload []byte) []byte {
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{first, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+func TestWebSocketRoomBroadcast(t *testing.T) {
+	s := New()
+	hub := NewHub()
+	s.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) {
+			c.Set("user", "u1")
+			next(c)
+		}
+	})
+	done := make(chan struct{})
+	s.GET("/ws", WebSocket(func(ws *WebSocketConn) {
+		defer close(done)
+		hub.Join("lobby", ws)
+		for {
+			_, msg, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			user := ws.Context().Get("user").(string)
+			hub.Broadcast("lobby", TextMessage, append([]byte(user+":"), msg...))
+		}
+	}))
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go s.handleConnectionFast(server)
+
+	io.WriteString(client, "GET /ws HTTP/1.1\r\nHost: x\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	br := bufio.NewReader(client)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil || resp.StatusCode != StatusSwitchingProtocols {
+		t.Fatalf("handshake failed: %v %v", resp, err)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("bad accept key: %q", got)
+	}
+
+	client.Write(maskedFrame(TextMessage, []byte("hi")))
+	head := make([]byte, 2)
+	io.ReadFull(br, head)
+	body := make([]byte, head[1])
+	io.ReadFull(br, body)
+	if head[0] != 0x81 || string(body) != "u1:hi" {
+		t.Fatalf("unexpected broadcast frame: %x %q", head, body)
+	}
+
+	go io.Copy(io.Discard, br)
+	client.Write(maskedFrame(CloseMessage, []byte{0x03, 0xe8}))
+	<-done
+	if n := hub.Members("lobby"); n != 0 {
+		t.Fatalf("connection should leave rooms on close, %d left", n)
+	}
+}
+
+func TestWebSocketDeflateFragments(t *testing.T) {
+	s := New()
+	s.GET("/ws", WebSocketWithConfig(WebSocketConfig{
+		EnableCompression:    true,
+		CompressionThreshold: 1,
+	}, func(ws *WebSocketConn) {
+		_, msg, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		ws.WriteMessage(TextMessage, msg)
+	}))
+
+	client, server := net.Pipe()
+	defer client.Close()
+	go s.handleConnectionFast(server)
+
+	io.WriteString(client, "GET /ws HTTP/1.1\r\nHost: x\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Extensions: permessage-deflate; client_max_window_bits\r\n\r\n")
+	br := bufio.NewReader(client)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil || !strings.HasPrefix(resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate") {
+		t.Fatalf("deflate not negotiated: %v %v", resp, err)
+	}
+
+	// 压缩后的消息分两个分片发送：RSV1 只在第一个分片上
+	compressed, _ := deflateMessage([]byte("hello hello hello"), flate.BestSpeed)
+	client.Write(rawFrame(0x40|TextMessage, compressed[:3]))
+	client.Write(rawFrame(0x80|continuationFrame, compressed[3:]))
+
+	head := make([]byte, 2)
+	io.ReadFull(br, head)
+	body := make([]byte, head[1])
+	io.ReadFull(br, body)
+	if head[0] != 0xc1 {
+		t.Fatalf("expected compressed text frame, got %x", head)
+	}
+	ws := &WebSocketConn{cfg: &WebSocketConfig{ReadLimit: 1 << 10}}
+	if plain, err := ws.inflate(body); err != nil || string(plain) != "hello hello hello" {
+		t.Fatalf("unexpected echo %q: %v", plain, err)
+	}
+}